@@ -0,0 +1,21 @@
+package compression
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/wal-g/wal-g/internal/multistorage/consts"
+)
+
+const CompressionShortDescription = "Compression tools"
+
+var (
+	CompressionCmd = &cobra.Command{
+		Use:   "compression",
+		Short: CompressionShortDescription,
+	}
+	targetStorage string
+)
+
+func init() {
+	CompressionCmd.PersistentFlags().StringVarP(&targetStorage, "target", "t", consts.DefaultStorage,
+		"execute for specific failover storage (Postgres only)")
+}
@@ -0,0 +1,62 @@
+package compression
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	zstdcompression "github.com/wal-g/wal-g/internal/compression/zstd"
+	"github.com/wal-g/wal-g/internal/multistorage/exec"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	trainDictShortDescription = "Trains the zstd dictionary on the recently archived objects"
+	trainDictLongDescription  = "Reads the beginnings of the last compressed objects under the prefix (WAL by default), " +
+		"trains the zstd dictionary on them and uploads it to " + utility.ZstdDictsPath + ". " +
+		"Set WALG_ZSTD_DICTIONARY to LATEST or to the printed dictionary ID to compress WAL and oplog with it."
+)
+
+var (
+	trainDictMaxSamples int
+	trainDictSampleSize int64
+	trainDictSize       int
+)
+
+// trainDictCmd represents the train-dict command
+var trainDictCmd = &cobra.Command{
+	Use:   "train-dict [relative folder path]",
+	Short: trainDictShortDescription,
+	Long:  trainDictLongDescription,
+	Args:  cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		prefix := utility.WalPath
+		if len(args) > 0 {
+			prefix = args[0]
+		}
+
+		internal.ConfigureLimiters()
+
+		ctx := cmd.Context()
+		err := exec.OnStorage(ctx, targetStorage, func(folder storage.Folder) error {
+			meta, err := internal.TrainZstdDictionary(ctx, folder, prefix,
+				trainDictMaxSamples, trainDictSampleSize, trainDictSize)
+			if err != nil {
+				return err
+			}
+			tracelog.InfoLogger.Printf("Trained the zstd dictionary %d on %d objects\n", meta.ID, meta.SamplesCount)
+			return nil
+		})
+		tracelog.ErrorLogger.FatalOnError(err)
+	},
+}
+
+func init() {
+	trainDictCmd.Flags().IntVar(&trainDictMaxSamples, "max-samples", 64,
+		"number of the last archived objects to train on")
+	trainDictCmd.Flags().Int64Var(&trainDictSampleSize, "sample-size", 1<<20,
+		"number of the decompressed bytes to read from each object")
+	trainDictCmd.Flags().IntVar(&trainDictSize, "dict-size", zstdcompression.DefaultDictionarySize,
+		"maximum size of the dictionary in bytes")
+	CompressionCmd.AddCommand(trainDictCmd)
+}
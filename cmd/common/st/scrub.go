@@ -0,0 +1,58 @@
+package st
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/multistorage/exec"
+	"github.com/wal-g/wal-g/internal/storagetools"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+const (
+	scrubShortDescription = "Checks the integrity of the stored objects"
+	scrubLongDescription  = "Reads every object under the prefix, decrypts and decompresses the tar and stream objects " +
+		"and compares the checksums of the stored bytes with the ones recorded at upload time. " +
+		"The JSON report is printed to stdout. Network rate limit applies to the reading."
+)
+
+var scrubConcurrency int
+
+// scrubCmd represents the scrub command
+var scrubCmd = &cobra.Command{
+	Use:   "scrub [relative folder path]",
+	Short: scrubShortDescription,
+	Long:  scrubLongDescription,
+	Args:  cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		prefix := ""
+		if len(args) > 0 {
+			prefix = args[0]
+		}
+
+		concurrency := scrubConcurrency
+		if concurrency == 0 {
+			var err error
+			concurrency, err = conf.GetMaxDownloadConcurrency()
+			tracelog.ErrorLogger.FatalOnError(err)
+		}
+
+		internal.ConfigureLimiters()
+		crypter := internal.ConfigureCrypter()
+
+		ctx := cmd.Context()
+		err := exec.OnStorage(ctx, targetStorage, func(folder storage.Folder) error {
+			return storagetools.HandleScrub(ctx, folder, prefix, crypter, concurrency, os.Stdout)
+		})
+		tracelog.ErrorLogger.FatalOnError(err)
+	},
+}
+
+func init() {
+	scrubCmd.Flags().IntVarP(&scrubConcurrency, "concurrency", "c", 0,
+		"number of objects to check concurrently, WALG_DOWNLOAD_CONCURRENCY is used by default")
+	StorageToolsCmd.AddCommand(scrubCmd)
+}
@@ -1,6 +1,8 @@
 package etcd
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
@@ -8,7 +10,22 @@ import (
 	"github.com/wal-g/wal-g/internal/databases/etcd"
 )
 
-const backupFetchShortDescription = "Fetches desired backup from storage"
+const (
+	backupFetchShortDescription = "Fetches desired backup from storage"
+
+	untilRevisionFlag        = "until-revision"
+	untilRevisionDescription = "Replay the archived WAL after the backup up to this revision"
+	untilTimeFlag            = "until-time"
+	untilTimeDescription     = "Replay the archived WAL after the backup up to the last checkpoint before this time in RFC3339 format"
+	etcdBinaryFlag           = "etcd-binary"
+	etcdBinaryDescription    = "The etcd binary to start on the restored data directory to replay the archived WAL"
+)
+
+var (
+	untilRevision int64
+	untilTime     string
+	etcdBinary    string
+)
 
 // backupFetchCmd represents the streamFetch command
 var backupFetchCmd = &cobra.Command{
@@ -17,6 +34,9 @@ var backupFetchCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	PreRun: func(cmd *cobra.Command, args []string) {
 		conf.RequiredSettings[conf.NameStreamRestoreCmd] = true
+		if untilRevision != 0 || untilTime != "" {
+			conf.RequiredSettings[conf.ETCDMemberDataDirectory] = true
+		}
 		err := internal.AssertRequiredSettingsSet()
 		tracelog.ErrorLogger.FatalOnError(err)
 	},
@@ -31,10 +51,33 @@ var backupFetchCmd = &cobra.Command{
 		tracelog.ErrorLogger.FatalOnError(err)
 		targetBackupSelector, err := internal.NewBackupNameSelector(args[0], true)
 		tracelog.ErrorLogger.FatalOnError(err)
-		etcd.HandleBackupFetch(ctx, storage.RootFolder(), targetBackupSelector, restoreCmd)
+
+		if untilRevision == 0 && untilTime == "" {
+			etcd.HandleBackupFetch(ctx, storage.RootFolder(), targetBackupSelector, restoreCmd)
+			return
+		}
+
+		target := etcd.RecoveryTarget{Revision: untilRevision}
+		if untilTime != "" {
+			targetTime, err := time.Parse(time.RFC3339, untilTime)
+			tracelog.ErrorLogger.FatalfOnError("Failed to parse the recovery target time: %v", err)
+			target.Time = &targetTime
+		}
+
+		dataDir, err := conf.GetRequiredSetting(conf.ETCDMemberDataDirectory)
+		tracelog.ErrorLogger.FatalOnError(err)
+		walDir, _ := conf.GetSetting(conf.ETCDWalDirectory)
+		dirs := etcd.MemberDirs{DataDir: dataDir, WalDir: walDir}
+
+		err = etcd.HandleBackupFetchUntil(ctx, storage.RootFolder(), targetBackupSelector, restoreCmd, target, dirs, etcdBinary)
+		tracelog.ErrorLogger.FatalOnError(err)
 	},
 }
 
 func init() {
+	backupFetchCmd.Flags().Int64Var(&untilRevision, untilRevisionFlag, 0, untilRevisionDescription)
+	backupFetchCmd.Flags().StringVar(&untilTime, untilTimeFlag, "", untilTimeDescription)
+	backupFetchCmd.Flags().StringVar(&etcdBinary, etcdBinaryFlag, "etcd", etcdBinaryDescription)
+	backupFetchCmd.MarkFlagsMutuallyExclusive(untilRevisionFlag, untilTimeFlag)
 	cmd.AddCommand(backupFetchCmd)
 }
@@ -9,6 +9,7 @@ import (
 
 var confirmed = false
 var deleteTargetUserData = ""
+var retainPolicy internal.GFSRetentionPolicy
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
@@ -31,6 +32,21 @@ var deleteRetainCmd = &cobra.Command{
 	Run:       runDeleteRetain,
 }
 
+var deleteRetainPolicyCmd = &cobra.Command{
+	Use:     internal.DeleteRetainPolicyUsageExample,
+	Example: internal.DeleteRetainPolicyExamples,
+	Args:    internal.DeleteRetainPolicyArgsValidator,
+	Run:     runDeleteRetainPolicy,
+}
+
+var deleteAutoCmd = &cobra.Command{
+	Use:   internal.DeleteAutoUsage,
+	Short: internal.DeleteAutoShortDescription,
+	Long:  internal.DeleteAutoLongDescription,
+	Args:  cobra.NoArgs,
+	Run:   runDeleteAuto,
+}
+
 var deleteEverythingCmd = &cobra.Command{
 	Use:       internal.DeleteEverythingUsageExample,
 	Example:   internal.DeleteEverythingExamples,
@@ -66,6 +82,29 @@ func runDeleteRetain(cmd *cobra.Command, args []string) {
 	deleteHandler.HandleDeleteRetain(cmd.Context(), args, confirmed)
 }
 
+func runDeleteRetainPolicy(cmd *cobra.Command, args []string) {
+	storage, err := internal.ConfigureStorage(cmd.Context())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler, err := etcd.NewEtcdDeleteHandler(cmd.Context(), storage.RootFolder())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteRetainPolicy(cmd.Context(), retainPolicy, confirmed)
+}
+
+func runDeleteAuto(cmd *cobra.Command, args []string) {
+	retention, err := internal.GetRetentionConfig()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	storage, err := internal.ConfigureStorage(cmd.Context())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler, err := etcd.NewEtcdDeleteHandler(cmd.Context(), storage.RootFolder())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteAuto(cmd.Context(), retention, confirmed)
+}
+
 func runDeleteEverything(cmd *cobra.Command, args []string) {
 	storage, err := internal.ConfigureStorage(cmd.Context())
 	tracelog.ErrorLogger.FatalOnError(err)
@@ -93,7 +132,8 @@ func init() {
 
 	deleteTargetCmd.Flags().StringVar(
 		&deleteTargetUserData, internal.DeleteTargetUserDataFlag, "", internal.DeleteTargetUserDataDescription)
+	internal.AddGFSRetentionPolicyFlags(deleteRetainPolicyCmd.Flags(), &retainPolicy, "")
 
-	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteEverythingCmd, deleteTargetCmd)
+	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteRetainPolicyCmd, deleteAutoCmd, deleteEverythingCmd, deleteTargetCmd)
 	deleteCmd.PersistentFlags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup deletion")
 }
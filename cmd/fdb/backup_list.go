@@ -4,23 +4,42 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/databases/fdb"
 	"github.com/wal-g/wal-g/utility"
 )
 
-const backupListShortDescription = "Prints available backups"
+const (
+	backupListShortDescription = "Prints available backups"
+	PrettyFlag                 = "pretty"
+	JSONFlag                   = "json"
+	DetailFlag                 = "detail"
+)
 
-// backupListCmd represents the backupList command
-var backupListCmd = &cobra.Command{
-	Use:   "backup-list",
-	Short: backupListShortDescription,
-	Args:  cobra.NoArgs,
-	Run: func(cmd *cobra.Command, args []string) {
-		storage, err := internal.ConfigureStorage(cmd.Context())
-		tracelog.ErrorLogger.FatalOnError(err)
-		internal.HandleDefaultBackupList(cmd.Context(), storage.RootFolder().GetSubFolder(utility.BaseBackupPath), false, false)
-	},
-}
+var (
+	// backupListCmd represents the backupList command
+	backupListCmd = &cobra.Command{
+		Use:   "backup-list",
+		Short: backupListShortDescription,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			storage, err := internal.ConfigureStorage(cmd.Context())
+			tracelog.ErrorLogger.FatalOnError(err)
+			if detail {
+				fdb.HandleDetailedBackupList(cmd.Context(), storage.RootFolder().GetSubFolder(utility.BaseBackupPath), pretty, json)
+			} else {
+				internal.HandleDefaultBackupList(cmd.Context(), storage.RootFolder().GetSubFolder(utility.BaseBackupPath), pretty, json)
+			}
+		},
+	}
+	json   = false
+	pretty = false
+	detail = false
+)
 
 func init() {
 	cmd.AddCommand(backupListCmd)
+
+	backupListCmd.Flags().BoolVar(&pretty, PrettyFlag, false, "Prints more readable output")
+	backupListCmd.Flags().BoolVar(&json, JSONFlag, false, "Prints output in json format")
+	backupListCmd.Flags().BoolVar(&detail, DetailFlag, false, "Prints extra backup details")
 }
@@ -2,6 +2,7 @@ package fdb
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
@@ -9,7 +10,14 @@ import (
 	"github.com/wal-g/wal-g/utility"
 )
 
-const backupPushShortDescription = "Pushes backup to storage"
+const (
+	backupPushShortDescription = "Pushes backup to storage"
+
+	addUserDataFlag = "add-user-data"
+	permanentFlag   = "permanent"
+
+	permanentShorthand = "p"
+)
 
 // backupPushCmd represents the backupPush command
 var backupPushCmd = &cobra.Command{
@@ -24,7 +32,12 @@ var backupPushCmd = &cobra.Command{
 
 		backupCmd, err := internal.GetCommandSettingContext(cmd.Context(), conf.NameStreamCreateCmd)
 		tracelog.ErrorLogger.FatalOnError(err)
-		fdb.HandleBackupPush(cmd.Context(), uploader, backupCmd)
+
+		if userDataRaw == "" {
+			userDataRaw = viper.GetString(conf.SentinelUserDataSetting)
+		}
+
+		fdb.HandleBackupPush(cmd.Context(), uploader, backupCmd, permanent, userDataRaw)
 	},
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		conf.RequiredSettings[conf.NameStreamCreateCmd] = true
@@ -33,6 +46,16 @@ var backupPushCmd = &cobra.Command{
 	},
 }
 
+var (
+	userDataRaw = ""
+	permanent   = false
+)
+
 func init() {
 	cmd.AddCommand(backupPushCmd)
+
+	backupPushCmd.Flags().BoolVarP(&permanent, permanentFlag, permanentShorthand,
+		false, "Pushes permanent backup")
+	backupPushCmd.Flags().StringVar(&userDataRaw, addUserDataFlag,
+		"", "Write the provided user data to the backup sentinel and metadata files.")
 }
@@ -6,11 +6,11 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
-	"github.com/wal-g/wal-g/pkg/storages/storage"
-	"github.com/wal-g/wal-g/utility"
+	"github.com/wal-g/wal-g/internal/databases/fdb"
 )
 
 var confirmed = false
+var retainPolicy internal.GFSRetentionPolicy
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
@@ -40,6 +40,21 @@ var deleteRetainCmd = &cobra.Command{
 	},
 }
 
+var deleteRetainPolicyCmd = &cobra.Command{
+	Use:     internal.DeleteRetainPolicyUsageExample,
+	Example: internal.DeleteRetainPolicyExamples,
+	Args:    internal.DeleteRetainPolicyArgsValidator,
+	Run:     runDeleteRetainPolicy,
+}
+
+var deleteAutoCmd = &cobra.Command{
+	Use:   internal.DeleteAutoUsage,
+	Short: internal.DeleteAutoShortDescription,
+	Long:  internal.DeleteAutoLongDescription,
+	Args:  cobra.NoArgs,
+	Run:   runDeleteAuto,
+}
+
 var deleteEverythingCmd = &cobra.Command{
 	Use:       internal.DeleteEverythingUsageExample,
 	Example:   internal.DeleteEverythingExamples,
@@ -52,7 +67,7 @@ func runDeleteEverything(cmd *cobra.Command, args []string) {
 	st, err := internal.ConfigureStorage(cmd.Context())
 	tracelog.ErrorLogger.FatalOnError(err)
 
-	deleteHandler, err := newFdbDeleteHandler(cmd.Context(), st.RootFolder())
+	deleteHandler, err := fdb.NewFdbDeleteHandler(cmd.Context(), st.RootFolder())
 	tracelog.ErrorLogger.FatalOnError(err)
 
 	deleteHandler.DeleteEverything(cmd.Context(), confirmed)
@@ -62,7 +77,7 @@ func runDeleteBefore(cmd *cobra.Command, args []string) {
 	st, err := internal.ConfigureStorage(cmd.Context())
 	tracelog.ErrorLogger.FatalOnError(err)
 
-	deleteHandler, err := newFdbDeleteHandler(cmd.Context(), st.RootFolder())
+	deleteHandler, err := fdb.NewFdbDeleteHandler(cmd.Context(), st.RootFolder())
 	tracelog.ErrorLogger.FatalOnError(err)
 
 	deleteHandler.HandleDeleteBefore(cmd.Context(), args, confirmed)
@@ -72,7 +87,7 @@ func runDeleteRetain(ctx context.Context, args []string) {
 	st, err := internal.ConfigureStorage(ctx)
 	tracelog.ErrorLogger.FatalOnError(err)
 
-	deleteHandler, err := newFdbDeleteHandler(ctx, st.RootFolder())
+	deleteHandler, err := fdb.NewFdbDeleteHandler(ctx, st.RootFolder())
 	tracelog.ErrorLogger.FatalOnError(err)
 
 	deleteHandler.HandleDeleteRetain(ctx, args, confirmed)
@@ -82,40 +97,39 @@ func runDeleteRetainAfter(ctx context.Context, args []string) {
 	st, err := internal.ConfigureStorage(ctx)
 	tracelog.ErrorLogger.FatalOnError(err)
 
-	deleteHandler, err := newFdbDeleteHandler(ctx, st.RootFolder())
+	deleteHandler, err := fdb.NewFdbDeleteHandler(ctx, st.RootFolder())
 	tracelog.ErrorLogger.FatalOnError(err)
 
 	deleteHandler.HandleDeleteRetainAfter(ctx, args, confirmed)
 }
 
-func init() {
-	cmd.AddCommand(deleteCmd)
-	deleteRetainCmd.Flags().StringP("after", "a", "", "Set the time after which retain backups")
-	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteEverythingCmd)
-	deleteCmd.PersistentFlags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup deletion")
+func runDeleteRetainPolicy(cmd *cobra.Command, args []string) {
+	st, err := internal.ConfigureStorage(cmd.Context())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler, err := fdb.NewFdbDeleteHandler(cmd.Context(), st.RootFolder())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteRetainPolicy(cmd.Context(), retainPolicy, confirmed)
 }
 
-func newFdbDeleteHandler(ctx context.Context, folder storage.Folder) (*internal.DeleteHandler, error) {
-	backups, err := internal.GetBackupSentinelObjects(ctx, folder)
-	if err != nil {
-		return nil, err
-	}
+func runDeleteAuto(cmd *cobra.Command, args []string) {
+	retention, err := internal.GetRetentionConfig()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	st, err := internal.ConfigureStorage(cmd.Context())
+	tracelog.ErrorLogger.FatalOnError(err)
 
-	backupObjects := make([]internal.BackupObject, 0, len(backups))
-	for _, object := range backups {
-		backupObjects = append(backupObjects, internal.NewDefaultBackupObject(object))
-	}
+	deleteHandler, err := fdb.NewFdbDeleteHandler(cmd.Context(), st.RootFolder())
+	tracelog.ErrorLogger.FatalOnError(err)
 
-	return internal.NewDeleteHandler(folder, backupObjects, makeLessFunc()), nil
+	deleteHandler.HandleDeleteAuto(cmd.Context(), retention, confirmed)
 }
 
-func makeLessFunc() func(object1, object2 storage.Object) bool {
-	return func(object1, object2 storage.Object) bool {
-		time1, ok1 := utility.TryFetchTimeRFC3999(object1.GetName())
-		time2, ok2 := utility.TryFetchTimeRFC3999(object2.GetName())
-		if !ok1 || !ok2 {
-			return object2.GetLastModified().After(object1.GetLastModified())
-		}
-		return time1 < time2
-	}
+func init() {
+	cmd.AddCommand(deleteCmd)
+	deleteRetainCmd.Flags().StringP("after", "a", "", "Set the time after which retain backups")
+	internal.AddGFSRetentionPolicyFlags(deleteRetainPolicyCmd.Flags(), &retainPolicy, "")
+	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteRetainPolicyCmd, deleteAutoCmd, deleteEverythingCmd)
+	deleteCmd.PersistentFlags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup deletion")
 }
@@ -0,0 +1,35 @@
+package fdb
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/databases/fdb"
+)
+
+const (
+	logFetchShortDescription = "Fetches the continuous backup logs from storage and saves them to the specified dir"
+	fetchSinceFlagShortDescr = "backup name (or LATEST) to fetch only the snapshots and logs needed after it, all by default"
+)
+
+var fetchBackupName string
+
+var logFetchCmd = &cobra.Command{
+	Use:   "log-fetch dest-dir",
+	Short: logFetchShortDescription,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		internal.ConfigureLimiters()
+
+		storage, err := internal.ConfigureStorage(cmd.Context())
+		tracelog.ErrorLogger.FatalOnError(err)
+		folderReader := internal.NewFolderReader(storage.RootFolder())
+		err = fdb.HandleLogFetch(cmd.Context(), storage.RootFolder(), fetchBackupName, args[0], folderReader)
+		tracelog.ErrorLogger.FatalOnError(err)
+	},
+}
+
+func init() {
+	logFetchCmd.Flags().StringVar(&fetchBackupName, "since", "", fetchSinceFlagShortDescr)
+	cmd.AddCommand(logFetchCmd)
+}
@@ -0,0 +1,38 @@
+package fdb
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/databases/fdb"
+)
+
+const logPushShortDescription = "Pushes the continuous backup logs to storage"
+
+var logPushCmd = &cobra.Command{
+	Use:   "log-push",
+	Short: logPushShortDescription,
+	Args:  cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		conf.RequiredSettings[conf.FDBLogDirectory] = true
+		err := internal.AssertRequiredSettingsSet()
+		tracelog.ErrorLogger.FatalOnError(err)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		internal.ConfigureLimiters()
+
+		uploader, err := internal.ConfigureUploader(cmd.Context())
+		tracelog.ErrorLogger.FatalOnError(err)
+
+		logDir, err := conf.GetRequiredSetting(conf.FDBLogDirectory)
+		tracelog.ErrorLogger.FatalOnError(err)
+
+		err = fdb.HandleLogPush(cmd.Context(), uploader, logDir)
+		tracelog.ErrorLogger.FatalOnError(err)
+	},
+}
+
+func init() {
+	cmd.AddCommand(logPushCmd)
+}
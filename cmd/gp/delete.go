@@ -12,6 +12,7 @@ var confirmed = false
 var forceDelete = false
 
 var deleteTargetUserData = ""
+var retainPolicy internal.GFSRetentionPolicy
 
 const DeleteGarbageExamples = `  garbage           Deletes outdated WAL archives and leftover backups files from storage`
 const DeleteGarbageUse = "garbage"
@@ -36,6 +37,21 @@ var deleteRetainCmd = &cobra.Command{
 	Run:       runDeleteRetain,
 }
 
+var deleteRetainPolicyCmd = &cobra.Command{
+	Use:     internal.DeleteRetainPolicyUsageExample,
+	Example: internal.DeleteRetainPolicyExamples,
+	Args:    internal.DeleteRetainPolicyArgsValidator,
+	Run:     runDeleteRetainPolicy,
+}
+
+var deleteAutoCmd = &cobra.Command{
+	Use:   internal.DeleteAutoUsage,
+	Short: internal.DeleteAutoShortDescription,
+	Long:  internal.DeleteAutoLongDescription,
+	Args:  cobra.NoArgs,
+	Run:   runDeleteAuto,
+}
+
 var deleteEverythingCmd = &cobra.Command{
 	Use:       internal.DeleteEverythingUsageExample, // TODO : improve description
 	Example:   internal.DeleteEverythingExamples,
@@ -80,6 +96,31 @@ func runDeleteRetain(cmd *cobra.Command, args []string) {
 	deleteHandler.HandleDeleteRetain(cmd.Context(), args)
 }
 
+func runDeleteRetainPolicy(cmd *cobra.Command, args []string) {
+	rootFolder, err := getMultistorageRootFolder(cmd.Context(), true, policies.UniteAllStorages)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	delArgs := greenplum.DeleteArgs{Confirmed: confirmed, Force: forceDelete}
+	deleteHandler, err := greenplum.NewDeleteHandler(cmd.Context(), rootFolder, delArgs)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteRetainPolicy(cmd.Context(), retainPolicy)
+}
+
+func runDeleteAuto(cmd *cobra.Command, args []string) {
+	retention, err := internal.GetRetentionConfig()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	rootFolder, err := getMultistorageRootFolder(cmd.Context(), true, policies.UniteAllStorages)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	delArgs := greenplum.DeleteArgs{Confirmed: confirmed, Force: forceDelete}
+	deleteHandler, err := greenplum.NewDeleteHandler(cmd.Context(), rootFolder, delArgs)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteAuto(cmd.Context(), retention)
+}
+
 func runDeleteEverything(cmd *cobra.Command, args []string) {
 	rootFolder, err := getMultistorageRootFolder(cmd.Context(), true, policies.UniteAllStorages)
 	tracelog.ErrorLogger.FatalOnError(err)
@@ -131,8 +172,10 @@ func init() {
 
 	deleteTargetCmd.Flags().StringVar(
 		&deleteTargetUserData, internal.DeleteTargetUserDataFlag, "", internal.DeleteTargetUserDataDescription)
+	internal.AddGFSRetentionPolicyFlags(deleteRetainPolicyCmd.Flags(), &retainPolicy, "")
 
-	deleteCmd.AddCommand(deleteRetainCmd, deleteBeforeCmd, deleteEverythingCmd, deleteTargetCmd, deleteGarbageCmd)
+	deleteCmd.AddCommand(deleteRetainCmd, deleteRetainPolicyCmd, deleteAutoCmd,
+		deleteBeforeCmd, deleteEverythingCmd, deleteTargetCmd, deleteGarbageCmd)
 	deleteCmd.PersistentFlags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup deletion")
 	deleteCmd.PersistentFlags().BoolVar(&forceDelete, "force-delete", false, "Force delete")
 	_ = deleteCmd.PersistentFlags().MarkHidden("force-delete")
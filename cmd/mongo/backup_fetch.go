@@ -7,6 +7,7 @@ import (
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/databases/mongo"
 )
 
 const backupFetchShortDescription = "Fetches desired backup from storage"
@@ -29,6 +30,8 @@ var backupFetchCmd = &cobra.Command{
 
 		backupSelector, err := internal.NewBackupNameSelector(args[0], true)
 		tracelog.ErrorLogger.FatalOnError(err)
+		err = mongo.EnsureNotClusterBackup(cmd.Context(), storage.RootFolder(), backupSelector)
+		tracelog.ErrorLogger.FatalOnError(err)
 
 		internal.HandleBackupFetch(cmd.Context(), storage.RootFolder(), backupSelector, internal.GetBackupToCommandFetcher(restoreCmd))
 	},
@@ -72,7 +72,8 @@ var binaryBackupFetchCmd = &cobra.Command{
 		mongodVersion := args[2]
 		err := mongo.HandleBinaryFetchPush(cmd.Context(), mongodConfigPath, minimalConfigPath, backupName, mongodVersion,
 			rsName, rsMembers, rsMemberIDs, shardName, mongocfgConnectionString, shardConnectionStrings,
-			skipBackupDownloadFlag, skipMongoReconfigFlag, skipCheckFlag, pitrSince, pitrUntil, whitelist, blacklist)
+			skipBackupDownloadFlag, skipMongoReconfigFlag, skipCheckFlag, pitrSince, pitrUntil, whitelist, blacklist,
+			clusterMember)
 		tracelog.ErrorLogger.FatalOnError(err)
 	},
 }
@@ -94,5 +95,7 @@ func init() {
 		[]string{}, BlacklistDescription)
 	binaryBackupFetchCmd.Flags().StringSliceVar(&whitelist, WhitelistFlag,
 		[]string{}, WhitelistDescription)
+	binaryBackupFetchCmd.Flags().StringVar(&clusterMember, ClusterMemberFlag, "",
+		"Restore the backup of the member (shard or config) of the cluster backup to the cluster restore point")
 	cmd.AddCommand(binaryBackupFetchCmd)
 }
@@ -13,12 +13,14 @@ const (
 	SkipMetadataFlag  = "skip-metadata"
 	CountJournalsFlag = "count-journals"
 	AddUserDataFlag   = "add-user-data"
+	ClusterMemberFlag = "cluster-member"
 )
 
 var (
 	countJournals = false
 	skipMetadata  = false
 	userDataRaw   = ""
+	clusterMember = ""
 )
 
 var binaryBackupPushCmd = &cobra.Command{
@@ -34,6 +36,7 @@ var binaryBackupPushCmd = &cobra.Command{
 			AppName:       "wal-g-mongo " + binaryBackupPushCommandName,
 			CountJournals: countJournals,
 			UserDataRaw:   userDataRaw,
+			ClusterMember: clusterMember,
 		}
 		err := mongo.HandleBinaryBackupPush(cmd.Context(), pushArgs)
 		tracelog.ErrorLogger.FatalOnError(err)
@@ -47,5 +50,7 @@ func init() {
 		"Count and store in S3 oplog sizes required to get replay data from a backup to the next one")
 	binaryBackupPushCmd.Flags().StringVar(&userDataRaw, AddUserDataFlag,
 		"", "Write the provided user data to the backup sentinel and metadata files.")
+	binaryBackupPushCmd.Flags().StringVar(&clusterMember, ClusterMemberFlag,
+		"", "Push the backup of the sharded cluster member (set by cluster-backup-push) to its subfolder")
 	cmd.AddCommand(binaryBackupPushCmd)
 }
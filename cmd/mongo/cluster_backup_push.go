@@ -0,0 +1,53 @@
+package mongo
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/databases/mongo"
+)
+
+const (
+	clusterBackupPushShortDescription = "Pushes consistent backups of all shards and the config server of the sharded cluster"
+	clusterBackupPushLongDescription  = "Discovers the shards and the config server through mongos (MONGODB_URI), " +
+		"stops the balancer and runs MONGODB_CLUSTER_MEMBER_BACKUP_COMMAND for every member. The command has to run " +
+		"binary-backup-push --cluster-member $WALG_MONGO_CLUSTER_MEMBER on a host of the member. " +
+		"The oplog of every member is uploaded up to the common restore point taken after all backups are finished. " +
+		"The member backups and oplog archives are stored in the subfolders named after the members, " +
+		"the cluster backup sentinel references them."
+)
+
+// clusterBackupPushCmd represents the cluster-backup-push command
+var clusterBackupPushCmd = &cobra.Command{
+	Use:   "cluster-backup-push",
+	Short: clusterBackupPushShortDescription,
+	Long:  clusterBackupPushLongDescription,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		internal.ConfigureLimiters()
+
+		mongodbURL, err := conf.GetRequiredSetting(conf.MongoDBUriSetting)
+		tracelog.ErrorLogger.FatalOnError(err)
+		archiveAfterSize, err := internal.GetOplogArchiveAfterSize()
+		tracelog.ErrorLogger.FatalOnError(err)
+
+		pushArgs := mongo.HandleClusterBackupPushArgs{
+			MongodbURI:       mongodbURL,
+			Permanent:        permanent,
+			ArchiveAfterSize: archiveAfterSize,
+		}
+		err = mongo.HandleClusterBackupPush(cmd.Context(), pushArgs)
+		tracelog.ErrorLogger.FatalfOnError("Cluster backup creation failed: %v", err)
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		conf.RequiredSettings[conf.MongoDBClusterMemberBackupCmd] = true
+		err := internal.AssertRequiredSettingsSet()
+		tracelog.ErrorLogger.FatalOnError(err)
+	},
+}
+
+func init() {
+	clusterBackupPushCmd.Flags().BoolVarP(&permanent, PermanentFlag, PermanentShorthand, false, "Pushes permanent backup")
+	cmd.AddCommand(clusterBackupPushCmd)
+}
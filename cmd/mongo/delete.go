@@ -23,6 +23,7 @@ var (
 	purgeGarbage bool
 	retainAfter  string
 	retainCount  uint
+	retainPolicy internal.GFSRetentionPolicy
 )
 
 // deleteCmd represents the delete command
@@ -32,6 +33,15 @@ var deleteCmd = &cobra.Command{
 	Run:   runPurge,
 }
 
+var deleteAutoCmd = &cobra.Command{
+	Use:   internal.DeleteAutoUsage,
+	Short: internal.DeleteAutoShortDescription,
+	Long: internal.DeleteAutoLongDescription + `
+The oplog archives not needed to restore the kept backups to any point after them are deleted too.`,
+	Args: cobra.NoArgs,
+	Run:  runDeleteAuto,
+}
+
 func runPurge(cmd *cobra.Command, args []string) {
 	opts := []mongo.PurgeOption{
 		mongo.PurgeDryRun(!confirmed),
@@ -49,6 +59,28 @@ func runPurge(cmd *cobra.Command, args []string) {
 		opts = append(opts, mongo.PurgeRetainCount(int(retainCount)))
 	}
 
+	if !retainPolicy.IsEmpty() {
+		err := retainPolicy.Validate()
+		tracelog.ErrorLogger.FatalfOnError("Invalid retention policy: %v", err)
+		opts = append(opts, mongo.PurgeRetainPolicy(retainPolicy))
+	}
+
+	handlePurge(cmd, opts)
+}
+
+func runDeleteAuto(cmd *cobra.Command, args []string) {
+	retention, err := internal.GetRetentionConfig()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	handlePurge(cmd, []mongo.PurgeOption{
+		mongo.PurgeDryRun(!confirmed),
+		mongo.PurgeOplog(true),
+		mongo.PurgeGarbage(purgeGarbage),
+		mongo.PurgeRetention(retention),
+	})
+}
+
+func handlePurge(cmd *cobra.Command, opts []mongo.PurgeOption) {
 	// set up storage downloader client
 	downloader, err := archive.NewStorageDownloader(cmd.Context(), archive.NewDefaultStorageSettings())
 	tracelog.ErrorLogger.FatalOnError(err)
@@ -57,6 +89,10 @@ func runPurge(cmd *cobra.Command, args []string) {
 	purger, err := archive.NewStoragePurger(cmd.Context(), archive.NewDefaultStorageSettings())
 	tracelog.ErrorLogger.FatalOnError(err)
 
+	storage, err := internal.ConfigureStorage(cmd.Context())
+	tracelog.ErrorLogger.FatalOnError(err)
+	opts = append(opts, mongo.PurgeClusterOplog(storage.RootFolder()))
+
 	err = mongo.HandlePurge(cmd.Context(), downloader, purger, opts...)
 	tracelog.ErrorLogger.FatalOnError(err)
 }
@@ -70,4 +106,9 @@ func init() {
 	deleteCmd.Flags().BoolVar(&purgeGarbage, purgeGarbageFlag, false, "Purge garbage in backup folder")
 	deleteCmd.Flags().StringVar(&retainAfter, retainAfterFlag, "", "Keep backups newer")
 	deleteCmd.Flags().UintVar(&retainCount, retainCountFlag, 0, "Keep minimum count, except permanent backups")
+	internal.AddGFSRetentionPolicyFlags(deleteCmd.Flags(), &retainPolicy, "retain-")
+
+	deleteCmd.AddCommand(deleteAutoCmd)
+	deleteAutoCmd.Flags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup, garbage and oplog deletion")
+	deleteAutoCmd.Flags().BoolVar(&purgeGarbage, purgeGarbageFlag, false, "Purge garbage in backup folder")
 }
@@ -0,0 +1,86 @@
+package mongo
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/databases/mongo"
+	"github.com/wal-g/wal-g/internal/databases/mongo/archive"
+	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"github.com/wal-g/wal-g/internal/databases/mongo/oplog"
+)
+
+const (
+	oplogInspectShortDescription = "Lists oplog operations grouped by namespace and document and reconstructs the documents"
+	oplogInspectLongDescription  = "Fetches oplog archives from storage and prints the operations between since and until " +
+		"grouped by namespace and document _id in the oplog-fetch format: the commands on the namespace go first, " +
+		"then the operations on every document. With --restore-at the state of every changed " +
+		"document at that timestamp is reconstructed from the logical backup and the oplog and written " +
+		"to the output directory as <db>/<collection>.bson files, which can be loaded by mongorestore."
+	InspectNsFlag           = "ns"
+	InspectNsDescription    = "Inspect only the operations on the namespaces (db or db.collection)"
+	RestoreAtFlag           = "restore-at"
+	RestoreAtDescription    = "Reconstruct the changed documents at the timestamp (ts.inc), the operation at it is not applied"
+	InspectBackupFlag       = "backup-name"
+	InspectBackupDesc       = "Logical backup to reconstruct the documents from, the latest one finished before --restore-at by default"
+	InspectOutputFlag       = "output-dir"
+	InspectOutputDesc       = "Directory to write the reconstructed documents to"
+	InspectOutputDefaultDir = "oplog_inspect"
+	InspectFormatFlag       = "format"
+	InspectFormatShorthand  = "f"
+	InspectFormatDesc       = "Valid values: json, bson, bson-raw"
+)
+
+var (
+	inspectNamespaces []string
+	restoreAt         string
+	inspectBackupName string
+	inspectOutputDir  string
+	inspectFormat     string
+)
+
+// oplogInspectCmd represents oplog inspect procedure
+var oplogInspectCmd = &cobra.Command{
+	Use:   "oplog-inspect <since ts.inc> <until ts.inc>",
+	Short: oplogInspectShortDescription,
+	Long:  oplogInspectLongDescription,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		since, err := models.TimestampFromStr(args[0])
+		tracelog.ErrorLogger.FatalOnError(err)
+		until, err := models.TimestampFromStr(args[1])
+		tracelog.ErrorLogger.FatalOnError(err)
+
+		inspectArgs := mongo.OplogInspectArgs{
+			Since:      since,
+			Until:      until,
+			Namespaces: inspectNamespaces,
+			BackupName: inspectBackupName,
+			OutputDir:  inspectOutputDir,
+		}
+		if restoreAt != "" {
+			restoreAtTS, err := models.TimestampFromStr(restoreAt)
+			tracelog.ErrorLogger.FatalOnError(err)
+			inspectArgs.RestoreAt = &restoreAtTS
+		}
+
+		report, err := oplog.NewWriteApplier(inspectFormat, os.Stdout)
+		tracelog.ErrorLogger.FatalOnError(err)
+
+		downloader, err := archive.NewStorageDownloader(cmd.Context(), archive.NewDefaultStorageSettings())
+		tracelog.ErrorLogger.FatalOnError(err)
+
+		err = mongo.HandleOplogInspect(cmd.Context(), downloader, inspectArgs, report)
+		tracelog.ErrorLogger.FatalOnError(err)
+	},
+}
+
+func init() {
+	oplogInspectCmd.Flags().StringSliceVar(&inspectNamespaces, InspectNsFlag, []string{}, InspectNsDescription)
+	oplogInspectCmd.Flags().StringVar(&restoreAt, RestoreAtFlag, "", RestoreAtDescription)
+	oplogInspectCmd.Flags().StringVar(&inspectBackupName, InspectBackupFlag, "", InspectBackupDesc)
+	oplogInspectCmd.Flags().StringVar(&inspectOutputDir, InspectOutputFlag, InspectOutputDefaultDir, InspectOutputDesc)
+	oplogInspectCmd.Flags().StringVarP(&inspectFormat, InspectFormatFlag, InspectFormatShorthand, "json", InspectFormatDesc)
+	cmd.AddCommand(oplogInspectCmd)
+}
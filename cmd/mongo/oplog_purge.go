@@ -45,6 +45,14 @@ func runOplogPurge(cmd *cobra.Command, args []string) {
 
 	err = mongo.HandleOplogPurge(cmd.Context(), downloader, purger, pitrAfterTime, !confirmedOplogPurge)
 	tracelog.ErrorLogger.FatalOnError(err)
+
+	// the oplog archives of the cluster members are kept only for the cluster backups
+	storage, err := internal.ConfigureStorage(cmd.Context())
+	tracelog.ErrorLogger.FatalOnError(err)
+	backups, err := mongo.LoadBackups(cmd.Context(), downloader)
+	tracelog.ErrorLogger.FatalOnError(err)
+	err = mongo.HandleClusterOplogPurge(cmd.Context(), storage.RootFolder(), backups, nil, !confirmedOplogPurge)
+	tracelog.ErrorLogger.FatalOnError(err)
 }
 
 func init() {
@@ -53,6 +53,10 @@ func runOplogPush(ctx context.Context, pushArgs oplogPushRunArgs, statsArgs oplo
 	if err != nil {
 		return err
 	}
+	err = internal.ConfigureZstdDictionary(ctx, uplProvider.Folder(), uplProvider)
+	if err != nil {
+		return err
+	}
 	uplProvider.ChangeDirectory(models.OplogArchBasePath)
 	uploader := archive.NewStorageUploader(uplProvider)
 
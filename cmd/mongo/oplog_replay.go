@@ -6,6 +6,7 @@ import (
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/databases/mongo"
 	"github.com/wal-g/wal-g/internal/databases/mongo/binary"
+	"github.com/wal-g/wal-g/internal/databases/mongo/stages"
 )
 
 const (
@@ -15,19 +16,38 @@ const (
 	WithCatchUpReconfigDescription          = "Reconfig MongoDB oplog service collections for mongod replica oplog catch up"
 	MinimalOplogReplayConfigPathFlag        = "minimal-mongod-config-path"
 	MinimalOplogReplayConfigPathDescription = "Path to mongod config with minimal working configuration"
+	IncludeNsFlag                           = "include-ns"
+	IncludeNsDescription                    = "Replay only the operations on the namespaces (db or db.collection)"
+	ExcludeNsFlag                           = "exclude-ns"
+	ExcludeNsDescription                    = "Skip the operations on the namespaces (db or db.collection)"
+	OpsFlag                                 = "ops"
+	OpsDescription                          = "Replay only the operations of the types: i (insert), u (update), " +
+		"d (delete), c (command), n (noop)"
+	ClusterBackupFlag        = "cluster-backup"
+	ClusterBackupDescription = "Replay the oplog of --cluster-member from its backup to the restore point " +
+		"of the cluster backup, the since and until timestamps are taken from the backup"
 )
 
 var (
 	partial                     bool
 	withCatchUpReconfig         bool
 	minimalOplogReplyConfigPath string
+	includeNs                   []string
+	excludeNs                   []string
+	replayOps                   []string
+	clusterBackup               string
 )
 
 // oplogReplayCmd represents oplog replay procedure
 var oplogReplayCmd = &cobra.Command{
 	Use:   "oplog-replay <since ts.inc> <until ts.inc>",
 	Short: "Fetches oplog archives from storage and applies to database",
-	Args:  cobra.ExactArgs(2),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if clusterBackup != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		var err error
 		defer func() { tracelog.ErrorLogger.FatalOnError(err) }()
@@ -46,11 +66,27 @@ func buildOplogReplayRunArgs(
 	cmdargs []string, partial,
 	withCatchUpReconfig bool, minimalConfigPath string,
 ) (binary.ReplyOplogConfig, string, error) {
-	args, err := binary.NewReplyOplogConfig(cmd.Context(), cmdargs[0], cmdargs[1], partial, withCatchUpReconfig, minimalConfigPath)
+	var args binary.ReplyOplogConfig
+	var err error
+	if clusterBackup != "" {
+		args, err = mongo.NewClusterMemberReplayConfig(cmd.Context(), clusterBackup, clusterMember, partial)
+		args.WithCatchUpReconfig = withCatchUpReconfig
+		args.MinimalConfigPath = minimalConfigPath
+	} else {
+		args, err = binary.NewReplyOplogConfig(cmd.Context(), cmdargs[0], cmdargs[1], partial, withCatchUpReconfig,
+			minimalConfigPath)
+	}
 	if err != nil {
 		return args, "", err
 	}
 
+	if len(includeNs)+len(excludeNs)+len(replayOps) > 0 {
+		args.OplogFilter, err = stages.NewOplogFilter(includeNs, excludeNs, replayOps)
+		if err != nil {
+			return args, "", err
+		}
+	}
+
 	mongodbURL, err := conf.GetRequiredSetting(conf.MongoDBUriSetting)
 	if err != nil {
 		return args, "", err
@@ -64,5 +100,10 @@ func init() {
 	oplogReplayCmd.Flags().BoolVar(&withCatchUpReconfig, WithCatchUpReconfigFlag, false, WithCatchUpReconfigDescription)
 	oplogReplayCmd.Flags().StringVar(&minimalOplogReplyConfigPath, MinimalOplogReplayConfigPathFlag,
 		"", MinimalOplogReplayConfigPathDescription)
+	oplogReplayCmd.Flags().StringSliceVar(&includeNs, IncludeNsFlag, []string{}, IncludeNsDescription)
+	oplogReplayCmd.Flags().StringSliceVar(&excludeNs, ExcludeNsFlag, []string{}, ExcludeNsDescription)
+	oplogReplayCmd.Flags().StringSliceVar(&replayOps, OpsFlag, []string{}, OpsDescription)
+	oplogReplayCmd.Flags().StringVar(&clusterBackup, ClusterBackupFlag, "", ClusterBackupDescription)
+	oplogReplayCmd.Flags().StringVar(&clusterMember, ClusterMemberFlag, "", "Member of the cluster backup to replay")
 	cmd.AddCommand(oplogReplayCmd)
 }
@@ -14,6 +14,8 @@ const (
 	targetUserDataDescription   = "Fetch storage backup which has the specified user data"
 	useXbtoolExtractDescription = "Use internal xbtool to extract data from xbstream"
 	inplaceDescription          = "(DANGEROUS) Apply diff-s inplace (reduce required disk space)"
+	tablesDescription           = "Restore only the listed tables (database.table) of xtrabackup backup " +
+		"and prepare them for ALTER TABLE ... IMPORT TABLESPACE"
 )
 
 var (
@@ -27,7 +29,7 @@ var (
 			storage, err := internal.ConfigureStorage(cmd.Context())
 			tracelog.ErrorLogger.FatalOnError(err)
 			restoreCmd, err := internal.GetCommandSettingContext(cmd.Context(), conf.NameStreamRestoreCmd)
-			if !useXbtoolExtract {
+			if !useXbtoolExtract && len(fetchTables) == 0 {
 				tracelog.ErrorLogger.FatalOnError(err)
 			}
 			prepareCmd, _ := internal.GetCommandSettingContext(cmd.Context(), conf.MysqlBackupPrepareCmd)
@@ -35,12 +37,14 @@ var (
 			targetBackupSelector, err := createTargetBackupSelector(args, fetchTargetUserData)
 			tracelog.ErrorLogger.FatalOnError(err)
 
-			mysql.HandleBackupFetch(cmd.Context(), storage.RootFolder(), targetBackupSelector, restoreCmd, prepareCmd, useXbtoolExtract, inplace)
+			mysql.HandleBackupFetch(cmd.Context(), storage.RootFolder(), targetBackupSelector, restoreCmd, prepareCmd,
+				useXbtoolExtract, inplace, fetchTables)
 		},
 	}
 	fetchTargetUserData string
 	useXbtoolExtract    bool
 	inplace             bool
+	fetchTables         []string
 )
 
 func createTargetBackupSelector(args []string, fetchTargetUserData string) (internal.BackupSelector, error) {
@@ -59,6 +63,7 @@ func init() {
 	backupFetchCmd.Flags().StringVar(&fetchTargetUserData, "target-user-data", "", targetUserDataDescription)
 	backupFetchCmd.Flags().BoolVar(&useXbtoolExtract, "use-xbtool-extract", false, useXbtoolExtractDescription)
 	backupFetchCmd.Flags().BoolVar(&inplace, "inplace", false, inplaceDescription)
+	backupFetchCmd.Flags().StringSliceVar(&fetchTables, "tables", nil, tablesDescription)
 	_ = backupFetchCmd.Flags().MarkHidden("use-xbtool-extract")
 	_ = backupFetchCmd.Flags().MarkHidden("inplace")
 }
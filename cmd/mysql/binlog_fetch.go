@@ -15,10 +15,16 @@ const fetchSinceFlagShortDescr = "backup name starting from which you want to fe
 const fetchUntilFlagShortDescr = "time in RFC3339 for PITR"
 const fetchUntilBinlogLastModifiedFlagShortDescr = "time in RFC3339 that is used to prevent wal-g from replaying" +
 	" binlogs that was created/modified after this time"
+const fetchUntilGTIDFlagShortDescr = "GTID set to stop fetching after, " +
+	"e.g. 'uuid:100' for MySQL or '0-1-100' for MariaDB"
+const fetchExcludeGTIDFlagShortDescr = "MySQL GTID set of the transactions to skip, " +
+	"it is written to binlogs_exclude_gtids file with the transactions after --until-gtid"
 
 var fetchBackupName string
 var fetchUntilTS string
 var fetchUntilBinlogLastModifiedTS string
+var fetchUntilGTID string
+var fetchExcludeGTID string
 
 // binlogPushCmd represents the cron command
 var binlogFetchCmd = &cobra.Command{
@@ -28,7 +34,8 @@ var binlogFetchCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		storage, err := internal.ConfigureStorage(cmd.Context())
 		tracelog.ErrorLogger.FatalOnError(err)
-		mysql.HandleBinlogFetch(cmd.Context(), storage.RootFolder(), fetchBackupName, fetchUntilTS, fetchUntilBinlogLastModifiedTS)
+		mysql.HandleBinlogFetch(cmd.Context(), storage.RootFolder(), fetchBackupName, fetchUntilTS, fetchUntilBinlogLastModifiedTS,
+			fetchUntilGTID, fetchExcludeGTID)
 	},
 	PreRun: func(cmd *cobra.Command, args []string) {
 		conf.RequiredSettings[conf.MysqlBinlogDstSetting] = true
@@ -47,5 +54,7 @@ func init() {
 		"until-binlog-last-modified-time",
 		"",
 		fetchUntilBinlogLastModifiedFlagShortDescr)
+	binlogFetchCmd.PersistentFlags().StringVar(&fetchUntilGTID, "until-gtid", "", fetchUntilGTIDFlagShortDescr)
+	binlogFetchCmd.PersistentFlags().StringVar(&fetchExcludeGTID, "exclude-gtid", "", fetchExcludeGTIDFlagShortDescr)
 	cmd.AddCommand(binlogFetchCmd)
 }
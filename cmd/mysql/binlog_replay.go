@@ -15,10 +15,24 @@ const replaySinceFlagShortDescr = "backup name starting from which you want to f
 const replayUntilFlagShortDescr = "time in RFC3339 for PITR"
 const replayUntilBinlogLastModifiedFlagShortDescr = "time in RFC3339 that is used to prevent wal-g from replaying" +
 	" binlogs that was created/modified after this time"
+const replayUntilGTIDFlagShortDescr = "GTID set to stop the replay right after, " +
+	"e.g. 'uuid:100' for MySQL or '0-1-100' for MariaDB"
+const replayExcludeGTIDFlagShortDescr = "MySQL GTID set of the transactions to skip during the replay"
+const replayNativeFlagShortDescr = "apply binlogs in-process over WALG_MYSQL_DATASOURCE_NAME connections " +
+	"instead of WALG_MYSQL_BINLOG_REPLAY_COMMAND"
+const replayNativeWorkersFlagShortDescr = "number of connections applying transactions in parallel in the native mode"
+const replayNativeParallelismFlagShortDescr = "how to split transactions between the workers in the native mode: " +
+	mysql.NativeParallelismLogicalClock + " (by the commit groups of the source) or " +
+	mysql.NativeParallelismTable + " (by the changed tables)"
 
 var replayBackupName string
 var replayUntilTS string
 var replayUntilBinlogLastModifiedTS string
+var replayUntilGTID string
+var replayExcludeGTID string
+var replayNative bool
+var replayNativeWorkers int
+var replayNativeParallelism string
 
 var binlogReplayCmd = &cobra.Command{
 	Use:   "binlog-replay",
@@ -27,10 +41,19 @@ var binlogReplayCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		storage, err := internal.ConfigureStorage(cmd.Context())
 		tracelog.ErrorLogger.FatalOnError(err)
-		mysql.HandleBinlogReplay(cmd.Context(), storage.RootFolder(), replayBackupName, replayUntilTS, replayUntilBinlogLastModifiedTS)
+		var nativeOptions *mysql.NativeReplayOptions
+		if replayNative {
+			nativeOptions = &mysql.NativeReplayOptions{Workers: replayNativeWorkers, Parallelism: replayNativeParallelism}
+		}
+		mysql.HandleBinlogReplay(cmd.Context(), storage.RootFolder(), replayBackupName, replayUntilTS, replayUntilBinlogLastModifiedTS,
+			replayUntilGTID, replayExcludeGTID, nativeOptions)
 	},
 	PreRun: func(cmd *cobra.Command, args []string) {
-		conf.RequiredSettings[conf.MysqlBinlogReplayCmd] = true
+		if replayNative {
+			conf.RequiredSettings[conf.MysqlDatasourceNameSetting] = true
+		} else {
+			conf.RequiredSettings[conf.MysqlBinlogReplayCmd] = true
+		}
 		err := internal.AssertRequiredSettingsSet()
 		tracelog.ErrorLogger.FatalOnError(err)
 	},
@@ -42,5 +65,11 @@ func init() {
 		utility.TimeNowCrossPlatformUTC().Format(time.RFC3339), replayUntilFlagShortDescr)
 	binlogReplayCmd.PersistentFlags().StringVar(&replayUntilBinlogLastModifiedTS, "until-binlog-last-modified-time",
 		"", replayUntilBinlogLastModifiedFlagShortDescr)
+	binlogReplayCmd.PersistentFlags().StringVar(&replayUntilGTID, "until-gtid", "", replayUntilGTIDFlagShortDescr)
+	binlogReplayCmd.PersistentFlags().StringVar(&replayExcludeGTID, "exclude-gtid", "", replayExcludeGTIDFlagShortDescr)
+	binlogReplayCmd.PersistentFlags().BoolVar(&replayNative, "native", false, replayNativeFlagShortDescr)
+	binlogReplayCmd.PersistentFlags().IntVar(&replayNativeWorkers, "native-workers", 1, replayNativeWorkersFlagShortDescr)
+	binlogReplayCmd.PersistentFlags().StringVar(&replayNativeParallelism, "native-parallelism",
+		mysql.NativeParallelismLogicalClock, replayNativeParallelismFlagShortDescr)
 	cmd.AddCommand(binlogReplayCmd)
 }
@@ -34,7 +34,7 @@ var (
 			tracelog.ErrorLogger.FatalfOnError("Cannot create destination folder: %v", err)
 
 			streamReader := xbstream.NewReader(src, false)
-			xbstream.BackupSink(streamReader, dataDir, decompress)
+			xbstream.BackupSink(streamReader, dataDir, decompress, nil)
 		},
 	}
 	decompress bool
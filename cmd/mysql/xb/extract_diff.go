@@ -32,7 +32,7 @@ var (
 			tracelog.ErrorLogger.FatalfOnError("Cannot create destination folder: %v", err)
 
 			streamReader := xbstream.NewReader(src, false)
-			xbstream.DiffBackupSink(streamReader, dataDir, incrementalDir)
+			xbstream.DiffBackupSink(streamReader, dataDir, incrementalDir, nil)
 		},
 	}
 	incrementalDir string
@@ -59,6 +59,9 @@ var (
 
 			uploader, err := internal.ConfigureUploaderToFolder(rootFolder)
 			tracelog.ErrorLogger.FatalOnError(err)
+			if viper.GetBool(conf.UploadChecksumsSetting) {
+				uploader.EnableChecksums()
+			}
 
 			var dataDirectory string
 
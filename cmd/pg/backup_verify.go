@@ -0,0 +1,65 @@
+package pg
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/databases/postgres"
+	"github.com/wal-g/wal-g/internal/multistorage"
+	"github.com/wal-g/wal-g/internal/multistorage/policies"
+)
+
+const (
+	backupVerifyShortDescription = "Verifies that a backup restores"
+	backupVerifyLongDescription  = `Fetches the backup into a scratch directory, checks every restored file against the backup files metadata
+and validates the page checksums. Optionally starts a throwaway postgres on the restored data to reach consistency.
+The report is printed and uploaded next to the backup sentinel.`
+	scratchDirDescription    = "Directory to restore the backup to, a temporary one is created by default"
+	keepScratchDescription   = "Do not remove the scratch directory after the verification"
+	startPostgresDescription = "Start postgres on the restored backup and wait for it to reach consistency"
+	pgBinDirDescription      = "Directory with the postgres binary, PATH is used by default"
+	noUploadDescription      = "Do not upload the verification report to storage"
+)
+
+var (
+	backupVerifyConfig   postgres.BackupVerifyConfig
+	noUploadVerifyReport bool
+)
+
+var backupVerifyCmd = &cobra.Command{
+	Use:   "backup-verify backup_name|LATEST",
+	Short: backupVerifyShortDescription,
+	Long:  backupVerifyLongDescription,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		internal.ConfigureLimiters()
+
+		targetBackupSelector, err := internal.NewTargetBackupSelector("", args[0], postgres.NewGenericMetaFetcher())
+		tracelog.ErrorLogger.FatalOnError(err)
+
+		storage, err := internal.ConfigureMultiStorage(cmd.Context(), false)
+		tracelog.ErrorLogger.FatalOnError(err)
+
+		rootFolder := multistorage.SetPolicies(storage.RootFolder(), policies.UniteAllStorages)
+		if targetStorage == "" {
+			rootFolder, err = multistorage.UseAllAliveStorages(cmd.Context(), rootFolder)
+		} else {
+			rootFolder, err = multistorage.UseSpecificStorage(cmd.Context(), targetStorage, rootFolder)
+		}
+		tracelog.ErrorLogger.FatalOnError(err)
+
+		backupVerifyConfig.UploadReport = !noUploadVerifyReport
+		postgres.HandleBackupVerify(cmd.Context(), rootFolder, targetBackupSelector, backupVerifyConfig)
+	},
+}
+
+func init() {
+	backupVerifyCmd.Flags().StringVar(&backupVerifyConfig.ScratchDir, "scratch-dir", "", scratchDirDescription)
+	backupVerifyCmd.Flags().BoolVar(&backupVerifyConfig.KeepScratch, "keep-scratch", false, keepScratchDescription)
+	backupVerifyCmd.Flags().BoolVar(&backupVerifyConfig.StartPostgres, "start-postgres", false, startPostgresDescription)
+	backupVerifyCmd.Flags().StringVar(&backupVerifyConfig.PgBinDir, "pg-bin-dir", "", pgBinDirDescription)
+	backupVerifyCmd.Flags().BoolVar(&noUploadVerifyReport, "no-upload", false, noUploadDescription)
+	backupVerifyCmd.Flags().StringVar(&targetStorage, "target-storage", "", targetStorageDescription)
+
+	Cmd.AddCommand(backupVerifyCmd)
+}
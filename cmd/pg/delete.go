@@ -10,6 +10,7 @@ import (
 	"github.com/wal-g/wal-g/internal/multistorage"
 	"github.com/wal-g/wal-g/internal/multistorage/policies"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
 )
 
 const UseSentinelTimeFlag = "use-sentinel-time"
@@ -24,6 +25,8 @@ var confirmed = false
 var deleteWithoutBackups = false
 var useSentinelTime = false
 var deleteTargetUserData = ""
+var retainPolicy internal.GFSRetentionPolicy
+var pitrWindow = ""
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
@@ -42,10 +45,25 @@ var deleteRetainCmd = &cobra.Command{
 	Use:       internal.DeleteRetainUsageExample, // TODO : improve description
 	Example:   internal.DeleteRetainExamples,
 	ValidArgs: internal.StringModifiers,
-	Args:      internal.DeleteRetainArgsValidator,
+	Args:      deleteRetainArgsValidator,
 	Run:       runDeleteRetain,
 }
 
+var deleteRetainPolicyCmd = &cobra.Command{
+	Use:     internal.DeleteRetainPolicyUsageExample,
+	Example: internal.DeleteRetainPolicyExamples,
+	Args:    internal.DeleteRetainPolicyArgsValidator,
+	Run:     runDeleteRetainPolicy,
+}
+
+var deleteAutoCmd = &cobra.Command{
+	Use:   internal.DeleteAutoUsage,
+	Short: internal.DeleteAutoShortDescription,
+	Long:  internal.DeleteAutoLongDescription,
+	Args:  cobra.NoArgs,
+	Run:   runDeleteAuto,
+}
+
 var deleteEverythingCmd = &cobra.Command{
 	Use:       internal.DeleteEverythingUsageExample, // TODO : improve description
 	Example:   internal.DeleteEverythingExamples,
@@ -68,6 +86,19 @@ var deleteGarbageCmd = &cobra.Command{
 	Run:     runDeleteGarbage,
 }
 
+// deleteRetainArgsValidator allows to omit the retention count if the PITR window is set
+func deleteRetainArgsValidator(cmd *cobra.Command, args []string) error {
+	if pitrWindow != "" {
+		if _, err := utility.ParseDurationWithDays(pitrWindow); err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			return nil
+		}
+	}
+	return internal.DeleteRetainArgsValidator(cmd, args)
+}
+
 func runDeleteBefore(cmd *cobra.Command, args []string) {
 	folder := configureFolder(cmd.Context())
 
@@ -88,13 +119,42 @@ func runDeleteRetain(cmd *cobra.Command, args []string) {
 	tracelog.ErrorLogger.FatalOnError(err)
 
 	afterValue, _ := cmd.Flags().GetString(afterFlag)
-	if afterValue == "" {
+	if pitrWindow != "" {
+		window, err := utility.ParseDurationWithDays(pitrWindow)
+		tracelog.ErrorLogger.FatalOnError(err)
+		deleteHandler.HandleDeleteRetainWithPITRWindow(cmd.Context(), args, afterValue, window, confirmed)
+	} else if afterValue == "" {
 		deleteHandler.HandleDeleteRetain(cmd.Context(), args, confirmed)
 	} else {
 		deleteHandler.HandleDeleteRetainAfter(cmd.Context(), append(args, afterValue), confirmed)
 	}
 }
 
+func runDeleteRetainPolicy(cmd *cobra.Command, args []string) {
+	folder := configureFolder(cmd.Context())
+
+	permanentBackups, permanentWals := postgres.GetPermanentBackupsAndWals(cmd.Context(), folder)
+
+	deleteHandler, err := postgres.NewDeleteHandler(cmd.Context(), folder, permanentBackups, permanentWals, useSentinelTime)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteRetainPolicy(cmd.Context(), retainPolicy, confirmed)
+}
+
+func runDeleteAuto(cmd *cobra.Command, args []string) {
+	retention, err := internal.GetRetentionConfig()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	folder := configureFolder(cmd.Context())
+
+	permanentBackups, permanentWals := postgres.GetPermanentBackupsAndWals(cmd.Context(), folder)
+
+	deleteHandler, err := postgres.NewDeleteHandler(cmd.Context(), folder, permanentBackups, permanentWals, useSentinelTime)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteAuto(cmd.Context(), retention, confirmed)
+}
+
 func runDeleteEverything(cmd *cobra.Command, args []string) {
 	folder := configureFolder(cmd.Context())
 
@@ -166,10 +226,13 @@ func init() {
 	deleteTargetCmd.Flags().StringVar(
 		&deleteTargetUserData, internal.DeleteTargetUserDataFlag, "", internal.DeleteTargetUserDataDescription)
 	deleteRetainCmd.Flags().StringP(afterFlag, "a", "", "Set the time after which retain backups")
+	deleteRetainCmd.Flags().StringVar(&pitrWindow, postgres.PITRWindowFlag, "", postgres.PITRWindowDescription)
+	internal.AddGFSRetentionPolicyFlags(deleteRetainPolicyCmd.Flags(), &retainPolicy, "")
 
 	deleteGarbageCmd.Flags().BoolVar(&deleteWithoutBackups, "without-backup-check", false, "skip check for existing non-permanent backups")
 
-	deleteCmd.AddCommand(deleteRetainCmd, deleteBeforeCmd, deleteEverythingCmd, deleteTargetCmd, deleteGarbageCmd)
+	deleteCmd.AddCommand(deleteRetainCmd, deleteRetainPolicyCmd, deleteAutoCmd,
+		deleteBeforeCmd, deleteEverythingCmd, deleteTargetCmd, deleteGarbageCmd)
 	deleteCmd.PersistentFlags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup deletion")
 	deleteCmd.PersistentFlags().BoolVar(&useSentinelTime, UseSentinelTimeFlag, false, UseSentinelTimeDescription)
 }
@@ -14,9 +14,14 @@ func runAOFBackupFetch(ctx context.Context, backupName string) error {
 		return err
 	}
 
+	until, err := parseFetchUntil()
+	if err != nil {
+		return err
+	}
+
 	sourceStorageFolder := uploader.Folder()
 	uploader.ChangeDirectory(utility.BaseBackupPath + "/")
 
-	return redisdb.HandleAofFetchPush(ctx, sourceStorageFolder, uploader, backupName, redisVersion,
+	return redisdb.HandleAofFetchPush(ctx, sourceStorageFolder, uploader, backupName, redisVersion, until,
 		skipBackupDownloadFlag, skipCheckFlag)
 }
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wal-g/wal-g/internal"
+	redisdb "github.com/wal-g/wal-g/internal/databases/redis"
+)
+
+const (
+	aofPushShortDescription = "Uploads the sealed incremental AOF files for point in time recovery"
+	continuousFlag          = "continuous"
+	intervalFlag            = "interval"
+)
+
+var (
+	continuous      bool
+	aofPushInterval time.Duration
+)
+
+var aofPushCmd = &cobra.Command{
+	Use:   "aof-push",
+	Short: aofPushShortDescription,
+	Args:  cobra.NoArgs,
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		if aofPushInterval <= 0 {
+			return fmt.Errorf("--%s should be positive", intervalFlag)
+		}
+		return nil
+	},
+	RunE: runAOFPush,
+}
+
+func runAOFPush(cmd *cobra.Command, _ []string) error {
+	internal.ConfigureLimiters()
+	ctx := cmd.Context()
+
+	uploader, err := internal.ConfigureUploader(ctx)
+	if err != nil {
+		return err
+	}
+	return redisdb.HandleAOFPush(ctx, redisdb.AOFPushArgs{
+		Uploader:   uploader,
+		Continuous: continuous,
+		Interval:   aofPushInterval,
+	})
+}
+
+func init() {
+	aofPushCmd.Flags().BoolVar(&continuous, continuousFlag, false,
+		"Keep running and upload each incremental AOF file as soon as Redis seals it")
+	aofPushCmd.Flags().DurationVar(&aofPushInterval, intervalFlag, 10*time.Second,
+		"How often to check the AOF manifest in the continuous mode")
+	cmd.AddCommand(aofPushCmd)
+}
@@ -21,6 +21,7 @@ var (
 	purgeGarbage bool
 	retainAfter  string
 	retainCount  uint
+	retainPolicy internal.GFSRetentionPolicy
 )
 
 // purgeCmd represents the command for purging old backups (for deleting separate backup see deleteCmd)
@@ -30,6 +31,14 @@ var purgeCmd = &cobra.Command{
 	Run:   runPurge,
 }
 
+var deleteAutoCmd = &cobra.Command{
+	Use:   internal.DeleteAutoUsage,
+	Short: internal.DeleteAutoShortDescription,
+	Long:  internal.DeleteAutoLongDescription,
+	Args:  cobra.NoArgs,
+	Run:   runDeleteAuto,
+}
+
 func runPurge(cmd *cobra.Command, args []string) {
 	opts := []redis.PurgeOption{
 		redis.PurgeDryRun(!confirmed),
@@ -49,6 +58,27 @@ func runPurge(cmd *cobra.Command, args []string) {
 		opts = append(opts, redis.PurgeRetainCount(int(retainCount)))
 	}
 
+	if !retainPolicy.IsEmpty() {
+		err := retainPolicy.Validate()
+		tracelog.ErrorLogger.FatalfOnError("Invalid retention policy: %v", err)
+		opts = append(opts, redis.PurgeRetainPolicy(retainPolicy))
+	}
+
+	handlePurge(cmd, opts)
+}
+
+func runDeleteAuto(cmd *cobra.Command, args []string) {
+	retention, err := internal.GetRetentionConfig()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	handlePurge(cmd, []redis.PurgeOption{
+		redis.PurgeDryRun(!confirmed),
+		redis.PurgeGarbage(purgeGarbage),
+		redis.PurgeRetention(retention),
+	})
+}
+
+func handlePurge(cmd *cobra.Command, opts []redis.PurgeOption) {
 	st, err := internal.ConfigureStorage(cmd.Context())
 	tracelog.ErrorLogger.FatalOnError(err)
 
@@ -64,4 +94,9 @@ func init() {
 	purgeCmd.Flags().BoolVar(&purgeGarbage, purgeGarbageFlag, false, "Purge garbage in backup folder")
 	purgeCmd.Flags().StringVar(&retainAfter, retainAfterFlag, "", "Keep backups newer")
 	purgeCmd.Flags().UintVar(&retainCount, retainCountFlag, 0, "Keep minimum count, except permanent backups")
+	internal.AddGFSRetentionPolicyFlags(purgeCmd.Flags(), &retainPolicy, "retain-")
+
+	purgeCmd.AddCommand(deleteAutoCmd)
+	deleteAutoCmd.Flags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup and garbage purge")
+	deleteAutoCmd.Flags().BoolVar(&purgeGarbage, purgeGarbageFlag, false, "Purge garbage in backup folder")
 }
@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"bufio"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	redisdb "github.com/wal-g/wal-g/internal/databases/redis"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	backupInspectShortDescription = "Prints the keys of an RDB backup and exports them as RESTORE commands"
+	backupInspectLongDescription  = "Streams the RDB backup from storage and parses it without a Redis instance. " +
+		"The keys matching --pattern are printed with their database, type, TTL in seconds at the backup time " +
+		"(-1 if the key doesn't expire) and memory usage estimation in bytes. With --export the matching keys " +
+		"are written to the file as RESP encoded RESTORE commands which can be replayed with redis-cli --pipe."
+	patternFlag = "pattern"
+	exportFlag  = "export"
+	replaceFlag = "replace"
+)
+
+var (
+	inspectPattern string
+	inspectExport  string
+	inspectReplace bool
+)
+
+var backupInspectCmd = &cobra.Command{
+	Use:   "backup-inspect backup-name",
+	Short: backupInspectShortDescription,
+	Long:  backupInspectLongDescription,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBackupInspect,
+}
+
+func runBackupInspect(cmd *cobra.Command, args []string) error {
+	internal.ConfigureLimiters()
+	storage, err := internal.ConfigureStorage(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	output := bufio.NewWriter(os.Stdout)
+	defer func() { tracelog.ErrorLogger.PrintOnError(output.Flush()) }()
+	inspectArgs := redisdb.BackupInspectArgs{
+		Pattern: inspectPattern,
+		Output:  output,
+		Replace: inspectReplace,
+	}
+	if inspectExport != "" {
+		exportFile, err := os.Create(inspectExport)
+		if err != nil {
+			return err
+		}
+		defer utility.LoggedClose(exportFile, "failed to close export file")
+		export := bufio.NewWriter(exportFile)
+		defer func() { tracelog.ErrorLogger.PrintOnError(export.Flush()) }()
+		inspectArgs.Export = export
+	}
+	return redisdb.HandleBackupInspect(cmd.Context(), storage.RootFolder(), args[0], inspectArgs)
+}
+
+func init() {
+	backupInspectCmd.Flags().StringVar(&inspectPattern, patternFlag, "*", "Glob style pattern of the keys as in KEYS")
+	backupInspectCmd.Flags().StringVar(&inspectExport, exportFlag, "",
+		"File to write the RESTORE commands of the matching keys to")
+	backupInspectCmd.Flags().BoolVar(&inspectReplace, replaceFlag, false,
+		"Add REPLACE to the exported RESTORE commands to overwrite the existing keys")
+	cmd.AddCommand(backupInspectCmd)
+}
@@ -61,3 +61,20 @@ func TestValidateBackupFetchRequiresAOFRedisVersion(t *testing.T) {
 	tsFetchBackup = t.TempDir()
 	assert.ErrorContains(t, validateBackupFetch(nil, nil), "--redis-version is required")
 }
+
+func TestValidateFetchUntil(t *testing.T) {
+	previousType, previousUntil := backupType, fetchUntil
+	t.Cleanup(func() {
+		backupType, fetchUntil = previousType, previousUntil
+	})
+
+	backupType = rdbBackupType
+	fetchUntil = "2026-07-21T12:00:00Z"
+	assert.ErrorContains(t, validateFetchUntil(), "only valid for AOF backup types")
+
+	backupType = aofBackupType
+	assert.NoError(t, validateFetchUntil())
+
+	fetchUntil = "yesterday"
+	assert.ErrorContains(t, validateFetchUntil(), "invalid --until value")
+}
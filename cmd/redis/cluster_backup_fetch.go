@@ -0,0 +1,43 @@
+package redis
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	redisdb "github.com/wal-g/wal-g/internal/databases/redis"
+)
+
+const (
+	clusterBackupFetchShortDescription = "Restores the cluster backup to the masters of the Redis Cluster"
+	clusterBackupFetchLongDescription  = "Discovers the masters of the Redis Cluster through the node set with " +
+		"WALG_REDIS_HOST and WALG_REDIS_PORT and restores every shard backup to the master serving its slots " +
+		"with WALG_STREAM_RESTORE_COMMAND, WALG_REDIS_HOST and WALG_REDIS_PORT point to the master. " +
+		"Every shard has to be served by a single master, and a master can't serve the slots of several shards."
+)
+
+var clusterBackupFetchCmd = &cobra.Command{
+	Use:     "cluster-backup-fetch backup-name",
+	Short:   clusterBackupFetchShortDescription,
+	Long:    clusterBackupFetchLongDescription,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: validateClusterBackupFetch,
+	RunE:    runClusterBackupFetch,
+}
+
+func validateClusterBackupFetch(_ *cobra.Command, _ []string) error {
+	conf.RequiredSettings[conf.NameStreamRestoreCmd] = true
+	return internal.AssertRequiredSettingsSet()
+}
+
+func runClusterBackupFetch(cmd *cobra.Command, args []string) error {
+	internal.ConfigureLimiters()
+	storage, err := internal.ConfigureStorage(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return redisdb.HandleClusterBackupFetch(cmd.Context(), storage.RootFolder(), args[0])
+}
+
+func init() {
+	cmd.AddCommand(clusterBackupFetchCmd)
+}
@@ -0,0 +1,35 @@
+package redis
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/wal-g/wal-g/internal"
+	redisdb "github.com/wal-g/wal-g/internal/databases/redis"
+)
+
+const (
+	clusterBackupPushShortDescription = "Creates and uploads RDB backups of all masters of the Redis Cluster"
+	clusterBackupPushLongDescription  = "Discovers the masters of the Redis Cluster through the node set with " +
+		"WALG_REDIS_HOST and WALG_REDIS_PORT and requests the RDB snapshot from every master in parallel " +
+		"the way a replica does. If WALG_STREAM_CREATE_COMMAND is set, it runs for every master instead " +
+		"with WALG_REDIS_HOST and WALG_REDIS_PORT pointing to it. The cluster backup sentinel stores the slot ranges " +
+		"of every shard backup."
+)
+
+var clusterBackupPushCmd = &cobra.Command{
+	Use:   "cluster-backup-push",
+	Short: clusterBackupPushShortDescription,
+	Long:  clusterBackupPushLongDescription,
+	Args:  cobra.NoArgs,
+	RunE:  runClusterBackupPush,
+}
+
+func runClusterBackupPush(cmd *cobra.Command, _ []string) error {
+	internal.ConfigureLimiters()
+	return redisdb.HandleClusterBackupPush(cmd.Context(), redisdb.ClusterBackupPushArgs{Permanent: permanent})
+}
+
+func init() {
+	clusterBackupPushCmd.Flags().BoolVarP(&permanent, PermanentFlag, PermanentShorthand, false,
+		"Push backup with the permanent flag")
+	cmd.AddCommand(clusterBackupPushCmd)
+}
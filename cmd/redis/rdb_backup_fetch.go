@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/wal-g/wal-g/internal"
@@ -22,6 +23,7 @@ const (
 	SkipChecksFlag                = "skip-checks"
 	SkipChecksDescription         = "Skip checking Redis version compatibility with the backup"
 	redisVersionFlag              = "redis-version"
+	untilFlag                     = "until"
 )
 
 var (
@@ -30,6 +32,7 @@ var (
 	skipCheckFlag          bool
 	redisVersion           string
 	tsFetchBackup          string
+	fetchUntil             string
 )
 
 var backupFetchCmd = &cobra.Command{
@@ -50,6 +53,9 @@ func validateBackupFetch(cmd *cobra.Command, args []string) error {
 	if err := validateTSBackupFetchInput(); err != nil {
 		return err
 	}
+	if err := validateFetchUntil(); err != nil {
+		return err
+	}
 	if backupType == rdbBackupType || backupType == rdbTSBackupType {
 		conf.RequiredSettings[conf.NameStreamRestoreCmd] = true
 		return internal.AssertRequiredSettingsSet()
@@ -71,6 +77,28 @@ func validateTSBackupFetchInput() error {
 	return nil
 }
 
+func validateFetchUntil() error {
+	if fetchUntil == "" {
+		return nil
+	}
+	if backupType != aofBackupType && backupType != aofTSBackupType {
+		return fmt.Errorf("--%s is only valid for AOF backup types", untilFlag)
+	}
+	_, err := parseFetchUntil()
+	return err
+}
+
+func parseFetchUntil() (time.Time, error) {
+	if fetchUntil == "" {
+		return time.Time{}, nil
+	}
+	until, err := time.Parse(time.RFC3339, fetchUntil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s value %q: %w", untilFlag, fetchUntil, err)
+	}
+	return until, nil
+}
+
 func runBackupFetch(cmd *cobra.Command, args []string) error {
 	internal.ConfigureLimiters()
 
@@ -140,6 +168,8 @@ func init() {
 	backupFetchCmd.Flags().BoolVar(&skipCheckFlag, SkipChecksFlag, false, SkipChecksDescription)
 	backupFetchCmd.Flags().StringVar(&redisVersion, redisVersionFlag, "", "Redis version for AOF backup compatibility checks")
 	backupFetchCmd.Flags().StringVar(&tsFetchBackup, tsBackupFlag, "", "Tiered-storage restore directory")
+	backupFetchCmd.Flags().StringVar(&fetchUntil, untilFlag, "",
+		"Time in RFC3339 to restore an AOF backup to with the archived incremental AOF files")
 	backupFetchCmd.Flags().StringVarP(&backupType, typeFlag, typeShorthand, rdbBackupType,
 		"Backup type: rdb, aof, rdb_ts, aof_ts, or ts")
 	cmd.AddCommand(backupFetchCmd)
@@ -10,13 +10,16 @@ const backupPushShortDescription = "Creates new backup and pushes it to the stor
 
 var backupPushDatabases []string
 var backupUpdateLatest bool
+var backupDifferential bool
+var backupCopyOnly bool
 
 var backupPushCmd = &cobra.Command{
 	Use:   "backup-push",
 	Short: backupPushShortDescription,
 	Run: func(cmd *cobra.Command, args []string) {
 		internal.ConfigureLimiters()
-		sqlserver.HandleBackupPush(cmd.Context(), backupPushDatabases, backupUpdateLatest)
+		sqlserver.HandleBackupPush(cmd.Context(), backupPushDatabases, backupUpdateLatest,
+			backupDifferential, backupCopyOnly)
 	},
 }
 
@@ -25,5 +28,9 @@ func init() {
 		"List of databases to backup. All not-system databases as default")
 	backupPushCmd.PersistentFlags().BoolVarP(&backupUpdateLatest, "update-latest", "u", false,
 		"Update latest backup instead of creating new one")
+	backupPushCmd.PersistentFlags().BoolVar(&backupDifferential, "differential", false,
+		"Create differential backup based on the latest full backup")
+	backupPushCmd.PersistentFlags().BoolVar(&backupCopyOnly, "copy-only", false,
+		"Create copy-only full backup, which doesn't affect the base of differential backups")
 	cmd.AddCommand(backupPushCmd)
 }
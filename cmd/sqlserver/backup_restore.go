@@ -11,6 +11,7 @@ const backupRestoreShortDescription = "Restores backup from the storage"
 var restoreDatabases []string
 var restoreFrom []string
 var restoreNoRecovery bool
+var restoreUntilTS string
 
 var backupRestoreCmd = &cobra.Command{
 	Use:   "backup-restore backup-name",
@@ -18,7 +19,8 @@ var backupRestoreCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		internal.ConfigureLimiters()
-		sqlserver.HandleBackupRestore(cmd.Context(), args[0], restoreDatabases, restoreFrom, restoreNoRecovery)
+		sqlserver.HandleBackupRestore(cmd.Context(), args[0], restoreUntilTS, restoreDatabases, restoreFrom,
+			restoreNoRecovery)
 	},
 }
 
@@ -30,5 +32,7 @@ func init() {
 			"those every database is restored from self backup")
 	backupRestoreCmd.PersistentFlags().BoolVarP(&restoreNoRecovery, "no-recovery", "n", false,
 		"Restore with NO_RECOVERY option")
+	backupRestoreCmd.PersistentFlags().StringVar(&restoreUntilTS, "until", "",
+		"time in RFC3339 for PITR, the log backups are restored up to this time")
 	cmd.AddCommand(backupRestoreCmd)
 }
@@ -6,11 +6,13 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/databases/sqlserver"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
 )
 
 var confirmed = false
+var retainPolicy internal.GFSRetentionPolicy
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
@@ -33,6 +35,21 @@ var deleteRetainCmd = &cobra.Command{
 	Run:       runDeleteRetain,
 }
 
+var deleteRetainPolicyCmd = &cobra.Command{
+	Use:     internal.DeleteRetainPolicyUsageExample,
+	Example: internal.DeleteRetainPolicyExamples,
+	Args:    internal.DeleteRetainPolicyArgsValidator,
+	Run:     runDeleteRetainPolicy,
+}
+
+var deleteAutoCmd = &cobra.Command{
+	Use:   internal.DeleteAutoUsage,
+	Short: internal.DeleteAutoShortDescription,
+	Long:  internal.DeleteAutoLongDescription,
+	Args:  cobra.NoArgs,
+	Run:   runDeleteAuto,
+}
+
 var deleteEverythingCmd = &cobra.Command{
 	Use:       internal.DeleteEverythingUsageExample,
 	Example:   internal.DeleteEverythingExamples,
@@ -62,9 +79,27 @@ func runDeleteRetain(cmd *cobra.Command, args []string) {
 	deleteHandler.HandleDeleteRetain(cmd.Context(), args, confirmed)
 }
 
+func runDeleteRetainPolicy(cmd *cobra.Command, args []string) {
+	deleteHandler, err := newSQLServerDeleteHandler(cmd.Context())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteRetainPolicy(cmd.Context(), retainPolicy, confirmed)
+}
+
+func runDeleteAuto(cmd *cobra.Command, args []string) {
+	retention, err := internal.GetRetentionConfig()
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler, err := newSQLServerDeleteHandler(cmd.Context())
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	deleteHandler.HandleDeleteAuto(cmd.Context(), retention, confirmed)
+}
+
 func init() {
 	cmd.AddCommand(deleteCmd)
-	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteEverythingCmd)
+	internal.AddGFSRetentionPolicyFlags(deleteRetainPolicyCmd.Flags(), &retainPolicy, "")
+	deleteCmd.AddCommand(deleteBeforeCmd, deleteRetainCmd, deleteRetainPolicyCmd, deleteAutoCmd, deleteEverythingCmd)
 	deleteCmd.PersistentFlags().BoolVar(&confirmed, internal.ConfirmFlag, false, "Confirms backup deletion")
 }
 
@@ -79,9 +114,9 @@ func newSQLServerDeleteHandler(ctx context.Context) (*internal.DeleteHandler, er
 		return nil, err
 	}
 
-	backupObjects := make([]internal.BackupObject, 0, len(backups))
-	for _, object := range backups {
-		backupObjects = append(backupObjects, internal.NewDefaultBackupObject(object))
+	backupObjects, err := sqlserver.MakeBackupObjects(ctx, folder, backups)
+	if err != nil {
+		return nil, err
 	}
 
 	return internal.NewDeleteHandler(folder, backupObjects, makeLessFunc()), nil
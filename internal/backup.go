@@ -9,6 +9,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/checksum"
 	"github.com/wal-g/wal-g/internal/multistorage"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
@@ -85,6 +86,11 @@ func (backup *Backup) GetTarPartitionFolder() storage.Folder {
 	return backup.Folder.GetSubFolder(backup.Name + TarPartitionFolderName)
 }
 
+// FetchChecksumManifest returns the checksums recorded at upload time, nil if the backup has no checksum manifest
+func (backup *Backup) FetchChecksumManifest(ctx context.Context) (*checksum.Manifest, error) {
+	return ReadChecksumManifest(ctx, NewFolderReader(backup.Folder), storage.JoinPath(backup.Name, checksum.ManifestFileName))
+}
+
 // SentinelExists checks that the sentinel file of the specified backup exists.
 func (backup *Backup) SentinelExists(ctx context.Context) (bool, error) {
 	return backup.Folder.Exists(ctx, backup.getStopSentinelPath())
@@ -16,7 +16,7 @@ type BackupFileDescription struct {
 }
 
 func NewBackupFileDescription(isIncremented, isSkipped bool, modTime time.Time) *BackupFileDescription {
-	return &BackupFileDescription{isIncremented, isSkipped, modTime, nil, 0}
+	return &BackupFileDescription{IsIncremented: isIncremented, IsSkipped: isSkipped, MTime: modTime}
 }
 
 type CorruptBlocksInfo struct {
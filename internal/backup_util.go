@@ -22,6 +22,7 @@ type NoBackupsFoundError struct {
 type TimedBackup interface {
 	Name() string
 	StartTime() time.Time
+	FinishTime() time.Time
 	IsPermanent() bool
 }
 
@@ -209,9 +210,21 @@ func FolderSize(ctx context.Context, folder storage.Folder, path string) (int64,
 func SplitPurgingBackups(backups []TimedBackup,
 	retainCount *int,
 	retainAfter *time.Time) (purge, retain map[string]bool, err error) {
+	return SplitPurgingBackupsWithPolicy(backups, retainCount, retainAfter, nil)
+}
+
+// SplitPurgingBackupsWithPolicy is SplitPurgingBackups which also retains the backups selected by the GFS policy
+func SplitPurgingBackupsWithPolicy(backups []TimedBackup,
+	retainCount *int,
+	retainAfter *time.Time,
+	retainPolicy *GFSRetentionPolicy) (purge, retain map[string]bool, err error) {
 	retain = make(map[string]bool)
 	purge = make(map[string]bool)
-	retainAll := retainCount == nil && retainAfter == nil
+	retainAll := retainCount == nil && retainAfter == nil && retainPolicy == nil
+	policyRetained := map[string]string{}
+	if retainPolicy != nil {
+		policyRetained = SelectGFSRetainedBackups(backups, *retainPolicy)
+	}
 	retainedCount := 0
 	for i := range backups {
 		backup := backups[i]
@@ -240,6 +253,12 @@ func SplitPurgingBackups(backups []TimedBackup,
 			retain[backup.Name()] = true
 			continue
 		}
+
+		if reason, ok := policyRetained[backup.Name()]; ok {
+			tracelog.DebugLogger.Printf("Preserving backup due to %s retention policy: %s", reason, backup.Name())
+			retain[backup.Name()] = true
+			continue
+		}
 		purge[backup.Name()] = true
 	}
 	return purge, retain, nil
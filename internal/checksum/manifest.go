@@ -0,0 +1,30 @@
+package checksum
+
+import "strings"
+
+// ManifestFileName is the name of the object which holds the checksums
+// of the objects stored in the same folder and its subfolders
+const ManifestFileName = "checksums.json"
+
+type Manifest struct {
+	Algorithm string `json:"algorithm"`
+	// Checksums maps the object path relative to the manifest folder to the checksum of its stored bytes
+	Checksums map[string]string `json:"checksums"`
+}
+
+func NewManifest() *Manifest {
+	return &Manifest{
+		Algorithm: CreateCalculator().Algorithm(),
+		Checksums: make(map[string]string),
+	}
+}
+
+// ObjectManifestName returns the name of the manifest stored next to a single object,
+// e.g. 000000010000000000000001.checksums.json for the WAL segment 000000010000000000000001
+func ObjectManifestName(objectName string) string {
+	return objectName + "." + ManifestFileName
+}
+
+func IsManifest(objectPath string) bool {
+	return strings.HasSuffix(objectPath, ManifestFileName)
+}
@@ -0,0 +1,52 @@
+package checksum
+
+import (
+	"strings"
+	"sync"
+)
+
+// Recorder collects the checksums of the uploaded objects by their full storage paths
+type Recorder struct {
+	mutex     sync.Mutex
+	checksums map[string]string
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{checksums: make(map[string]string)}
+}
+
+func (recorder *Recorder) Record(objectPath string, checksum string) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.checksums[objectPath] = checksum
+}
+
+func (recorder *Recorder) Get(objectPath string) (string, bool) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	checksum, ok := recorder.checksums[objectPath]
+	return checksum, ok
+}
+
+// Remove forgets the checksums of the objects, e.g. after they are uploaded in a manifest
+func (recorder *Recorder) Remove(objectPaths ...string) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	for _, objectPath := range objectPaths {
+		delete(recorder.checksums, objectPath)
+	}
+}
+
+// Manifest returns the manifest of the recorded objects stored under the folder path
+func (recorder *Recorder) Manifest(folderPath string) *Manifest {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	folderPath = strings.Trim(folderPath, "/") + "/"
+	manifest := NewManifest()
+	for objectPath, checksum := range recorder.checksums {
+		if relativePath, ok := strings.CutPrefix(objectPath, folderPath); ok {
+			manifest.Checksums[relativePath] = checksum
+		}
+	}
+	return manifest
+}
@@ -0,0 +1,45 @@
+package checksum
+
+import (
+	"fmt"
+	"io"
+)
+
+type MismatchError struct {
+	ObjectPath string
+	Expected   string
+	Actual     string
+}
+
+func (err MismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected %s, got %s", err.ObjectPath, err.Expected, err.Actual)
+}
+
+// VerifyingReader calculates the checksum of the read data and fails
+// at the end of the stream if it differs from the expected one
+type VerifyingReader struct {
+	io.ReadCloser
+	objectPath string
+	expected   string
+	calculator *Calculator
+}
+
+func NewVerifyingReader(underlying io.ReadCloser, objectPath, expected string) *VerifyingReader {
+	return &VerifyingReader{
+		ReadCloser: underlying,
+		objectPath: objectPath,
+		expected:   expected,
+		calculator: CreateCalculator(),
+	}
+}
+
+func (reader *VerifyingReader) Read(data []byte) (n int, err error) {
+	n, err = reader.ReadCloser.Read(data)
+	reader.calculator.AddData(data[0:n])
+	if err == io.EOF {
+		if actual := reader.calculator.Checksum(); actual != reader.expected {
+			return n, MismatchError{ObjectPath: reader.objectPath, Expected: reader.expected, Actual: actual}
+		}
+	}
+	return
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/checksum"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// UploadChecksumManifest uploads the manifest with the recorded checksums of the objects
+// stored in the folder relative to the uploader folder. Does nothing if the checksums are not enabled.
+// The uploaded checksums are removed from the recorder.
+func UploadChecksumManifest(ctx context.Context, uploader Uploader, folderPath string) error {
+	recorder := uploader.Checksums()
+	if recorder == nil {
+		return nil
+	}
+	fullFolderPath := storage.JoinPath(uploader.Folder().GetPath(), folderPath)
+	manifest := recorder.Manifest(fullFolderPath)
+	err := uploader.UploadJSON(ctx, storage.JoinPath(folderPath, checksum.ManifestFileName), manifest)
+	if err != nil {
+		return err
+	}
+	recorder.Remove(storage.JoinPath(fullFolderPath, checksum.ManifestFileName))
+	for objectPath := range manifest.Checksums {
+		recorder.Remove(storage.JoinPath(fullFolderPath, objectPath))
+	}
+	return nil
+}
+
+// ReadChecksumManifest reads the checksum manifest, returns nil if there is no manifest
+func ReadChecksumManifest(ctx context.Context, reader StorageFolderReader, manifestPath string) (*checksum.Manifest, error) {
+	manifestReader, exists, err := TryDownloadFile(ctx, reader, manifestPath)
+	if err != nil || !exists {
+		return nil, err
+	}
+	defer utility.LoggedClose(manifestReader, "")
+
+	manifest := &checksum.Manifest{}
+	err = json.NewDecoder(manifestReader).Decode(manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the checksum manifest %s", manifestPath)
+	}
+	if manifest.Algorithm != checksum.CreateCalculator().Algorithm() {
+		tracelog.WarningLogger.Printf("Checksum manifest %s uses unsupported algorithm %q, will not verify checksums\n",
+			manifestPath, manifest.Algorithm)
+		return nil, nil
+	}
+	return manifest, nil
+}
+
+// ChecksumVerifyingFolderReader verifies the downloaded objects against the checksums from the manifest.
+// The objects which are not in the manifest are read as is.
+type ChecksumVerifyingFolderReader struct {
+	StorageFolderReader
+	checksums map[string]string
+}
+
+func NewChecksumVerifyingFolderReader(reader StorageFolderReader, manifest *checksum.Manifest) StorageFolderReader {
+	if manifest == nil {
+		return reader
+	}
+	return &ChecksumVerifyingFolderReader{StorageFolderReader: reader, checksums: manifest.Checksums}
+}
+
+func (reader *ChecksumVerifyingFolderReader) ReadObject(ctx context.Context, objectRelativePath string) (io.ReadCloser, error) {
+	readCloser, err := reader.StorageFolderReader.ReadObject(ctx, objectRelativePath)
+	if err != nil {
+		return nil, err
+	}
+	expected, ok := reader.checksums[objectRelativePath]
+	if !ok {
+		return readCloser, nil
+	}
+	return checksum.NewVerifyingReader(readCloser, objectRelativePath, expected), nil
+}
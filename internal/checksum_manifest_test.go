@@ -0,0 +1,120 @@
+package internal_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/checksum"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/ioextensions"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+)
+
+func TestChecksumManifest(t *testing.T) {
+	folder := memory.NewFolder("in_memory/", memory.NewKVS())
+	uploader := internal.NewRegularUploader(lz4.Compressor{}, folder)
+	uploader.EnableChecksums()
+
+	content := []byte("some WAL file content")
+	err := uploader.UploadFile(t.Context(),
+		ioextensions.NewNamedReaderImpl(bytes.NewReader(content), "000000010000000000000001"))
+	require.NoError(t, err)
+	require.NoError(t, internal.UploadChecksumManifest(t.Context(), uploader, ""))
+
+	folderReader := internal.NewFolderReader(folder)
+	manifest, err := internal.ReadChecksumManifest(t.Context(), folderReader, checksum.ManifestFileName)
+	require.NoError(t, err)
+	require.NotNil(t, manifest)
+	assert.Contains(t, manifest.Checksums, "000000010000000000000001.lz4")
+
+	dstPath := filepath.Join(t.TempDir(), "000000010000000000000001")
+	err = internal.DownloadFileTo(t.Context(), internal.NewChecksumVerifyingFolderReader(folderReader, manifest),
+		"000000010000000000000001", dstPath)
+	require.NoError(t, err)
+	downloaded, err := os.ReadFile(dstPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+
+	manifest.Checksums["000000010000000000000001.lz4"] = "0123456789"
+	dstPath = filepath.Join(t.TempDir(), "000000010000000000000001")
+	err = internal.DownloadFileTo(t.Context(), internal.NewChecksumVerifyingFolderReader(folderReader, manifest),
+		"000000010000000000000001", dstPath)
+	assert.ErrorAs(t, err, &checksum.MismatchError{})
+}
+
+func TestReadChecksumManifest_NoManifest(t *testing.T) {
+	folder := memory.NewFolder("in_memory/", memory.NewKVS())
+	manifest, err := internal.ReadChecksumManifest(t.Context(), internal.NewFolderReader(folder), checksum.ManifestFileName)
+	require.NoError(t, err)
+	assert.Nil(t, manifest)
+
+	manifestBytes, err := json.Marshal(checksum.Manifest{Algorithm: "md5"})
+	require.NoError(t, err)
+	require.NoError(t, folder.PutObject(t.Context(), checksum.ManifestFileName, bytes.NewReader(manifestBytes)))
+	manifest, err = internal.ReadChecksumManifest(t.Context(), internal.NewFolderReader(folder), checksum.ManifestFileName)
+	require.NoError(t, err)
+	assert.Nil(t, manifest)
+}
+
+func TestUploadChecksumManifest_ForgetsUploadedChecksums(t *testing.T) {
+	folder := memory.NewFolder("in_memory/", memory.NewKVS())
+	uploader := internal.NewRegularUploader(lz4.Compressor{}, folder)
+	uploader.EnableChecksums()
+
+	require.NoError(t, uploader.Upload(t.Context(), "base_000/tar_partitions/part_1.tar", bytes.NewReader([]byte("backup"))))
+	require.NoError(t, uploader.Upload(t.Context(), "base_001/tar_partitions/part_1.tar", bytes.NewReader([]byte("other"))))
+	require.NoError(t, internal.UploadChecksumManifest(t.Context(), uploader, "base_000"))
+
+	assert.Empty(t, uploader.Checksums().Manifest("in_memory/base_000").Checksums)
+	assert.Len(t, uploader.Checksums().Manifest("in_memory/base_001").Checksums, 1)
+}
+
+type streamBuffer struct {
+	bytes.Buffer
+}
+
+func (*streamBuffer) Close() error {
+	return nil
+}
+
+func TestPushStream_ChecksumManifestVerifiedOnFetch(t *testing.T) {
+	viper.Set(conf.UploadChecksumsSetting, true)
+	viper.Set(conf.VerifyChecksumsSetting, true)
+	t.Cleanup(resetToDefaults)
+
+	folder := memory.NewFolder("in_memory/", memory.NewKVS())
+	uploader := internal.NewRegularUploader(lz4.Compressor{}, folder)
+	_, err := uploader.PushStreamWithName(t.Context(), bytes.NewReader([]byte("stream backup")), "stream_1")
+	require.NoError(t, err)
+	_, err = uploader.PushStreamWithName(t.Context(), bytes.NewReader([]byte("another stream")), "stream_2")
+	require.NoError(t, err)
+	assert.Nil(t, uploader.Checksums(), "the checksums are recorded by the stream uploader only")
+
+	backup, err := internal.NewBackup(folder, "stream_1")
+	require.NoError(t, err)
+	manifest, err := backup.FetchChecksumManifest(t.Context())
+	require.NoError(t, err)
+	require.NotNil(t, manifest)
+	assert.Len(t, manifest.Checksums, 1)
+	assert.Contains(t, manifest.Checksums, "stream.lz4")
+
+	var output streamBuffer
+	require.NoError(t, internal.DownloadAndDecompressStream(t.Context(), backup, &output))
+	assert.Equal(t, "stream backup", output.String())
+
+	// replace the stream object with the valid object of the other backup
+	other, err := folder.ReadObject(t.Context(), "stream_2/stream.lz4")
+	require.NoError(t, err)
+	require.NoError(t, folder.PutObject(t.Context(), "stream_1/stream.lz4", other))
+	err = internal.DownloadAndDecompressStream(t.Context(), backup, &streamBuffer{})
+	var mismatchErr checksum.MismatchError
+	assert.ErrorAs(t, err, &mismatchErr)
+}
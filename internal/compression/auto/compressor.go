@@ -0,0 +1,283 @@
+package auto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/internal/compression/none"
+	"github.com/wal-g/wal-g/internal/compression/zstd"
+	"github.com/wal-g/wal-g/internal/ioextensions"
+)
+
+const (
+	AlgorithmName = "auto"
+	FileExtension = "auto"
+
+	// SampleSize is the number of the first bytes of the object used to choose the compression method
+	SampleSize = 128 << 10
+
+	// The entropy thresholds in bits per byte: the data above IncompressibleEntropy is likely already compressed
+	// or encrypted, the data above FastEntropy compresses moderately, so the cheap lz4 is used for it
+	IncompressibleEntropy = 7.5
+	FastEntropy           = 6.0
+
+	// framesMethod is the name in the header of the objects consisting of frames, the objects
+	// with the name of the compression method in the header are compressed with it as a whole
+	framesMethod = "frames"
+)
+
+type compressor interface {
+	NewWriter(writer io.Writer) ioextensions.WriteFlushCloser
+}
+
+// encoder is the compressing writer which is reset to compress the next frame of its method after it is closed
+type encoder interface {
+	ioextensions.WriteFlushCloser
+	Reset(writer io.Writer)
+}
+
+// methods are the compressors the auto mode chooses from, by the name written to the object header
+var methods = map[string]compressor{
+	none.AlgorithmName: none.Compressor{},
+	lz4.AlgorithmName:  lz4.Compressor{},
+	zstd.AlgorithmName: zstd.Compressor{},
+}
+
+// Compressor chooses none, lz4 or zstd by the entropy of the data. The object consists of frames compressed
+// with their own methods: by default the method of the single frame is chosen by the first SampleSize bytes,
+// the tarballs start a new frame for every file with Writer.SetMethod. The name of the method is written
+// to the header of each frame, so the Decompressor needs no other metadata.
+type Compressor struct{}
+
+func (compressor Compressor) NewWriter(writer io.Writer) ioextensions.WriteFlushCloser {
+	return &Writer{writer: writer}
+}
+
+func (compressor Compressor) FileExtension() string {
+	return FileExtension
+}
+
+// ChooseMethod returns the name of the compression method for the data sample
+func ChooseMethod(sample []byte) string {
+	entropy := Entropy(sample)
+	switch {
+	case len(sample) == 0 || entropy >= IncompressibleEntropy:
+		return none.AlgorithmName
+	case entropy >= FastEntropy:
+		return lz4.AlgorithmName
+	default:
+		return zstd.AlgorithmName
+	}
+}
+
+// Entropy returns the Shannon entropy of the byte distribution in bits per byte
+func Entropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / float64(len(data))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Writer compresses the frames: it buffers the sample until the method of the frame is chosen,
+// then writes the frame header and compresses the frame data with the method
+type Writer struct {
+	writer io.Writer
+	sample bytes.Buffer
+	// method of the current frame, the method of the next frame is chosen by the sample when it is empty
+	method string
+	frame  ioextensions.WriteFlushCloser
+	// encoders are the writers of the methods used so far, they are reused by the next frames of the methods
+	encoders      map[string]encoder
+	headerWritten bool
+	isClosed      bool
+}
+
+// Method returns the compression method of the current frame, it is empty until the method is chosen
+func (w *Writer) Method() string {
+	return w.method
+}
+
+// SetMethod ends the current frame, the data written next is compressed with the method in the new frame.
+// The empty method makes the writer choose the method of the next frame by its sample.
+func (w *Writer) SetMethod(method string) error {
+	if w.isClosed {
+		return io.ErrClosedPipe
+	}
+	if _, ok := methods[method]; !ok && method != "" {
+		return fmt.Errorf("unknown compression method '%s'", method)
+	}
+	err := w.endFrame()
+	if err != nil {
+		return err
+	}
+	w.method = method
+	return nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.isClosed {
+		return 0, io.ErrClosedPipe
+	}
+	if w.frame != nil {
+		return w.frame.Write(p)
+	}
+	if w.method != "" {
+		err := w.startFrame()
+		if err != nil {
+			return 0, err
+		}
+		return w.frame.Write(p)
+	}
+
+	n := min(len(p), SampleSize-w.sample.Len())
+	w.sample.Write(p[:n])
+	if w.sample.Len() < SampleSize {
+		return n, nil
+	}
+	err := w.startSampledFrame()
+	if err != nil {
+		return n, err
+	}
+	written, err := w.frame.Write(p[n:])
+	return n + written, err
+}
+
+func (w *Writer) startSampledFrame() error {
+	w.method = ChooseMethod(w.sample.Bytes())
+	tracelog.DebugLogger.Printf("Compression method %s is chosen, the entropy of the sample is %.2f bits per byte",
+		w.method, Entropy(w.sample.Bytes()))
+
+	err := w.startFrame()
+	if err != nil {
+		return err
+	}
+	_, err = w.frame.Write(w.sample.Bytes())
+	w.sample = bytes.Buffer{}
+	return err
+}
+
+func (w *Writer) startFrame() error {
+	if !w.headerWritten {
+		_, err := w.writer.Write(encodeHeader(framesMethod))
+		if err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+	_, err := w.writer.Write(encodeHeader(w.method))
+	if err != nil {
+		return err
+	}
+	chunks := &chunkWriter{writer: w.writer}
+	if frame, ok := w.encoders[w.method]; ok {
+		frame.Reset(chunks)
+		w.frame = frame
+		return nil
+	}
+	w.frame = methods[w.method].NewWriter(chunks)
+	if frame, ok := w.frame.(encoder); ok {
+		if w.encoders == nil {
+			w.encoders = make(map[string]encoder)
+		}
+		w.encoders[w.method] = frame
+	}
+	return nil
+}
+
+// endFrame compresses the rest of the frame and writes the empty chunk ending it
+func (w *Writer) endFrame() error {
+	if w.frame == nil {
+		if w.sample.Len() == 0 {
+			return nil
+		}
+		err := w.startSampledFrame()
+		if err != nil {
+			return err
+		}
+	}
+	err := w.frame.Close()
+	if err != nil {
+		return err
+	}
+	w.frame = nil
+	_, err = w.writer.Write([]byte{0})
+	return err
+}
+
+// Flush chooses the method by the data written so far if the sample is not collected yet
+func (w *Writer) Flush() error {
+	if w.isClosed {
+		return nil
+	}
+	if w.frame == nil {
+		if w.sample.Len() == 0 {
+			return nil
+		}
+		err := w.startSampledFrame()
+		if err != nil {
+			return err
+		}
+	}
+	return w.frame.Flush()
+}
+
+func (w *Writer) Close() error {
+	if w.isClosed {
+		return nil
+	}
+	if !w.headerWritten && w.frame == nil && w.sample.Len() == 0 {
+		// the empty object has a single empty frame
+		if w.method == "" {
+			w.method = none.AlgorithmName
+		}
+		err := w.startFrame()
+		if err != nil {
+			return err
+		}
+	}
+	err := w.endFrame()
+	if err != nil {
+		return err
+	}
+	w.isClosed = true
+	return nil
+}
+
+// chunkWriter splits the compressed frame into the chunks prefixed with their lengths,
+// so the end of the frame is found without decompressing it
+type chunkWriter struct {
+	writer io.Writer
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	_, err := w.writer.Write(binary.AppendUvarint(nil, uint64(len(p))))
+	if err != nil {
+		return 0, err
+	}
+	return w.writer.Write(p)
+}
+
+// encodeHeader returns the header holding the method name: the name length byte followed by the name
+func encodeHeader(method string) []byte {
+	return append([]byte{byte(len(method))}, method...)
+}
@@ -0,0 +1,140 @@
+package auto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/internal/compression/none"
+	"github.com/wal-g/wal-g/internal/compression/zstd"
+)
+
+func TestCompressDecompress(t *testing.T) {
+	randomData := make([]byte, SampleSize*2)
+	_, err := rand.Read(randomData)
+	require.NoError(t, err)
+
+	// the bytes from the 128 values alphabet shuffled randomly have the entropy close to 7 bits
+	moderateData := make([]byte, SampleSize*2)
+	for i := range moderateData {
+		moderateData[i] = randomData[i] & 0x7f
+	}
+
+	testCases := []struct {
+		name   string
+		input  []byte
+		method string
+	}{
+		{name: "empty", input: nil, method: none.AlgorithmName},
+		{name: "random", input: randomData, method: none.AlgorithmName},
+		{name: "moderate", input: moderateData, method: lz4.AlgorithmName},
+		{name: "text", input: []byte(strings.Repeat("How much wood could a woodchuck chuck? ", 10000)),
+			method: zstd.AlgorithmName},
+		{name: "short text", input: []byte("How much wood could a woodchuck chuck?"), method: zstd.AlgorithmName},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var compressed bytes.Buffer
+			writer := Compressor{}.NewWriter(&compressed)
+			_, err := writer.Write(tc.input)
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+			assert.Equal(t, tc.method, writer.(*Writer).Method())
+
+			reader, err := Decompressor{}.Decompress(&compressed)
+			require.NoError(t, err)
+			decompressed, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.input), len(decompressed))
+			assert.True(t, bytes.Equal(tc.input, decompressed))
+		})
+	}
+}
+
+func TestDecompressUnknownMethod(t *testing.T) {
+	_, err := Decompressor{}.Decompress(bytes.NewReader(encodeHeader("brotli")))
+	assert.Error(t, err)
+}
+
+func TestCompressDecompressFrames(t *testing.T) {
+	text := []byte(strings.Repeat("How much wood could a woodchuck chuck? ", 1000))
+	randomData := make([]byte, SampleSize/2)
+	_, err := rand.Read(randomData)
+	require.NoError(t, err)
+
+	var compressed bytes.Buffer
+	writer := Compressor{}.NewWriter(&compressed).(*Writer)
+	var expected []byte
+	for _, frame := range []struct {
+		method string
+		data   []byte
+	}{
+		{method: zstd.AlgorithmName, data: text},
+		{method: none.AlgorithmName, data: randomData},
+		{method: none.AlgorithmName, data: nil},
+		{method: lz4.AlgorithmName, data: text},
+		{method: "", data: text},
+	} {
+		require.NoError(t, writer.SetMethod(frame.method))
+		_, err = writer.Write(frame.data)
+		require.NoError(t, err)
+		expected = append(expected, frame.data...)
+	}
+	require.NoError(t, writer.Close())
+	assert.Equal(t, zstd.AlgorithmName, writer.Method())
+
+	reader, err := Decompressor{}.Decompress(&compressed)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(expected, decompressed))
+}
+
+func TestFramesReuseEncoders(t *testing.T) {
+	text := []byte(strings.Repeat("How much wood could a woodchuck chuck? ", 1000))
+
+	var compressed bytes.Buffer
+	writer := Compressor{}.NewWriter(&compressed).(*Writer)
+	var expected []byte
+	for i := range 6 {
+		method := []string{zstd.AlgorithmName, lz4.AlgorithmName, none.AlgorithmName}[i%3]
+		require.NoError(t, writer.SetMethod(method))
+		_, err := writer.Write(text[i:])
+		require.NoError(t, err)
+		expected = append(expected, text[i:]...)
+	}
+	require.NoError(t, writer.Close())
+	assert.Len(t, writer.encoders, 3)
+
+	reader, err := Decompressor{}.Decompress(&compressed)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(expected, decompressed))
+}
+
+func TestDecompressSingleMethodObject(t *testing.T) {
+	var compressed bytes.Buffer
+	compressed.Write(encodeHeader(zstd.AlgorithmName))
+	zstdWriter := zstd.Compressor{}.NewWriter(&compressed)
+	_, err := zstdWriter.Write([]byte("How much wood could a woodchuck chuck?"))
+	require.NoError(t, err)
+	require.NoError(t, zstdWriter.Close())
+
+	reader, err := Decompressor{}.Decompress(&compressed)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "How much wood could a woodchuck chuck?", string(decompressed))
+}
+
+func TestSetUnknownMethod(t *testing.T) {
+	writer := Compressor{}.NewWriter(io.Discard).(*Writer)
+	assert.Error(t, writer.SetMethod("brotli"))
+}
@@ -0,0 +1,146 @@
+package auto
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/internal/compression/none"
+	"github.com/wal-g/wal-g/internal/compression/zstd"
+)
+
+type decompressor interface {
+	Decompress(src io.Reader) (io.ReadCloser, error)
+}
+
+var methodDecompressors = map[string]decompressor{
+	none.AlgorithmName: none.Decompressor{},
+	lz4.AlgorithmName:  lz4.Decompressor{},
+	zstd.AlgorithmName: zstd.Decompressor{},
+}
+
+type Decompressor struct{}
+
+// Decompress reads the method name from the header and decompresses the rest of the object with it,
+// the objects consisting of frames are decompressed frame by frame
+func (decompressor Decompressor) Decompress(src io.Reader) (io.ReadCloser, error) {
+	name, err := readHeader(src)
+	if err != nil {
+		return nil, fmt.Errorf("read the compression method header: %w", err)
+	}
+	if name == framesMethod {
+		return &framesReader{src: bufio.NewReader(src)}, nil
+	}
+
+	methodDecompressor, ok := methodDecompressors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown compression method in the header: '%s'", name)
+	}
+	return methodDecompressor.Decompress(src)
+}
+
+func (decompressor Decompressor) FileExtension() string {
+	return FileExtension
+}
+
+func readHeader(src io.Reader) (string, error) {
+	var nameLength [1]byte
+	_, err := io.ReadFull(src, nameLength[:])
+	if err != nil {
+		return "", err
+	}
+	name := make([]byte, nameLength[0])
+	_, err = io.ReadFull(src, name)
+	if err != nil {
+		return "", io.ErrUnexpectedEOF
+	}
+	return string(name), nil
+}
+
+// framesReader decompresses every frame with the method from its header
+type framesReader struct {
+	src    *bufio.Reader
+	chunks *chunkReader
+	frame  io.ReadCloser
+}
+
+func (r *framesReader) Read(p []byte) (int, error) {
+	for {
+		if r.frame == nil {
+			name, err := readHeader(r.src)
+			if err == io.EOF {
+				return 0, io.EOF
+			}
+			if err != nil {
+				return 0, fmt.Errorf("read the compression method header of the frame: %w", err)
+			}
+			methodDecompressor, ok := methodDecompressors[name]
+			if !ok {
+				return 0, fmt.Errorf("unknown compression method in the frame header: '%s'", name)
+			}
+			r.chunks = &chunkReader{src: r.src}
+			r.frame, err = methodDecompressor.Decompress(r.chunks)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		n, err := r.frame.Read(p)
+		if !errors.Is(err, io.EOF) {
+			return n, err
+		}
+		// skip the rest of the frame the decompressor did not read, e.g. the empty chunk ending it
+		_, err = io.Copy(io.Discard, r.chunks)
+		if err != nil {
+			return n, err
+		}
+		err = r.frame.Close()
+		r.frame = nil
+		if err != nil || n > 0 {
+			return n, err
+		}
+	}
+}
+
+func (r *framesReader) Close() error {
+	if r.frame == nil {
+		return nil
+	}
+	return r.frame.Close()
+}
+
+// chunkReader reads the chunks of the frame until the empty chunk ending it
+type chunkReader struct {
+	src  *bufio.Reader
+	left uint64
+	done bool
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, io.EOF
+	}
+	if r.left == 0 {
+		length, err := binary.ReadUvarint(r.src)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		if length == 0 {
+			r.done = true
+			return 0, io.EOF
+		}
+		r.left = length
+	}
+	n, err := r.src.Read(p[:min(uint64(len(p)), r.left)])
+	r.left -= uint64(n)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
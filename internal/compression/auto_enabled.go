@@ -0,0 +1,11 @@
+package compression
+
+import (
+	"github.com/wal-g/wal-g/internal/compression/auto"
+)
+
+func init() {
+	Decompressors = append(Decompressors, auto.Decompressor{})
+	Compressors[auto.AlgorithmName] = auto.Compressor{}
+	CompressingAlgorithms = append(CompressingAlgorithms, auto.AlgorithmName)
+}
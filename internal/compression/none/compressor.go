@@ -37,6 +37,12 @@ func (w *Writer) Flush() error {
 	return nil
 }
 
+// Reset makes the writer write to the writer again, even if it is closed
+func (w *Writer) Reset(writer io.Writer) {
+	w.writer = writer
+	w.isClosed = false
+}
+
 func (w *Writer) Close() error {
 	if w.isClosed {
 		return nil
@@ -14,8 +14,11 @@ const (
 
 // Compressor writes zstd-compressed streams. A zero Level keeps the historical
 // default (zstd.SpeedDefault), so an unconfigured Compressor behaves as before.
+// If the Dictionary is set, its ID is written to the frame header and the Decompressor
+// loads the dictionary with the DictionaryLoader.
 type Compressor struct {
-	Level zstd.EncoderLevel
+	Level      zstd.EncoderLevel
+	Dictionary []byte
 }
 
 func (compressor Compressor) NewWriter(writer io.Writer) ioextensions.WriteFlushCloser {
@@ -23,9 +26,11 @@ func (compressor Compressor) NewWriter(writer io.Writer) ioextensions.WriteFlush
 	if level == 0 { // level not set: preserve the previous default
 		level = zstd.SpeedDefault
 	}
-	zw, err := zstd.NewWriter(writer,
-		zstd.WithEncoderLevel(level),
-	)
+	options := []zstd.EOption{zstd.WithEncoderLevel(level)}
+	if len(compressor.Dictionary) > 0 {
+		options = append(options, zstd.WithEncoderDict(compressor.Dictionary))
+	}
+	zw, err := zstd.NewWriter(writer, options...)
 	if err != nil {
 		panic(err)
 	}
@@ -1,6 +1,7 @@
 package zstd
 
 import (
+	"bufio"
 	"io"
 
 	"github.com/klauspost/compress/zstd"
@@ -10,7 +11,25 @@ import (
 type Decompressor struct{}
 
 func (decompressor Decompressor) Decompress(src io.Reader) (io.ReadCloser, error) {
-	zstdReader, err := zstd.NewReader(computils.NewUntilEOFReader(src))
+	var loader DictionaryLoader
+	if loaderReader, ok := src.(dictionaryLoaderReader); ok {
+		src, loader = loaderReader.Reader, loaderReader.loader
+	}
+	bufferedSrc := bufio.NewReader(computils.NewUntilEOFReader(src))
+
+	var options []zstd.DOption
+	// the frame header refers to the dictionary if the stream is compressed with one
+	var header zstd.Header
+	headerBytes, _ := bufferedSrc.Peek(zstd.HeaderMaxSize)
+	if header.Decode(headerBytes) == nil && header.DictionaryID != 0 {
+		dictionary, err := loadDictionary(header.DictionaryID, loader)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, zstd.WithDecoderDicts(dictionary))
+	}
+
+	zstdReader, err := zstd.NewReader(bufferedSrc, options...)
 	if err != nil {
 		return nil, err
 	}
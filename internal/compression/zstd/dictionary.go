@@ -0,0 +1,77 @@
+package zstd
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+)
+
+// DefaultDictionarySize is the default max size of the trained dictionary, the same as the zstd CLI uses
+const DefaultDictionarySize = 112640
+
+// DictionaryLoader returns the dictionary by the ID written to the zstd frame header
+type DictionaryLoader func(id uint32) ([]byte, error)
+
+var dictionaries = struct {
+	sync.Mutex
+	loader DictionaryLoader
+	cache  map[uint32][]byte
+}{cache: make(map[uint32][]byte)}
+
+// SetDictionaryLoader sets the loader of the dictionaries required to decompress the frames compressed with them.
+// The loaded dictionaries are cached until the loader is replaced.
+func SetDictionaryLoader(loader DictionaryLoader) {
+	dictionaries.Lock()
+	defer dictionaries.Unlock()
+	dictionaries.loader = loader
+	dictionaries.cache = make(map[uint32][]byte)
+}
+
+type dictionaryLoaderReader struct {
+	io.Reader
+	loader DictionaryLoader
+}
+
+// WithDictionaryLoader makes Decompress load the dictionaries the stream refers to with the loader
+// instead of the one set by SetDictionaryLoader, e.g. from the storage the stream is read from.
+// The dictionary IDs are unique, so the loaded dictionaries share the cache.
+func WithDictionaryLoader(src io.Reader, loader DictionaryLoader) io.Reader {
+	return dictionaryLoaderReader{Reader: src, loader: loader}
+}
+
+func loadDictionary(id uint32, loader DictionaryLoader) ([]byte, error) {
+	dictionaries.Lock()
+	defer dictionaries.Unlock()
+	if dictionary, ok := dictionaries.cache[id]; ok {
+		return dictionary, nil
+	}
+	if loader == nil {
+		loader = dictionaries.loader
+	}
+	if loader == nil {
+		return nil, fmt.Errorf("zstd dictionary %d is required, but no dictionary loader is configured", id)
+	}
+	dictionary, err := loader(id)
+	if err != nil {
+		return nil, fmt.Errorf("load zstd dictionary %d: %w", id, err)
+	}
+	dictionaries.cache[id] = dictionary
+	return dictionary, nil
+}
+
+// TrainDictionary builds the dictionary from the samples, the dictionary ID is chosen randomly
+func TrainDictionary(samples [][]byte, maxSize int) ([]byte, error) {
+	return dict.BuildZstdDict(samples, dict.Options{MaxDictSize: maxSize, HashBytes: 6})
+}
+
+// DictionaryID returns the ID of the dictionary in the zstd format
+func DictionaryID(dictionary []byte) (uint32, error) {
+	info, err := zstd.InspectDictionary(dictionary)
+	if err != nil {
+		return 0, err
+	}
+	return info.ID(), nil
+}
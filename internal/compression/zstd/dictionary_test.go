@@ -0,0 +1,63 @@
+package zstd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressDecompressWithDictionary(t *testing.T) {
+	samples := make([][]byte, 0, 100)
+	for i := 0; i < 100; i++ {
+		var sample bytes.Buffer
+		for j := 0; j < 50; j++ {
+			fmt.Fprintf(&sample, "INSERT INTO accounts (id, owner, balance) VALUES (%d, 'owner_%d', %d);\n", i*50+j, j, i)
+		}
+		samples = append(samples, sample.Bytes())
+	}
+	dictionary, err := TrainDictionary(samples, 4096)
+	require.NoError(t, err)
+	id, err := DictionaryID(dictionary)
+	require.NoError(t, err)
+
+	in := []byte("INSERT INTO accounts (id, owner, balance) VALUES (100500, 'owner_42', 7);\n")
+	var comp bytes.Buffer
+	wc := Compressor{Dictionary: dictionary}.NewWriter(&comp)
+	_, err = wc.Write(in)
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+	compressed := comp.Bytes()
+
+	SetDictionaryLoader(nil)
+	_, err = Decompressor{}.Decompress(bytes.NewReader(compressed))
+	assert.Error(t, err)
+
+	// the loader of the stream takes precedence over the missing default one
+	rdr, err := Decompressor{}.Decompress(WithDictionaryLoader(bytes.NewReader(compressed),
+		func(uint32) ([]byte, error) { return dictionary, nil }))
+	require.NoError(t, err)
+	out, err := io.ReadAll(rdr)
+	require.NoError(t, err)
+	assert.Equal(t, in, out)
+
+	loads := 0
+	SetDictionaryLoader(func(loadID uint32) ([]byte, error) {
+		loads++
+		assert.Equal(t, id, loadID)
+		return dictionary, nil
+	})
+	defer SetDictionaryLoader(nil)
+	for i := 0; i < 2; i++ {
+		rdr, err := Decompressor{}.Decompress(bytes.NewReader(compressed))
+		require.NoError(t, err)
+		out, err := io.ReadAll(rdr)
+		require.NoError(t, err)
+		require.NoError(t, rdr.Close())
+		assert.Equal(t, in, out)
+	}
+	assert.Equal(t, 1, loads)
+}
@@ -43,6 +43,7 @@ const (
 	DeltaOriginSetting            = "WALG_DELTA_ORIGIN"
 	CompressionMethodSetting      = "WALG_COMPRESSION_METHOD"
 	ZstdLevelSetting              = "WALG_ZSTD_LEVEL"
+	ZstdDictionarySetting         = "WALG_ZSTD_DICTIONARY"
 	StoragePrefixSetting          = "WALG_STORAGE_PREFIX"
 	DiskRateLimitSetting          = "WALG_DISK_RATE_LIMIT"
 	NetworkRateLimitSetting       = "WALG_NETWORK_RATE_LIMIT"
@@ -51,6 +52,8 @@ const (
 	SkipRedundantTarsSetting      = "WALG_SKIP_REDUNDANT_TARS"
 	VerifyPageChecksumsSetting    = "WALG_VERIFY_PAGE_CHECKSUMS"
 	StoreAllCorruptBlocksSetting  = "WALG_STORE_ALL_CORRUPT_BLOCKS"
+	UploadChecksumsSetting        = "WALG_UPLOAD_CHECKSUMS"
+	VerifyChecksumsSetting        = "WALG_VERIFY_CHECKSUMS"
 	UseRatingComposerSetting      = "WALG_USE_RATING_COMPOSER"
 	UseCopyComposerSetting        = "WALG_USE_COPY_COMPOSER"
 	UseDatabaseComposerSetting    = "WALG_USE_DATABASE_COMPOSER"
@@ -80,6 +83,14 @@ const (
 	DirectIO                      = "WALG_DIRECT_IO"
 	DirectIOBlockCountSetting     = "WALG_DIRECT_IO_BLOCK_COUNT"
 
+	RetentionBackupCountSetting = "WALG_RETENTION_BACKUP_COUNT"
+	RetentionBackupAgeSetting   = "WALG_RETENTION_BACKUP_AGE"
+	RetentionDailySetting       = "WALG_RETENTION_DAILY"
+	RetentionWeeklySetting      = "WALG_RETENTION_WEEKLY"
+	RetentionMonthlySetting     = "WALG_RETENTION_MONTHLY"
+	RetentionYearlySetting      = "WALG_RETENTION_YEARLY"
+	RetentionPITRWindowSetting  = "WALG_RETENTION_PITR_WINDOW"
+
 	PgDataSetting           = "PGDATA"
 	UserSetting             = "USER" // TODO : do something with it
 	PgPortSetting           = "PGPORT"
@@ -126,6 +137,11 @@ const (
 	PgDaemonWALUploadTimeout             = "WALG_DAEMON_WAL_UPLOAD_TIMEOUT"
 	PgTargetStorage                      = "WALG_TARGET_STORAGE"
 	DisablePartialRestore                = "WALG_DISABLE_PARTIAL_RESTORE"
+	PgCatchupTLSCertFile                 = "WALG_CATCHUP_TLS_CERT_FILE"
+	PgCatchupTLSKeyFile                  = "WALG_CATCHUP_TLS_KEY_FILE"
+	PgCatchupTLSCAFile                   = "WALG_CATCHUP_TLS_CA_FILE"
+	PgCatchupToken                       = "WALG_CATCHUP_TOKEN"
+	PgCatchupInsecure                    = "WALG_CATCHUP_INSECURE"
 
 	ProfileSamplingRatio = "PROFILE_SAMPLING_RATIO"
 	ProfileMode          = "PROFILE_MODE"
@@ -136,6 +152,7 @@ const (
 	MongoDBExtendBackupCursor           = "MONGODB_EXTEND_BACKUP_CURSOR"
 	MongoDBDeletionProtectionWhitelist  = "MONGODB_DELETION_PROTECTION_WHITELIST"
 	MongoDBExtraInternalDatabases       = "MONGODB_EXTRA_INTERNAL_DATABASES"
+	MongoDBClusterMemberBackupCmd       = "MONGODB_CLUSTER_MEMBER_BACKUP_COMMAND"
 	OplogArchiveAfterSize               = "OPLOG_ARCHIVE_AFTER_SIZE"
 	OplogArchiveTimeoutInterval         = "OPLOG_ARCHIVE_TIMEOUT_INTERVAL"
 	OplogPITRDiscoveryInterval          = "OPLOG_PITR_DISCOVERY_INTERVAL"
@@ -199,6 +216,9 @@ const (
 
 	ETCDMemberDataDirectory = "WALG_ETCD_DATA_DIR"
 	ETCDWalDirectory        = "WALG_ETCD_WAL_DIR"
+	ETCDEndpoint            = "WALG_ETCD_ENDPOINT"
+
+	FDBLogDirectory = "WALG_FDB_LOG_DIR"
 
 	GoMaxProcs = "GOMAXPROCS"
 	GoDebug    = "GODEBUG"
@@ -276,6 +296,8 @@ var (
 		SkipRedundantTarsSetting:     "false",
 		VerifyPageChecksumsSetting:   "false",
 		StoreAllCorruptBlocksSetting: "false",
+		UploadChecksumsSetting:       "true",
+		VerifyChecksumsSetting:       "true",
 		UseRatingComposerSetting:     "false",
 		UseCopyComposerSetting:       "false",
 		UseDatabaseComposerSetting:   "false",
@@ -372,6 +394,7 @@ var (
 		DeltaOriginSetting:            true,
 		CompressionMethodSetting:      true,
 		ZstdLevelSetting:              true,
+		ZstdDictionarySetting:         true,
 		StoragePrefixSetting:          true,
 		DiskRateLimitSetting:          true,
 		NetworkRateLimitSetting:       true,
@@ -403,6 +426,8 @@ var (
 		SkipRedundantTarsSetting:      true,
 		VerifyPageChecksumsSetting:    true,
 		StoreAllCorruptBlocksSetting:  true,
+		UploadChecksumsSetting:        true,
+		VerifyChecksumsSetting:        true,
 		UseRatingComposerSetting:      true,
 		UseCopyComposerSetting:        true,
 		UseDatabaseComposerSetting:    true,
@@ -415,6 +440,15 @@ var (
 		StatsdAddressSetting:          true,
 		StatsdExtraTagsSetting:        true,
 
+		// Retention
+		RetentionBackupCountSetting: true,
+		RetentionBackupAgeSetting:   true,
+		RetentionDailySetting:       true,
+		RetentionWeeklySetting:      true,
+		RetentionMonthlySetting:     true,
+		RetentionYearlySetting:      true,
+		RetentionPITRWindowSetting:  true,
+
 		ProfileSamplingRatio: true,
 		ProfileMode:          true,
 		ProfilePath:          true,
@@ -548,6 +582,11 @@ var (
 		DisablePartialRestore:                true,
 		ForceWalDetal:                        true,
 		PgAppName:                            true,
+		PgCatchupTLSCertFile:                 true,
+		PgCatchupTLSKeyFile:                  true,
+		PgCatchupTLSCAFile:                   true,
+		PgCatchupToken:                       true,
+		PgCatchupInsecure:                    true,
 	}
 
 	MongoAllowedSettings = map[string]bool{
@@ -557,6 +596,7 @@ var (
 		MongoDBExtendBackupCursor:          true,
 		MongoDBDeletionProtectionWhitelist: true,
 		MongoDBExtraInternalDatabases:      true,
+		MongoDBClusterMemberBackupCmd:      true,
 		OplogArchiveTimeoutInterval:        true,
 		OplogArchiveAfterSize:              true,
 		OplogPushStatsEnabled:              true,
@@ -620,6 +660,18 @@ var (
 		RedisClusterConfPath:     true,
 	}
 
+	ETCDAllowedSettings = map[string]bool{
+		// ETCD
+		ETCDMemberDataDirectory: true,
+		ETCDWalDirectory:        true,
+		ETCDEndpoint:            true,
+	}
+
+	FDBAllowedSettings = map[string]bool{
+		// FoundationDB
+		FDBLogDirectory: true,
+	}
+
 	GPAllowedSettings = map[string]bool{
 		GPLogsDirectory:                      true,
 		GPSegContentID:                       true,
@@ -671,6 +723,7 @@ var (
 		AlicloudSecurityToken:         true,
 		LibsodiumKeySetting:           true,
 		PgPasswordSetting:             true,
+		PgCatchupToken:                true,
 		PgpKeyPassphraseSetting:       true,
 		PgpKeySetting:                 true,
 		PgpEnvelopeKeySetting:         true,
@@ -28,6 +28,7 @@ import (
 	"github.com/wal-g/wal-g/internal/fsutil"
 	"github.com/wal-g/wal-g/internal/limiters"
 	"github.com/wal-g/wal-g/internal/multistorage"
+	"github.com/wal-g/wal-g/internal/multistorage/consts"
 	"github.com/wal-g/wal-g/internal/multistorage/stats/cache"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
@@ -149,6 +150,7 @@ func ConfigureStorage(ctx context.Context) (storage.HashableStorage, error) {
 	if err != nil {
 		return nil, err
 	}
+	setZstdDictionaryFolder(consts.DefaultStorage, st.RootFolder())
 
 	return st, nil
 }
@@ -219,12 +221,16 @@ func GetPgSlotName() (pgSlotName string) {
 }
 
 func ConfigureCompressor() (compression.Compressor, error) {
-	compressionMethod := viper.GetString(conf.CompressionMethodSetting)
+	return ConfigureCompressorForSpecificConfig(viper.GetViper())
+}
+
+func ConfigureCompressorForSpecificConfig(config *viper.Viper) (compression.Compressor, error) {
+	compressionMethod := config.GetString(conf.CompressionMethodSetting)
 	compressor, ok := compression.Compressors[compressionMethod]
 	if !ok {
 		return nil, newUnknownCompressionMethodError(compressionMethod)
 	}
-	if levelName := viper.GetString(conf.ZstdLevelSetting); levelName != "" {
+	if levelName := config.GetString(conf.ZstdLevelSetting); levelName != "" {
 		if compressionMethod != zstdcompression.AlgorithmName {
 			return nil, newZstdLevelWithoutZstdMethodError(compressionMethod)
 		}
@@ -524,6 +530,10 @@ func ConfigureSettings(currentType string) {
 			dbSpecificSettings = conf.SQLServerAllowedSettings
 		case conf.REDIS:
 			dbSpecificSettings = conf.RedisAllowedSettings
+		case conf.FDB:
+			dbSpecificSettings = conf.FDBAllowedSettings
+		case conf.ETCD:
+			dbSpecificSettings = conf.ETCDAllowedSettings
 		}
 
 		for k, v := range dbSpecificSettings {
@@ -601,6 +611,7 @@ func ConfigureFailoverStorages(ctx context.Context) (failovers map[string]storag
 			return nil, fmt.Errorf("failover storage %s: %v", name, err)
 		}
 		errClosers = append(errClosers, st)
+		setZstdDictionaryFolder(name, st.RootFolder())
 
 		storages[name] = st
 	}
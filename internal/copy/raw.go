@@ -20,6 +20,7 @@ import (
 const defaultRawCopyConcurrency = 8
 
 var compressionExtensions = map[string]struct{}{
+	".auto": {},
 	".br":   {},
 	".gz":   {},
 	".lz4":  {},
@@ -61,6 +62,7 @@ type Entry struct {
 	Sequence   int
 	Mutable    bool
 	inline     []byte
+	transform  SourceTransformerFunc
 }
 
 // AddInline replaces a manifest destination with small planner-generated
@@ -91,6 +93,23 @@ func (p *Plan) SetOrder(targetPath string, phase Phase, sequence int) error {
 	return nil
 }
 
+// SetTransform makes the payload of an entry already in the manifest pass through
+// transform, for example to re-encrypt it for the destination. The size of a
+// transformed object changes, so an existing destination object is kept as is.
+func (p *Plan) SetTransform(targetPath string, transform SourceTransformerFunc) error {
+	targetPath = path.Clean(targetPath)
+	entry, ok := p.entries[targetPath]
+	if !ok {
+		return fmt.Errorf("destination object %q is not in the copy manifest", targetPath)
+	}
+	if entry.inline != nil {
+		return fmt.Errorf("destination object %q is planner-generated and can't be transformed", targetPath)
+	}
+	entry.transform = transform
+	p.entries[targetPath] = entry
+	return nil
+}
+
 // Plan is a complete, deduplicated copy manifest between two root folders.
 type Plan struct {
 	From storage.Folder
@@ -113,7 +132,25 @@ func NewPlan(ctx context.Context, from, to storage.Folder) (*Plan, error) {
 		source[path.Clean(object.GetName())] = object
 	}
 
-	return &Plan{From: from, To: to, source: source, entries: make(map[string]Entry)}, nil
+	plan := &Plan{From: from, To: to, source: source, entries: make(map[string]Entry)}
+	if err := plan.addZstdDictionaries(); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// addZstdDictionaries adds the zstd dictionaries: the copied objects compressed with them can't be read without them.
+// The pointer to the latest dictionary describes the source uploads, so it is not copied.
+func (p *Plan) addZstdDictionaries() error {
+	for name := range p.source {
+		if !strings.HasPrefix(name, utility.ZstdDictsPath) || path.Base(name) == internal.LatestZstdDictionaryName {
+			continue
+		}
+		if err := p.AddObject(name, name, PayloadPhase, false); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // SourceObjects returns the immutable source inventory used by this plan.
@@ -180,6 +217,7 @@ func (p *Plan) AddObject(sourcePath, targetPath string, phase Phase, mutable boo
 			entry.Sequence = previous.Sequence
 		}
 		entry.Mutable = previous.Mutable || entry.Mutable
+		entry.transform = previous.transform
 	}
 	p.entries[targetPath] = entry
 	return nil
@@ -274,7 +312,8 @@ type publicationOrder struct {
 }
 
 // ExecuteRaw copies only missing immutable entries, rejects conflicting ones,
-// and refreshes mutable metadata. No payload transformation is performed.
+// and refreshes mutable metadata. Only the payloads of entries with a transform
+// set by SetTransform are changed.
 func ExecuteRaw(ctx context.Context, plan *Plan) error {
 	targetObjects, err := storage.ListFolderRecursively(ctx, plan.To)
 	if err != nil {
@@ -288,7 +327,7 @@ func ExecuteRaw(ctx context.Context, plan *Plan) error {
 	byOrder := map[publicationOrder][]Entry{}
 	for _, entry := range plan.Entries() {
 		if existing, ok := target[entry.TargetPath]; ok && !entry.Mutable {
-			if existing.GetSize() != entry.Size {
+			if entry.transform == nil && existing.GetSize() != entry.Size {
 				return fmt.Errorf("destination object %q conflicts with source: source size %d, destination size %d",
 					entry.TargetPath, entry.Size, existing.GetSize())
 			}
@@ -345,6 +384,17 @@ func copyRawObject(ctx context.Context, from, to storage.Folder, entry Entry) er
 	}
 	defer reader.Close()
 
+	if entry.transform != nil {
+		transformed, err := entry.transform(reader)
+		if err != nil {
+			return fmt.Errorf("transform source object %q: %w", entry.SourcePath, err)
+		}
+		if err := putRawObject(ctx, to, entry.TargetPath, transformed); err != nil {
+			return fmt.Errorf("write destination object %q: %w", entry.TargetPath, err)
+		}
+		tracelog.InfoLogger.Printf("Copied %q to %q with transformation.", entry.SourcePath, entry.TargetPath)
+		return nil
+	}
 	if err := putRawObject(ctx, to, entry.TargetPath, reader); err != nil {
 		return fmt.Errorf("write destination object %q: %w", entry.TargetPath, err)
 	}
@@ -80,6 +80,36 @@ func TestExecuteRawRejectsDestinationSizeConflict(t *testing.T) {
 	require.ErrorContains(t, copyutil.ExecuteRaw(t.Context(), plan), "conflicts with source")
 }
 
+func TestExecuteRawTransformsMarkedEntries(t *testing.T) {
+	from := testtools.MakeDefaultInMemoryStorageFolder()
+	to := testtools.MakeDefaultInMemoryStorageFolder()
+	require.NoError(t, from.PutObject(t.Context(), "block", bytes.NewBufferString("block")))
+	require.NoError(t, from.PutObject(t.Context(), "existing", bytes.NewBufferString("source")))
+	require.NoError(t, to.PutObject(t.Context(), "existing", bytes.NewBufferString("transformed")))
+
+	plan, err := copyutil.NewPlan(t.Context(), from, to)
+	require.NoError(t, err)
+	upper := func(r io.Reader) (io.Reader, error) {
+		data, err := io.ReadAll(r)
+		return bytes.NewReader(bytes.ToUpper(data)), err
+	}
+	for _, name := range []string{"block", "existing"} {
+		require.NoError(t, plan.AddObject(name, name, copyutil.PayloadPhase, false))
+		require.NoError(t, plan.SetTransform(name, upper))
+	}
+	require.Error(t, plan.SetTransform("missing", upper))
+	require.NoError(t, copyutil.ExecuteRaw(t.Context(), plan))
+
+	for name, expected := range map[string]string{"block": "BLOCK", "existing": "transformed"} {
+		reader, err := to.ReadObject(t.Context(), name)
+		require.NoError(t, err)
+		actual, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+		require.Equal(t, expected, string(actual), "an existing transformed object must be kept")
+	}
+}
+
 func TestPlanAddBackupUsesExactBoundariesAndCommitsLast(t *testing.T) {
 	from := testtools.MakeDefaultInMemoryStorageFolder()
 	to := testtools.MakeDefaultInMemoryStorageFolder()
@@ -97,6 +127,20 @@ func TestPlanAddBackupUsesExactBoundariesAndCommitsLast(t *testing.T) {
 	require.Equal(t, copyutil.BackupCommitPhase, entries[1].Phase)
 }
 
+func TestNewPlanAddsZstdDictionaries(t *testing.T) {
+	from := testtools.MakeDefaultInMemoryStorageFolder()
+	to := testtools.MakeDefaultInMemoryStorageFolder()
+	require.NoError(t, from.PutObject(t.Context(), utility.ZstdDictsPath+"42.dict", bytes.NewBufferString("dictionary")))
+	require.NoError(t, from.PutObject(t.Context(), utility.ZstdDictsPath+internal.LatestZstdDictionaryName,
+		bytes.NewBufferString(`{"ID":42}`)))
+
+	plan, err := copyutil.NewPlan(t.Context(), from, to)
+	require.NoError(t, err)
+	entries := plan.Entries()
+	require.Len(t, entries, 1)
+	require.Equal(t, utility.ZstdDictsPath+"42.dict", entries[0].TargetPath)
+}
+
 func TestExecuteRawOrdersStagesBeforeArbitrarySequence(t *testing.T) {
 	from := testtools.MakeDefaultInMemoryStorageFolder()
 	to := &recordingPutFolder{Folder: testtools.MakeDefaultInMemoryStorageFolder()}
@@ -0,0 +1,148 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// RecoveryTarget is the point to recover the etcd data directory to, either the revision or the time is set
+type RecoveryTarget struct {
+	Revision int64
+	Time     *time.Time
+}
+
+func (target RecoveryTarget) String() string {
+	if target.Time != nil {
+		return "time " + target.Time.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("revision %d", target.Revision)
+}
+
+// MemberDirs are the directories of the restored etcd member, WalDir is empty for the default one
+type MemberDirs struct {
+	DataDir string
+	WalDir  string
+}
+
+// HandleBackupFetchUntil restores the snapshot with the restore command, starts the temporary etcd member on it
+// and replays the requests of the WAL archived after the backup through the v3 API until the target revision.
+// The archived WAL is not copied into the member, the restored member has the new raft log
+// which the archived entries do not continue.
+func HandleBackupFetchUntil(ctx context.Context,
+	folder storage.Folder,
+	targetBackupSelector internal.BackupSelector,
+	restoreCmd *exec.Cmd,
+	target RecoveryTarget,
+	dirs MemberDirs,
+	etcdBinary string) error {
+	backup, err := targetBackupSelector.Select(ctx, folder)
+	if err != nil {
+		return errors.Wrap(err, "failed to select backup")
+	}
+	var sentinel StreamSentinelDto
+	err = backup.FetchSentinel(ctx, &sentinel)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch backup sentinel")
+	}
+	if sentinel.RaftIndex == 0 {
+		return fmt.Errorf("backup %s has no raft index, "+
+			"the point in time recovery needs the backup pushed with WALG_ETCD_ENDPOINT set", backup.Name)
+	}
+	targetRevision, err := resolveTargetRevision(ctx, folder.GetSubFolder(utility.WalPath), target)
+	if err != nil {
+		return err
+	}
+	if sentinel.Revision > targetRevision {
+		return fmt.Errorf("backup %s contains revision %d after the recovery target %s",
+			backup.Name, sentinel.Revision, target)
+	}
+
+	scratchDir, err := os.MkdirTemp("", "walg_etcd_replay")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(scratchDir)
+
+	tracelog.InfoLogger.Println("Fetching WAL archived after the backup")
+	err = FetchWals(ctx, folder, sentinel.StartLocalTime, scratchDir, internal.NewFolderReader(folder))
+	if err != nil {
+		return err
+	}
+	entries, err := readWalEntries(scratchDir)
+	if err != nil {
+		return err
+	}
+	requests, err := selectWalRequests(entries, sentinel.RaftIndex)
+	if err != nil {
+		return err
+	}
+
+	tracelog.InfoLogger.Printf("Restoring backup %s\n", backup.Name)
+	internal.GetBackupToCommandFetcher(restoreCmd)(ctx, folder, backup)
+
+	member, err := startTemporaryMember(ctx, etcdBinary, dirs.DataDir, dirs.WalDir, scratchDir)
+	if err != nil {
+		return err
+	}
+	err = replayWal(ctx, member.endpoint, requests, sentinel.Revision, targetRevision)
+	stopErr := member.stop()
+	if err != nil {
+		return err
+	}
+	return stopErr
+}
+
+func replayWal(ctx context.Context, endpoint string, requests []walRequest, backupRevision, targetRevision int64) error {
+	client, err := newEtcdClient(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	snapshot, err := fetchCheckpoint(ctx, client)
+	if err != nil {
+		return err
+	}
+	if snapshot.Revision > targetRevision {
+		return fmt.Errorf("the restored snapshot contains revision %d after the target revision %d",
+			snapshot.Revision, targetRevision)
+	}
+	start, err := findReplayStart(ctx, client, requests, backupRevision, snapshot.Revision)
+	if err != nil {
+		return err
+	}
+	tracelog.InfoLogger.Printf("Replaying the archived WAL from revision %d to revision %d\n",
+		snapshot.Revision, targetRevision)
+	return replayRequests(ctx, client, requests[start:], snapshot.Revision, targetRevision)
+}
+
+// resolveTargetRevision returns the revision of the target, the time target is resolved
+// to the revision of the last checkpoint recorded before it
+func resolveTargetRevision(ctx context.Context, walFolder storage.Folder, target RecoveryTarget) (int64, error) {
+	if target.Time == nil {
+		return target.Revision, nil
+	}
+	checkpoints, err := FetchCheckpoints(ctx, walFolder)
+	if err != nil {
+		return 0, err
+	}
+	var lower *Checkpoint
+	for i := range checkpoints {
+		if !checkpoints[i].Time.After(*target.Time) {
+			lower = &checkpoints[i]
+		}
+	}
+	if lower == nil {
+		return 0, fmt.Errorf("there is no checkpoint recorded before the recovery target %s", target)
+	}
+	tracelog.InfoLogger.Printf("Recovery target %s is resolved to revision %d of the checkpoint at %s\n",
+		target, lower.Revision, lower.Time.Format(time.RFC3339))
+	return lower.Revision, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/utility"
 )
 
@@ -14,6 +15,10 @@ type StreamSentinelDto struct {
 	StartLocalTime time.Time `json:"StartLocalTime,omitempty"`
 	IsPermanent    bool      `json:"IsPermanent"`
 	SnapshotSize   int64     `json:"SnapshotSize"`
+	// Revision is the revision of the etcd member at the backup start, it is recorded if WALG_ETCD_ENDPOINT is set
+	Revision int64 `json:"Revision,omitempty"`
+	// RaftIndex is the applied raft index of the revision, the archived WAL is replayed from the next entry
+	RaftIndex uint64 `json:"RaftIndex,omitempty"`
 
 	UserData interface{} `json:"UserData,omitempty"`
 }
@@ -22,6 +27,15 @@ type StreamSentinelDto struct {
 func HandleBackupPush(ctx context.Context, uploader internal.Uploader, backupCmd *exec.Cmd, permanent bool, userDataRaw string) {
 	timeStart := utility.TimeNowCrossPlatformLocal()
 
+	var checkpoint Checkpoint
+	if endpoint, ok := conf.GetSetting(conf.ETCDEndpoint); ok {
+		var err error
+		checkpoint, err = FetchCheckpoint(ctx, endpoint)
+		if err != nil {
+			tracelog.WarningLogger.Printf("Failed to get the revision of etcd member: %v", err)
+		}
+	}
+
 	stdout, stderr, err := utility.StartCommandWithStdoutStderr(backupCmd)
 	tracelog.ErrorLogger.FatalfOnError("failed to start backup create command: %v", err)
 
@@ -45,6 +59,8 @@ func HandleBackupPush(ctx context.Context, uploader internal.Uploader, backupCmd
 		IsPermanent:    permanent,
 		UserData:       userData,
 		SnapshotSize:   dataSize,
+		Revision:       checkpoint.Revision,
+		RaftIndex:      checkpoint.RaftIndex,
 	}
 
 	err = internal.UploadSentinel(ctx, uploader, &sentinel, fileName)
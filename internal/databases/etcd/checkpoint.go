@@ -0,0 +1,101 @@
+package etcd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// CheckpointsPath is the folder inside the WAL folder where the revision checkpoints are stored.
+// The etcd WAL contains neither revisions nor timestamps, so the checkpoints recorded by wal-push
+// map them to the raft index to find the point of the recovery.
+const CheckpointsPath = "checkpoints/"
+
+type Checkpoint struct {
+	Time      time.Time `json:"Time"`
+	Revision  int64     `json:"Revision"`
+	RaftIndex uint64    `json:"RaftIndex"`
+}
+
+// maintenanceStatus is the part of the response of the etcd gRPC gateway /v3/maintenance/status endpoint
+type maintenanceStatus struct {
+	Header           responseHeader `json:"header"`
+	RaftIndex        uint64         `json:"raftIndex,string"`
+	RaftAppliedIndex uint64         `json:"raftAppliedIndex,string"`
+}
+
+// appliedIndex returns the applied raft index, old etcd versions report only the committed one
+func (status maintenanceStatus) appliedIndex() uint64 {
+	if status.RaftAppliedIndex == 0 {
+		return status.RaftIndex
+	}
+	return status.RaftAppliedIndex
+}
+
+const maxCheckpointAttempts = 10
+
+// FetchCheckpoint asks the etcd member for its current revision and the applied raft index.
+// The member reads the raft index before the revision, so the status is read until the applied index
+// does not change between two reads, then the revision is the one of the applied index.
+func FetchCheckpoint(ctx context.Context, endpoint string) (Checkpoint, error) {
+	client, err := newEtcdClient(ctx, endpoint)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	return fetchCheckpoint(ctx, client)
+}
+
+func fetchCheckpoint(ctx context.Context, client *etcdClient) (Checkpoint, error) {
+	var prev maintenanceStatus
+	for attempt := 0; attempt < maxCheckpointAttempts; attempt++ {
+		var status maintenanceStatus
+		err := client.call(ctx, "/v3/maintenance/status", struct{}{}, &status)
+		if err != nil {
+			return Checkpoint{}, errors.Wrapf(err, "failed to get the status of etcd member %s", client.endpoint)
+		}
+		if attempt > 0 && status.appliedIndex() == prev.appliedIndex() && status.Header.Revision == prev.Header.Revision {
+			return Checkpoint{
+				Time:      utility.TimeNowCrossPlatformUTC(),
+				Revision:  status.Header.Revision,
+				RaftIndex: status.appliedIndex(),
+			}, nil
+		}
+		prev = status
+	}
+	return Checkpoint{}, fmt.Errorf("etcd member %s applies the writes too often to record the checkpoint", client.endpoint)
+}
+
+func UploadCheckpoint(ctx context.Context, walFolder storage.Folder, checkpoint Checkpoint) error {
+	checkpointName := checkpoint.Time.Format(utility.BackupTimeFormat) + ".json"
+	return internal.UploadDto(ctx, walFolder.GetSubFolder(CheckpointsPath), checkpoint, checkpointName)
+}
+
+// FetchCheckpoints returns all recorded checkpoints sorted by the raft index
+func FetchCheckpoints(ctx context.Context, walFolder storage.Folder) ([]Checkpoint, error) {
+	checkpointsFolder := walFolder.GetSubFolder(CheckpointsPath)
+	objects, _, err := checkpointsFolder.ListFolder(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the checkpoints")
+	}
+
+	checkpoints := make([]Checkpoint, 0, len(objects))
+	for _, object := range objects {
+		var checkpoint Checkpoint
+		err = internal.FetchDto(ctx, checkpointsFolder, &checkpoint, object.GetName())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch the checkpoint %s", object.GetName())
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	slices.SortFunc(checkpoints, func(a, b Checkpoint) int {
+		return cmp.Compare(a.RaftIndex, b.RaftIndex)
+	})
+	return checkpoints, nil
+}
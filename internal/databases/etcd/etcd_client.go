@@ -0,0 +1,147 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// The environment variables of etcdctl, the stream create command usually runs etcdctl with them,
+// so the requests of wal-g to the member are secured and authenticated the same way
+const (
+	etcdctlCACertEnv             = "ETCDCTL_CACERT"
+	etcdctlCertEnv               = "ETCDCTL_CERT"
+	etcdctlKeyEnv                = "ETCDCTL_KEY"
+	etcdctlInsecureSkipVerifyEnv = "ETCDCTL_INSECURE_SKIP_TLS_VERIFY"
+	etcdctlUserEnv               = "ETCDCTL_USER"
+	etcdctlPasswordEnv           = "ETCDCTL_PASSWORD"
+)
+
+// etcdClient calls the etcd gRPC gateway of the member
+type etcdClient struct {
+	endpoint string
+	client   *http.Client
+	token    string
+}
+
+// responseHeader is the header of every etcd response
+type responseHeader struct {
+	Revision int64 `json:"revision,string"`
+}
+
+// etcdError is the error response of the etcd gRPC gateway
+type etcdError struct {
+	Status    string
+	Message   string `json:"message"`
+	ErrorText string `json:"error"`
+}
+
+func (e *etcdError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = e.ErrorText
+	}
+	return fmt.Sprintf("%s: %s", e.Status, message)
+}
+
+// newEtcdClient configures the client with the etcdctl TLS settings and authenticates with the etcdctl user
+func newEtcdClient(ctx context.Context, endpoint string) (*etcdClient, error) {
+	tlsConfig, err := newEtcdTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	client := &etcdClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}},
+	}
+	user, ok := os.LookupEnv(etcdctlUserEnv)
+	if !ok {
+		return client, nil
+	}
+	name, password, found := strings.Cut(user, ":")
+	if !found {
+		password = os.Getenv(etcdctlPasswordEnv)
+	}
+	var response struct {
+		Token string `json:"token"`
+	}
+	err = client.call(ctx, "/v3/auth/authenticate", map[string]string{"name": name, "password": password}, &response)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to authenticate as %s", name)
+	}
+	client.token = response.Token
+	return client, nil
+}
+
+func newEtcdTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile := os.Getenv(etcdctlCACertEnv); caFile != "" {
+		caCert, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", etcdctlCACertEnv)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+	}
+	certFile, keyFile := os.Getenv(etcdctlCertEnv), os.Getenv(etcdctlKeyEnv)
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load %s and %s", etcdctlCertEnv, etcdctlKeyEnv)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if skipVerify := os.Getenv(etcdctlInsecureSkipVerifyEnv); skipVerify != "" {
+		insecure, err := strconv.ParseBool(skipVerify)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", etcdctlInsecureSkipVerifyEnv)
+		}
+		tlsConfig.InsecureSkipVerify = insecure
+	}
+	return tlsConfig, nil
+}
+
+// call posts the JSON request to the gateway path and decodes the response,
+// the error responses are returned as *etcdError
+func (c *etcdClient) call(ctx context.Context, path string, request any, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		httpRequest.Header.Set("Authorization", c.token)
+	}
+
+	httpResponse, err := c.client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer utility.LoggedClose(httpResponse.Body, "")
+	if httpResponse.StatusCode != http.StatusOK {
+		responseError := &etcdError{Status: httpResponse.Status}
+		errorBody, _ := io.ReadAll(httpResponse.Body)
+		if json.Unmarshal(errorBody, responseError) != nil {
+			responseError.Message = string(errorBody)
+		}
+		return responseError
+	}
+	return json.NewDecoder(httpResponse.Body).Decode(response)
+}
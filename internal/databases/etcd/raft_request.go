@@ -0,0 +1,293 @@
+package etcd
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The requests of the etcdserverpb.InternalRaftRequest which change the v3 keyspace or the leases.
+// The structs are marshaled to the JSON of the etcd gRPC gateway, so the requests can be applied again.
+// The other requests (v2, range, alarm, auth, membership, cluster version) are not replayed.
+type raftRequest struct {
+	Put         *putRequest
+	DeleteRange *deleteRangeRequest
+	Txn         *txnRequest
+	Compaction  *compactionRequest
+	LeaseGrant  *leaseGrantRequest
+	LeaseRevoke *leaseRevokeRequest
+}
+
+type putRequest struct {
+	Key         []byte `json:"key,omitempty"`
+	Value       []byte `json:"value,omitempty"`
+	Lease       int64  `json:"lease,string,omitempty"`
+	PrevKv      bool   `json:"prev_kv,omitempty"`
+	IgnoreValue bool   `json:"ignore_value,omitempty"`
+	IgnoreLease bool   `json:"ignore_lease,omitempty"`
+}
+
+type deleteRangeRequest struct {
+	Key      []byte `json:"key,omitempty"`
+	RangeEnd []byte `json:"range_end,omitempty"`
+	PrevKv   bool   `json:"prev_kv,omitempty"`
+}
+
+// rangeRequest is the read of the transaction, it does not change anything,
+// so only the range is kept to replay the transaction
+type rangeRequest struct {
+	Key      []byte `json:"key,omitempty"`
+	RangeEnd []byte `json:"range_end,omitempty"`
+	Revision int64  `json:"revision,string,omitempty"`
+	KeysOnly bool   `json:"keys_only,omitempty"`
+}
+
+type txnRequest struct {
+	Compare []compare   `json:"compare,omitempty"`
+	Success []requestOp `json:"success,omitempty"`
+	Failure []requestOp `json:"failure,omitempty"`
+}
+
+// The values of the etcdserverpb.Compare enums
+const (
+	compareEqual    int32 = 0
+	compareGreater  int32 = 1
+	compareLess     int32 = 2
+	compareNotEqual int32 = 3
+
+	compareVersion int32 = 0
+	compareCreate  int32 = 1
+	compareMod     int32 = 2
+	compareValue   int32 = 3
+	compareLease   int32 = 4
+)
+
+type compare struct {
+	Result         int32  `json:"result,omitempty"`
+	Target         int32  `json:"target,omitempty"`
+	Key            []byte `json:"key,omitempty"`
+	Version        int64  `json:"version,string,omitempty"`
+	CreateRevision int64  `json:"create_revision,string,omitempty"`
+	ModRevision    int64  `json:"mod_revision,string,omitempty"`
+	Value          []byte `json:"value,omitempty"`
+	Lease          int64  `json:"lease,string,omitempty"`
+	RangeEnd       []byte `json:"range_end,omitempty"`
+}
+
+type requestOp struct {
+	RequestRange       *rangeRequest       `json:"request_range,omitempty"`
+	RequestPut         *putRequest         `json:"request_put,omitempty"`
+	RequestDeleteRange *deleteRangeRequest `json:"request_delete_range,omitempty"`
+	RequestTxn         *txnRequest         `json:"request_txn,omitempty"`
+}
+
+type compactionRequest struct {
+	Revision int64 `json:"revision,string,omitempty"`
+	Physical bool  `json:"physical,omitempty"`
+}
+
+type leaseGrantRequest struct {
+	TTL int64 `json:"TTL,string,omitempty"`
+	ID  int64 `json:"ID,string,omitempty"`
+}
+
+type leaseRevokeRequest struct {
+	ID int64 `json:"ID,string,omitempty"`
+}
+
+// isReplayed tells whether the request is one of the replayed ones
+func (request raftRequest) isReplayed() bool {
+	return request.Put != nil || request.DeleteRange != nil || request.Txn != nil ||
+		request.Compaction != nil || request.LeaseGrant != nil || request.LeaseRevoke != nil
+}
+
+// unmarshalRaftRequest decodes the data of the normal raft entry
+func unmarshalRaftRequest(data []byte) (raftRequest, error) {
+	request := raftRequest{}
+	var err error
+	walkErr := walkProtoFields(data, func(number protowire.Number, _ uint64, bytes []byte) {
+		if err != nil {
+			return
+		}
+		switch number {
+		case 4:
+			request.Put, err = unmarshalPutRequest(bytes)
+		case 5:
+			request.DeleteRange, err = unmarshalDeleteRangeRequest(bytes)
+		case 6:
+			request.Txn, err = unmarshalTxnRequest(bytes)
+		case 7:
+			request.Compaction, err = unmarshalCompactionRequest(bytes)
+		case 8:
+			request.LeaseGrant, err = unmarshalLeaseGrantRequest(bytes)
+		case 9:
+			request.LeaseRevoke, err = unmarshalLeaseRevokeRequest(bytes)
+		}
+	})
+	if walkErr != nil {
+		return raftRequest{}, walkErr
+	}
+	return request, err
+}
+
+func unmarshalPutRequest(data []byte) (*putRequest, error) {
+	request := &putRequest{}
+	err := walkProtoFields(data, func(number protowire.Number, value uint64, bytes []byte) {
+		switch number {
+		case 1:
+			request.Key = bytes
+		case 2:
+			request.Value = bytes
+		case 3:
+			request.Lease = int64(value)
+		case 4:
+			request.PrevKv = value != 0
+		case 5:
+			request.IgnoreValue = value != 0
+		case 6:
+			request.IgnoreLease = value != 0
+		}
+	})
+	return request, err
+}
+
+func unmarshalDeleteRangeRequest(data []byte) (*deleteRangeRequest, error) {
+	request := &deleteRangeRequest{}
+	err := walkProtoFields(data, func(number protowire.Number, value uint64, bytes []byte) {
+		switch number {
+		case 1:
+			request.Key = bytes
+		case 2:
+			request.RangeEnd = bytes
+		case 3:
+			request.PrevKv = value != 0
+		}
+	})
+	return request, err
+}
+
+func unmarshalRangeRequest(data []byte) (*rangeRequest, error) {
+	request := &rangeRequest{}
+	err := walkProtoFields(data, func(number protowire.Number, _ uint64, bytes []byte) {
+		switch number {
+		case 1:
+			request.Key = bytes
+		case 2:
+			request.RangeEnd = bytes
+		}
+	})
+	return request, err
+}
+
+func unmarshalTxnRequest(data []byte) (*txnRequest, error) {
+	request := &txnRequest{}
+	var err error
+	walkErr := walkProtoFields(data, func(number protowire.Number, _ uint64, bytes []byte) {
+		if err != nil {
+			return
+		}
+		switch number {
+		case 1:
+			var cmp compare
+			cmp, err = unmarshalCompare(bytes)
+			request.Compare = append(request.Compare, cmp)
+		case 2:
+			var op requestOp
+			op, err = unmarshalRequestOp(bytes)
+			request.Success = append(request.Success, op)
+		case 3:
+			var op requestOp
+			op, err = unmarshalRequestOp(bytes)
+			request.Failure = append(request.Failure, op)
+		}
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	return request, err
+}
+
+func unmarshalCompare(data []byte) (compare, error) {
+	cmp := compare{}
+	err := walkProtoFields(data, func(number protowire.Number, value uint64, bytes []byte) {
+		switch number {
+		case 1:
+			cmp.Result = int32(value)
+		case 2:
+			cmp.Target = int32(value)
+		case 3:
+			cmp.Key = bytes
+		case 4:
+			cmp.Version = int64(value)
+		case 5:
+			cmp.CreateRevision = int64(value)
+		case 6:
+			cmp.ModRevision = int64(value)
+		case 7:
+			cmp.Value = bytes
+		case 8:
+			cmp.Lease = int64(value)
+		case 64:
+			cmp.RangeEnd = bytes
+		}
+	})
+	return cmp, err
+}
+
+func unmarshalRequestOp(data []byte) (requestOp, error) {
+	op := requestOp{}
+	var err error
+	walkErr := walkProtoFields(data, func(number protowire.Number, _ uint64, bytes []byte) {
+		if err != nil {
+			return
+		}
+		switch number {
+		case 1:
+			op.RequestRange, err = unmarshalRangeRequest(bytes)
+		case 2:
+			op.RequestPut, err = unmarshalPutRequest(bytes)
+		case 3:
+			op.RequestDeleteRange, err = unmarshalDeleteRangeRequest(bytes)
+		case 4:
+			op.RequestTxn, err = unmarshalTxnRequest(bytes)
+		}
+	})
+	if walkErr != nil {
+		return requestOp{}, walkErr
+	}
+	return op, err
+}
+
+func unmarshalCompactionRequest(data []byte) (*compactionRequest, error) {
+	request := &compactionRequest{}
+	err := walkProtoFields(data, func(number protowire.Number, value uint64, _ []byte) {
+		switch number {
+		case 1:
+			request.Revision = int64(value)
+		case 2:
+			request.Physical = value != 0
+		}
+	})
+	return request, err
+}
+
+func unmarshalLeaseGrantRequest(data []byte) (*leaseGrantRequest, error) {
+	request := &leaseGrantRequest{}
+	err := walkProtoFields(data, func(number protowire.Number, value uint64, _ []byte) {
+		switch number {
+		case 1:
+			request.TTL = int64(value)
+		case 2:
+			request.ID = int64(value)
+		}
+	})
+	return request, err
+}
+
+func unmarshalLeaseRevokeRequest(data []byte) (*leaseRevokeRequest, error) {
+	request := &leaseRevokeRequest{}
+	err := walkProtoFields(data, func(number protowire.Number, value uint64, _ []byte) {
+		if number == 1 {
+			request.ID = int64(value)
+		}
+	})
+	return request, err
+}
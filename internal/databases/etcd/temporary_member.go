@@ -0,0 +1,153 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	memberStartTimeout = time.Minute
+	memberStopTimeout  = 30 * time.Second
+	memberPollInterval = 200 * time.Millisecond
+)
+
+// temporaryMember is the etcd started on the restored data directory to replay the archived WAL.
+// It listens only on the loopback interface, so nothing else changes the restored keyspace.
+type temporaryMember struct {
+	cmd      *exec.Cmd
+	exited   chan error
+	endpoint string
+	logPath  string
+}
+
+// startTemporaryMember starts etcd as the single member cluster on the data directory and waits for the leader
+func startTemporaryMember(ctx context.Context, etcdBinary, dataDir, walDir, logDir string) (*temporaryMember, error) {
+	clientURL, err := loopbackURL()
+	if err != nil {
+		return nil, err
+	}
+	peerURL, err := loopbackURL()
+	if err != nil {
+		return nil, err
+	}
+	args := []string{
+		"--data-dir", dataDir,
+		"--force-new-cluster",
+		"--listen-client-urls", clientURL,
+		"--advertise-client-urls", clientURL,
+		"--listen-peer-urls", peerURL,
+		"--initial-advertise-peer-urls", peerURL,
+	}
+	if walDir != "" {
+		args = append(args, "--wal-dir", walDir)
+	}
+
+	logPath := filepath.Join(logDir, "etcd.log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return nil, err
+	}
+	defer utility.LoggedClose(logFile, "")
+
+	cmd := exec.Command(etcdBinary, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	tracelog.InfoLogger.Printf("Starting temporary etcd member: %s\n", cmd.String())
+	err = cmd.Start()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to start %s", etcdBinary)
+	}
+	member := &temporaryMember{cmd: cmd, exited: make(chan error, 1), endpoint: clientURL, logPath: logPath}
+	go func() {
+		member.exited <- cmd.Wait()
+	}()
+
+	err = member.waitLeader(ctx)
+	if err != nil {
+		_ = member.stop()
+		return nil, err
+	}
+	return member, nil
+}
+
+func (m *temporaryMember) waitLeader(ctx context.Context) error {
+	client, err := newEtcdClient(ctx, m.endpoint)
+	if err != nil {
+		return err
+	}
+	deadline := time.After(memberStartTimeout)
+	for {
+		var status struct {
+			Leader uint64 `json:"leader,string"`
+		}
+		err = client.call(ctx, "/v3/maintenance/status", struct{}{}, &status)
+		if err == nil && status.Leader != 0 {
+			return nil
+		}
+		select {
+		case exitErr := <-m.exited:
+			m.exited <- exitErr
+			return fmt.Errorf("temporary etcd member exited: %v, see %s", exitErr, m.logPath)
+		case <-deadline:
+			return fmt.Errorf("temporary etcd member has not elected the leader in %s: %v, see %s",
+				memberStartTimeout, err, m.logPath)
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(memberPollInterval):
+		}
+	}
+}
+
+// stop terminates the member gracefully, so the replayed writes are synced to the data directory
+func (m *temporaryMember) stop() error {
+	select {
+	case err := <-m.exited:
+		return fmt.Errorf("temporary etcd member exited: %v, see %s", err, m.logPath)
+	default:
+	}
+	err := m.cmd.Process.Signal(syscall.SIGTERM)
+	if err != nil {
+		return err
+	}
+	select {
+	case err = <-m.exited:
+		if err != nil && !isTerminated(err) {
+			return errors.Wrapf(err, "temporary etcd member failed to stop, see %s", m.logPath)
+		}
+		return nil
+	case <-time.After(memberStopTimeout):
+		_ = m.cmd.Process.Kill()
+		<-m.exited
+		return fmt.Errorf("temporary etcd member has not stopped in %s, see %s", memberStopTimeout, m.logPath)
+	}
+}
+
+// isTerminated tells whether the process exited by SIGTERM, etcd raises the signal again after the graceful stop
+func isTerminated(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == syscall.SIGTERM
+}
+
+// loopbackURL returns the URL of the free loopback port
+func loopbackURL() (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer utility.LoggedClose(listener, "")
+	return "http://" + listener.Addr().String(), nil
+}
@@ -20,8 +20,6 @@ type SentinelDto struct {
 }
 
 func HandleWalFetch(ctx context.Context, folder storage.Folder, backupName string, dstDir string, baseReader internal.StorageFolderReader) {
-	reader := baseReader.SubFolder(utility.WalPath)
-
 	backup, err := internal.GetBackupByName(ctx, internal.LatestString, utility.BaseBackupPath, folder)
 	tracelog.ErrorLogger.FatalfOnError("Failed to get mentioned backup: %v", err)
 
@@ -29,21 +27,34 @@ func HandleWalFetch(ctx context.Context, folder storage.Folder, backupName strin
 	err = backup.FetchSentinel(ctx, &lastBackupSentinel)
 	tracelog.ErrorLogger.FatalfOnError("Failed to unmarshall backup sentinel: %v", err)
 
+	err = FetchWals(ctx, folder, lastBackupSentinel.StartLocalTime, dstDir, baseReader)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
+// FetchWals downloads the WAL files archived after the specified time into the dstDir
+func FetchWals(ctx context.Context, folder storage.Folder, since time.Time, dstDir string, baseReader internal.StorageFolderReader) error {
+	reader := baseReader.SubFolder(utility.WalPath)
+
 	walFiles, _, err := folder.GetSubFolder(utility.WalPath).ListFolder(ctx)
-	tracelog.ErrorLogger.FatalfOnError("Failed to list wal folder from storage: %v", err)
-	fmt.Println(walFiles)
+	if err != nil {
+		return fmt.Errorf("failed to list wal folder from storage: %w", err)
+	}
+	tracelog.DebugLogger.Println(walFiles)
 
 	slices.SortFunc(walFiles, func(a, b storage.Object) int {
 		return a.GetLastModified().Compare(b.GetLastModified())
 	})
 
 	for _, walFile := range walFiles {
-		if lastBackupSentinel.StartLocalTime.Before(walFile.GetLastModified()) {
+		if since.Before(walFile.GetLastModified()) {
 			walName := strings.TrimSuffix(walFile.GetName(), filepath.Ext(walFile.GetName()))
 			walPath := path.Join(dstDir, walName)
 			tracelog.InfoLogger.Printf("fetching %s into %s", walName, walPath)
 			err = internal.DownloadFileTo(ctx, reader, walName, walPath)
-			tracelog.ErrorLogger.FatalfOnError("Failed to download wal file: %v", err)
+			if err != nil {
+				return fmt.Errorf("failed to download wal file: %w", err)
+			}
 		}
 	}
+	return nil
 }
@@ -26,12 +26,12 @@ type LogsCache struct {
 
 func cacheDir(dataDir string) string { return filepath.Join(dataDir, ".walg_etcd_wals_cache") }
 
-func getWalDir(dataDir string) string { return filepath.Join(dataDir, "member", "wal") }
+func GetWalDir(dataDir string) string { return filepath.Join(dataDir, "member", "wal") }
 
 func HandleWALPush(ctx context.Context, uploader internal.Uploader, dataDir string) error {
 	walDir, ok := conf.GetSetting(conf.ETCDWalDirectory)
 	if !ok {
-		walDir = getWalDir(dataDir)
+		walDir = GetWalDir(dataDir)
 	}
 
 	uploader.ChangeDirectory(utility.WalPath)
@@ -76,6 +76,10 @@ func HandleWALPush(ctx context.Context, uploader internal.Uploader, dataDir stri
 
 	// Write Wal Cache (even when no data uploaded, it will create file on first run)
 	putCache(cache)
+
+	if endpoint, ok := conf.GetSetting(conf.ETCDEndpoint); ok {
+		return recordCheckpoint(ctx, uploader, endpoint)
+	}
 	return nil
 }
 
@@ -96,6 +100,16 @@ func archiveWal(ctx context.Context, uploader internal.Uploader, dataDir string,
 	return nil
 }
 
+// recordCheckpoint uploads the current revision and raft index of the member to find the recovery target later
+func recordCheckpoint(ctx context.Context, uploader internal.Uploader, endpoint string) error {
+	checkpoint, err := FetchCheckpoint(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	tracelog.InfoLogger.Printf("Recording checkpoint: revision %d, raft index %d\n", checkpoint.Revision, checkpoint.RaftIndex)
+	return UploadCheckpoint(ctx, uploader.Folder(), checkpoint)
+}
+
 func getCache() LogsCache {
 	var cache LogsCache
 	var cacheFilename string
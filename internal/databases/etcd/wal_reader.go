@@ -0,0 +1,82 @@
+package etcd
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// readWalEntries returns the raft entries of the WAL directory in the order they are applied,
+// the entries overwritten by the new raft leader are dropped
+func readWalEntries(walDir string) ([]raftEntry, error) {
+	entries := make([]raftEntry, 0)
+	err := walkWalRecords(walDir, func(record walRecord) error {
+		if record.Type != walEntryRecordType {
+			return nil
+		}
+		entry, err := unmarshalRaftEntry(record.Data)
+		if err != nil {
+			return err
+		}
+		for len(entries) > 0 && entries[len(entries)-1].Index >= entry.Index {
+			entries = entries[:len(entries)-1]
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// walkWalRecords calls the visitor for every record of the WAL files in the directory
+func walkWalRecords(walDir string, visitor func(record walRecord) error) error {
+	walFiles, err := readWalFileNames(walDir)
+	if err != nil {
+		return err
+	}
+
+	var crc uint32
+	for _, walFile := range walFiles {
+		err = walkWalFileRecords(walDir, walFile, &crc, visitor)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkWalFileRecords(walDir, walFile string, crc *uint32, visitor func(record walRecord) error) error {
+	file, err := os.Open(filepath.Join(walDir, walFile))
+	if err != nil {
+		return err
+	}
+	defer utility.LoggedClose(file, "")
+
+	reader := newWalRecordReader(bufio.NewReader(file), *crc)
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read WAL file %s", walFile)
+		}
+		err = visitor(record)
+		if err != nil {
+			return err
+		}
+	}
+	*crc = reader.Crc()
+	return nil
+}
+
+func readWalFileNames(walDir string) ([]string, error) {
+	files, err := ReadDir(walDir)
+	if err != nil {
+		return nil, err
+	}
+	return checkWalNames(files), nil
+}
@@ -0,0 +1,164 @@
+package etcd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestReadWalEntries(t *testing.T) {
+	walDir := t.TempDir()
+	writeTestWal(t, walDir, []testEntry{
+		{term: 1, index: 1}, {term: 1, index: 2}, {term: 1, index: 3},
+	}, []testEntry{
+		// the new leader overwrites the uncommitted entry 3
+		{term: 2, index: 3}, {term: 2, index: 4},
+	})
+
+	entries, err := readWalEntries(walDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	for i, entry := range entries {
+		assert.Equal(t, uint64(i+1), entry.Index)
+	}
+	assert.Equal(t, uint64(2), entries[2].Term)
+	assert.Equal(t, testPutRequest(3), entries[2].Data)
+}
+
+func TestReadWalEntries_Corrupted(t *testing.T) {
+	walDir := t.TempDir()
+	writeTestWal(t, walDir, []testEntry{{term: 1, index: 1}, {term: 1, index: 2}}, nil)
+
+	walFile := filepath.Join(walDir, walName(0, 0))
+	content, err := os.ReadFile(walFile)
+	require.NoError(t, err)
+	content[bytes.Index(content, []byte("key2"))] = 'x'
+	require.NoError(t, os.WriteFile(walFile, content, 0600))
+
+	_, err = readWalEntries(walDir)
+	assert.Error(t, err)
+}
+
+func TestSelectWalRequests(t *testing.T) {
+	entries := []raftEntry{
+		{Index: 4, Data: testPutRequest(4)},
+		{Index: 5, Data: testPutRequest(5)},
+		// the conf change entry
+		{Index: 6, Type: 1, Data: []byte("conf")},
+		// the empty entry of the new leader
+		{Index: 7},
+		{Index: 8, Data: testPutRequest(8)},
+	}
+
+	requests, err := selectWalRequests(entries, 4)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, uint64(5), requests[0].index)
+	assert.Equal(t, []byte("key5"), requests[0].request.Put.Key)
+	assert.Equal(t, uint64(8), requests[1].index)
+
+	_, err = selectWalRequests(entries, 2)
+	assert.Error(t, err)
+
+	_, err = selectWalRequests(append(entries[:2:2], entries[3:]...), 4)
+	assert.Error(t, err)
+}
+
+type testEntry struct {
+	term  uint64
+	index uint64
+}
+
+// writeTestWal writes the WAL file for every list of the put entries
+func writeTestWal(t *testing.T, walDir string, files ...[]testEntry) {
+	var crc uint32
+	for fileNumber, entries := range files {
+		if len(entries) == 0 {
+			continue
+		}
+		var content []byte
+		var frame []byte
+		frame, _ = encodeWalRecord(walCrcRecordType, nil, crc)
+		content = append(content, frame...)
+		if fileNumber == 0 {
+			frame, crc = encodeWalRecord(walMetadataRecordType, []byte("metadata"), crc)
+			content = append(content, frame...)
+		}
+		for _, entry := range entries {
+			frame, crc = encodeWalRecord(walEntryRecordType, marshalTestEntry(entry), crc)
+			content = append(content, frame...)
+			frame, crc = encodeWalRecord(walStateRecordType, marshalTestHardState(entry), crc)
+			content = append(content, frame...)
+		}
+		// the preallocated space of the WAL file is zeroed
+		content = append(content, make([]byte, 64)...)
+
+		walFile := walName(uint64(fileNumber), entries[0].index-1)
+		require.NoError(t, os.WriteFile(filepath.Join(walDir, walFile), content, 0600))
+	}
+}
+
+// encodeWalRecord returns the frame of the record with the rolling checksum continued from prevCrc,
+// the crc record holds prevCrc itself
+func encodeWalRecord(recordType int64, data []byte, prevCrc uint32) (frame []byte, crc uint32) {
+	crc = prevCrc
+	if recordType != walCrcRecordType {
+		crc = crc32.Update(prevCrc, walCrcTable, data)
+	}
+
+	var recordBytes []byte
+	recordBytes = protowire.AppendTag(recordBytes, 1, protowire.VarintType)
+	recordBytes = protowire.AppendVarint(recordBytes, uint64(recordType))
+	recordBytes = protowire.AppendTag(recordBytes, 2, protowire.VarintType)
+	recordBytes = protowire.AppendVarint(recordBytes, uint64(crc))
+	if data != nil {
+		recordBytes = protowire.AppendTag(recordBytes, 3, protowire.BytesType)
+		recordBytes = protowire.AppendBytes(recordBytes, data)
+	}
+
+	lenField := uint64(len(recordBytes))
+	padBytes := (8 - len(recordBytes)%8) % 8
+	if padBytes != 0 {
+		lenField |= uint64(0x80|padBytes) << 56
+	}
+	frame = binary.LittleEndian.AppendUint64(nil, lenField)
+	frame = append(frame, recordBytes...)
+	frame = append(frame, make([]byte, padBytes)...)
+	return frame, crc
+}
+
+func marshalTestEntry(testEntry testEntry) []byte {
+	var entry []byte
+	entry = protowire.AppendTag(entry, 1, protowire.VarintType)
+	entry = protowire.AppendVarint(entry, raftNormalEntryType)
+	entry = protowire.AppendTag(entry, 2, protowire.VarintType)
+	entry = protowire.AppendVarint(entry, testEntry.term)
+	entry = protowire.AppendTag(entry, 3, protowire.VarintType)
+	entry = protowire.AppendVarint(entry, testEntry.index)
+	entry = protowire.AppendTag(entry, 4, protowire.BytesType)
+	return protowire.AppendBytes(entry, testPutRequest(testEntry.index))
+}
+
+func marshalTestHardState(testEntry testEntry) []byte {
+	var state []byte
+	state = protowire.AppendTag(state, 1, protowire.VarintType)
+	state = protowire.AppendVarint(state, testEntry.term)
+	state = protowire.AppendTag(state, 3, protowire.VarintType)
+	return protowire.AppendVarint(state, testEntry.index)
+}
+
+func testPutRequest(index uint64) []byte {
+	return marshalTestRequest(4, marshalTestPut(fmt.Sprintf("key%d", index), "value"))
+}
+
+func walName(seq, index uint64) string {
+	return fmt.Sprintf("%016x-%016x.wal", seq, index)
+}
@@ -0,0 +1,179 @@
+package etcd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The etcd WAL file is a sequence of frames: the little-endian uint64 length field followed by the walpb.Record
+// protobuf padded to 8 bytes. The top bit of the length field marks the padding, the next 3 bits hold its size.
+// The record checksum is a rolling crc32c of the record data, every file starts with the crc record
+// holding the checksum of the previous file.
+const (
+	walMetadataRecordType int64 = 1
+	walEntryRecordType    int64 = 2
+	walStateRecordType    int64 = 3
+	walCrcRecordType      int64 = 4
+
+	raftNormalEntryType uint64 = 0
+)
+
+var walCrcTable = crc32.MakeTable(crc32.Castagnoli)
+
+type walRecord struct {
+	Type int64
+	Crc  uint32
+	Data []byte
+
+	// Offset is the position of the record frame in the WAL file
+	Offset int64
+}
+
+// raftEntry is the part of the raftpb.Entry needed to replay the WAL
+type raftEntry struct {
+	Term  uint64
+	Index uint64
+	Type  uint64
+	Data  []byte
+}
+
+// walRecordReader reads the records of a single WAL file and validates the rolling checksum
+type walRecordReader struct {
+	reader io.Reader
+	offset int64
+	crc    uint32
+}
+
+func newWalRecordReader(reader io.Reader, prevCrc uint32) *walRecordReader {
+	return &walRecordReader{reader: reader, crc: prevCrc}
+}
+
+// Next returns io.EOF at the end of the written records: the end of the file, the zeroed preallocated space
+// or the torn last record.
+func (r *walRecordReader) Next() (walRecord, error) {
+	var lenField uint64
+	err := binary.Read(r.reader, binary.LittleEndian, &lenField)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return walRecord{}, io.EOF
+	}
+	if err != nil {
+		return walRecord{}, err
+	}
+	if lenField == 0 {
+		return walRecord{}, io.EOF
+	}
+
+	recordBytes, padBytes := decodeFrameSize(lenField)
+	frame := make([]byte, recordBytes+padBytes)
+	_, err = io.ReadFull(r.reader, frame)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return walRecord{}, io.EOF
+	}
+	if err != nil {
+		return walRecord{}, err
+	}
+
+	record, err := unmarshalWalRecord(frame[:recordBytes])
+	if err != nil {
+		return walRecord{}, errors.Wrapf(err, "failed to decode WAL record at offset %d", r.offset)
+	}
+	record.Offset = r.offset
+	r.offset += 8 + recordBytes + padBytes
+
+	if record.Type == walCrcRecordType {
+		if r.crc != 0 && record.Crc != r.crc {
+			return walRecord{}, fmt.Errorf("WAL crc record at offset %d mismatches the previous file", record.Offset)
+		}
+		r.crc = record.Crc
+		return record, nil
+	}
+	r.crc = crc32.Update(r.crc, walCrcTable, record.Data)
+	if record.Crc != r.crc {
+		return walRecord{}, fmt.Errorf("WAL record at offset %d is corrupted: crc mismatch", record.Offset)
+	}
+	return record, nil
+}
+
+// Crc returns the rolling checksum after the last read record
+func (r *walRecordReader) Crc() uint32 {
+	return r.crc
+}
+
+func decodeFrameSize(lenField uint64) (recordBytes int64, padBytes int64) {
+	recordBytes = int64(lenField & ^(uint64(0xff) << 56))
+	if lenField>>63 == 1 {
+		padBytes = int64((lenField >> 56) & 0x7)
+	}
+	return recordBytes, padBytes
+}
+
+func unmarshalWalRecord(data []byte) (walRecord, error) {
+	record := walRecord{}
+	err := walkProtoFields(data, func(number protowire.Number, value uint64, bytes []byte) {
+		switch number {
+		case 1:
+			record.Type = int64(value)
+		case 2:
+			record.Crc = uint32(value)
+		case 3:
+			record.Data = bytes
+		}
+	})
+	return record, err
+}
+
+func unmarshalRaftEntry(data []byte) (raftEntry, error) {
+	entry := raftEntry{}
+	err := walkProtoFields(data, func(number protowire.Number, value uint64, bytes []byte) {
+		switch number {
+		case 1:
+			entry.Type = value
+		case 2:
+			entry.Term = value
+		case 3:
+			entry.Index = value
+		case 4:
+			entry.Data = bytes
+		}
+	})
+	return entry, err
+}
+
+// walkProtoFields calls the visitor for every varint and length-delimited field of the protobuf message
+func walkProtoFields(data []byte, visitor func(number protowire.Number, value uint64, bytes []byte)) error {
+	for len(data) > 0 {
+		number, fieldType, tagLen := protowire.ConsumeTag(data)
+		if tagLen < 0 {
+			return protowire.ParseError(tagLen)
+		}
+		data = data[tagLen:]
+
+		var valueLen int
+		switch fieldType {
+		case protowire.VarintType:
+			var value uint64
+			value, valueLen = protowire.ConsumeVarint(data)
+			if valueLen >= 0 {
+				visitor(number, value, nil)
+			}
+		case protowire.BytesType:
+			var bytes []byte
+			bytes, valueLen = protowire.ConsumeBytes(data)
+			if valueLen >= 0 {
+				visitor(number, 0, bytes)
+			}
+		default:
+			valueLen = protowire.ConsumeFieldValue(number, fieldType, data)
+		}
+		if valueLen < 0 {
+			return protowire.ParseError(valueLen)
+		}
+		data = data[valueLen:]
+	}
+	return nil
+}
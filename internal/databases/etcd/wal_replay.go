@@ -0,0 +1,305 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wal-g/tracelog"
+)
+
+// keyValue is the mvccpb.KeyValue in the etcd gRPC gateway JSON
+type keyValue struct {
+	Key            []byte `json:"key"`
+	CreateRevision int64  `json:"create_revision,string"`
+	ModRevision    int64  `json:"mod_revision,string"`
+	Version        int64  `json:"version,string"`
+	Value          []byte `json:"value"`
+	Lease          int64  `json:"lease,string"`
+}
+
+// keyValueStore is the etcd member the archived WAL is replayed to
+type keyValueStore interface {
+	// rangeAt returns the keys of the range at the revision
+	rangeAt(ctx context.Context, key, rangeEnd []byte, revision int64) ([]keyValue, error)
+	// apply applies the request through the v3 API and returns the revision after it
+	apply(ctx context.Context, request raftRequest) (int64, error)
+}
+
+// walRequest is the request of the raft entry from the archived WAL
+type walRequest struct {
+	index   uint64
+	request raftRequest
+}
+
+// The errors the etcd apply path returns for the requests which failed the same way when they were
+// applied for the first time, or were already applied by the restored snapshot and do not change anything.
+var rejectedRequestErrors = []string{
+	"lease already exists",
+	"requested lease not found",
+	"required revision has been compacted",
+	"key not found",
+}
+
+// selectWalRequests decodes the requests of the raft entries after the backup raft index,
+// the entries have to continue the backup without gaps
+func selectWalRequests(entries []raftEntry, backupIndex uint64) ([]walRequest, error) {
+	requests := make([]walRequest, 0)
+	prevIndex := backupIndex
+	for _, entry := range entries {
+		if entry.Index <= backupIndex {
+			continue
+		}
+		if entry.Index != prevIndex+1 {
+			return nil, fmt.Errorf("the archived WAL misses the raft entries from %d to %d", prevIndex+1, entry.Index-1)
+		}
+		prevIndex = entry.Index
+		if entry.Type != raftNormalEntryType || len(entry.Data) == 0 {
+			continue
+		}
+		request, err := unmarshalRaftRequest(entry.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the request of raft entry %d: %w", entry.Index, err)
+		}
+		if !request.isReplayed() {
+			tracelog.DebugLogger.Printf("Skipping the request of raft entry %d, it does not change the keyspace\n", entry.Index)
+			continue
+		}
+		requests = append(requests, walRequest{index: entry.Index, request: request})
+	}
+	return requests, nil
+}
+
+// findReplayStart finds the first request which is not contained in the restored snapshot.
+// The snapshot is taken after the backup checkpoint, so starting from the checkpoint revision
+// the revisions created by the requests are computed from the history of the keys until the snapshot revision.
+// The requests after the last one creating the snapshot revision do not change the keyspace at it,
+// so replaying them gives the same result whether they are contained in the snapshot or not.
+func findReplayStart(ctx context.Context, store keyValueStore, requests []walRequest,
+	backupRevision, snapshotRevision int64) (int, error) {
+	if snapshotRevision < backupRevision {
+		return 0, fmt.Errorf("the restored snapshot revision %d is before the backup revision %d",
+			snapshotRevision, backupRevision)
+	}
+	revision := backupRevision
+	for i, request := range requests {
+		if revision == snapshotRevision {
+			return i, nil
+		}
+		created, err := createsRevision(ctx, store, request.request, revision)
+		if err != nil {
+			return 0, fmt.Errorf("failed to check the request of raft entry %d: %w", request.index, err)
+		}
+		if created {
+			revision++
+		}
+	}
+	if revision != snapshotRevision {
+		return 0, fmt.Errorf("the archived WAL ends at revision %d before the restored snapshot revision %d",
+			revision, snapshotRevision)
+	}
+	return len(requests), nil
+}
+
+// replayRequests applies the requests until the target revision is created
+func replayRequests(ctx context.Context, store keyValueStore, requests []walRequest,
+	revision, targetRevision int64) error {
+	for _, request := range requests {
+		if revision == targetRevision {
+			break
+		}
+		newRevision, err := store.apply(ctx, request.request)
+		if isRejectedRequest(err) {
+			tracelog.DebugLogger.Printf("The request of raft entry %d is rejected: %v\n", request.index, err)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply the request of raft entry %d: %w", request.index, err)
+		}
+		if newRevision != revision && newRevision != revision+1 {
+			return fmt.Errorf("the revision changed from %d to %d after the request of raft entry %d, "+
+				"the member is changed by something else", revision, newRevision, request.index)
+		}
+		revision = newRevision
+	}
+	if revision != targetRevision {
+		return fmt.Errorf("the archived WAL ends at revision %d before the target revision %d", revision, targetRevision)
+	}
+	tracelog.InfoLogger.Printf("Replayed the archived WAL up to revision %d\n", revision)
+	return nil
+}
+
+func isRejectedRequest(err error) bool {
+	var responseErr *etcdError
+	if !errors.As(err, &responseErr) {
+		return false
+	}
+	for _, rejected := range rejectedRequestErrors {
+		if strings.Contains(responseErr.Error(), rejected) {
+			return true
+		}
+	}
+	return false
+}
+
+// createsRevision tells whether the request applied to the keyspace at the revision creates the next one
+func createsRevision(ctx context.Context, store keyValueStore, request raftRequest, revision int64) (bool, error) {
+	switch {
+	case request.Put != nil:
+		return putCreatesRevision(ctx, store, request.Put, revision)
+	case request.DeleteRange != nil:
+		return rangeExists(ctx, store, request.DeleteRange.Key, request.DeleteRange.RangeEnd, revision)
+	case request.Txn != nil:
+		return txnCreatesRevision(ctx, store, request.Txn, revision)
+	case request.LeaseRevoke != nil:
+		// the revoked lease deletes the attached keys
+		kvs, err := store.rangeAt(ctx, []byte{0}, []byte{0}, revision)
+		if err != nil {
+			return false, err
+		}
+		for _, kv := range kvs {
+			if kv.Lease == request.LeaseRevoke.ID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// putCreatesRevision returns true unless the put keeps the value or the lease of the absent key and fails
+func putCreatesRevision(ctx context.Context, store keyValueStore, request *putRequest, revision int64) (bool, error) {
+	if !request.IgnoreValue && !request.IgnoreLease {
+		return true, nil
+	}
+	return rangeExists(ctx, store, request.Key, nil, revision)
+}
+
+func rangeExists(ctx context.Context, store keyValueStore, key, rangeEnd []byte, revision int64) (bool, error) {
+	kvs, err := store.rangeAt(ctx, key, rangeEnd, revision)
+	return len(kvs) > 0, err
+}
+
+func txnCreatesRevision(ctx context.Context, store keyValueStore, request *txnRequest, revision int64) (bool, error) {
+	succeeded := true
+	for _, cmp := range request.Compare {
+		kvs, err := store.rangeAt(ctx, cmp.Key, cmp.RangeEnd, revision)
+		if err != nil {
+			return false, err
+		}
+		if !applyCompare(cmp, kvs) {
+			succeeded = false
+			break
+		}
+	}
+	ops := request.Success
+	if !succeeded {
+		ops = request.Failure
+	}
+	for _, op := range ops {
+		var created bool
+		var err error
+		switch {
+		case op.RequestPut != nil:
+			created, err = putCreatesRevision(ctx, store, op.RequestPut, revision)
+		case op.RequestDeleteRange != nil:
+			created, err = rangeExists(ctx, store, op.RequestDeleteRange.Key, op.RequestDeleteRange.RangeEnd, revision)
+		case op.RequestTxn != nil:
+			created, err = txnCreatesRevision(ctx, store, op.RequestTxn, revision)
+		}
+		if err != nil || created {
+			return created, err
+		}
+	}
+	return false, nil
+}
+
+// applyCompare evaluates the compare the same way as the etcd apply path: the absent key compares
+// as the key with zero fields except for the value, and every key of the range has to satisfy the compare
+func applyCompare(cmp compare, kvs []keyValue) bool {
+	if len(kvs) == 0 {
+		if cmp.Target == compareValue {
+			return false
+		}
+		return compareKeyValue(cmp, keyValue{})
+	}
+	for _, kv := range kvs {
+		if !compareKeyValue(cmp, kv) {
+			return false
+		}
+	}
+	return true
+}
+
+func compareKeyValue(cmp compare, kv keyValue) bool {
+	var result int
+	switch cmp.Target {
+	case compareValue:
+		result = bytes.Compare(kv.Value, cmp.Value)
+	case compareCreate:
+		result = compareInt64(kv.CreateRevision, cmp.CreateRevision)
+	case compareMod:
+		result = compareInt64(kv.ModRevision, cmp.ModRevision)
+	case compareVersion:
+		result = compareInt64(kv.Version, cmp.Version)
+	case compareLease:
+		result = compareInt64(kv.Lease, cmp.Lease)
+	}
+	switch cmp.Result {
+	case compareEqual:
+		return result == 0
+	case compareNotEqual:
+		return result != 0
+	case compareGreater:
+		return result > 0
+	case compareLess:
+		return result < 0
+	}
+	return false
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (c *etcdClient) rangeAt(ctx context.Context, key, rangeEnd []byte, revision int64) ([]keyValue, error) {
+	var response struct {
+		Kvs []keyValue `json:"kvs"`
+	}
+	request := rangeRequest{Key: key, RangeEnd: rangeEnd, Revision: revision}
+	err := c.call(ctx, "/v3/kv/range", request, &response)
+	return response.Kvs, err
+}
+
+func (c *etcdClient) apply(ctx context.Context, request raftRequest) (int64, error) {
+	var path string
+	var body any
+	switch {
+	case request.Put != nil:
+		path, body = "/v3/kv/put", request.Put
+	case request.DeleteRange != nil:
+		path, body = "/v3/kv/deleterange", request.DeleteRange
+	case request.Txn != nil:
+		path, body = "/v3/kv/txn", request.Txn
+	case request.Compaction != nil:
+		path, body = "/v3/kv/compaction", request.Compaction
+	case request.LeaseGrant != nil:
+		path, body = "/v3/lease/grant", request.LeaseGrant
+	case request.LeaseRevoke != nil:
+		path, body = "/v3/lease/revoke", request.LeaseRevoke
+	default:
+		return 0, fmt.Errorf("the request is not replayed")
+	}
+	var response struct {
+		Header responseHeader `json:"header"`
+	}
+	err := c.call(ctx, path, body, &response)
+	return response.Header.Revision, err
+}
@@ -0,0 +1,228 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// testStore keeps the keyspace of every revision
+type testStore struct {
+	revisions []map[string]keyValue
+	leases    map[int64]bool
+}
+
+func newTestStore(revision int64, kvs ...keyValue) *testStore {
+	store := &testStore{leases: map[int64]bool{}}
+	store.revisions = make([]map[string]keyValue, revision+1)
+	store.revisions[revision] = map[string]keyValue{}
+	for _, kv := range kvs {
+		store.revisions[revision][string(kv.Key)] = kv
+	}
+	return store
+}
+
+func (s *testStore) revision() int64 {
+	return int64(len(s.revisions) - 1)
+}
+
+func (s *testStore) rangeAt(_ context.Context, key, rangeEnd []byte, revision int64) ([]keyValue, error) {
+	kvs := make([]keyValue, 0)
+	for _, name := range slices.Sorted(maps.Keys(s.revisions[revision])) {
+		k := []byte(name)
+		if bytes.Equal(k, key) || len(rangeEnd) > 0 && bytes.Compare(k, key) >= 0 &&
+			(bytes.Equal(rangeEnd, []byte{0}) || bytes.Compare(k, rangeEnd) < 0) {
+			kvs = append(kvs, s.revisions[revision][name])
+		}
+	}
+	return kvs, nil
+}
+
+func (s *testStore) apply(ctx context.Context, request raftRequest) (int64, error) {
+	current := s.revision()
+	switch {
+	case request.Put != nil:
+		kvs, _ := s.rangeAt(ctx, request.Put.Key, nil, current)
+		if request.Put.IgnoreValue && len(kvs) == 0 {
+			return 0, &etcdError{Message: "etcdserver: key not found"}
+		}
+		s.put(request.Put.Key, request.Put.Value)
+	case request.DeleteRange != nil:
+		kvs, _ := s.rangeAt(ctx, request.DeleteRange.Key, request.DeleteRange.RangeEnd, current)
+		if len(kvs) > 0 {
+			next := maps.Clone(s.revisions[current])
+			for _, kv := range kvs {
+				delete(next, string(kv.Key))
+			}
+			s.revisions = append(s.revisions, next)
+		}
+	case request.Txn != nil:
+		// the test transactions have single operation
+		ops := request.Txn.Success
+		for _, cmp := range request.Txn.Compare {
+			kvs, _ := s.rangeAt(ctx, cmp.Key, cmp.RangeEnd, current)
+			if !applyCompare(cmp, kvs) {
+				ops = request.Txn.Failure
+			}
+		}
+		if len(ops) > 0 {
+			return s.apply(ctx, raftRequest{Put: ops[0].RequestPut, DeleteRange: ops[0].RequestDeleteRange})
+		}
+	case request.LeaseGrant != nil:
+		if s.leases[request.LeaseGrant.ID] {
+			return 0, &etcdError{Message: "etcdserver: lease already exists"}
+		}
+		s.leases[request.LeaseGrant.ID] = true
+	}
+	return s.revision(), nil
+}
+
+func (s *testStore) put(key, value []byte) {
+	next := maps.Clone(s.revisions[s.revision()])
+	kv := next[string(key)]
+	if kv.Version == 0 {
+		kv.CreateRevision = s.revision() + 1
+	}
+	kv.Key, kv.Value, kv.ModRevision = key, value, s.revision()+1
+	kv.Version++
+	next[string(key)] = kv
+	s.revisions = append(s.revisions, next)
+}
+
+func TestUnmarshalRaftRequest(t *testing.T) {
+	txn := appendTestMessage(nil, 1, marshalTestCompare(compareNotEqual, compareValue, "a", "1"))
+	txn = appendTestMessage(txn, 2, appendTestMessage(nil, 2, marshalTestPut("b", "2")))
+	txn = appendTestMessage(txn, 3, appendTestMessage(nil, 3, appendTestMessage(nil, 1, []byte("c"))))
+
+	request, err := unmarshalRaftRequest(marshalTestRequest(6, txn))
+	require.NoError(t, err)
+	require.NotNil(t, request.Txn)
+	assert.Equal(t, []compare{{Result: compareNotEqual, Target: compareValue, Key: []byte("a"), Value: []byte("1")}},
+		request.Txn.Compare)
+	assert.Equal(t, &putRequest{Key: []byte("b"), Value: []byte("2")}, request.Txn.Success[0].RequestPut)
+	assert.Equal(t, &deleteRangeRequest{Key: []byte("c")}, request.Txn.Failure[0].RequestDeleteRange)
+
+	var grant []byte
+	grant = protowire.AppendTag(grant, 1, protowire.VarintType)
+	grant = protowire.AppendVarint(grant, 60)
+	grant = protowire.AppendTag(grant, 2, protowire.VarintType)
+	grant = protowire.AppendVarint(grant, 7)
+	request, err = unmarshalRaftRequest(marshalTestRequest(8, grant))
+	require.NoError(t, err)
+	assert.Equal(t, &leaseGrantRequest{TTL: 60, ID: 7}, request.LeaseGrant)
+
+	// the auth requests are not replayed
+	request, err = unmarshalRaftRequest(marshalTestRequest(1100, []byte("user")))
+	require.NoError(t, err)
+	assert.False(t, request.isReplayed())
+}
+
+func TestApplyCompare(t *testing.T) {
+	kv := keyValue{Key: []byte("a"), Value: []byte("1"), Version: 2}
+
+	assert.True(t, applyCompare(compare{Target: compareValue, Value: []byte("1")}, []keyValue{kv}))
+	assert.False(t, applyCompare(compare{Result: compareGreater, Target: compareVersion, Version: 2}, []keyValue{kv}))
+	// the absent key has zero version, but its value never matches
+	assert.True(t, applyCompare(compare{Target: compareVersion}, nil))
+	assert.False(t, applyCompare(compare{Result: compareNotEqual, Target: compareValue, Value: []byte("1")}, nil))
+}
+
+// testWalRequests returns the requests of the raft entries from 6 to 11 after the backup at revision 10
+func testWalRequests(t *testing.T) []walRequest {
+	txn := appendTestMessage(nil, 1, marshalTestCompare(compareEqual, compareValue, "a", "1"))
+	txn = appendTestMessage(txn, 2, appendTestMessage(nil, 2, marshalTestPut("b", "2")))
+	var grant []byte
+	grant = protowire.AppendTag(grant, 2, protowire.VarintType)
+	grant = protowire.AppendVarint(grant, 7)
+
+	entries := []raftEntry{
+		{Index: 6, Data: marshalTestRequest(4, marshalTestPut("a", "1"))},
+		// the delete of the absent key does not create the revision
+		{Index: 7, Data: marshalTestRequest(5, appendTestMessage(nil, 1, []byte("absent")))},
+		{Index: 8, Data: marshalTestRequest(6, txn)},
+		{Index: 9, Data: marshalTestRequest(8, grant)},
+		{Index: 10, Data: marshalTestRequest(4, marshalTestPut("c", "3"))},
+		{Index: 11, Data: marshalTestRequest(4, marshalTestPut("d", "4"))},
+	}
+	requests, err := selectWalRequests(entries, 5)
+	require.NoError(t, err)
+	return requests
+}
+
+// newTestSnapshot returns the store restored from the snapshot at revision 12 taken after the raft entry 9
+func newTestSnapshot(ctx context.Context, t *testing.T, requests []walRequest) *testStore {
+	store := newTestStore(10, keyValue{Key: []byte("x"), Value: []byte("0"), CreateRevision: 3, ModRevision: 3, Version: 1})
+	for _, request := range requests[:4] {
+		_, err := store.apply(ctx, request.request)
+		require.NoError(t, err)
+	}
+	require.Equal(t, int64(12), store.revision())
+	return store
+}
+
+func TestReplayRequests(t *testing.T) {
+	ctx := t.Context()
+	requests := testWalRequests(t)
+	store := newTestSnapshot(ctx, t, requests)
+
+	start, err := findReplayStart(ctx, store, requests, 10, 12)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), requests[start].index)
+
+	// the lease grant contained in the snapshot is rejected
+	err = replayRequests(ctx, store, requests[start:], 12, 13)
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), store.revision())
+	kvs, _ := store.rangeAt(ctx, []byte("c"), nil, 13)
+	assert.Len(t, kvs, 1)
+	kvs, _ = store.rangeAt(ctx, []byte("d"), nil, 13)
+	assert.Empty(t, kvs)
+}
+
+func TestReplayRequests_TargetAfterWal(t *testing.T) {
+	ctx := t.Context()
+	requests := testWalRequests(t)
+	store := newTestSnapshot(ctx, t, requests)
+
+	err := replayRequests(ctx, store, requests[3:], 12, 15)
+	assert.Error(t, err)
+}
+
+func TestFindReplayStart_SnapshotAfterWal(t *testing.T) {
+	ctx := t.Context()
+	requests := testWalRequests(t)
+	store := newTestSnapshot(ctx, t, requests)
+
+	_, err := findReplayStart(ctx, store, requests[:2], 10, 12)
+	assert.Error(t, err)
+}
+
+func marshalTestRequest(field protowire.Number, request []byte) []byte {
+	return appendTestMessage(nil, field, request)
+}
+
+func marshalTestPut(key, value string) []byte {
+	put := appendTestMessage(nil, 1, []byte(key))
+	return appendTestMessage(put, 2, []byte(value))
+}
+
+func marshalTestCompare(result, target int32, key, value string) []byte {
+	var cmp []byte
+	cmp = protowire.AppendTag(cmp, 1, protowire.VarintType)
+	cmp = protowire.AppendVarint(cmp, uint64(result))
+	cmp = protowire.AppendTag(cmp, 2, protowire.VarintType)
+	cmp = protowire.AppendVarint(cmp, uint64(target))
+	cmp = appendTestMessage(cmp, 3, []byte(key))
+	return appendTestMessage(cmp, 7, []byte(value))
+}
+
+func appendTestMessage(data []byte, field protowire.Number, message []byte) []byte {
+	data = protowire.AppendTag(data, field, protowire.BytesType)
+	return protowire.AppendBytes(data, message)
+}
@@ -2,7 +2,10 @@ package fdb
 
 import (
 	"context"
+	"os"
 	"os/exec"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/wal-g/tracelog"
@@ -10,12 +13,31 @@ import (
 	"github.com/wal-g/wal-g/utility"
 )
 
-// TODO: add more metadata
-type streamSentinelDto struct {
-	StartLocalTime time.Time
+type StreamSentinelDto struct {
+	StartLocalTime  time.Time `json:"StartLocalTime,omitempty"`
+	FinishLocalTime time.Time `json:"FinishLocalTime,omitempty"`
+	IsPermanent     bool      `json:"IsPermanent"`
+	// StartVersion is the cluster read version before the backup started
+	StartVersion int64 `json:"StartVersion,omitempty"`
+
+	UncompressedSize int64  `json:"UncompressedSize,omitempty"`
+	CompressedSize   int64  `json:"CompressedSize,omitempty"`
+	Hostname         string `json:"Hostname,omitempty"`
+	FdbVersion       string `json:"FdbVersion,omitempty"`
+
+	UserData interface{} `json:"UserData,omitempty"`
 }
 
-func HandleBackupPush(ctx context.Context, uploader internal.Uploader, backupCmd *exec.Cmd) {
+// HandleBackupPush starts backup procedure.
+func HandleBackupPush(ctx context.Context, uploader internal.Uploader, backupCmd *exec.Cmd, permanent bool, userDataRaw string) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = ""
+		tracelog.WarningLogger.Printf("Failed to obtain the OS hostname")
+	}
+	fdbVersion := getFdbVersion(ctx)
+
+	startVersion := getFdbReadVersion(ctx)
 	timeStart := utility.TimeNowCrossPlatformLocal()
 
 	stdout, stderr, err := utility.StartCommandWithStdoutStderr(backupCmd)
@@ -29,9 +51,64 @@ func HandleBackupPush(ctx context.Context, uploader internal.Uploader, backupCmd
 		tracelog.ErrorLogger.Printf("Backup command output:\n%s", stderr.String())
 		tracelog.ErrorLogger.Fatalf("backup create command failed: %v", err)
 	}
+	timeFinish := utility.TimeNowCrossPlatformLocal()
+
+	uploadedSize, err := uploader.UploadedDataSize()
+	if err != nil {
+		tracelog.ErrorLogger.Printf("Failed to calc uploaded data size: %v", err)
+	}
+
+	rawSize, err := uploader.RawDataSize()
+	if err != nil {
+		tracelog.ErrorLogger.Printf("Failed to calc raw data size: %v", err)
+	}
+
+	userData, err := internal.UnmarshalSentinelUserData(userDataRaw)
+	tracelog.ErrorLogger.FatalfOnError("Failed to unmarshal the provided UserData: %s", err)
 
-	sentinel := streamSentinelDto{StartLocalTime: timeStart}
+	sentinel := StreamSentinelDto{
+		StartLocalTime:   timeStart,
+		FinishLocalTime:  timeFinish,
+		IsPermanent:      permanent,
+		StartVersion:     startVersion,
+		UncompressedSize: rawSize,
+		CompressedSize:   uploadedSize,
+		Hostname:         hostname,
+		FdbVersion:       fdbVersion,
+		UserData:         userData,
+	}
 
 	err = internal.UploadSentinel(ctx, uploader, &sentinel, fileName)
 	tracelog.ErrorLogger.FatalOnError(err)
 }
+
+// getFdbReadVersion returns the current read version of the cluster or 0 if fdbcli fails
+func getFdbReadVersion(ctx context.Context) int64 {
+	output, err := exec.CommandContext(ctx, "fdbcli", "--exec", "getversion").Output()
+	if err != nil {
+		tracelog.WarningLogger.Printf("Failed to obtain the FoundationDB read version: %v", err)
+		return 0
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		tracelog.WarningLogger.Printf("Failed to obtain the FoundationDB read version: empty fdbcli output")
+		return 0
+	}
+	version, err := strconv.ParseInt(fields[len(fields)-1], 10, 64)
+	if err != nil {
+		tracelog.WarningLogger.Printf("Failed to parse the FoundationDB read version: %v", err)
+		return 0
+	}
+	return version
+}
+
+// getFdbVersion returns the first line of `fdbcli --version` output or empty string if fdbcli is not available
+func getFdbVersion(ctx context.Context) string {
+	output, err := exec.CommandContext(ctx, "fdbcli", "--version").Output()
+	if err != nil {
+		tracelog.WarningLogger.Printf("Failed to obtain the FoundationDB version: %v", err)
+		return ""
+	}
+	version, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return version
+}
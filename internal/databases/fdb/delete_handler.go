@@ -0,0 +1,45 @@
+package fdb
+
+import (
+	"context"
+
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+func NewFdbDeleteHandler(ctx context.Context, folder storage.Folder) (*internal.DeleteHandler, error) {
+	backups, err := internal.GetBackupSentinelObjects(ctx, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	backupObjects := make([]internal.BackupObject, 0, len(backups))
+	for _, object := range backups {
+		backupObjects = append(backupObjects, internal.NewDefaultBackupObject(object))
+	}
+
+	permanentBackups := internal.GetPermanentBackups(ctx, folder.GetSubFolder(utility.BaseBackupPath), NewGenericMetaFetcher())
+
+	isPermanentFunc := func(object storage.Object) bool {
+		return internal.IsPermanent(object.GetName(), permanentBackups, internal.StreamBackupNameLength)
+	}
+
+	return internal.NewDeleteHandler(
+			folder,
+			backupObjects,
+			makeLessFunc(),
+			internal.IsPermanentFunc(isPermanentFunc)),
+		nil
+}
+
+func makeLessFunc() func(object1, object2 storage.Object) bool {
+	return func(object1, object2 storage.Object) bool {
+		time1, ok1 := utility.TryFetchTimeRFC3999(object1.GetName())
+		time2, ok2 := utility.TryFetchTimeRFC3999(object2.GetName())
+		if !ok1 || !ok2 {
+			return object2.GetLastModified().After(object1.GetLastModified())
+		}
+		return time1 < time2
+	}
+}
@@ -0,0 +1,99 @@
+package fdb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+type GenericMetaInteractor struct {
+	GenericMetaFetcher
+	GenericMetaSetter
+}
+
+func NewGenericMetaInteractor() GenericMetaInteractor {
+	return GenericMetaInteractor{
+		GenericMetaFetcher: NewGenericMetaFetcher(),
+		GenericMetaSetter:  NewGenericMetaSetter(),
+	}
+}
+
+type GenericMetaFetcher struct{}
+
+func NewGenericMetaFetcher() GenericMetaFetcher {
+	return GenericMetaFetcher{}
+}
+
+func (mf GenericMetaFetcher) Fetch(ctx context.Context, backupName string, backupFolder storage.Folder) (internal.GenericMetadata, error) {
+	backup, err := internal.NewBackup(backupFolder, backupName)
+	if err != nil {
+		return internal.GenericMetadata{}, err
+	}
+	var sentinel StreamSentinelDto
+	err = backup.FetchSentinel(ctx, &sentinel)
+	if err != nil {
+		return internal.GenericMetadata{}, err
+	}
+
+	return internal.GenericMetadata{
+		BackupName:       backupName,
+		UncompressedSize: sentinel.UncompressedSize,
+		CompressedSize:   sentinel.CompressedSize,
+		Hostname:         sentinel.Hostname,
+		StartTime:        sentinel.StartLocalTime,
+		FinishTime:       sentinel.FinishLocalTime,
+		IsPermanent:      sentinel.IsPermanent,
+		IncrementDetails: &internal.NopIncrementDetailsFetcher{},
+		UserData:         sentinel.UserData,
+	}, nil
+}
+
+// TODO implement fetch from storage in fdb
+func (mf GenericMetaFetcher) FetchFromStorage(
+	ctx context.Context, backupName string, backupFolder storage.Folder, storage string,
+) (internal.GenericMetadata, error) {
+	return mf.Fetch(ctx, backupName, backupFolder)
+}
+
+type GenericMetaSetter struct{}
+
+func NewGenericMetaSetter() GenericMetaSetter {
+	return GenericMetaSetter{}
+}
+
+func (ms GenericMetaSetter) SetUserData(ctx context.Context, backupName string, backupFolder storage.Folder, userData interface{}) error {
+	modifier := func(dto StreamSentinelDto) StreamSentinelDto {
+		dto.UserData = userData
+		return dto
+	}
+	return modifyBackupSentinel(ctx, backupName, backupFolder, modifier)
+}
+
+func (ms GenericMetaSetter) SetIsPermanent(ctx context.Context, backupName string, backupFolder storage.Folder, isPermanent bool) error {
+	modifier := func(dto StreamSentinelDto) StreamSentinelDto {
+		dto.IsPermanent = isPermanent
+		return dto
+	}
+	return modifyBackupSentinel(ctx, backupName, backupFolder, modifier)
+}
+
+func modifyBackupSentinel(ctx context.Context,
+	backupName string, backupFolder storage.Folder, modifier func(StreamSentinelDto) StreamSentinelDto) error {
+	backup, err := internal.NewBackup(backupFolder, backupName)
+	if err != nil {
+		return err
+	}
+	var sentinel StreamSentinelDto
+	err = backup.FetchSentinel(ctx, &sentinel)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch the existing backup metadata for modifying")
+	}
+	sentinel = modifier(sentinel)
+	err = backup.UploadSentinel(ctx, sentinel)
+	if err != nil {
+		return errors.Wrap(err, "failed to upload the modified metadata to the storage")
+	}
+	return nil
+}
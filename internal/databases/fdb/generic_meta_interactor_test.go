@@ -0,0 +1,70 @@
+package fdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/databases/fdb"
+	"github.com/wal-g/wal-g/testtools"
+)
+
+func init() {
+	internal.ConfigureSettings("")
+	config.InitConfig()
+	config.Configure()
+}
+
+func TestFetch(t *testing.T) {
+	folder := testtools.CreateMockStorageFolder(t.Context())
+	backupName := "test"
+	startTime := time.Date(2002, 3, 21, 0, 0, 0, 0, time.UTC)
+	finishTime := startTime.Add(time.Hour)
+
+	sentinel := fdb.StreamSentinelDto{
+		StartLocalTime:   startTime,
+		FinishLocalTime:  finishTime,
+		UncompressedSize: 100,
+		CompressedSize:   10,
+		Hostname:         "fdb-host",
+		FdbVersion:       "FoundationDB CLI 7.1.57",
+		UserData:         "Data",
+	}
+	err := internal.UploadDto(t.Context(), folder, sentinel, internal.SentinelNameFromBackup(backupName))
+	require.NoError(t, err)
+
+	actualResult, err := fdb.NewGenericMetaFetcher().Fetch(t.Context(), backupName, folder)
+	require.NoError(t, err)
+
+	assert.Equal(t, backupName, actualResult.BackupName)
+	assert.True(t, startTime.Equal(actualResult.StartTime))
+	assert.True(t, finishTime.Equal(actualResult.FinishTime))
+	assert.Equal(t, int64(100), actualResult.UncompressedSize)
+	assert.Equal(t, int64(10), actualResult.CompressedSize)
+	assert.Equal(t, "fdb-host", actualResult.Hostname)
+	assert.False(t, actualResult.IsPermanent)
+	assert.Equal(t, "Data", actualResult.UserData)
+}
+
+func TestSetIsPermanent(t *testing.T) {
+	folder := testtools.CreateMockStorageFolder(t.Context())
+	backupName := "test"
+
+	sentinel := fdb.StreamSentinelDto{FdbVersion: "FoundationDB CLI 7.1.57"}
+	err := internal.UploadDto(t.Context(), folder, sentinel, internal.SentinelNameFromBackup(backupName))
+	require.NoError(t, err)
+
+	err = fdb.NewGenericMetaSetter().SetIsPermanent(t.Context(), backupName, folder, true)
+	require.NoError(t, err)
+
+	backup, err := internal.NewBackup(folder, backupName)
+	require.NoError(t, err)
+	var modified fdb.StreamSentinelDto
+	err = backup.FetchSentinel(t.Context(), &modified)
+	require.NoError(t, err)
+	assert.True(t, modified.IsPermanent)
+	assert.Equal(t, sentinel.FdbVersion, modified.FdbVersion)
+}
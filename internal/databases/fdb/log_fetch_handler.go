@@ -0,0 +1,153 @@
+package fdb
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// HandleLogFetch downloads the continuous backup container to the destination directory,
+// so it can be restored with `fdbrestore start -r file://<dstDir> --version|--timestamp`.
+// If the backup name is set, only the snapshots and the mutation logs needed to restore
+// to the versions after the backup start are downloaded.
+func HandleLogFetch(ctx context.Context, folder storage.Folder, backupName string, dstDir string,
+	baseReader internal.StorageFolderReader) error {
+	var sinceVersion int64
+	if backupName != "" {
+		backup, err := internal.GetBackupByName(ctx, backupName, utility.BaseBackupPath, folder)
+		if err != nil {
+			return errors.Wrap(err, "failed to get mentioned backup")
+		}
+		var sentinel StreamSentinelDto
+		err = backup.FetchSentinel(ctx, &sentinel)
+		if err != nil {
+			return errors.Wrap(err, "failed to unmarshal backup sentinel")
+		}
+		sinceVersion = sentinel.StartVersion
+		if sinceVersion == 0 {
+			tracelog.WarningLogger.Printf("Backup %s has no start version, all log files are fetched\n", backupName)
+		}
+	}
+
+	logObjects, err := storage.ListFolderRecursively(ctx, folder.GetSubFolder(utility.WalPath))
+	if err != nil {
+		return errors.Wrap(err, "failed to list the log folder in storage")
+	}
+	relativePaths := make([]string, 0, len(logObjects))
+	for _, logObject := range logObjects {
+		relativePaths = append(relativePaths, utility.TrimFileExtension(logObject.GetName()))
+	}
+
+	reader := baseReader.SubFolder(utility.WalPath)
+	fetchedCount := 0
+	for _, relativePath := range selectLogFiles(relativePaths, sinceVersion) {
+		dstPath := filepath.Join(dstDir, filepath.FromSlash(relativePath))
+		err = os.MkdirAll(filepath.Dir(dstPath), 0755)
+		if err != nil {
+			return err
+		}
+		tracelog.DebugLogger.Printf("Fetching %s into %s\n", relativePath, dstPath)
+		err = internal.DownloadFileTo(ctx, reader, relativePath, dstPath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to download log file %s", relativePath)
+		}
+		fetchedCount++
+	}
+
+	tracelog.InfoLogger.Printf("Fetched %d log backup files into %s\n", fetchedCount, dstDir)
+	return nil
+}
+
+// selectLogFiles selects the container files needed to restore to any version after sinceVersion:
+// the latest snapshot finished by sinceVersion with the later snapshots, their range files
+// and the mutation logs since the selected snapshot begin. The other container files are always selected.
+func selectLogFiles(relativePaths []string, sinceVersion int64) []string {
+	if sinceVersion == 0 {
+		return relativePaths
+	}
+	// the files which names are not recognized are fetched, so the container stays complete
+	var minVersion int64
+	for _, relativePath := range relativePaths {
+		begin, end, ok := parseSnapshotFileName(relativePath)
+		if ok && end <= sinceVersion && begin > minVersion {
+			minVersion = begin
+		}
+	}
+	if minVersion == 0 {
+		tracelog.WarningLogger.Printf("No snapshot finished by version %d, all log files are fetched\n", sinceVersion)
+		return relativePaths
+	}
+
+	selected := make([]string, 0, len(relativePaths))
+	for _, relativePath := range relativePaths {
+		if begin, _, ok := parseSnapshotFileName(relativePath); ok && begin < minVersion {
+			continue
+		}
+		if begin, ok := parseRangeSnapshotVersion(relativePath); ok && begin < minVersion {
+			continue
+		}
+		if _, end, ok := parseLogFileName(relativePath); ok && end <= minVersion {
+			continue
+		}
+		selected = append(selected, relativePath)
+	}
+	return selected
+}
+
+// parseSnapshotFileName parses snapshots/snapshot,<beginVersion>,<endVersion>,<totalBytes>
+func parseSnapshotFileName(relativePath string) (begin, end int64, ok bool) {
+	if path.Base(path.Dir(relativePath)) != "snapshots" {
+		return 0, 0, false
+	}
+	return parseVersionRange(path.Base(relativePath), "snapshot")
+}
+
+// parseLogFileName parses logs/<folder>/log,<beginVersion>,<endVersion>,<uid>,<blockSize>
+func parseLogFileName(relativePath string) (begin, end int64, ok bool) {
+	if !isImmutableLogFile(relativePath) {
+		return 0, 0, false
+	}
+	return parseVersionRange(path.Base(relativePath), "log")
+}
+
+// parseRangeSnapshotVersion parses the snapshot begin version of kvranges/snapshot.<beginVersion>/<folder>/range,...
+func parseRangeSnapshotVersion(relativePath string) (int64, bool) {
+	parts := strings.Split(relativePath, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] != "kvranges" {
+			continue
+		}
+		version, found := strings.CutPrefix(parts[i+1], "snapshot.")
+		if !found {
+			return 0, false
+		}
+		begin, err := strconv.ParseInt(version, 10, 64)
+		return begin, err == nil
+	}
+	return 0, false
+}
+
+func parseVersionRange(fileName, prefix string) (begin, end int64, ok bool) {
+	fields := strings.Split(fileName, ",")
+	if len(fields) < 3 || fields[0] != prefix {
+		return 0, 0, false
+	}
+	begin, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	end, err = strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return begin, end, true
+}
@@ -0,0 +1,104 @@
+package fdb
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// The continuous backup container written by `fdbbackup start -z -d file://...` keeps the mutation logs
+// in the logs folder and the snapshot ranges in the kvranges folder. These files are never changed
+// after they are written, the other files (properties, snapshot descriptions) may be rewritten by fdbbackup.
+var immutableLogFolders = []string{"logs", "kvranges"}
+
+// fdbbackup writes the files to a temporary name and renames them when they are complete
+const temporaryLogFileSuffix = ".temp"
+
+// HandleLogPush uploads the continuous backup container from the log directory to the storage.
+// The mutation log and range files that are already uploaded are skipped.
+func HandleLogPush(ctx context.Context, uploader internal.Uploader, logDir string) error {
+	uploader.ChangeDirectory(utility.WalPath)
+	uploadedFiles, err := getUploadedLogFiles(ctx, uploader.Folder())
+	if err != nil {
+		return errors.Wrap(err, "failed to list the uploaded log files")
+	}
+
+	uploadedCount := 0
+	err = filepath.WalkDir(logDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), temporaryLogFileSuffix) {
+			return nil
+		}
+		relativePath, err := filepath.Rel(logDir, filePath)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		if isImmutableLogFile(relativePath) && uploadedFiles[relativePath] {
+			return nil
+		}
+
+		err = uploadLogFile(ctx, uploader, filePath, relativePath)
+		if err != nil {
+			return err
+		}
+		uploadedCount++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	tracelog.InfoLogger.Printf("Uploaded %d log backup files\n", uploadedCount)
+	return nil
+}
+
+func uploadLogFile(ctx context.Context, uploader internal.Uploader, filePath, relativePath string) error {
+	tracelog.DebugLogger.Printf("Archiving %s\n", relativePath)
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return errors.Wrapf(err, "upload: could not open '%s'", filePath)
+	}
+	defer utility.LoggedClose(file, "")
+
+	compressor := uploader.Compression()
+	dstPath := utility.AddFileExtension(relativePath, compressor.FileExtension())
+	err = uploader.Upload(ctx, dstPath, internal.CompressAndEncrypt(file, compressor, internal.ConfigureCrypter()))
+	if err != nil {
+		return errors.Wrapf(err, "upload: could not upload '%s'", filePath)
+	}
+	return nil
+}
+
+// getUploadedLogFiles returns the relative paths of the uploaded log files without the compression extension
+func getUploadedLogFiles(ctx context.Context, folder storage.Folder) (map[string]bool, error) {
+	objects, err := storage.ListFolderRecursively(ctx, folder)
+	if err != nil {
+		return nil, err
+	}
+	uploadedFiles := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		uploadedFiles[utility.TrimFileExtension(object.GetName())] = true
+	}
+	return uploadedFiles, nil
+}
+
+func isImmutableLogFile(relativePath string) bool {
+	for _, folder := range immutableLogFolders {
+		if strings.HasPrefix(relativePath, folder+"/") || strings.Contains(relativePath, "/"+folder+"/") {
+			return true
+		}
+	}
+	return false
+}
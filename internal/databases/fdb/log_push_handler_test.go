@@ -0,0 +1,103 @@
+package fdb_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/internal/databases/fdb"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/utility"
+)
+
+func TestLogPushAndFetch(t *testing.T) {
+	logDir := t.TempDir()
+	logFiles := map[string]string{
+		"backup-1/properties/log_begin_version":      "100",
+		"backup-1/logs/0000/log,100,200,uid,1048576": "mutations 1",
+		"backup-1/kvranges/snapshot.1/0/range,150":   "range",
+	}
+	for name, content := range logFiles {
+		writeLogFile(t, logDir, name, content)
+	}
+	writeLogFile(t, logDir, "backup-1/logs/0000/log,200,300,uid,1048576.temp", "incomplete")
+
+	folder := memory.NewFolder("in_memory/", memory.NewKVS())
+	err := fdb.HandleLogPush(t.Context(), internal.NewRegularUploader(lz4.Compressor{}, folder), logDir)
+	require.NoError(t, err)
+
+	_, err = folder.GetSubFolder(utility.WalPath).ReadObject(t.Context(),
+		"backup-1/logs/0000/log,200,300,uid,1048576.temp.lz4")
+	assert.Error(t, err, "temporary files should not be uploaded")
+
+	// the uploaded log files are not uploaded again, the mutable files are
+	writeLogFile(t, logDir, "backup-1/logs/0000/log,100,200,uid,1048576", "changed")
+	writeLogFile(t, logDir, "backup-1/properties/log_begin_version", "200")
+	logFiles["backup-1/properties/log_begin_version"] = "200"
+	err = fdb.HandleLogPush(t.Context(), internal.NewRegularUploader(lz4.Compressor{}, folder), logDir)
+	require.NoError(t, err)
+
+	dstDir := t.TempDir()
+	err = fdb.HandleLogFetch(t.Context(), folder, "", dstDir, internal.NewFolderReader(folder))
+	require.NoError(t, err)
+
+	for name, content := range logFiles {
+		fetched, err := os.ReadFile(filepath.Join(dstDir, filepath.FromSlash(name)))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(fetched), name)
+	}
+}
+
+func writeLogFile(t *testing.T, logDir, name, content string) {
+	filePath := filepath.Join(logDir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+}
+
+func TestLogFetchSinceBackup(t *testing.T) {
+	logDir := t.TempDir()
+	logFiles := []string{
+		"backup-1/properties/log_begin_version",
+		"backup-1/snapshots/snapshot,100,200,1024",
+		"backup-1/snapshots/snapshot,300,400,1024",
+		"backup-1/snapshots/snapshot,600,700,1024",
+		"backup-1/kvranges/snapshot.000000000000000100/0/range,150,uid,1048576",
+		"backup-1/kvranges/snapshot.000000000000000300/0/range,350,uid,1048576",
+		"backup-1/kvranges/snapshot.000000000000000600/0/range,650,uid,1048576",
+		"backup-1/logs/0000/log,100,250,uid,1048576",
+		"backup-1/logs/0000/log,250,350,uid,1048576",
+		"backup-1/logs/0000/log,350,800,uid,1048576",
+	}
+	for _, name := range logFiles {
+		writeLogFile(t, logDir, name, name)
+	}
+	folder := memory.NewFolder("in_memory/", memory.NewKVS())
+	require.NoError(t, fdb.HandleLogPush(t.Context(), internal.NewRegularUploader(lz4.Compressor{}, folder), logDir))
+
+	// the log files are uploaded before the backup, but the backup starts at version 500
+	sentinel, err := json.Marshal(fdb.StreamSentinelDto{StartVersion: 500})
+	require.NoError(t, err)
+	require.NoError(t, folder.PutObject(t.Context(),
+		path.Join(utility.BaseBackupPath, "stream_20260721T010000Z"+utility.SentinelSuffix), bytes.NewReader(sentinel)))
+
+	dstDir := t.TempDir()
+	err = fdb.HandleLogFetch(t.Context(), folder, "stream_20260721T010000Z", dstDir, internal.NewFolderReader(folder))
+	require.NoError(t, err)
+
+	skipped := map[string]bool{
+		"backup-1/snapshots/snapshot,100,200,1024":                              true,
+		"backup-1/kvranges/snapshot.000000000000000100/0/range,150,uid,1048576": true,
+		"backup-1/logs/0000/log,100,250,uid,1048576":                            true,
+	}
+	for _, name := range logFiles {
+		_, err := os.Stat(filepath.Join(dstDir, filepath.FromSlash(name)))
+		assert.Equal(t, skipped[name], os.IsNotExist(err), name)
+	}
+}
@@ -114,6 +114,68 @@ func (h *DeleteHandler) HandleDeleteRetainAfter(ctx context.Context, args []stri
 	tracelog.ErrorLogger.FatalOnError(err)
 }
 
+func (h *DeleteHandler) HandleDeleteRetainPolicy(ctx context.Context, policy internal.GFSRetentionPolicy) {
+	err := h.DeleteRetainPolicy(ctx, policy)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
+// DeleteRetainPolicy deletes the backups not retained by the GFS policy on the segments first and then on the coordinator
+func (h *DeleteHandler) DeleteRetainPolicy(ctx context.Context, policy internal.GFSRetentionPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	retained := h.FindRetainPolicyBackups(policy)
+	if len(retained) == 0 {
+		tracelog.InfoLogger.Printf("No backup found for deletion")
+		return nil
+	}
+	return h.deleteNotRetained(ctx, retained)
+}
+
+func (h *DeleteHandler) HandleDeleteAuto(ctx context.Context, retention internal.RetentionConfig) {
+	err := h.DeleteAuto(ctx, retention)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
+// DeleteAuto prints the retention plan and deletes the backups not retained by it
+// on the segments first and then on the coordinator
+func (h *DeleteHandler) DeleteAuto(ctx context.Context, retention internal.RetentionConfig) error {
+	retained, err := h.PlanAutoRetention(retention)
+	if err != nil || retained == nil {
+		return err
+	}
+	return h.deleteNotRetained(ctx, retained)
+}
+
+func (h *DeleteHandler) deleteNotRetained(ctx context.Context, retained map[string]string) error {
+	toDelete, oldestRetained := h.SplitRetainedBackups(retained)
+	if oldestRetained == nil {
+		return utility.NewForbiddenActionError("Retention policy would delete every backup. Check out delete everything")
+	}
+	deletedNames := make(map[string]bool, len(toDelete))
+	for _, backup := range toDelete {
+		deletedNames[backup.GetBackupName()] = true
+	}
+
+	tracelog.InfoLogger.Println("Deleting the segments backups...")
+	for _, backup := range toDelete {
+		// deleting a backup on the segments deletes all its deltas as well
+		if !backup.IsFullBackup() && deletedNames[backup.GetIncrementFromName()] {
+			continue
+		}
+		if err := h.dispatchDeleteCmd(ctx, backup, SegDeleteTarget); err != nil {
+			return fmt.Errorf("failed to delete the segments backups: %w", err)
+		}
+	}
+	if err := h.dispatchDeleteCmd(ctx, oldestRetained, SegDeleteBefore); err != nil {
+		return fmt.Errorf("failed to delete the segments backups: %w", err)
+	}
+	tracelog.InfoLogger.Printf("Finished deleting the segments backups")
+
+	folderFilter := func(name string) bool { return strings.HasPrefix(name, utility.BaseBackupPath) }
+	return h.DeleteNotRetained(ctx, retained, h.args.Confirmed, folderFilter)
+}
+
 func (h *DeleteHandler) HandleDeleteEverything(ctx context.Context, args []string) {
 	h.DeleteHandler.HandleDeleteEverything(ctx, args, h.permanentBackups, h.args.Confirmed)
 }
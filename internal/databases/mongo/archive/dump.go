@@ -0,0 +1,117 @@
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// The mongodump archive is the magic number followed by the blocks: the header BSON document, the body BSON documents
+// and the terminator. The first block holds the archive prelude and the collections metadata, the next ones hold
+// the documents of the namespace set in the block header. The archive may be gzipped as a whole.
+const (
+	dumpMagicNumber uint32 = 0x8199e26d
+	dumpTerminator  uint32 = 0xffffffff
+	maxBSONSize            = 48 << 20
+)
+
+type dumpNamespaceHeader struct {
+	Database   string `bson:"db"`
+	Collection string `bson:"collection"`
+	EOF        bool   `bson:"EOF"`
+}
+
+// ReadDumpDocuments reads the mongodump archive and calls the visitor for every document with its namespace
+func ReadDumpDocuments(reader io.Reader, visitor func(ns string, doc bson.Raw) error) error {
+	bufReader := bufio.NewReader(reader)
+	gzipMagic, err := bufReader.Peek(2)
+	if err == nil && gzipMagic[0] == 0x1f && gzipMagic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(bufReader)
+		if err != nil {
+			return fmt.Errorf("can not read gzipped mongodump archive: %w", err)
+		}
+		defer gzipReader.Close()
+		bufReader = bufio.NewReader(gzipReader)
+	}
+
+	var magic uint32
+	if err := binary.Read(bufReader, binary.LittleEndian, &magic); err != nil {
+		return fmt.Errorf("can not read mongodump archive magic number: %w", err)
+	}
+	if magic != dumpMagicNumber {
+		return fmt.Errorf("stream is not a mongodump archive: unexpected magic number %x", magic)
+	}
+
+	// the prelude block
+	if _, err := readDumpBlock(bufReader, nil); err != nil {
+		return fmt.Errorf("can not read mongodump archive prelude: %w", err)
+	}
+	for {
+		eof, err := readDumpBlock(bufReader, visitor)
+		if err != nil {
+			return err
+		}
+		if eof {
+			return nil
+		}
+	}
+}
+
+func readDumpBlock(reader io.Reader, visitor func(ns string, doc bson.Raw) error) (bool, error) {
+	headerDoc, isTerminator, err := readDumpDocument(reader)
+	if err == io.EOF {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if isTerminator {
+		return false, fmt.Errorf("unexpected terminator instead of the mongodump block header")
+	}
+
+	var header dumpNamespaceHeader
+	if visitor != nil {
+		if err := bson.Unmarshal(headerDoc, &header); err != nil {
+			return false, fmt.Errorf("can not unmarshal mongodump block header: %w", err)
+		}
+	}
+	ns := header.Database + "." + header.Collection
+	for {
+		doc, isTerminator, err := readDumpDocument(reader)
+		if err != nil {
+			return false, fmt.Errorf("can not read mongodump block of %s: %w", ns, err)
+		}
+		if isTerminator {
+			return false, nil
+		}
+		if visitor != nil && !header.EOF {
+			if err := visitor(ns, doc); err != nil {
+				return false, err
+			}
+		}
+	}
+}
+
+func readDumpDocument(reader io.Reader) (bson.Raw, bool, error) {
+	var sizeBytes [4]byte
+	if _, err := io.ReadFull(reader, sizeBytes[:]); err != nil {
+		return nil, false, err
+	}
+	size := binary.LittleEndian.Uint32(sizeBytes[:])
+	if size == dumpTerminator {
+		return nil, true, nil
+	}
+	if size < 5 || size > maxBSONSize {
+		return nil, false, fmt.Errorf("invalid BSON document size %d", size)
+	}
+	doc := make([]byte, size)
+	copy(doc, sizeBytes[:])
+	if _, err := io.ReadFull(reader, doc[4:]); err != nil {
+		return nil, false, err
+	}
+	return doc, false, nil
+}
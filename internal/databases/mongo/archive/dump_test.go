@@ -0,0 +1,76 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestReadDumpDocuments(t *testing.T) {
+	archive := buildTestDump(t)
+
+	var gzipped bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	_, err := gzipWriter.Write(archive)
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+
+	for name, content := range map[string][]byte{"plain": archive, "gzipped": gzipped.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			namespaces := make([]string, 0)
+			ids := make([]int32, 0)
+			err := ReadDumpDocuments(bytes.NewReader(content), func(ns string, doc bson.Raw) error {
+				namespaces = append(namespaces, ns)
+				ids = append(ids, doc.Lookup("_id").Int32())
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"db1.users", "db1.users", "db2.orders"}, namespaces)
+			assert.Equal(t, []int32{1, 2, 3}, ids)
+		})
+	}
+}
+
+func TestReadDumpDocuments_NotArchive(t *testing.T) {
+	err := ReadDumpDocuments(bytes.NewReader([]byte{1, 2, 3, 4, 5}), func(string, bson.Raw) error { return nil })
+	assert.Error(t, err)
+}
+
+func buildTestDump(t *testing.T) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, dumpMagicNumber))
+
+	writeDoc := func(doc interface{}) {
+		raw, err := bson.Marshal(doc)
+		require.NoError(t, err)
+		buf.Write(raw)
+	}
+	writeTerminator := func() {
+		require.NoError(t, binary.Write(&buf, binary.LittleEndian, dumpTerminator))
+	}
+
+	// prelude with the collection metadata
+	writeDoc(bson.D{{Key: "formatVersion", Value: "0.1"}})
+	writeDoc(bson.D{{Key: "db", Value: "db1"}, {Key: "collection", Value: "users"}, {Key: "metadata", Value: "{}"}})
+	writeTerminator()
+
+	writeDoc(bson.D{{Key: "db", Value: "db1"}, {Key: "collection", Value: "users"}})
+	writeDoc(bson.D{{Key: "_id", Value: int32(1)}})
+	writeDoc(bson.D{{Key: "_id", Value: int32(2)}})
+	writeTerminator()
+
+	writeDoc(bson.D{{Key: "db", Value: "db2"}, {Key: "collection", Value: "orders"}})
+	writeDoc(bson.D{{Key: "_id", Value: int32(3)}})
+	writeTerminator()
+
+	writeDoc(bson.D{{Key: "db", Value: "db1"}, {Key: "collection", Value: "users"}, {Key: "EOF", Value: true}})
+	writeTerminator()
+	writeDoc(bson.D{{Key: "db", Value: "db2"}, {Key: "collection", Value: "orders"}, {Key: "EOF", Value: true}})
+	writeTerminator()
+	return buf.Bytes()
+}
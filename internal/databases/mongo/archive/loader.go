@@ -66,6 +66,14 @@ func NewDefaultStorageSettings() StorageSettings {
 	}
 }
 
+// NewClusterMemberStorageSettings builds storage settings of the sharded cluster member subfolder
+func NewClusterMemberStorageSettings(member string) StorageSettings {
+	return StorageSettings{
+		oplogsPath:  common.ClusterMemberPath(member) + models.OplogArchBasePath,
+		backupsPath: common.ClusterMemberBackupsPath(member),
+	}
+}
+
 // StorageDownloader extends base folder with mongodb specific.
 type StorageDownloader struct {
 	rootFolder    storage.Folder
@@ -79,11 +87,14 @@ func NewStorageDownloader(ctx context.Context, opts StorageSettings) (*StorageDo
 	if err != nil {
 		return nil, err
 	}
-	folder := st.RootFolder()
-	return &StorageDownloader{rootFolder: folder,
-			oplogsFolder:  folder.GetSubFolder(opts.oplogsPath),
-			backupsFolder: folder.GetSubFolder(opts.backupsPath)},
-		nil
+	return NewFolderDownloader(st.RootFolder(), opts), nil
+}
+
+// NewFolderDownloader builds mongodb downloader of the root folder.
+func NewFolderDownloader(rootFolder storage.Folder, opts StorageSettings) *StorageDownloader {
+	return &StorageDownloader{rootFolder: rootFolder,
+		oplogsFolder:  rootFolder.GetSubFolder(opts.oplogsPath),
+		backupsFolder: rootFolder.GetSubFolder(opts.backupsPath)}
 }
 
 // BackupMeta downloads sentinel contents.
@@ -121,6 +132,19 @@ func (sd *StorageDownloader) LastBackupName(ctx context.Context) (string, error)
 	return backup.Name, nil
 }
 
+// DownloadBackupStream downloads, decompresses and decrypts (if needed) the logical backup stream.
+func (sd *StorageDownloader) DownloadBackupStream(ctx context.Context, name string, writeCloser io.WriteCloser) error {
+	backup, err := internal.NewBackup(sd.backupsFolder, name)
+	if err != nil {
+		return err
+	}
+	fetcher, err := internal.GetBackupStreamFetcher(ctx, backup)
+	if err != nil {
+		return err
+	}
+	return fetcher(ctx, backup, writeCloser)
+}
+
 // DownloadOplogArchive downloads, decompresses and decrypts (if needed) oplog archive.
 func (sd *StorageDownloader) DownloadOplogArchive(ctx context.Context, arch models.Archive, writeCloser io.WriteCloser) error {
 	return internal.DownloadFile(ctx, internal.NewFolderReader(sd.oplogsFolder), arch.Filename(), arch.Extension(), writeCloser)
@@ -334,6 +358,7 @@ func (su *StorageUploader) UploadBackup(ctx context.Context, stream io.Reader, c
 
 // StoragePurger deletes files in storage.
 type StoragePurger struct {
+	rootFolder    storage.Folder
 	oplogsFolder  storage.Folder
 	backupsFolder storage.Folder
 }
@@ -345,13 +370,28 @@ func NewStoragePurger(ctx context.Context, opts StorageSettings) (*StoragePurger
 		return nil, err
 	}
 
-	return &StoragePurger{oplogsFolder: st.RootFolder().GetSubFolder(opts.oplogsPath),
-		backupsFolder: st.RootFolder().GetSubFolder(opts.backupsPath)}, nil
+	return NewFolderPurger(st.RootFolder(), opts), nil
 }
 
-// DeleteBackups purges given backups files
+// NewFolderPurger builds mongodb StoragePurger of the root folder.
+func NewFolderPurger(rootFolder storage.Folder, opts StorageSettings) *StoragePurger {
+	return &StoragePurger{rootFolder: rootFolder,
+		oplogsFolder:  rootFolder.GetSubFolder(opts.oplogsPath),
+		backupsFolder: rootFolder.GetSubFolder(opts.backupsPath)}
+}
+
+// DeleteBackups purges given backups files, the member backups of the cluster backups are purged too
 // TODO: extract BackupLayout abstraction and provide DataPath(), SentinelPath(), Exists() methods
 func (sp *StoragePurger) DeleteBackups(ctx context.Context, backups []*models.Backup) error {
+	for _, backup := range backups {
+		for _, shard := range backup.Shards {
+			tracelog.InfoLogger.Printf("Deleting backup %s of cluster member %s", shard.BackupName, shard.ShardName)
+			memberFolder := sp.rootFolder.GetSubFolder(common.ClusterMemberBackupsPath(shard.ShardName))
+			if err := internal.DeleteBackups(ctx, memberFolder, []string{shard.BackupName}); err != nil {
+				return fmt.Errorf("can not delete backup of cluster member %s: %w", shard.ShardName, err)
+			}
+		}
+	}
 	backupNames := BackupNamesFromBackups(backups)
 	return internal.DeleteBackups(ctx, sp.backupsFolder, backupNames)
 }
@@ -3,6 +3,7 @@ package archive
 import (
 	"context"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/wal-g/wal-g/internal/compression"
 	"github.com/wal-g/wal-g/internal/compression/lz4"
 	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/test/mocks"
 	"go.uber.org/mock/gomock"
@@ -107,3 +109,31 @@ func mustArchive(t *testing.T, start, end models.Timestamp) models.Archive {
 	require.NoError(t, err)
 	return arch
 }
+
+func TestStoragePurgerDeleteBackupsDeletesClusterMemberBackups(t *testing.T) {
+	folder := memory.NewFolder("", memory.NewKVS())
+	for _, path := range []string{
+		"basebackups_005/cluster_20240101T000000Z_backup_stop_sentinel.json",
+		"shard01/basebackups_005/binary_20240101T000001Z_backup_stop_sentinel.json",
+		"shard01/basebackups_005/binary_20240101T000001Z/data.tar.lz4",
+		"shard01/basebackups_005/binary_20240102T000001Z_backup_stop_sentinel.json",
+		"config/basebackups_005/binary_20240101T000002Z_backup_stop_sentinel.json",
+	} {
+		require.NoError(t, folder.PutObject(t.Context(), path, strings.NewReader("{}")))
+	}
+	backup := &models.Backup{
+		BackupName: "cluster_20240101T000000Z",
+		Shards: []models.ShardBackup{
+			{ShardName: "shard01", BackupName: "binary_20240101T000001Z"},
+			{ShardName: "config", BackupName: "binary_20240101T000002Z"},
+		},
+	}
+
+	purger := NewFolderPurger(folder, NewDefaultStorageSettings())
+	require.NoError(t, purger.DeleteBackups(t.Context(), []*models.Backup{backup}))
+
+	objects, err := storage.ListFolderRecursively(t.Context(), folder)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "shard01/basebackups_005/binary_20240102T000001Z_backup_stop_sentinel.json", objects[0].GetName())
+}
@@ -58,5 +58,22 @@ func HandleBackupDelete(ctx context.Context, backupName string, downloader archi
 	}
 	tracelog.InfoLogger.Printf("Backup was deleted: %+v", backup)
 	purgeJournalInfo(ctx, backupName, dryRun)
+	if len(backup.Shards) > 0 {
+		return purgeClusterBackupOplog(ctx, downloader, backup)
+	}
 	return nil
 }
+
+// purgeClusterBackupOplog deletes the oplog archives of the members of the deleted cluster backup
+// which are not used by the other cluster backups
+func purgeClusterBackupOplog(ctx context.Context, downloader archive.Downloader, backup *models.Backup) error {
+	st, err := internal.ConfigureStorage(ctx)
+	if err != nil {
+		return err
+	}
+	retained, err := LoadBackups(ctx, downloader)
+	if err != nil {
+		return err
+	}
+	return HandleClusterOplogPurge(ctx, st.RootFolder(), retained, []*models.Backup{backup}, false)
+}
@@ -1 +1,28 @@
 package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/databases/mongo/common"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+// EnsureNotClusterBackup rejects the cluster backups: they reference the binary backups of the cluster members,
+// which are restored by binary-backup-fetch with the --cluster-member flag
+func EnsureNotClusterBackup(ctx context.Context, rootFolder storage.Folder, selector internal.BackupSelector) error {
+	backup, err := selector.Select(ctx, rootFolder)
+	if err != nil {
+		return err
+	}
+	sentinel, err := common.DownloadSentinel(ctx, backup.Folder, backup.Name)
+	if err != nil {
+		return err
+	}
+	if sentinel.BackupType == common.ClusterBackupType {
+		return fmt.Errorf("backup %s is a cluster backup, restore every member of it "+
+			"with binary-backup-fetch --cluster-member", backup.Name)
+	}
+	return nil
+}
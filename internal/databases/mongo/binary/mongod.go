@@ -14,6 +14,7 @@ import (
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/databases/mongo/archive"
 	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"github.com/wal-g/wal-g/internal/databases/mongo/stages"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -472,6 +473,15 @@ type ReplyOplogConfig struct {
 
 	Whitelist map[string]map[string]struct{}
 	Blacklist map[string]map[string]struct{}
+
+	// OplogFilter selects the replayed oplog records, all records are replayed if it is nil
+	OplogFilter *stages.OplogFilter
+
+	// ClusterMember is the sharded cluster member whose oplog archives of the cluster backup are replayed
+	ClusterMember string
+	// ClusterTS is the restore timestamp of the cluster backup, the records after it are not replayed
+	// except for the commits of the prepared transactions visible at it
+	ClusterTS *models.Timestamp
 }
 
 type ShConfig struct {
@@ -88,10 +88,20 @@ func RunOplogReplay(ctx context.Context, mongodbURL string, replayArgs ReplyOplo
 		Reconfig:       replayArgs.WithCatchUpReconfig,
 		IgnoreErrCodes: replayArgs.IgnoreErrCodes,
 	})
-	oplogApplier := stages.NewGenericApplier(dbApplier)
+	var oplogApplier stages.Applier = stages.NewGenericApplier(dbApplier)
+	if replayArgs.OplogFilter != nil {
+		oplogApplier = stages.NewFilteringApplier(replayArgs.OplogFilter, oplogApplier)
+	}
+	if replayArgs.ClusterTS != nil {
+		oplogApplier = stages.NewFilteringApplier(stages.NewClusterTimeFilter(*replayArgs.ClusterTS), oplogApplier)
+	}
 
 	// set up storage downloader client
-	downloader, err := archive.NewStorageDownloader(ctx, archive.NewDefaultStorageSettings())
+	storageSettings := archive.NewDefaultStorageSettings()
+	if replayArgs.ClusterMember != "" {
+		storageSettings = archive.NewClusterMemberStorageSettings(replayArgs.ClusterMember)
+	}
+	downloader, err := archive.NewStorageDownloader(ctx, storageSettings)
 	if err != nil {
 		return err
 	}
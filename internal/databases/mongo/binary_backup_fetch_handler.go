@@ -3,9 +3,12 @@ package mongo
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/databases/mongo/binary"
+	"github.com/wal-g/wal-g/internal/databases/mongo/common"
+	"github.com/wal-g/wal-g/internal/databases/mongo/models"
 	"github.com/wal-g/wal-g/utility"
 )
 
@@ -19,6 +22,7 @@ func HandleBinaryFetchPush(
 	skipBackupDownload, skipReconfig, skipChecks bool,
 	pitrSince, pitrUntil string,
 	whitelist, blacklist []string,
+	clusterMember string,
 ) error {
 	config, err := binary.CreateMongodConfig(mongodConfigPath)
 	if err != nil {
@@ -30,7 +34,44 @@ func HandleBinaryFetchPush(
 	if err != nil {
 		return err
 	}
-	uploader.ChangeDirectory(utility.BaseBackupPath + "/")
+
+	// check backup existence and resolve flag LATEST
+	backupsFolder := uploader.Folder().GetSubFolder(utility.BaseBackupPath)
+	backup, err := internal.GetBackupByName(ctx, backupName, "", backupsFolder)
+	if err != nil {
+		return err
+	}
+	sentinel, err := common.DownloadSentinel(ctx, backupsFolder, backup.Name)
+	if err != nil {
+		return err
+	}
+	partial := len(whitelist)+len(blacklist) > 0
+
+	var replyOplogConfig binary.ReplyOplogConfig
+	switch {
+	case sentinel.BackupType == common.ClusterBackupType:
+		if pitrSince != "" || pitrUntil != "" {
+			return fmt.Errorf("cluster backup %s is restored to its restore point, pitr is not supported", backup.Name)
+		}
+		var memberBackup models.ShardBackup
+		memberBackup, replyOplogConfig, err = resolveClusterMemberRestore(ctx, sentinel, clusterMember, partial)
+		if err != nil {
+			return err
+		}
+		backupName = memberBackup.BackupName
+		uploader.ChangeDirectory(common.ClusterMemberBackupsPath(clusterMember) + "/")
+	case clusterMember != "":
+		return fmt.Errorf("backup %s is not a cluster backup, it has no members", backup.Name)
+	default:
+		backupName = backup.Name
+		uploader.ChangeDirectory(utility.BaseBackupPath + "/")
+		if pitrSince != "" && pitrUntil != "" {
+			replyOplogConfig, err = binary.NewReplyOplogConfig(ctx, pitrSince, pitrUntil, partial, false, "")
+			if err != nil {
+				return err
+			}
+		}
+	}
 
 	if minimalConfigPath == "" {
 		minimalConfigPath, err = config.SaveConfigToTempFile("storage", "systemLog")
@@ -59,20 +100,6 @@ func HandleBinaryFetchPush(
 		return err
 	}
 
-	// check backup existence and resolve flag LATEST
-	backup, err := internal.GetBackupByName(ctx, backupName, "", uploader.Folder())
-	if err != nil {
-		return err
-	}
-
-	var replyOplogConfig binary.ReplyOplogConfig
-	if pitrSince != "" && pitrUntil != "" {
-		replyOplogConfig, err = binary.NewReplyOplogConfig(ctx, pitrSince, pitrUntil, len(whitelist)+len(blacklist) > 0, false, "")
-		if err != nil {
-			return err
-		}
-	}
-
 	return restoreService.DoRestore(
 		ctx,
 		rsConfig,
@@ -80,7 +107,7 @@ func HandleBinaryFetchPush(
 		mongocfgConfig,
 		replyOplogConfig,
 		binary.RestoreArgs{
-			BackupName:     backup.Name,
+			BackupName:     backupName,
 			RestoreVersion: restoreMongodVersion,
 
 			SkipChecks:         skipChecks,
@@ -92,3 +119,31 @@ func HandleBinaryFetchPush(
 		},
 	)
 }
+
+// resolveClusterMemberRestore finds the backup of the cluster member and builds the oplog replay config
+// bringing the restored member to the cluster restore point
+func resolveClusterMemberRestore(ctx context.Context, sentinel *models.Backup, clusterMember string, partial bool,
+) (models.ShardBackup, binary.ReplyOplogConfig, error) {
+	if clusterMember == "" {
+		return models.ShardBackup{}, binary.ReplyOplogConfig{},
+			fmt.Errorf("backup %s is a cluster backup, the member to restore has to be set", sentinel.BackupName)
+	}
+	idx := slices.IndexFunc(sentinel.Shards, func(shard models.ShardBackup) bool {
+		return shard.ShardName == clusterMember
+	})
+	if idx < 0 {
+		return models.ShardBackup{}, binary.ReplyOplogConfig{},
+			fmt.Errorf("cluster backup %s has no member %s", sentinel.BackupName, clusterMember)
+	}
+	memberBackup := sentinel.Shards[idx]
+
+	replyOplogConfig, err := binary.NewReplyOplogConfig(ctx,
+		memberBackup.OplogSince.String(), memberBackup.OplogUntil.String(), partial, false, "")
+	if err != nil {
+		return models.ShardBackup{}, binary.ReplyOplogConfig{}, err
+	}
+	restoreTS := models.TimestampFromBson(sentinel.MongoMeta.BackupLastTS)
+	replyOplogConfig.ClusterMember = clusterMember
+	replyOplogConfig.ClusterTS = &restoreTS
+	return memberBackup, replyOplogConfig, nil
+}
@@ -2,11 +2,13 @@ package mongo
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/databases/mongo/binary"
+	"github.com/wal-g/wal-g/internal/databases/mongo/common"
 	"github.com/wal-g/wal-g/utility"
 )
 
@@ -16,9 +18,15 @@ type HandleBinaryBackupPushArgs struct {
 	Permanent     bool
 	SkipMetadata  bool
 	UserDataRaw   string
+	// ClusterMember is the sharded cluster member the backup is made for by cluster-backup-push,
+	// the backup is stored in the member subfolder
+	ClusterMember string
 }
 
 func HandleBinaryBackupPush(ctx context.Context, args HandleBinaryBackupPushArgs) error {
+	if args.ClusterMember != "" && args.CountJournals {
+		return fmt.Errorf("oplog journals are not counted for the backups of the cluster members")
+	}
 	mongodbURI, err := conf.GetRequiredSetting(conf.MongoDBUriSetting)
 	if err != nil {
 		return err
@@ -32,7 +40,11 @@ func HandleBinaryBackupPush(ctx context.Context, args HandleBinaryBackupPushArgs
 	if err != nil {
 		return err
 	}
-	uploader.ChangeDirectory(utility.BaseBackupPath + "/")
+	if args.ClusterMember != "" {
+		uploader.ChangeDirectory(common.ClusterMemberBackupsPath(args.ClusterMember) + "/")
+	} else {
+		uploader.ChangeDirectory(utility.BaseBackupPath + "/")
+	}
 
 	backupService, err := binary.CreateBackupService(mongodService, uploader)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/mongodb/mongo-tools/common/db"
@@ -429,6 +430,66 @@ func (mc *MongoClient) ApplyOp(ctx context.Context, dbop *db.Oplog) error {
 	return nil
 }
 
+// ShardMap returns the connection strings (rsName/host1,host2) of the shards and of the config server replica set
+// named "config" of the sharded cluster
+func (mc *MongoClient) ShardMap(ctx context.Context) (map[string]string, error) {
+	var resp struct {
+		Map map[string]string `bson:"map"`
+	}
+	if err := mc.c.Database("admin").RunCommand(ctx, bson.D{{Key: "getShardMap", Value: 1}}).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("getShardMap command failed: %w", err)
+	}
+
+	shardMap := make(map[string]string, len(resp.Map))
+	for name, connStr := range resp.Map {
+		// old versions also map every host:port to the connection string of its replica set
+		if strings.Contains(name, ":") {
+			continue
+		}
+		shardMap[name] = connStr
+	}
+	return shardMap, nil
+}
+
+// BalancerEnabled checks if the balancer of the sharded cluster is enabled, the client must be connected to mongos
+func (mc *MongoClient) BalancerEnabled(ctx context.Context) (bool, error) {
+	var resp struct {
+		Mode string `bson:"mode"`
+	}
+	if err := mc.c.Database("admin").RunCommand(ctx, bson.D{{Key: "balancerStatus", Value: 1}}).Decode(&resp); err != nil {
+		return false, fmt.Errorf("balancerStatus command failed: %w", err)
+	}
+	return resp.Mode != "off", nil
+}
+
+// StopBalancer disables the balancer and waits for the current balancing round to finish
+func (mc *MongoClient) StopBalancer(ctx context.Context) error {
+	if err := mc.c.Database("admin").RunCommand(ctx, bson.D{{Key: "balancerStop", Value: 1}}).Err(); err != nil {
+		return fmt.Errorf("balancerStop command failed: %w", err)
+	}
+	return nil
+}
+
+// StartBalancer enables the balancer
+func (mc *MongoClient) StartBalancer(ctx context.Context) error {
+	if err := mc.c.Database("admin").RunCommand(ctx, bson.D{{Key: "balancerStart", Value: 1}}).Err(); err != nil {
+		return fmt.Errorf("balancerStart command failed: %w", err)
+	}
+	return nil
+}
+
+// AppendOplogNote writes the noop entry with the message to the oplog, it advances the last write optime of idle nodes
+func (mc *MongoClient) AppendOplogNote(ctx context.Context, msg string) error {
+	cmd := bson.D{
+		{Key: "appendOplogNote", Value: 1},
+		{Key: "data", Value: bson.D{{Key: "msg", Value: msg}}},
+	}
+	if err := mc.c.Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("appendOplogNote command failed: %w", err)
+	}
+	return nil
+}
+
 // BsonCursor implements OplogCursor with source io.reader
 type BsonCursor struct {
 	r      io.Reader
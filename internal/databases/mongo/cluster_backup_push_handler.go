@@ -0,0 +1,398 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/databases/mongo/archive"
+	"github.com/wal-g/wal-g/internal/databases/mongo/client"
+	"github.com/wal-g/wal-g/internal/databases/mongo/common"
+	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"github.com/wal-g/wal-g/internal/databases/mongo/stages"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	clusterConfigServerName = "config"
+	clusterTimeCheckPeriod  = time.Second
+
+	// the environment of MONGODB_CLUSTER_MEMBER_BACKUP_COMMAND
+	clusterMemberEnv     = "WALG_MONGO_CLUSTER_MEMBER"
+	clusterMemberHostEnv = "WALG_MONGO_CLUSTER_MEMBER_HOST"
+)
+
+type HandleClusterBackupPushArgs struct {
+	// MongodbURI is the connection string of mongos
+	MongodbURI       string
+	Permanent        bool
+	ArchiveAfterSize int
+}
+
+// clusterMember is the shard or the config server replica set of the sharded cluster
+type clusterMember struct {
+	Name             string
+	ConnectionString string
+	primaryHost      string
+	client           *client.MongoClient
+	backup           *models.Backup
+	oplogUntil       models.Timestamp
+}
+
+func GenerateClusterBackupName() string {
+	return common.ClusterBackupType + "_" + utility.TimeNowCrossPlatformUTC().Format(utility.BackupTimeFormat)
+}
+
+// HandleClusterBackupPush makes the binary backups of all shards and the config server of the sharded cluster
+// which are restored to the same cluster time. The balancer is stopped during the backup. The restore point
+// is taken after the end of all member backups, the oplog of every member from the end of its backup up to
+// the restore point is uploaded next to the backup. The cluster sentinel references the member backups.
+func HandleClusterBackupPush(ctx context.Context, args HandleClusterBackupPushArgs) error {
+	baseURI, err := url.Parse(args.MongodbURI)
+	if err != nil {
+		return fmt.Errorf("can not parse mongodb uri: %w", err)
+	}
+	mongosClient, err := client.NewMongoClient(ctx, args.MongodbURI)
+	if err != nil {
+		return err
+	}
+	defer func() { tracelog.ErrorLogger.PrintOnError(mongosClient.Close(ctx, false)) }()
+
+	members, err := discoverClusterMembers(ctx, mongosClient, baseURI)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, member := range members {
+			tracelog.ErrorLogger.PrintOnError(member.client.Close(ctx, false))
+		}
+	}()
+
+	balancerEnabled, err := mongosClient.BalancerEnabled(ctx)
+	if err != nil {
+		return err
+	}
+	if balancerEnabled {
+		tracelog.InfoLogger.Println("Stopping the balancer")
+		if err := mongosClient.StopBalancer(ctx); err != nil {
+			return err
+		}
+		defer func() {
+			tracelog.InfoLogger.Println("Starting the balancer")
+			tracelog.ErrorLogger.PrintOnError(mongosClient.StartBalancer(context.Background()))
+		}()
+	}
+
+	userData, err := internal.GetSentinelUserData()
+	if err != nil {
+		return err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	sentinel := models.Backup{
+		BackupName:     GenerateClusterBackupName(),
+		BackupType:     common.ClusterBackupType,
+		Hostname:       hostname,
+		StartLocalTime: utility.TimeNowCrossPlatformLocal(),
+		Permanent:      args.Permanent,
+		UserData:       userData,
+	}
+
+	st, err := internal.ConfigureStorage(ctx)
+	if err != nil {
+		return err
+	}
+	if err := pushMemberBackups(ctx, st.RootFolder(), members, baseURI); err != nil {
+		return err
+	}
+
+	restoreTS, err := awaitClusterRestoreTS(ctx, members)
+	if err != nil {
+		return err
+	}
+	tracelog.InfoLogger.Printf("Cluster restore point is %s", restoreTS)
+
+	oplogUploader, err := internal.ConfigureUploader(ctx)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		memberUploader := oplogUploader.Clone()
+		memberUploader.ChangeDirectory(common.ClusterMemberPath(member.Name) + models.OplogArchBasePath)
+		member.oplogUntil, err = uploadOplogTail(ctx, member.client, archive.NewStorageUploader(memberUploader),
+			models.TimestampFromBson(member.backup.MongoMeta.BackupLastTS), restoreTS, args.ArchiveAfterSize)
+		if err != nil {
+			return fmt.Errorf("can not upload oplog of %s: %w", member.Name, err)
+		}
+	}
+
+	sentinel.MongoMeta.Before = members[0].backup.MongoMeta.Before
+	for _, member := range members {
+		if models.LessTS(member.backup.MongoMeta.Before.LastMajTS, sentinel.MongoMeta.Before.LastMajTS) {
+			sentinel.MongoMeta.Before = member.backup.MongoMeta.Before
+		}
+		sentinel.UncompressedSize += member.backup.UncompressedSize
+		sentinel.CompressedSize += member.backup.CompressedSize
+		sentinel.Shards = append(sentinel.Shards, models.ShardBackup{
+			ShardName:        member.Name,
+			ConnectionString: member.ConnectionString,
+			BackupName:       member.backup.BackupName,
+			OplogSince:       models.TimestampFromBson(member.backup.MongoMeta.BackupLastTS),
+			OplogUntil:       member.oplogUntil,
+		})
+	}
+	sentinel.MongoMeta.After = models.NodeMeta{LastTS: restoreTS, LastMajTS: restoreTS}
+	sentinel.MongoMeta.BackupLastTS = models.BsonTimestampFromOplogTS(restoreTS)
+	sentinel.FinishLocalTime = utility.TimeNowCrossPlatformLocal()
+
+	sentinelUploader, err := internal.ConfigureUploader(ctx)
+	if err != nil {
+		return err
+	}
+	sentinelUploader.ChangeDirectory(utility.BaseBackupPath)
+	tracelog.InfoLogger.Printf("Uploading the cluster backup sentinel %s", sentinel.BackupName)
+	return internal.UploadSentinel(ctx, sentinelUploader, &sentinel, sentinel.BackupName)
+}
+
+// discoverClusterMembers finds the shards and the config server through mongos and connects to their primaries
+func discoverClusterMembers(ctx context.Context, mongosClient *client.MongoClient, baseURI *url.URL) ([]*clusterMember, error) {
+	shardMap, err := mongosClient.ShardMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := shardMap[clusterConfigServerName]; !ok {
+		return nil, fmt.Errorf("config server is not found in the shard map, mongodb uri must point to mongos")
+	}
+
+	members := make([]*clusterMember, 0, len(shardMap))
+	for name, connStr := range shardMap {
+		members = append(members, &clusterMember{Name: name, ConnectionString: connStr})
+	}
+	slices.SortFunc(members, func(a, b *clusterMember) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	for _, member := range members {
+		tracelog.InfoLogger.Printf("Found cluster member %s: %s", member.Name, member.ConnectionString)
+		member.client, member.primaryHost, err = connectToPrimary(ctx, baseURI, member.ConnectionString)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return members, nil
+}
+
+func connectToPrimary(ctx context.Context, baseURI *url.URL, connStr string) (*client.MongoClient, string, error) {
+	_, hosts, _ := strings.Cut(connStr, "/")
+	for _, host := range strings.Split(hosts, ",") {
+		memberClient, err := client.NewMongoClient(ctx, buildMemberURI(baseURI, host, ""))
+		if err == nil {
+			err = memberClient.EnsureIsMaster(ctx)
+			if err == nil {
+				return memberClient, host, nil
+			}
+		}
+		tracelog.DebugLogger.Printf("Host %s is skipped: %v", host, err)
+		if memberClient != nil {
+			tracelog.ErrorLogger.PrintOnError(memberClient.Close(ctx, false))
+		}
+	}
+	return nil, "", fmt.Errorf("can not find the primary of %s", connStr)
+}
+
+// buildMemberURI replaces the hosts of the mongos uri keeping the credentials and the options
+func buildMemberURI(baseURI *url.URL, hosts, replicaSet string) string {
+	uri := *baseURI
+	query := uri.Query()
+	if uri.Scheme == "mongodb+srv" {
+		uri.Scheme = "mongodb"
+		if !query.Has("tls") && !query.Has("ssl") {
+			query.Set("tls", "true")
+		}
+	}
+	uri.Host = hosts
+	if uri.Path == "" {
+		uri.Path = "/"
+	}
+	query.Del("replicaSet")
+	if replicaSet != "" {
+		query.Set("replicaSet", replicaSet)
+	}
+	uri.RawQuery = query.Encode()
+	return uri.String()
+}
+
+// pushMemberBackups runs MONGODB_CLUSTER_MEMBER_BACKUP_COMMAND for every member concurrently. The command runs
+// binary-backup-push with the --cluster-member flag on the member host, so the binary backup is stored in
+// the member subfolder, and gets MONGODB_URI pointing to the member replica set, the member name and its primary.
+// Exactly one new backup has to appear in the member subfolder after the command.
+func pushMemberBackups(ctx context.Context, rootFolder storage.Folder, members []*clusterMember, baseURI *url.URL) error {
+	errgrp, ctx := errgroup.WithContext(ctx)
+	for _, member := range members {
+		backupCmd, err := internal.GetCommandSettingContext(ctx, conf.MongoDBClusterMemberBackupCmd)
+		if err != nil {
+			return err
+		}
+		replicaSet, hosts, _ := strings.Cut(member.ConnectionString, "/")
+		backupCmd.Env = append(os.Environ(),
+			conf.MongoDBUriSetting+"="+buildMemberURI(baseURI, hosts, replicaSet),
+			clusterMemberEnv+"="+member.Name,
+			clusterMemberHostEnv+"="+member.primaryHost)
+		backupCmd.Stdout = os.Stdout
+		backupCmd.Stderr = os.Stderr
+		memberFolder := rootFolder.GetSubFolder(common.ClusterMemberBackupsPath(member.Name))
+
+		errgrp.Go(func() error {
+			tracelog.InfoLogger.Printf("Starting backup of %s", member.Name)
+			backupsBefore, err := listBackupNames(ctx, memberFolder)
+			if err != nil {
+				return err
+			}
+			if err := backupCmd.Run(); err != nil {
+				return fmt.Errorf("backup command of %s failed: %w", member.Name, err)
+			}
+			backupsAfter, err := listBackupNames(ctx, memberFolder)
+			if err != nil {
+				return err
+			}
+			newBackups := slices.DeleteFunc(backupsAfter, func(name string) bool {
+				return slices.Contains(backupsBefore, name)
+			})
+			if len(newBackups) != 1 {
+				return fmt.Errorf("backup command of %s has to push exactly one backup to %s, found %v",
+					member.Name, common.ClusterMemberBackupsPath(member.Name), newBackups)
+			}
+			member.backup, err = common.DownloadSentinel(ctx, memberFolder, newBackups[0])
+			if err != nil {
+				return err
+			}
+			if member.backup.BackupType != common.BinaryBackupType {
+				return fmt.Errorf("backup %s of %s is not a binary backup", member.backup.BackupName, member.Name)
+			}
+			tracelog.InfoLogger.Printf("Backup of %s is finished: %s", member.Name, member.backup.BackupName)
+			return nil
+		})
+	}
+	return errgrp.Wait()
+}
+
+func listBackupNames(ctx context.Context, folder storage.Folder) ([]string, error) {
+	sentinels, err := internal.GetBackupSentinelObjects(ctx, folder)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(sentinels))
+	for _, backupTime := range internal.GetBackupTimeSlices(sentinels) {
+		names = append(names, backupTime.BackupName)
+	}
+	return names, nil
+}
+
+// awaitClusterRestoreTS returns the timestamp after the end of every member backup
+// and waits for every member to majority commit an oplog record after it.
+// The noop entries are written to the oplog, so the idle members pass the timestamp too.
+func awaitClusterRestoreTS(ctx context.Context, members []*clusterMember) (models.Timestamp, error) {
+	var restoreTS models.Timestamp
+	for _, member := range members {
+		backupLastTS := models.TimestampFromBson(member.backup.MongoMeta.BackupLastTS)
+		if models.LessTS(restoreTS, backupLastTS) {
+			restoreTS = backupLastTS
+		}
+	}
+
+	for _, member := range members {
+		for {
+			err := member.client.AppendOplogNote(ctx, "wal-g cluster backup restore point "+restoreTS.String())
+			if err != nil {
+				return models.Timestamp{}, err
+			}
+			_, lastMajTS, err := member.client.LastWriteTS(ctx)
+			if err != nil {
+				return models.Timestamp{}, err
+			}
+			if models.LessTS(restoreTS, lastMajTS) {
+				break
+			}
+			tracelog.DebugLogger.Printf("Waiting for %s to pass %s, last majority timestamp is %s",
+				member.Name, restoreTS, lastMajTS)
+			select {
+			case <-time.After(clusterTimeCheckPeriod):
+			case <-ctx.Done():
+				return models.Timestamp{}, ctx.Err()
+			}
+		}
+	}
+	return restoreTS, nil
+}
+
+// uploadOplogTail uploads the oplog records from the since timestamp up to the first record after the restore
+// timestamp when all transactions prepared at the restore timestamp are finished. The commits of the prepared
+// transactions are written after their commit timestamps, so the replay needs the records after the restore
+// timestamp to commit the cross-shard transactions. The returned timestamp of the last uploaded record is
+// the exclusive end of the replay. The archives are split by size like the ones made by oplog-push.
+func uploadOplogTail(ctx context.Context,
+	mongoClient client.MongoDriver,
+	uploader archive.Uploader,
+	since, restoreTS models.Timestamp,
+	archiveAfterSize int) (models.Timestamp, error) {
+	cursor, err := mongoClient.TailOplogFrom(ctx, since)
+	if err != nil {
+		return models.Timestamp{}, err
+	}
+	defer func() { tracelog.ErrorLogger.PrintOnError(cursor.Close(ctx)) }()
+
+	var buf bytes.Buffer
+	var batchStartTS, lastTS models.Timestamp
+	preparedTxns := make(map[string]bool)
+	for cursor.Next(ctx) {
+		op, err := models.OplogFromRaw(cursor.Data())
+		if err != nil {
+			return models.Timestamp{}, err
+		}
+		if lastTS == (models.Timestamp{}) {
+			if op.TS != since {
+				return models.Timestamp{}, fmt.Errorf("oplog entry with timestamp %s is not found, the first one is %s",
+					since, op.TS)
+			}
+			batchStartTS = op.TS
+		}
+		lastTS = op.TS
+		afterRestoreTS := models.LessTS(restoreTS, lastTS)
+		reached := afterRestoreTS && len(preparedTxns) == 0
+		if key, prepared, finished := stages.PreparedTxnChange(op.Data); prepared && !afterRestoreTS {
+			preparedTxns[key] = true
+		} else if finished {
+			delete(preparedTxns, key)
+		}
+		buf.Write(op.Data)
+		models.PutOplogEntry(op)
+
+		if buf.Len() < archiveAfterSize && !reached {
+			continue
+		}
+		if err := uploader.UploadOplogArchive(ctx, &buf, batchStartTS, lastTS); err != nil {
+			return models.Timestamp{}, err
+		}
+		if reached {
+			return lastTS, nil
+		}
+		buf.Reset()
+		batchStartTS = lastTS
+	}
+	if err := cursor.Err(); err != nil {
+		return models.Timestamp{}, err
+	}
+	return models.Timestamp{}, fmt.Errorf("oplog cursor is closed before timestamp %s", restoreTS)
+}
@@ -11,6 +11,7 @@ import (
 
 const LogicalBackupType = "logical"
 const BinaryBackupType = "binary"
+const ClusterBackupType = "cluster"
 
 func DownloadMetadata(ctx context.Context, folder storage.Folder, backupName string) (*models.BackupRoutesInfo, error) {
 	var metadata models.BackupRoutesInfo
@@ -50,3 +51,14 @@ func GetBackupFolder(ctx context.Context) (backupFolder storage.Folder, err erro
 	}
 	return st.RootFolder().GetSubFolder(utility.BaseBackupPath), err
 }
+
+// ClusterMemberPath is the storage subfolder of the sharded cluster member (the shard or the config server)
+// holding its backups and oplog archives of the cluster backups
+func ClusterMemberPath(member string) string {
+	return member + "/"
+}
+
+// ClusterMemberBackupsPath is the backups folder of the sharded cluster member
+func ClusterMemberBackupsPath(member string) string {
+	return ClusterMemberPath(member) + utility.BaseBackupPath
+}
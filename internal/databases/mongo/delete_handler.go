@@ -2,20 +2,27 @@ package mongo
 
 import (
 	"context"
+	"os"
 	"time"
 
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/databases/mongo/archive"
 	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
 )
 
 type PurgeSettings struct {
 	retainCount  *int
 	retainAfter  *time.Time
+	retainPolicy *internal.GFSRetentionPolicy
+	// retention replaces the other retain settings, the oplog is kept from the oldest backup retained by it
+	retention    *internal.RetentionConfig
 	purgeOplog   bool
 	purgeGarbage bool
 	dryRun       bool
+	// clusterRootFolder holds the subfolders of the cluster members, their oplog is purged if it is set
+	clusterRootFolder storage.Folder
 }
 
 type PurgeOption func(*PurgeSettings)
@@ -41,6 +48,20 @@ func PurgeOplog(purgeOplog bool) PurgeOption {
 	}
 }
 
+// PurgeRetainPolicy ...
+func PurgeRetainPolicy(retainPolicy internal.GFSRetentionPolicy) PurgeOption {
+	return func(args *PurgeSettings) {
+		args.retainPolicy = &retainPolicy
+	}
+}
+
+// PurgeRetention ...
+func PurgeRetention(retention internal.RetentionConfig) PurgeOption {
+	return func(args *PurgeSettings) {
+		args.retention = &retention
+	}
+}
+
 // PurgeGarbage ...
 func PurgeGarbage(purgeGarbage bool) PurgeOption {
 	return func(args *PurgeSettings) {
@@ -55,6 +76,13 @@ func PurgeDryRun(dryRun bool) PurgeOption {
 	}
 }
 
+// PurgeClusterOplog ...
+func PurgeClusterOplog(rootFolder storage.Folder) PurgeOption {
+	return func(args *PurgeSettings) {
+		args.clusterRootFolder = rootFolder
+	}
+}
+
 // HandlePurge delete backups and oplog archives according to settings
 func HandlePurge(ctx context.Context, downloader archive.Downloader, purger archive.Purger, setters ...PurgeOption) error {
 	opts := PurgeSettings{purgeOplog: false, dryRun: true}
@@ -67,14 +95,26 @@ func HandlePurge(ctx context.Context, downloader archive.Downloader, purger arch
 		return err
 	}
 
-	_, _, err = HandleBackupsPurge(ctx, backupTimes, downloader, purger, opts)
+	purge, retain, err := HandleBackupsPurge(ctx, backupTimes, downloader, purger, opts)
 	if err != nil {
 		return err
 	}
 
+	if opts.clusterRootFolder != nil {
+		if err := HandleClusterOplogPurge(ctx, opts.clusterRootFolder, retain, purge, opts.dryRun); err != nil {
+			return err
+		}
+	}
+
 	if opts.purgeOplog {
+		retainAfter := opts.retainAfter
+		if opts.retention != nil {
+			retainAfter = oldestRetainedFinishTime(retain)
+		}
 		// TODO: fix error if retainBackups is empty
-		if err := HandleOplogPurge(ctx, downloader, purger, opts.retainAfter, opts.dryRun); err != nil {
+		if retainAfter == nil {
+			tracelog.WarningLogger.Println("No backups are retained, oplog archives are not purged")
+		} else if err := HandleOplogPurge(ctx, downloader, purger, retainAfter, opts.dryRun); err != nil {
 			return err
 		}
 	}
@@ -109,8 +149,13 @@ func HandleBackupsPurge(ctx context.Context, backupTimes []internal.BackupTime,
 	timedBackups := archive.MongoModelToTimedBackup(backups)
 
 	internal.SortTimedBackup(timedBackups)
-	purgeBackups, retainBackups, err := internal.SplitPurgingBackups(timedBackups, opts.retainCount, opts.retainAfter)
-
+	var purgeBackups, retainBackups map[string]bool
+	if opts.retention != nil {
+		purgeBackups, retainBackups, err = internal.SplitAutoRetainedBackups(os.Stdout, timedBackups, *opts.retention, time.Now())
+	} else {
+		purgeBackups, retainBackups, err = internal.SplitPurgingBackupsWithPolicy(timedBackups, opts.retainCount, opts.retainAfter,
+			opts.retainPolicy)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -127,3 +172,18 @@ func HandleBackupsPurge(ctx context.Context, backupTimes []internal.BackupTime,
 	}
 	return purge, retain, nil
 }
+
+// oldestRetainedFinishTime returns the finish time of the oldest retained backup which is not permanent,
+// the oplog from it on is needed for PITR
+func oldestRetainedFinishTime(retained []*models.Backup) *time.Time {
+	var oldest *time.Time
+	for _, backup := range retained {
+		if backup.IsPermanent() {
+			continue
+		}
+		if oldest == nil || backup.FinishLocalTime.Before(*oldest) {
+			oldest = &backup.FinishLocalTime
+		}
+	}
+	return oldest
+}
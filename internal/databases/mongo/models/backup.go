@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/wal-g/wal-g/internal"
@@ -26,6 +27,21 @@ type Backup struct {
 	CompressedSize   int64       `json:"DataSize,omitempty"`
 	Top100Namespaces []string    `json:"Top100Namespaces,omitempty"`
 	NamespacesCount  int64       `json:"NamespacesCount,omitempty"`
+	// Shards are the backups of the sharded cluster members referenced by the cluster backup
+	Shards []ShardBackup `json:"Shards,omitempty"`
+}
+
+// ShardBackup references the backup of the sharded cluster member: the shard or the config server replica set.
+// The backup and the oplog archives of the member are stored in the storage subfolder named after it.
+type ShardBackup struct {
+	ShardName        string `json:"ShardName"`
+	ConnectionString string `json:"ConnectionString"`
+	BackupName       string `json:"BackupName"`
+	// OplogSince is the timestamp to replay the oplog of the member from to reach the cluster restore point
+	OplogSince Timestamp `json:"OplogSince"`
+	// OplogUntil is the timestamp of the first uploaded oplog record of the member which is not replayed,
+	// the records up to it finish the prepared transactions committed at the cluster restore point
+	OplogUntil Timestamp `json:"OplogUntil"`
 }
 
 func (b *Backup) Name() string {
@@ -36,6 +52,10 @@ func (b *Backup) StartTime() time.Time {
 	return b.StartLocalTime
 }
 
+func (b *Backup) FinishTime() time.Time {
+	return b.FinishLocalTime
+}
+
 func (b *Backup) IsPermanent() bool {
 	return b.Permanent
 }
@@ -102,7 +122,21 @@ func (b *Backup) PrintableFields() []printlist.TableField {
 			PrettyName: "User data",
 			Value:      marshalUserData(b.UserData),
 		},
+		{
+			Name:       "shards",
+			PrettyName: "Shards",
+			Value:      formatShards(b.Shards),
+		},
+	}
+}
+
+// formatShards lists the member backups of the cluster backup as member:backup
+func formatShards(shards []ShardBackup) string {
+	members := make([]string, 0, len(shards))
+	for _, shard := range shards {
+		members = append(members, shard.ShardName+":"+shard.BackupName)
 	}
+	return strings.Join(members, ",")
 }
 
 func marshalUserData(userData interface{}) string {
@@ -335,6 +335,10 @@ func TestBackup_PrintableFields(t *testing.T) {
 		CompressedSize:   100000,
 		Permanent:        true,
 		UserData:         []string{"a", "b", "c"},
+		Shards: []ShardBackup{
+			{ShardName: "config", BackupName: "binary_20230823T171831Z"},
+			{ShardName: "shard01", BackupName: "binary_20230823T171832Z"},
+		},
 	}
 	got := b.PrintableFields()
 	want := []printlist.TableField{
@@ -408,6 +412,11 @@ func TestBackup_PrintableFields(t *testing.T) {
 			Value:       "[\"a\",\"b\",\"c\"]",
 			PrettyValue: nil,
 		},
+		{
+			Name:       "shards",
+			PrettyName: "Shards",
+			Value:      "config:binary_20230823T171831Z,shard01:binary_20230823T171832Z",
+		},
 	}
 	assert.Equal(t, want, got)
 }
@@ -0,0 +1,229 @@
+package oplog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mongodb/mongo-tools/common/db"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ApplyToDocument returns the state of the document after the CRUD oplog operation, nil means the document
+// does not exist. The other operations leave the document unchanged.
+func ApplyToDocument(doc bson.D, op *db.Oplog) (bson.D, error) {
+	switch op.Operation {
+	case "i":
+		return op.Object, nil
+	case "d":
+		return nil, nil
+	case "u":
+		if doc == nil {
+			// the document is inserted later or was not in the snapshot, the insert op brings its full state
+			return nil, nil
+		}
+		return applyUpdate(doc, op.Object)
+	}
+	return doc, nil
+}
+
+func applyUpdate(doc bson.D, update bson.D) (bson.D, error) {
+	if version, ok := lookup(update, "$v"); ok && fmt.Sprint(version) == "2" {
+		diff, ok := lookupDocument(update, "diff")
+		if !ok {
+			return nil, fmt.Errorf("unexpected $v:2 update format: %v", update)
+		}
+		return applyDocumentDiff(doc, diff)
+	}
+	if len(update) == 0 || !strings.HasPrefix(update[0].Key, "$") {
+		// the replacement update
+		return update, nil
+	}
+
+	for _, modifier := range update {
+		fields, ok := modifier.Value.(bson.D)
+		if !ok {
+			continue
+		}
+		for _, field := range fields {
+			var err error
+			switch modifier.Key {
+			case "$set":
+				doc, err = setPath(doc, strings.Split(field.Key, "."), field.Value)
+			case "$unset":
+				doc = unsetPath(doc, strings.Split(field.Key, "."))
+			case "$v":
+			default:
+				err = fmt.Errorf("unsupported update modifier %s", modifier.Key)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return doc, nil
+}
+
+// applyDocumentDiff applies the document diff of the $v:2 update: the deleted, updated and inserted fields
+// and the diffs of the nested documents and arrays prefixed with 's'
+func applyDocumentDiff(doc bson.D, diff bson.D) (bson.D, error) {
+	result := append(bson.D{}, doc...)
+	for _, section := range diff {
+		switch {
+		case section.Key == "d":
+			fields, _ := section.Value.(bson.D)
+			for _, field := range fields {
+				result = unsetPath(result, []string{field.Key})
+			}
+		case section.Key == "u" || section.Key == "i":
+			fields, _ := section.Value.(bson.D)
+			for _, field := range fields {
+				result = setField(result, field.Key, field.Value)
+			}
+		case strings.HasPrefix(section.Key, "s"):
+			subDiff, ok := section.Value.(bson.D)
+			if !ok {
+				return nil, fmt.Errorf("unexpected sub diff format of field %s", section.Key[1:])
+			}
+			fieldName := section.Key[1:]
+			value, _ := lookup(result, fieldName)
+			newValue, err := applySubDiff(value, subDiff)
+			if err != nil {
+				return nil, err
+			}
+			result = setField(result, fieldName, newValue)
+		}
+	}
+	return result, nil
+}
+
+func applySubDiff(value interface{}, diff bson.D) (interface{}, error) {
+	if isArray, _ := lookup(diff, "a"); isArray != true {
+		doc, _ := value.(bson.D)
+		return applyDocumentDiff(doc, diff)
+	}
+
+	array, _ := value.(bson.A)
+	array = append(bson.A{}, array...)
+	for _, section := range diff {
+		switch {
+		case section.Key == "a":
+		case section.Key == "l":
+			length, err := toInt(section.Value)
+			if err != nil {
+				return nil, err
+			}
+			array = resizeArray(array, length)
+		case strings.HasPrefix(section.Key, "u") || strings.HasPrefix(section.Key, "s"):
+			index, err := strconv.Atoi(section.Key[1:])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected array diff key %s", section.Key)
+			}
+			array = resizeArray(array, max(len(array), index+1))
+			if section.Key[0] == 'u' {
+				array[index] = section.Value
+				continue
+			}
+			subDiff, _ := section.Value.(bson.D)
+			array[index], err = applySubDiff(array[index], subDiff)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return array, nil
+}
+
+func resizeArray(array bson.A, length int) bson.A {
+	if length <= len(array) {
+		return array[:length]
+	}
+	return append(array, make(bson.A, length-len(array))...)
+}
+
+func toInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int32:
+		return int(v), nil
+	case int64:
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	}
+	return 0, fmt.Errorf("unexpected numeric value %v", value)
+}
+
+func setPath(doc bson.D, path []string, value interface{}) (bson.D, error) {
+	if len(path) == 1 {
+		return setField(doc, path[0], value), nil
+	}
+	nested, _ := lookup(doc, path[0])
+	newNested, err := setNestedPath(nested, path[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	return setField(doc, path[0], newNested), nil
+}
+
+func setNestedPath(nested interface{}, path []string, value interface{}) (interface{}, error) {
+	array, isArray := nested.(bson.A)
+	if !isArray {
+		doc, _ := nested.(bson.D)
+		return setPath(append(bson.D{}, doc...), path, value)
+	}
+	index, err := strconv.Atoi(path[0])
+	if err != nil {
+		return nil, fmt.Errorf("can not set field %s of the array", path[0])
+	}
+	array = resizeArray(append(bson.A{}, array...), max(len(array), index+1))
+	if len(path) == 1 {
+		array[index] = value
+		return array, nil
+	}
+	array[index], err = setNestedPath(array[index], path[1:], value)
+	return array, err
+}
+
+func unsetPath(doc bson.D, path []string) bson.D {
+	result := make(bson.D, 0, len(doc))
+	for _, field := range doc {
+		if field.Key != path[0] {
+			result = append(result, field)
+			continue
+		}
+		if len(path) == 1 {
+			continue
+		}
+		if nested, ok := field.Value.(bson.D); ok {
+			field.Value = unsetPath(nested, path[1:])
+		}
+		result = append(result, field)
+	}
+	return result
+}
+
+func setField(doc bson.D, key string, value interface{}) bson.D {
+	result := append(bson.D{}, doc...)
+	for i := range result {
+		if result[i].Key == key {
+			result[i].Value = value
+			return result
+		}
+	}
+	return append(result, bson.E{Key: key, Value: value})
+}
+
+func lookup(doc bson.D, key string) (interface{}, bool) {
+	for _, field := range doc {
+		if field.Key == key {
+			return field.Value, true
+		}
+	}
+	return nil, false
+}
+
+func lookupDocument(doc bson.D, key string) (bson.D, bool) {
+	value, _ := lookup(doc, key)
+	nested, ok := value.(bson.D)
+	return nested, ok
+}
@@ -0,0 +1,115 @@
+package oplog
+
+import (
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestApplyToDocument(t *testing.T) {
+	doc := bson.D{
+		{Key: "_id", Value: int32(1)},
+		{Key: "name", Value: "alice"},
+		{Key: "address", Value: bson.D{{Key: "city", Value: "Moscow"}, {Key: "zip", Value: "101000"}}},
+		{Key: "tags", Value: bson.A{"a", "b", "c"}},
+	}
+
+	tests := []struct {
+		name     string
+		op       db.Oplog
+		doc      bson.D
+		expected bson.D
+	}{
+		{
+			name:     "insert",
+			op:       db.Oplog{Operation: "i", Object: bson.D{{Key: "_id", Value: int32(2)}}},
+			doc:      nil,
+			expected: bson.D{{Key: "_id", Value: int32(2)}},
+		},
+		{
+			name:     "delete",
+			op:       db.Oplog{Operation: "d", Object: bson.D{{Key: "_id", Value: int32(1)}}},
+			doc:      doc,
+			expected: nil,
+		},
+		{
+			name:     "update of the missing document",
+			op:       db.Oplog{Operation: "u", Object: bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "bob"}}}}},
+			doc:      nil,
+			expected: nil,
+		},
+		{
+			name:     "noop",
+			op:       db.Oplog{Operation: "n", Object: bson.D{{Key: "msg", Value: "periodic noop"}}},
+			doc:      doc,
+			expected: doc,
+		},
+		{
+			name: "set and unset with dotted paths",
+			op: db.Oplog{Operation: "u", Object: bson.D{
+				{Key: "$set", Value: bson.D{{Key: "address.city", Value: "Paris"}, {Key: "tags.4", Value: "e"}}},
+				{Key: "$unset", Value: bson.D{{Key: "address.zip", Value: true}}},
+			}},
+			doc: doc,
+			expected: bson.D{
+				{Key: "_id", Value: int32(1)},
+				{Key: "name", Value: "alice"},
+				{Key: "address", Value: bson.D{{Key: "city", Value: "Paris"}}},
+				{Key: "tags", Value: bson.A{"a", "b", "c", nil, "e"}},
+			},
+		},
+		{
+			name: "replacement",
+			op: db.Oplog{Operation: "u", Object: bson.D{
+				{Key: "_id", Value: int32(1)},
+				{Key: "name", Value: "bob"},
+			}},
+			doc:      doc,
+			expected: bson.D{{Key: "_id", Value: int32(1)}, {Key: "name", Value: "bob"}},
+		},
+		{
+			name: "v2 diff",
+			op: db.Oplog{Operation: "u", Object: bson.D{
+				{Key: "$v", Value: int32(2)},
+				{Key: "diff", Value: bson.D{
+					{Key: "d", Value: bson.D{{Key: "name", Value: false}}},
+					{Key: "i", Value: bson.D{{Key: "age", Value: int32(30)}}},
+					{Key: "saddress", Value: bson.D{{Key: "u", Value: bson.D{{Key: "city", Value: "Berlin"}}}}},
+					{Key: "stags", Value: bson.D{
+						{Key: "a", Value: true},
+						{Key: "l", Value: int32(2)},
+						{Key: "u1", Value: "x"},
+					}},
+				}},
+			}},
+			doc: doc,
+			expected: bson.D{
+				{Key: "_id", Value: int32(1)},
+				{Key: "address", Value: bson.D{{Key: "city", Value: "Berlin"}, {Key: "zip", Value: "101000"}}},
+				{Key: "tags", Value: bson.A{"a", "x"}},
+				{Key: "age", Value: int32(30)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ApplyToDocument(tt.doc, &tt.op)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	// the source document is not modified
+	assert.Equal(t, "Moscow", doc[2].Value.(bson.D)[0].Value)
+	assert.Equal(t, bson.A{"a", "b", "c"}, doc[3].Value)
+}
+
+func TestApplyToDocument_UnsupportedModifier(t *testing.T) {
+	op := db.Oplog{Operation: "u", Object: bson.D{{Key: "$inc", Value: bson.D{{Key: "count", Value: int32(1)}}}}}
+	_, err := ApplyToDocument(bson.D{{Key: "_id", Value: int32(1)}}, &op)
+	assert.Error(t, err)
+}
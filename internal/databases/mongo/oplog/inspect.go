@@ -0,0 +1,191 @@
+package oplog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mongodb/mongo-tools/common/db"
+	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var _ = []Applier{&InspectApplier{}}
+
+// DocumentHistory holds the operations on the single document in the order they were applied
+type DocumentHistory struct {
+	Namespace string
+	ID        bson.RawValue
+	Ops       []db.Oplog
+}
+
+// NamespaceHistory holds the operations on the namespace grouped by the document _id
+type NamespaceHistory struct {
+	Namespace string
+	Documents []*DocumentHistory
+	// Commands are the commands changing the whole collection or database, e.g. drop, rename or dropDatabase.
+	// The rename is held by its source namespace, or by its target one if only the target is inspected.
+	Commands []db.Oplog
+
+	documentsByID map[string]*DocumentHistory
+}
+
+// InspectApplier implements Applier interface, it collects the operations grouped by the namespace and the document.
+// The operations inside applyOps are unwrapped.
+type InspectApplier struct {
+	match         func(ns string) bool
+	matchDocument func(id bson.RawValue) bool
+	namespaces    []*NamespaceHistory
+	byName        map[string]*NamespaceHistory
+}
+
+// NewInspectApplier builds InspectApplier, it collects the operations on the namespaces accepted by match
+func NewInspectApplier(match func(ns string) bool) *InspectApplier {
+	return &InspectApplier{match: match, byName: make(map[string]*NamespaceHistory)}
+}
+
+// NewDocumentInspectApplier builds InspectApplier, it collects the commands on all namespaces
+// and the operations on the documents accepted by matchDocument in any namespace
+func NewDocumentInspectApplier(matchDocument func(id bson.RawValue) bool) *InspectApplier {
+	ap := NewInspectApplier(func(string) bool { return true })
+	ap.matchDocument = matchDocument
+	return ap
+}
+
+func (ap *InspectApplier) Apply(ctx context.Context, opr models.Oplog) error {
+	op := db.Oplog{}
+	if err := bson.Unmarshal(opr.Data, &op); err != nil {
+		return fmt.Errorf("can not unmarshal oplog entry: %w", err)
+	}
+	return ap.collect(op)
+}
+
+func (ap *InspectApplier) collect(op db.Oplog) error {
+	if op.Operation == "c" && isApplyOpsCmd(op.Object) {
+		nestedOps, err := unwrapNestedApplyOps(&op.Object)
+		if err != nil {
+			return err
+		}
+		for _, nestedOp := range nestedOps {
+			if nestedOp.Timestamp.IsZero() {
+				nestedOp.Timestamp = op.Timestamp
+			}
+			if err := ap.collect(nestedOp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch op.Operation {
+	case "i", "u", "d":
+		if !ap.match(op.Namespace) {
+			return nil
+		}
+		id, err := DocumentID(&op)
+		if err != nil {
+			return err
+		}
+		if ap.matchDocument != nil && !ap.matchDocument(id) {
+			return nil
+		}
+		ap.document(op.Namespace, id).Ops = append(ap.document(op.Namespace, id).Ops, op)
+	case "c":
+		ns := CommandNamespace(&op)
+		if ns != "" && !ap.match(ns) {
+			ns = RenameTarget(&op)
+		}
+		if ns != "" && ap.match(ns) {
+			history := ap.namespace(ns)
+			history.Commands = append(history.Commands, op)
+		}
+	}
+	return nil
+}
+
+func (ap *InspectApplier) Close(ctx context.Context) error {
+	return nil
+}
+
+// Namespaces returns the collected operations in the order the namespaces were met
+func (ap *InspectApplier) Namespaces() []*NamespaceHistory {
+	return ap.namespaces
+}
+
+func (ap *InspectApplier) namespace(ns string) *NamespaceHistory {
+	history, ok := ap.byName[ns]
+	if !ok {
+		history = &NamespaceHistory{Namespace: ns, documentsByID: make(map[string]*DocumentHistory)}
+		ap.byName[ns] = history
+		ap.namespaces = append(ap.namespaces, history)
+	}
+	return history
+}
+
+func (ap *InspectApplier) document(ns string, id bson.RawValue) *DocumentHistory {
+	history := ap.namespace(ns)
+	key := DocumentIDKey(id)
+	document, ok := history.documentsByID[key]
+	if !ok {
+		document = &DocumentHistory{Namespace: ns, ID: id}
+		history.documentsByID[key] = document
+		history.Documents = append(history.Documents, document)
+	}
+	return document
+}
+
+// DocumentID returns the _id of the document changed by the CRUD operation
+func DocumentID(op *db.Oplog) (bson.RawValue, error) {
+	source := op.Object
+	if op.Operation == "u" {
+		source = op.Query
+	}
+	id, ok := lookup(source, "_id")
+	if !ok {
+		return bson.RawValue{}, fmt.Errorf("can not find _id of the %s operation at %v on %s",
+			op.Operation, op.Timestamp, op.Namespace)
+	}
+	bsonType, data, err := bson.MarshalValue(id)
+	if err != nil {
+		return bson.RawValue{}, fmt.Errorf("can not marshal _id: %w", err)
+	}
+	return bson.RawValue{Type: bsonType, Value: data}, nil
+}
+
+// DocumentIDKey returns the key comparing the _id values by their type and BSON representation
+func DocumentIDKey(id bson.RawValue) string {
+	return string(rune(id.Type)) + string(id.Value)
+}
+
+// CommandNamespace returns the collection changed by the command, the source collection of the rename
+// or the database dropped by dropDatabase. It is empty for the other commands.
+func CommandNamespace(op *db.Oplog) string {
+	if len(op.Object) == 0 {
+		return ""
+	}
+	dbName := op.Namespace
+	if dot := strings.IndexByte(dbName, '.'); dot >= 0 {
+		dbName = dbName[:dot]
+	}
+	switch op.Object[0].Key {
+	case "dropDatabase":
+		return dbName
+	case "drop", "create", "collMod", "convertToCapped", "emptycapped":
+		coll, _ := op.Object[0].Value.(string)
+		return dbName + "." + coll
+	case "renameCollection":
+		from, _ := op.Object[0].Value.(string)
+		return from
+	}
+	return ""
+}
+
+// RenameTarget returns the target collection of renameCollection, it is empty for the other commands
+func RenameTarget(op *db.Oplog) string {
+	if len(op.Object) == 0 || op.Object[0].Key != "renameCollection" {
+		return ""
+	}
+	to, _ := lookup(op.Object, "to")
+	target, _ := to.(string)
+	return target
+}
@@ -0,0 +1,387 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mongodb/mongo-tools/common/db"
+	"github.com/mongodb/mongo-tools/common/util"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/databases/mongo/archive"
+	"github.com/wal-g/wal-g/internal/databases/mongo/common"
+	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"github.com/wal-g/wal-g/internal/databases/mongo/oplog"
+	"github.com/wal-g/wal-g/internal/databases/mongo/stages"
+	"github.com/wal-g/wal-g/utility"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// OplogInspectDownloader downloads the oplog archives and the logical backups
+type OplogInspectDownloader interface {
+	archive.Downloader
+	DownloadBackupStream(ctx context.Context, name string, writeCloser io.WriteCloser) error
+}
+
+type OplogInspectArgs struct {
+	Since models.Timestamp
+	Until models.Timestamp
+	// Namespaces are the databases or collections to inspect, all namespaces are inspected if empty
+	Namespaces []string
+
+	// RestoreAt is the timestamp to reconstruct the changed documents at, the reconstruction is skipped if nil
+	RestoreAt *models.Timestamp
+	// BackupName is the logical backup to start the reconstruction from, the latest suitable one is used if empty
+	BackupName string
+	OutputDir  string
+}
+
+// HandleOplogInspect writes the operations between since and until to the report applier grouped by the namespace
+// and the document _id: the commands on the namespace go first, then the operations on every document.
+// If the restore point is set, the state of every changed document at that point is reconstructed
+// from the logical backup and the oplog and written as <db>/<collection>.bson files readable by mongorestore.
+func HandleOplogInspect(ctx context.Context,
+	downloader OplogInspectDownloader,
+	args OplogInspectArgs,
+	report oplog.Applier) error {
+	inspector := oplog.NewInspectApplier(namespaceMatcher(args.Namespaces))
+	err := fetchOplogInto(ctx, downloader, args.Since, args.Until, inspector)
+	if err != nil {
+		return err
+	}
+	err = writeInspectReport(ctx, inspector.Namespaces(), report)
+	if err != nil {
+		return err
+	}
+	if args.RestoreAt == nil {
+		return nil
+	}
+	return reconstructDocuments(ctx, downloader, inspector.Namespaces(), args)
+}
+
+func namespaceMatcher(namespaces []string) func(ns string) bool {
+	return func(ns string) bool {
+		if len(namespaces) == 0 {
+			return true
+		}
+		db, coll := util.SplitNamespace(ns)
+		for _, namespace := range namespaces {
+			filterDB, filterColl := util.SplitNamespace(namespace)
+			// dropDatabase is met with the database namespace
+			if filterDB == db && (filterColl == "" || coll == "" || filterColl == coll) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func fetchOplogInto(ctx context.Context,
+	downloader archive.Downloader,
+	since, until models.Timestamp,
+	applier oplog.Applier) error {
+	archives, err := downloader.ListOplogArchives(ctx)
+	if err != nil {
+		return err
+	}
+	path, err := archive.SequenceBetweenTS(archives, since, until)
+	if err != nil {
+		return err
+	}
+	return HandleOplogReplay(ctx, since, until, stages.NewStorageFetcher(downloader, path), stages.NewGenericApplier(applier))
+}
+
+func writeInspectReport(ctx context.Context, namespaces []*oplog.NamespaceHistory, report oplog.Applier) error {
+	for _, namespace := range namespaces {
+		for i := range namespace.Commands {
+			if err := applyReportOp(ctx, report, &namespace.Commands[i]); err != nil {
+				return err
+			}
+		}
+		for _, document := range namespace.Documents {
+			for i := range document.Ops {
+				if err := applyReportOp(ctx, report, &document.Ops[i]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return report.Close(ctx)
+}
+
+func applyReportOp(ctx context.Context, report oplog.Applier, op *db.Oplog) error {
+	data, err := bson.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("can not marshal oplog entry: %w", err)
+	}
+	return report.Apply(ctx, models.Oplog{TS: models.TimestampFromBson(op.Timestamp), Data: data})
+}
+
+// reconstructDocuments takes the changed documents from the logical backup and applies the oplog from the backup start
+// up to the restore point to them. The oplog is idempotent, so the documents changed while the backup was taken
+// get the right state too.
+func reconstructDocuments(ctx context.Context,
+	downloader OplogInspectDownloader,
+	changed []*oplog.NamespaceHistory,
+	args OplogInspectArgs) error {
+	backup, err := selectInspectBackup(ctx, downloader, args.BackupName, *args.RestoreAt)
+	if err != nil {
+		return err
+	}
+	tracelog.InfoLogger.Printf("Reconstructing the documents at %s from backup %s", args.RestoreAt, backup.BackupName)
+
+	states := make(map[string]map[string]bson.D, len(changed))
+	for _, namespace := range changed {
+		states[namespace.Namespace] = make(map[string]bson.D, len(namespace.Documents))
+		for _, document := range namespace.Documents {
+			states[namespace.Namespace][oplog.DocumentIDKey(document.ID)] = nil
+		}
+	}
+	var replayed []*oplog.NamespaceHistory
+	replayFrom := backup.MongoMeta.Before.LastMajTS
+	if models.LessTS(replayFrom, *args.RestoreAt) {
+		ids := make(map[string]struct{})
+		for _, documents := range states {
+			for key := range documents {
+				ids[key] = struct{}{}
+			}
+		}
+		// the documents may be renamed into the changed collections from any other one,
+		// so the commands and the operations on the changed _ids are collected in all namespaces
+		replay := oplog.NewDocumentInspectApplier(func(id bson.RawValue) bool {
+			_, ok := ids[oplog.DocumentIDKey(id)]
+			return ok
+		})
+		err = fetchOplogInto(ctx, downloader, replayFrom, *args.RestoreAt, replay)
+		if err != nil {
+			return err
+		}
+		replayed = replay.Namespaces()
+		trackRenameSources(states, replayed)
+	}
+
+	err = readBackupDocuments(ctx, downloader, backup.BackupName, states)
+	if err != nil {
+		return err
+	}
+	err = applyHistory(states, replayed)
+	if err != nil {
+		return err
+	}
+
+	for _, namespace := range changed {
+		if len(namespace.Documents) == 0 {
+			continue
+		}
+		err = writeDocuments(args.OutputDir, namespace, states[namespace.Namespace])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func selectInspectBackup(ctx context.Context,
+	downloader archive.Downloader,
+	backupName string,
+	restoreAt models.Timestamp) (*models.Backup, error) {
+	if backupName != "" {
+		backup, err := downloader.BackupMeta(ctx, backupName)
+		if err != nil {
+			return nil, err
+		}
+		if models.LessTS(restoreAt, backup.MongoMeta.After.LastMajTS) {
+			return nil, fmt.Errorf("backup %s was finished at %s after the restore point %s",
+				backupName, backup.MongoMeta.After.LastMajTS, restoreAt)
+		}
+		return backup, nil
+	}
+
+	_, names, err := downloader.ListBackups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	backups, err := downloader.LoadBackups(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	// the backups are sorted from the newest one
+	for _, backup := range backups {
+		if backup.BackupType == common.LogicalBackupType && !models.LessTS(restoreAt, backup.MongoMeta.After.LastMajTS) {
+			return backup, nil
+		}
+	}
+	return nil, fmt.Errorf("no logical backup finished before %s is found", restoreAt)
+}
+
+func readBackupDocuments(ctx context.Context,
+	downloader OplogInspectDownloader,
+	backupName string,
+	states map[string]map[string]bson.D) error {
+	reader, writer := io.Pipe()
+	go func() {
+		_ = writer.CloseWithError(downloader.DownloadBackupStream(ctx, backupName, writer))
+	}()
+	defer utility.LoggedClose(reader, "")
+
+	return archive.ReadDumpDocuments(reader, func(ns string, raw bson.Raw) error {
+		documents, ok := states[ns]
+		if !ok {
+			return nil
+		}
+		id, err := raw.LookupErr("_id")
+		if err != nil {
+			return nil
+		}
+		key := oplog.DocumentIDKey(id)
+		if _, ok := documents[key]; !ok {
+			return nil
+		}
+		var doc bson.D
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return fmt.Errorf("can not unmarshal document of %s: %w", ns, err)
+		}
+		documents[key] = doc
+		return nil
+	})
+}
+
+// trackRenameSources makes the documents of the rename target tracked in the source collection too,
+// so their state before the rename is known. The renames are walked from the latest one to follow the chains.
+func trackRenameSources(states map[string]map[string]bson.D, namespaces []*oplog.NamespaceHistory) {
+	var renames []*db.Oplog
+	for _, namespace := range namespaces {
+		for i := range namespace.Commands {
+			if namespace.Commands[i].Object[0].Key == "renameCollection" {
+				renames = append(renames, &namespace.Commands[i])
+			}
+		}
+	}
+	slices.SortStableFunc(renames, compareOps)
+	for _, rename := range slices.Backward(renames) {
+		target, ok := states[oplog.RenameTarget(rename)]
+		if !ok {
+			continue
+		}
+		source := oplog.CommandNamespace(rename)
+		if _, ok := states[source]; !ok {
+			states[source] = make(map[string]bson.D, len(target))
+		}
+		for key := range target {
+			states[source][key] = nil
+		}
+	}
+}
+
+// applyHistory applies the operations and the commands to the document states in the oplog order:
+// the dropped collection or database loses its documents, the renamed collection passes them to the target one
+func applyHistory(states map[string]map[string]bson.D, namespaces []*oplog.NamespaceHistory) error {
+	var ops []*db.Oplog
+	for _, namespace := range namespaces {
+		for i := range namespace.Commands {
+			ops = append(ops, &namespace.Commands[i])
+		}
+		if _, ok := states[namespace.Namespace]; !ok {
+			continue
+		}
+		for _, document := range namespace.Documents {
+			for i := range document.Ops {
+				ops = append(ops, &document.Ops[i])
+			}
+		}
+	}
+	slices.SortStableFunc(ops, compareOps)
+
+	for _, op := range ops {
+		if op.Operation == "c" {
+			applyCommand(states, op)
+			continue
+		}
+		id, err := oplog.DocumentID(op)
+		if err != nil {
+			return err
+		}
+		documents := states[op.Namespace]
+		key := oplog.DocumentIDKey(id)
+		if _, ok := documents[key]; !ok {
+			continue
+		}
+		state, err := oplog.ApplyToDocument(documents[key], op)
+		if err != nil {
+			return fmt.Errorf("can not apply the operation at %v to the document of %s: %w",
+				op.Timestamp, op.Namespace, err)
+		}
+		documents[key] = state
+	}
+	return nil
+}
+
+func applyCommand(states map[string]map[string]bson.D, op *db.Oplog) {
+	switch op.Object[0].Key {
+	case "drop":
+		clearDocuments(states[oplog.CommandNamespace(op)])
+	case "dropDatabase":
+		dbName := oplog.CommandNamespace(op)
+		for ns, documents := range states {
+			if nsDB, _ := util.SplitNamespace(ns); nsDB == dbName {
+				clearDocuments(documents)
+			}
+		}
+	case "renameCollection":
+		// the target collection does not exist or is dropped by dropTarget, the source documents take its place
+		source, target := states[oplog.CommandNamespace(op)], states[oplog.RenameTarget(op)]
+		for key := range target {
+			target[key] = source[key]
+		}
+		clearDocuments(source)
+	}
+}
+
+func clearDocuments(documents map[string]bson.D) {
+	for key := range documents {
+		documents[key] = nil
+	}
+}
+
+func compareOps(a, b *db.Oplog) int {
+	return a.Timestamp.Compare(b.Timestamp)
+}
+
+func writeDocuments(outputDir string, namespace *oplog.NamespaceHistory, documents map[string]bson.D) error {
+	dbName, collName := util.SplitNamespace(namespace.Namespace)
+	if collName == "" || strings.ContainsAny(dbName+collName, `/\`) {
+		return fmt.Errorf("unexpected namespace %s", namespace.Namespace)
+	}
+	err := os.MkdirAll(filepath.Join(outputDir, dbName), 0750)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(outputDir, dbName, collName+".bson"))
+	if err != nil {
+		return err
+	}
+	defer utility.LoggedClose(file, "")
+
+	absent := 0
+	for _, document := range namespace.Documents {
+		state := documents[oplog.DocumentIDKey(document.ID)]
+		if state == nil {
+			absent++
+			continue
+		}
+		data, err := bson.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("can not marshal document of %s: %w", namespace.Namespace, err)
+		}
+		if _, err := file.Write(data); err != nil {
+			return err
+		}
+	}
+	tracelog.InfoLogger.Printf("%s: %d documents are reconstructed, %d documents did not exist at the restore point",
+		namespace.Namespace, len(namespace.Documents)-absent, absent)
+	return file.Sync()
+}
@@ -0,0 +1,128 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/mongodb/mongo-tools/common/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"github.com/wal-g/wal-g/internal/databases/mongo/oplog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func inspectTestDoc(id int32, value string) bson.D {
+	return bson.D{{Key: "_id", Value: id}, {Key: "v", Value: value}}
+}
+
+func inspectTestID(t *testing.T, id int32) string {
+	bsonType, data, err := bson.MarshalValue(id)
+	require.NoError(t, err)
+	return oplog.DocumentIDKey(bson.RawValue{Type: bsonType, Value: data})
+}
+
+func TestApplyHistory(t *testing.T) {
+	ts := func(i uint32) bson.Timestamp { return bson.Timestamp{T: 1000, I: i} }
+	tests := []struct {
+		name     string
+		ops      []db.Oplog
+		expected map[string]map[int32]bson.D
+	}{
+		{
+			name: "rename into the namespace",
+			ops: []db.Oplog{
+				{Timestamp: ts(1), Operation: "u", Namespace: "shop.orders",
+					Query:  bson.D{{Key: "_id", Value: int32(1)}},
+					Object: bson.D{{Key: "$set", Value: bson.D{{Key: "v", Value: "orders2"}}}}},
+				{Timestamp: ts(2), Operation: "u", Namespace: "shop.tmp",
+					Query:  bson.D{{Key: "_id", Value: int32(1)}},
+					Object: bson.D{{Key: "$set", Value: bson.D{{Key: "v", Value: "tmp2"}}}}},
+				{Timestamp: ts(3), Operation: "c", Namespace: "admin.$cmd", Object: bson.D{
+					{Key: "renameCollection", Value: "shop.tmp"},
+					{Key: "to", Value: "shop.orders"},
+					{Key: "dropTarget", Value: true},
+				}},
+				{Timestamp: ts(4), Operation: "i", Namespace: "shop.orders", Object: inspectTestDoc(2, "new")},
+				{Timestamp: ts(5), Operation: "u", Namespace: "shop.tmp",
+					Query:  bson.D{{Key: "_id", Value: int32(1)}},
+					Object: bson.D{{Key: "$set", Value: bson.D{{Key: "v", Value: "recreated"}}}}},
+			},
+			expected: map[string]map[int32]bson.D{
+				"shop.orders": {1: inspectTestDoc(1, "tmp2"), 2: inspectTestDoc(2, "new")},
+				"shop.items":  {3: inspectTestDoc(3, "items")},
+			},
+		},
+		{
+			name: "dropDatabase",
+			ops: []db.Oplog{
+				{Timestamp: ts(1), Operation: "i", Namespace: "shop.orders", Object: inspectTestDoc(2, "new")},
+				{Timestamp: ts(2), Operation: "c", Namespace: "shop.$cmd", Object: bson.D{{Key: "dropDatabase", Value: int32(1)}}},
+				{Timestamp: ts(3), Operation: "i", Namespace: "shop.items", Object: inspectTestDoc(3, "again")},
+			},
+			expected: map[string]map[int32]bson.D{
+				"shop.orders": {1: nil, 2: nil},
+				"shop.items":  {3: inspectTestDoc(3, "again")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			states := map[string]map[string]bson.D{
+				"shop.orders": {inspectTestID(t, 1): nil, inspectTestID(t, 2): nil},
+				"shop.items":  {inspectTestID(t, 3): nil},
+			}
+			replay := oplog.NewDocumentInspectApplier(func(bson.RawValue) bool { return true })
+			for i := range tt.ops {
+				data, err := bson.Marshal(tt.ops[i])
+				require.NoError(t, err)
+				require.NoError(t, replay.Apply(t.Context(), models.Oplog{Data: data}))
+			}
+			trackRenameSources(states, replay.Namespaces())
+
+			// the documents read from the backup
+			backup := map[string]map[int32]bson.D{
+				"shop.orders": {1: inspectTestDoc(1, "orders")},
+				"shop.tmp":    {1: inspectTestDoc(1, "tmp")},
+				"shop.items":  {3: inspectTestDoc(3, "items")},
+			}
+			for ns, documents := range backup {
+				for id, doc := range documents {
+					if _, ok := states[ns][inspectTestID(t, id)]; ok {
+						states[ns][inspectTestID(t, id)] = doc
+					}
+				}
+			}
+
+			require.NoError(t, applyHistory(states, replay.Namespaces()))
+			for ns, documents := range tt.expected {
+				for id, doc := range documents {
+					assert.Equal(t, doc, states[ns][inspectTestID(t, id)], "%s %d", ns, id)
+				}
+			}
+		})
+	}
+}
+
+func TestInspectApplier_CollectsRenameIntoNamespace(t *testing.T) {
+	inspector := oplog.NewInspectApplier(namespaceMatcher([]string{"shop.orders"}))
+	ops := []db.Oplog{
+		{Operation: "c", Namespace: "admin.$cmd", Object: bson.D{
+			{Key: "renameCollection", Value: "shop.tmp"},
+			{Key: "to", Value: "shop.orders"},
+		}},
+		{Operation: "c", Namespace: "shop.$cmd", Object: bson.D{{Key: "dropDatabase", Value: int32(1)}}},
+		{Operation: "c", Namespace: "other.$cmd", Object: bson.D{{Key: "dropDatabase", Value: int32(1)}}},
+	}
+	for i := range ops {
+		data, err := bson.Marshal(ops[i])
+		require.NoError(t, err)
+		require.NoError(t, inspector.Apply(t.Context(), models.Oplog{Data: data}))
+	}
+
+	namespaces := inspector.Namespaces()
+	require.Len(t, namespaces, 2)
+	assert.Equal(t, "shop.orders", namespaces[0].Namespace)
+	assert.Equal(t, "renameCollection", namespaces[0].Commands[0].Object[0].Key)
+	assert.Equal(t, "shop", namespaces[1].Namespace)
+	assert.Equal(t, "dropDatabase", namespaces[1].Commands[0].Object[0].Key)
+}
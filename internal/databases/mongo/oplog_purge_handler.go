@@ -3,12 +3,14 @@ package mongo
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/wal-g/tracelog"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/databases/mongo/archive"
 	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
 )
 
 func LoadBackups(ctx context.Context, downloader archive.Downloader) ([]*models.Backup, error) {
@@ -64,3 +66,44 @@ func HandleOplogPurge(ctx context.Context,
 	}
 	return nil
 }
+
+// HandleClusterOplogPurge deletes the oplog archives of the cluster members which are not used by the retained
+// cluster backups. The member oplog is uploaded by cluster-backup-push and replayed only from the member backup
+// up to the cluster restore point, so the archives outside of these intervals are not needed.
+func HandleClusterOplogPurge(ctx context.Context, rootFolder storage.Folder, retained, purged []*models.Backup,
+	dryRun bool) error {
+	members := make(map[string][]*models.Backup)
+	for _, backup := range slices.Concat(retained, purged) {
+		for _, shard := range backup.Shards {
+			if _, ok := members[shard.ShardName]; !ok {
+				members[shard.ShardName] = nil
+			}
+		}
+	}
+	for _, backup := range retained {
+		for _, shard := range backup.Shards {
+			// the oplog interval of the member is matched as the backup interval
+			members[shard.ShardName] = append(members[shard.ShardName], &models.Backup{MongoMeta: models.MongoMeta{
+				Before: models.NodeMeta{LastMajTS: shard.OplogSince},
+				After:  models.NodeMeta{LastMajTS: shard.OplogUntil},
+			}})
+		}
+	}
+
+	for member, intervals := range members {
+		settings := archive.NewClusterMemberStorageSettings(member)
+		archives, err := archive.NewFolderDownloader(rootFolder, settings).ListOplogArchives(ctx)
+		if err != nil {
+			return fmt.Errorf("can not load oplog archives of %s: %w", member, err)
+		}
+		purgeArchives := archive.SelectPurgingOplogArchives(archives, intervals, nil, nil)
+		tracelog.InfoLogger.Printf("Oplog archives of %s selected to be deleted: %d", member, len(purgeArchives))
+		if dryRun || len(purgeArchives) == 0 {
+			continue
+		}
+		if err := archive.NewFolderPurger(rootFolder, settings).DeleteOplogArchives(ctx, purgeArchives); err != nil {
+			return fmt.Errorf("can not purge oplog archives of %s: %w", member, err)
+		}
+	}
+	return nil
+}
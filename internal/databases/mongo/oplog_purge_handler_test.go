@@ -3,14 +3,18 @@ package mongo
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/wal-g/wal-g/internal"
 	mocks "github.com/wal-g/wal-g/internal/databases/mongo/archive/mocks"
 	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
 )
 
 var (
@@ -178,3 +182,35 @@ func TestHandleOplogPurge(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleClusterOplogPurge(t *testing.T) {
+	folder := memory.NewFolder("", memory.NewKVS())
+	archives := []models.Archive{
+		{Start: models.Timestamp{TS: 100}, End: models.Timestamp{TS: 200}, Ext: "lz4", Type: models.ArchiveTypeOplog},
+		{Start: models.Timestamp{TS: 200}, End: models.Timestamp{TS: 250}, Ext: "lz4", Type: models.ArchiveTypeOplog},
+		{Start: models.Timestamp{TS: 500}, End: models.Timestamp{TS: 600}, Ext: "lz4", Type: models.ArchiveTypeOplog},
+	}
+	for _, arch := range archives {
+		err := folder.PutObject(t.Context(), "shard01/oplog_005/"+arch.Filename(), strings.NewReader("oplog"))
+		require.NoError(t, err)
+	}
+	purged := &models.Backup{Shards: []models.ShardBackup{
+		{ShardName: "shard01", OplogSince: models.Timestamp{TS: 100}, OplogUntil: models.Timestamp{TS: 250}},
+	}}
+	retained := &models.Backup{Shards: []models.ShardBackup{
+		{ShardName: "shard01", OplogSince: models.Timestamp{TS: 500}, OplogUntil: models.Timestamp{TS: 600}},
+	}}
+
+	err := HandleClusterOplogPurge(t.Context(), folder, []*models.Backup{retained}, []*models.Backup{purged}, true)
+	require.NoError(t, err)
+	objects, err := storage.ListFolderRecursively(t.Context(), folder)
+	require.NoError(t, err)
+	assert.Len(t, objects, 3)
+
+	err = HandleClusterOplogPurge(t.Context(), folder, []*models.Backup{retained}, []*models.Backup{purged}, false)
+	require.NoError(t, err)
+	objects, err = storage.ListFolderRecursively(t.Context(), folder)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "shard01/oplog_005/"+archives[2].Filename(), objects[0].GetName())
+}
@@ -2,8 +2,11 @@ package mongo
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/databases/mongo/binary"
+	"github.com/wal-g/wal-g/internal/databases/mongo/common"
 	"github.com/wal-g/wal-g/internal/databases/mongo/models"
 	"github.com/wal-g/wal-g/internal/databases/mongo/stages"
 )
@@ -19,3 +22,26 @@ func HandleOplogReplay(ctx context.Context,
 func RunOplogReplay(ctx context.Context, mongodbURL string, replayArgs binary.ReplyOplogConfig) error {
 	return binary.RunOplogReplay(ctx, mongodbURL, replayArgs)
 }
+
+// NewClusterMemberReplayConfig builds the oplog replay config bringing the member of the cluster backup
+// restored from its member backup to the cluster restore point
+func NewClusterMemberReplayConfig(ctx context.Context, backupName, clusterMember string, partial bool,
+) (binary.ReplyOplogConfig, error) {
+	backupsFolder, err := common.GetBackupFolder(ctx)
+	if err != nil {
+		return binary.ReplyOplogConfig{}, err
+	}
+	backup, err := internal.GetBackupByName(ctx, backupName, "", backupsFolder)
+	if err != nil {
+		return binary.ReplyOplogConfig{}, err
+	}
+	sentinel, err := common.DownloadSentinel(ctx, backupsFolder, backup.Name)
+	if err != nil {
+		return binary.ReplyOplogConfig{}, err
+	}
+	if sentinel.BackupType != common.ClusterBackupType {
+		return binary.ReplyOplogConfig{}, fmt.Errorf("backup %s is not a cluster backup", backup.Name)
+	}
+	_, replayConfig, err := resolveClusterMemberRestore(ctx, sentinel, clusterMember, partial)
+	return replayConfig, err
+}
@@ -0,0 +1,68 @@
+package stages
+
+import (
+	"fmt"
+
+	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var _ = []RecordFilter{&OplogFilter{}, &ClusterTimeFilter{}}
+
+// RecordFilter selects the oplog records to apply
+type RecordFilter interface {
+	// Filter returns the record to apply or nil if the record is skipped
+	Filter(opr *models.Oplog) (*models.Oplog, error)
+}
+
+// ClusterTimeFilter selects the oplog records visible at the cluster restore timestamp: the records up to it and
+// the commits of the prepared transactions with the commit timestamp up to it. The commit of the prepared
+// transaction is written to the oplog after the commit timestamp, so the cross-shard transactions committed
+// at the restore timestamp are restored on every shard.
+type ClusterTimeFilter struct {
+	restoreTS models.Timestamp
+}
+
+// NewClusterTimeFilter builds ClusterTimeFilter with given restore timestamp.
+func NewClusterTimeFilter(restoreTS models.Timestamp) *ClusterTimeFilter {
+	return &ClusterTimeFilter{restoreTS: restoreTS}
+}
+
+// Filter returns the record if it is visible at the restore timestamp
+func (f *ClusterTimeFilter) Filter(opr *models.Oplog) (*models.Oplog, error) {
+	if !models.LessTS(f.restoreTS, opr.TS) {
+		return opr, nil
+	}
+	command, _ := bson.Raw(opr.Data).Lookup("o").DocumentOK()
+	if firstKey(command) != "commitTransaction" {
+		return nil, nil
+	}
+	t, i, ok := command.Lookup("commitTimestamp").TimestampOK()
+	if !ok {
+		return nil, fmt.Errorf("commitTransaction without commitTimestamp at %s", opr.TS)
+	}
+	if models.LessTS(f.restoreTS, models.Timestamp{TS: t, Inc: i}) {
+		return nil, nil
+	}
+	return opr, nil
+}
+
+// PreparedTxnChange tells whether the oplog record prepares or finishes the prepared transaction,
+// the transaction is identified by the key built from the session id and the transaction number
+func PreparedTxnChange(raw bson.Raw) (key string, prepared, finished bool) {
+	op, _ := raw.Lookup("op").StringValueOK()
+	txnNumber, isTxn := raw.Lookup("txnNumber").Int64OK()
+	if op != "c" || !isTxn {
+		return "", false, false
+	}
+	key = fmt.Sprintf("%x:%d", raw.Lookup("lsid").Value, txnNumber)
+	command, _ := raw.Lookup("o").DocumentOK()
+	switch firstKey(command) {
+	case "applyOps":
+		prepare, _ := command.Lookup("prepare").BooleanOK()
+		return key, prepare, false
+	case "commitTransaction", "abortTransaction":
+		return key, false, true
+	}
+	return key, false, false
+}
@@ -0,0 +1,66 @@
+package stages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestClusterTimeFilter_Filter(t *testing.T) {
+	filter := NewClusterTimeFilter(models.Timestamp{TS: 100, Inc: 3})
+	commit := func(commitTS bson.Timestamp) bson.D {
+		return commandOp("admin.$cmd", bson.D{{Key: "commitTransaction", Value: 1}, {Key: "commitTimestamp", Value: commitTS}})
+	}
+
+	tests := []struct {
+		name string
+		ts   models.Timestamp
+		op   bson.D
+		want bool
+	}{
+		{name: "before_restore_point", ts: models.Timestamp{TS: 100, Inc: 2}, op: crudOp("i", "shop.orders"), want: true},
+		{name: "at_restore_point", ts: models.Timestamp{TS: 100, Inc: 3}, op: crudOp("i", "shop.orders"), want: true},
+		{name: "after_restore_point", ts: models.Timestamp{TS: 100, Inc: 4}, op: crudOp("i", "shop.orders"), want: false},
+		{name: "commit_visible_at_restore_point", ts: models.Timestamp{TS: 100, Inc: 5},
+			op: commit(bson.Timestamp{T: 100, I: 3}), want: true},
+		{name: "commit_after_restore_point", ts: models.Timestamp{TS: 100, Inc: 5},
+			op: commit(bson.Timestamp{T: 100, I: 4}), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := makeFilterTestOp(t, tt.op)
+			op.TS = tt.ts
+			got, err := filter.Filter(op)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got != nil)
+		})
+	}
+}
+
+func TestPreparedTxnChange(t *testing.T) {
+	txnOp := func(command bson.D) bson.Raw {
+		data, err := bson.Marshal(bson.D{{Key: "op", Value: "c"}, {Key: "ns", Value: "admin.$cmd"},
+			{Key: "lsid", Value: bson.D{{Key: "id", Value: "session"}}}, {Key: "txnNumber", Value: int64(7)},
+			{Key: "o", Value: command}})
+		require.NoError(t, err)
+		return data
+	}
+
+	prepareKey, prepared, finished := PreparedTxnChange(txnOp(bson.D{{Key: "applyOps", Value: bson.A{}},
+		{Key: "prepare", Value: true}}))
+	assert.True(t, prepared)
+	assert.False(t, finished)
+
+	commitKey, prepared, finished := PreparedTxnChange(txnOp(bson.D{{Key: "commitTransaction", Value: 1}}))
+	assert.False(t, prepared)
+	assert.True(t, finished)
+	assert.Equal(t, prepareKey, commitKey)
+
+	_, prepared, finished = PreparedTxnChange(txnOp(bson.D{{Key: "applyOps", Value: bson.A{}},
+		{Key: "partialTxn", Value: true}}))
+	assert.False(t, prepared)
+	assert.False(t, finished)
+}
@@ -0,0 +1,285 @@
+package stages
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mongodb/mongo-tools/common/util"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	_ = []Applier{&FilteringApplier{}}
+
+	// supportedFilterOps are the oplog operation types: insert, update, delete, command and noop
+	supportedFilterOps = []string{"i", "u", "d", "c", "n"}
+
+	// collectionCommands are the commands whose first field holds the name of the collection they change
+	collectionCommands = map[string]bool{
+		"create":           true,
+		"drop":             true,
+		"collMod":          true,
+		"createIndexes":    true,
+		"dropIndexes":      true,
+		"deleteIndexes":    true,
+		"startIndexBuild":  true,
+		"commitIndexBuild": true,
+		"abortIndexBuild":  true,
+		"convertToCapped":  true,
+		"emptycapped":      true,
+	}
+
+	// txnCommands finish the transactions, they are kept to let the applier commit the filtered transaction ops
+	txnCommands = map[string]bool{
+		"commitTransaction": true,
+		"abortTransaction":  true,
+	}
+)
+
+// OplogFilter selects the oplog records by the namespace and the operation type.
+// The namespace is either the database or the database and the collection, the excluded namespaces take
+// precedence over the included ones. The commands are matched by the namespace they change and the nested
+// operations of applyOps are filtered one by one.
+type OplogFilter struct {
+	include namespaceSet
+	exclude namespaceSet
+	ops     map[string]bool
+}
+
+// NewOplogFilter builds OplogFilter, the empty include list or ops list matches everything
+func NewOplogFilter(includeNs, excludeNs, ops []string) (*OplogFilter, error) {
+	filter := &OplogFilter{
+		include: newNamespaceSet(includeNs),
+		exclude: newNamespaceSet(excludeNs),
+	}
+	if len(ops) > 0 {
+		filter.ops = make(map[string]bool, len(ops))
+		for _, op := range ops {
+			if !slices.Contains(supportedFilterOps, op) {
+				return nil, fmt.Errorf("unknown oplog operation type '%s', supported types are: %s",
+					op, strings.Join(supportedFilterOps, ","))
+			}
+			filter.ops[op] = true
+		}
+	}
+	return filter, nil
+}
+
+// Filter returns the record to apply: the record itself, the new record with the filtered applyOps or nil.
+// The given record is never changed.
+func (f *OplogFilter) Filter(opr *models.Oplog) (*models.Oplog, error) {
+	raw := bson.Raw(opr.Data)
+	op, _ := raw.Lookup("op").StringValueOK()
+	command, _ := raw.Lookup("o").DocumentOK()
+	if op != "c" || firstKey(command) != "applyOps" {
+		if f.match(raw) {
+			return opr, nil
+		}
+		return nil, nil
+	}
+
+	nestedOps, ok := command.Lookup("applyOps").ArrayOK()
+	if !ok {
+		return nil, fmt.Errorf("unexpected applyOps command format at %s", opr.TS)
+	}
+	values, err := nestedOps.Values()
+	if err != nil {
+		return nil, err
+	}
+	filtered := make(bson.A, 0, len(values))
+	for _, value := range values {
+		nestedOp, ok := value.DocumentOK()
+		if !ok {
+			return nil, fmt.Errorf("unexpected applyOps operation format at %s", opr.TS)
+		}
+		if f.match(nestedOp) {
+			filtered = append(filtered, value)
+		}
+	}
+	if len(filtered) == len(values) {
+		return opr, nil
+	}
+	// the transaction ops are kept even if empty, the transaction may be continued or committed by the next ones
+	_, isTxn := raw.Lookup("txnNumber").Int64OK()
+	if len(filtered) == 0 && !isTxn {
+		return nil, nil
+	}
+	return replaceApplyOps(opr, filtered)
+}
+
+func (f *OplogFilter) match(raw bson.Raw) bool {
+	op, _ := raw.Lookup("op").StringValueOK()
+	ns, _ := raw.Lookup("ns").StringValueOK()
+	command, _ := raw.Lookup("o").DocumentOK()
+	if op == "c" && txnCommands[firstKey(command)] {
+		return true
+	}
+	if f.ops != nil && !f.ops[op] {
+		return false
+	}
+	if len(f.include) == 0 && len(f.exclude) == 0 {
+		return true
+	}
+
+	namespaces := []string{ns}
+	if op == "c" {
+		namespaces = commandNamespaces(ns, command)
+	}
+	for _, namespace := range namespaces {
+		db, coll := util.SplitNamespace(namespace)
+		if (len(f.include) == 0 || f.include.contains(db, coll)) && !f.exclude.contains(db, coll) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandNamespaces returns the namespaces changed by the command, the database namespace is used for
+// the commands not bound to a collection
+func commandNamespaces(ns string, command bson.Raw) []string {
+	db, _ := util.SplitNamespace(ns)
+	name := firstKey(command)
+	switch {
+	case collectionCommands[name]:
+		coll, _ := command.Lookup(name).StringValueOK()
+		return []string{db + "." + coll}
+	case name == "renameCollection":
+		from, _ := command.Lookup("renameCollection").StringValueOK()
+		to, _ := command.Lookup("to").StringValueOK()
+		return []string{from, to}
+	}
+	return []string{db}
+}
+
+func firstKey(doc bson.Raw) string {
+	element, err := doc.IndexErr(0)
+	if err != nil {
+		return ""
+	}
+	return element.Key()
+}
+
+// replaceApplyOps builds the copy of the applyOps record with the nested operations replaced,
+// the nested operations may refer to the data of the original record
+func replaceApplyOps(opr *models.Oplog, nestedOps bson.A) (*models.Oplog, error) {
+	var doc bson.D
+	if err := bson.Unmarshal(opr.Data, &doc); err != nil {
+		return nil, fmt.Errorf("can not unmarshal oplog entry: %w", err)
+	}
+	for i := range doc {
+		if doc[i].Key != "o" {
+			continue
+		}
+		command, ok := doc[i].Value.(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("unexpected applyOps command format at %s", opr.TS)
+		}
+		for j := range command {
+			if command[j].Key == "applyOps" {
+				command[j].Value = nestedOps
+			}
+		}
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("can not marshal filtered applyOps: %w", err)
+	}
+	replaced := models.GetOplogEntry()
+	replaced.TS = opr.TS
+	replaced.Data = data
+	return replaced, nil
+}
+
+// namespaceSet holds the collections by the database, the empty collections set means the whole database
+type namespaceSet map[string]map[string]bool
+
+func newNamespaceSet(namespaces []string) namespaceSet {
+	set := make(namespaceSet)
+	for _, namespace := range namespaces {
+		db, coll := util.SplitNamespace(namespace)
+		if _, ok := set[db]; !ok {
+			set[db] = make(map[string]bool)
+		}
+		if coll != "" {
+			set[db][coll] = true
+		}
+	}
+	return set
+}
+
+func (s namespaceSet) contains(db, coll string) bool {
+	colls, ok := s[db]
+	return ok && (len(colls) == 0 || colls[coll])
+}
+
+// FilteringApplier passes the oplog records matching the filter to the next applier
+type FilteringApplier struct {
+	filter RecordFilter
+	next   Applier
+}
+
+// NewFilteringApplier builds FilteringApplier with given args.
+func NewFilteringApplier(filter RecordFilter, next Applier) *FilteringApplier {
+	return &FilteringApplier{filter: filter, next: next}
+}
+
+// Apply runs working cycle that filters oplog records.
+func (fa *FilteringApplier) Apply(ctx context.Context, ch chan *models.Oplog) (chan error, error) {
+	filteredc := make(chan *models.Oplog)
+	nextErrc, err := fa.next.Apply(ctx, filteredc)
+	if err != nil {
+		return nil, err
+	}
+
+	errc := make(chan error)
+	go func() {
+		defer close(errc)
+		skipped := 0
+		filterErr := func() error {
+			defer close(filteredc)
+			for opr := range ch {
+				filtered, err := fa.filter.Filter(opr)
+				if err != nil {
+					return fmt.Errorf("can not filter op: %w", err)
+				}
+				if filtered == nil {
+					skipped++
+					models.PutOplogEntry(opr)
+					continue
+				}
+				if filtered != opr {
+					models.PutOplogEntry(opr)
+				}
+				select {
+				case filteredc <- filtered:
+				case err := <-nextErrc:
+					if err == nil {
+						err = fmt.Errorf("applier is stopped")
+					}
+					return err
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		}()
+		tracelog.InfoLogger.Printf("Skipped %d oplog records not matching the filter", skipped)
+		if filterErr != nil {
+			go func() {
+				for range nextErrc {
+				}
+			}()
+			errc <- filterErr
+			return
+		}
+		if err := <-nextErrc; err != nil {
+			errc <- err
+		}
+	}()
+
+	return errc, nil
+}
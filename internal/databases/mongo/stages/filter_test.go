@@ -0,0 +1,109 @@
+package stages
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/databases/mongo/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func makeFilterTestOp(t *testing.T, doc bson.D) *models.Oplog {
+	data, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	return &models.Oplog{TS: models.Timestamp{TS: 1579002001, Inc: 1}, Data: data}
+}
+
+func crudOp(op, ns string) bson.D {
+	return bson.D{{Key: "op", Value: op}, {Key: "ns", Value: ns}, {Key: "o", Value: bson.D{{Key: "_id", Value: 1}}}}
+}
+
+func commandOp(ns string, command bson.D) bson.D {
+	return bson.D{{Key: "op", Value: "c"}, {Key: "ns", Value: ns}, {Key: "o", Value: command}}
+}
+
+func TestOplogFilter_Filter(t *testing.T) {
+	filter, err := NewOplogFilter([]string{"shop.orders", "stats"}, []string{"stats.tmp"}, []string{"i", "u", "c"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		op   bson.D
+		want bool
+	}{
+		{name: "included_collection", op: crudOp("i", "shop.orders"), want: true},
+		{name: "other_collection", op: crudOp("i", "shop.users"), want: false},
+		{name: "included_database", op: crudOp("u", "stats.daily"), want: true},
+		{name: "excluded_collection", op: crudOp("i", "stats.tmp"), want: false},
+		{name: "excluded_op", op: crudOp("d", "shop.orders"), want: false},
+		{name: "drop_included_collection", op: commandOp("shop.$cmd", bson.D{{Key: "drop", Value: "orders"}}), want: true},
+		{name: "drop_other_collection", op: commandOp("shop.$cmd", bson.D{{Key: "drop", Value: "users"}}), want: false},
+		{name: "drop_included_database", op: commandOp("stats.$cmd", bson.D{{Key: "dropDatabase", Value: 1}}), want: true},
+		{name: "drop_database_of_collection",
+			op: commandOp("shop.$cmd", bson.D{{Key: "dropDatabase", Value: 1}}), want: false},
+		{name: "rename_to_included_collection", op: commandOp("admin.$cmd", bson.D{
+			{Key: "renameCollection", Value: "shop.orders_tmp"}, {Key: "to", Value: "shop.orders"}}), want: true},
+		{name: "commit_transaction",
+			op: commandOp("admin.$cmd", bson.D{{Key: "commitTransaction", Value: 1}}), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opr := makeFilterTestOp(t, tt.op)
+			filtered, err := filter.Filter(opr)
+			require.NoError(t, err)
+			if tt.want {
+				assert.Same(t, opr, filtered)
+			} else {
+				assert.Nil(t, filtered)
+			}
+		})
+	}
+}
+
+func TestOplogFilter_FilterApplyOps(t *testing.T) {
+	filter, err := NewOplogFilter([]string{"shop.orders"}, nil, nil)
+	require.NoError(t, err)
+
+	applyOps := func(txn bool, ops ...bson.D) bson.D {
+		nested := bson.A{}
+		for _, op := range ops {
+			nested = append(nested, op)
+		}
+		doc := commandOp("admin.$cmd", bson.D{{Key: "applyOps", Value: nested}})
+		if txn {
+			doc = append(doc, bson.E{Key: "txnNumber", Value: int64(1)})
+		}
+		return doc
+	}
+
+	opr := makeFilterTestOp(t, applyOps(false, crudOp("i", "shop.orders"), crudOp("i", "shop.users")))
+	original := slices.Clone(opr.Data)
+	filtered, err := filter.Filter(opr)
+	require.NoError(t, err)
+	require.NotNil(t, filtered)
+	assert.Equal(t, original, opr.Data, "the original record is changed")
+	assert.Equal(t, opr.TS, filtered.TS)
+	values, err := bson.Raw(filtered.Data).Lookup("o", "applyOps").Array().Values()
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	ns, _ := values[0].Document().Lookup("ns").StringValueOK()
+	assert.Equal(t, "shop.orders", ns)
+
+	filtered, err = filter.Filter(makeFilterTestOp(t, applyOps(false, crudOp("i", "shop.users"))))
+	require.NoError(t, err)
+	assert.Nil(t, filtered)
+
+	filtered, err = filter.Filter(makeFilterTestOp(t, applyOps(true, crudOp("i", "shop.users"))))
+	require.NoError(t, err)
+	require.NotNil(t, filtered)
+	values, err = bson.Raw(filtered.Data).Lookup("o", "applyOps").Array().Values()
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func TestNewOplogFilter_UnknownOp(t *testing.T) {
+	_, err := NewOplogFilter(nil, nil, []string{"x"})
+	assert.Error(t, err)
+}
@@ -2,10 +2,12 @@ package mysql
 
 import (
 	"context"
+	"errors"
 	"os/exec"
 
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/databases/mysql/xbstream"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 )
 
@@ -15,6 +17,7 @@ func HandleBackupFetch(ctx context.Context, folder storage.Folder,
 	prepareCmd *exec.Cmd,
 	useXbtoolExtract bool,
 	inplace bool,
+	tables []string,
 ) {
 	backup, err := targetBackupSelector.Select(ctx, folder)
 	tracelog.ErrorLogger.FatalfOnError("Failed to get backup: %v", err)
@@ -23,9 +26,17 @@ func HandleBackupFetch(ctx context.Context, folder storage.Folder,
 	err = backup.FetchSentinel(ctx, &sentinel)
 	tracelog.ErrorLogger.FatalfOnError("Failed to fetch sentinel: %v", err)
 
+	var tableFilter *xbstream.TableFilter
+	if len(tables) > 0 {
+		tableFilter, err = newTableFilter(sentinel, prepareCmd, tables)
+		tracelog.ErrorLogger.FatalfOnError("Failed to restore tables: %v", err)
+		tracelog.InfoLogger.Printf("Restoring tables %v only", tableFilter.Tables())
+	}
+
 	// we should ba able to read & restore any backup we ever created:
 	if sentinel.Tool == WalgXtrabackupTool {
-		internal.HandleBackupFetch(ctx, folder, targetBackupSelector, GetXtrabackupFetcher(restoreCmd, prepareCmd, useXbtoolExtract, inplace))
+		internal.HandleBackupFetch(ctx, folder, targetBackupSelector,
+			GetXtrabackupFetcher(restoreCmd, prepareCmd, useXbtoolExtract, inplace, tableFilter))
 	} else {
 		internal.HandleBackupFetch(ctx, folder, targetBackupSelector, internal.GetBackupToCommandFetcher(restoreCmd))
 		if prepareCmd != nil {
@@ -34,3 +45,15 @@ func HandleBackupFetch(ctx context.Context, folder storage.Folder,
 		}
 	}
 }
+
+// newTableFilter checks that the tables can be restored separately: the files of xtrabackup backups
+// are extracted in-house and the tables are exported by WALG_MYSQL_BACKUP_PREPARE_COMMAND
+func newTableFilter(sentinel StreamSentinelDto, prepareCmd *exec.Cmd, tables []string) (*xbstream.TableFilter, error) {
+	if sentinel.Tool != WalgXtrabackupTool {
+		return nil, errors.New("restoring separate tables is supported only for xtrabackup backups")
+	}
+	if prepareCmd == nil {
+		return nil, errors.New("WALG_MYSQL_BACKUP_PREPARE_COMMAND is required to export the tables")
+	}
+	return xbstream.NewTableFilter(tables)
+}
@@ -197,9 +197,13 @@ func handleXtrabackupBackup(
 	stdout, stderr, err := utility.StartCommandWithStdoutStderr(backupCmd)
 	tracelog.ErrorLogger.FatalfOnError("failed to start backup create command: %v", err)
 
-	backupName, err = uploader.PushStream(ctx, limiters.NewDiskLimitReader(ctx, stdout))
+	stream, indexer := newXbstreamIndexer(limiters.NewDiskLimitReader(ctx, stdout))
+	backupName, err = uploader.PushStream(ctx, stream)
 	tracelog.ErrorLogger.FatalfOnError("failed to push backup: %v", err)
 
+	err = indexer.upload(ctx, uploader, backupName)
+	tracelog.ErrorLogger.FatalfOnError("failed to upload backup stream index: %v", err)
+
 	cmdErr := backupCmd.Wait()
 	if cmdErr != nil {
 		tracelog.ErrorLogger.Printf("Backup command output:\n%s", stderr.String())
@@ -0,0 +1,750 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/client"
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/wal-g/tracelog"
+)
+
+const (
+	NativeParallelismTable        = "table"
+	NativeParallelismLogicalClock = "logical-clock"
+
+	applyWorkerQueueSize  = 64
+	applyStmtCacheSize    = 1024
+	applyProgressInterval = 30 * time.Second
+)
+
+var errApplyStopped = errors.New("binlog replay stop point is reached")
+
+// NativeReplayOptions configures the in-process binlog applier which is used instead of WALG_MYSQL_BINLOG_REPLAY_COMMAND
+type NativeReplayOptions struct {
+	Workers     int
+	Parallelism string
+}
+
+// applyStatement is a single SQL statement of the replayed transaction
+type applyStatement struct {
+	query string
+	args  []any
+	// text statements are logged as SQL (DDL and statement-based DML) and are executed
+	// in the logged schema at the logged time, row statements are built from the row images
+	text      bool
+	schema    string
+	timestamp uint32
+	// session is the SET statement of the session variables the statement is executed with
+	session string
+}
+
+// binlogTxn is a transaction collected from the binlog events between its GTID event and its commit
+type binlogTxn struct {
+	gtid          mysql.GTIDSet // nil for anonymous transactions
+	transactional bool
+	// exclusive transactions contain text statements, the tables they touch are unknown
+	exclusive  bool
+	skip       bool
+	statements []applyStatement
+	tables     []string
+	rows       int
+
+	// MySQL logical clock
+	lastCommitted  int64
+	sequenceNumber int64
+	// MariaDB group commit
+	commitID uint64
+}
+
+func (txn *binlogTxn) String() string {
+	if txn.gtid == nil {
+		return "anonymous transaction"
+	}
+	return txn.gtid.String()
+}
+
+// binlogApplier parses the binlogs and applies their events over SQL connections
+// without mysqlbinlog: row events are turned into INSERT, UPDATE and DELETE statements,
+// query events are executed as is. The transactions are applied by several workers
+// in parallel by table or by the logical clock of the source.
+type binlogApplier struct {
+	ctx        context.Context //nolint:containedctx // cancelled by the workers on the first apply error
+	cancel     context.CancelCauseFunc
+	endTS      time.Time
+	gtidTarget *gtidReplayTarget
+	stopPoint  *gtidStopPoint
+	parser     *replication.BinlogParser
+	metaConn   *client.Conn
+	columns    map[string]*tableColumns
+	workers    []*applyWorker
+	scheduler  *applyScheduler
+	progress   *applyProgress
+	// inflight counts the dispatched transactions which are not applied yet
+	inflight sync.WaitGroup
+	running  sync.WaitGroup
+	txn      *binlogTxn
+	stopped  bool
+}
+
+func newBinlogApplier(ctx context.Context, options NativeReplayOptions, endTS time.Time,
+	gtidTarget *gtidReplayTarget) (*binlogApplier, error) {
+	if options.Workers < 1 {
+		return nil, fmt.Errorf("number of workers should be positive, got %d", options.Workers)
+	}
+	if options.Parallelism != NativeParallelismTable && options.Parallelism != NativeParallelismLogicalClock {
+		return nil, fmt.Errorf("unknown parallelism '%s', expected '%s' or '%s'",
+			options.Parallelism, NativeParallelismTable, NativeParallelismLogicalClock)
+	}
+	metaConn, err := getMySQLConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	flavor, err := getMySQLFlavor(metaConn)
+	if err != nil {
+		metaConn.Close()
+		return nil, err
+	}
+	if gtidTarget != nil && gtidTarget.flavor != flavor {
+		metaConn.Close()
+		return nil, fmt.Errorf("%s GTIDs can't be used with %s server", gtidTarget.flavor, flavor)
+	}
+
+	parser := replication.NewBinlogParser()
+	parser.SetFlavor(flavor)
+	parser.SetVerifyChecksum(true)
+	parser.SetTimestampStringLocation(time.UTC)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	a := &binlogApplier{
+		ctx:        ctx,
+		cancel:     cancel,
+		endTS:      endTS,
+		gtidTarget: gtidTarget,
+		parser:     parser,
+		metaConn:   metaConn,
+		columns:    make(map[string]*tableColumns),
+		scheduler:  &applyScheduler{workers: options.Workers, parallelism: options.Parallelism},
+		progress:   newApplyProgress(),
+	}
+	if gtidTarget != nil {
+		a.stopPoint = gtidTarget.newStopPoint()
+	}
+	for range options.Workers {
+		worker, err := newApplyWorker(ctx)
+		if err != nil {
+			a.close()
+			return nil, err
+		}
+		a.workers = append(a.workers, worker)
+		a.running.Add(1)
+		go worker.run(a)
+	}
+	if a.scheduler.byTable() {
+		if err = a.loadTableGroups(); err != nil {
+			a.close()
+			return nil, err
+		}
+	}
+	tracelog.InfoLogger.Printf("Applying binlogs to %s server with %d workers, parallelism by %s",
+		flavor, options.Workers, options.Parallelism)
+	return a, nil
+}
+
+func (a *binlogApplier) replayLog(_ context.Context, binlogPath string) error {
+	if a.stopped {
+		tracelog.InfoLogger.Printf("Stop point is reached, skipping %s", path.Base(binlogPath))
+		return nil
+	}
+	a.progress.setBinlog(path.Base(binlogPath))
+	a.txn = nil
+	// the logical clock is relative to the binlog file
+	a.scheduler.reset()
+	err := a.parser.ParseFile(binlogPath, 4, a.handleEvent)
+	if a.stopped {
+		err = nil
+	}
+	a.inflight.Wait()
+	if cause := context.Cause(a.ctx); cause != nil {
+		return cause
+	}
+	return err
+}
+
+func (a *binlogApplier) handleEvent(e *replication.BinlogEvent) error {
+	if cause := context.Cause(a.ctx); cause != nil {
+		return cause
+	}
+	if int64(e.Header.Timestamp) > a.endTS.Unix() {
+		tracelog.InfoLogger.Printf("Stop point %s is reached at position %d", a.endTS.Format(time.RFC3339), e.Header.LogPos)
+		a.txn = nil
+		a.stopped = true
+		return errApplyStopped
+	}
+	a.progress.setPosition(e.Header.LogPos, e.Header.Timestamp)
+
+	switch event := e.Event.(type) {
+	case *replication.TransactionPayloadEvent:
+		for _, inner := range event.Events {
+			if err := a.handleEvent(inner); err != nil {
+				return err
+			}
+		}
+	case *replication.GTIDEvent:
+		return a.handleGTID(e.Header, event)
+	case *replication.GtidTaggedLogEvent:
+		return a.handleGTID(e.Header, &event.GTIDEvent)
+	case *replication.MariadbGTIDEvent:
+		gtid, err := event.GTIDNext()
+		if err != nil {
+			return err
+		}
+		return a.beginTxn(&binlogTxn{gtid: gtid, transactional: !event.IsStandalone(), commitID: event.CommitID})
+	case *replication.PreviousGTIDsEvent, *replication.MariadbGTIDListEvent:
+		if a.stopPoint == nil {
+			return nil
+		}
+		_, previous, err := eventGTIDs(e)
+		if err != nil {
+			return err
+		}
+		a.stopPoint.passPrevious(previous)
+	case *replication.QueryEvent:
+		return a.handleQuery(e.Header, event)
+	case *replication.IntVarEvent:
+		variable := "LAST_INSERT_ID"
+		if event.Type == replication.INSERT_ID {
+			variable = "INSERT_ID"
+		}
+		txn := a.currentTxn()
+		txn.statements = append(txn.statements, applyStatement{query: fmt.Sprintf("SET %s=%d", variable, event.Value), text: true})
+	case *replication.RowsEvent:
+		return a.handleRows(event)
+	case *replication.XIDEvent:
+		return a.commit()
+	}
+	return nil
+}
+
+func (a *binlogApplier) handleGTID(header *replication.EventHeader, event *replication.GTIDEvent) error {
+	txn := &binlogTxn{lastCommitted: event.LastCommitted, sequenceNumber: event.SequenceNumber}
+	if header.EventType != replication.ANONYMOUS_GTID_EVENT {
+		gtid, err := event.GTIDNext()
+		if err != nil {
+			return err
+		}
+		txn.gtid = gtid
+	}
+	return a.beginTxn(txn)
+}
+
+func (a *binlogApplier) beginTxn(txn *binlogTxn) error {
+	if a.txn != nil {
+		tracelog.WarningLogger.Printf("Transaction %s is not committed, discarding it", a.txn)
+	}
+	if a.stopPoint != nil && txn.gtid != nil && a.stopPoint.after(txn.gtid) {
+		tracelog.InfoLogger.Printf("GTID target is reached before %s", txn)
+		a.txn = nil
+		a.stopped = true
+		return errApplyStopped
+	}
+	if a.gtidTarget != nil && txn.gtid != nil && a.gtidTarget.skips(txn.gtid) {
+		tracelog.DebugLogger.Printf("Skipping transaction %s", txn)
+		txn.skip = true
+	}
+	a.txn = txn
+	return nil
+}
+
+// currentTxn returns the transaction of the event, binlogs without GTIDs start transactions with BEGIN
+func (a *binlogApplier) currentTxn() *binlogTxn {
+	if a.txn == nil {
+		a.txn = &binlogTxn{}
+	}
+	return a.txn
+}
+
+func (a *binlogApplier) handleQuery(header *replication.EventHeader, event *replication.QueryEvent) error {
+	query := string(event.Query)
+	switch strings.ToUpper(strings.TrimSpace(query)) {
+	case "BEGIN":
+		a.currentTxn().transactional = true
+		return nil
+	case "COMMIT":
+		return a.commit()
+	case "ROLLBACK":
+		a.txn = nil
+		return nil
+	}
+	session, err := querySession(event.StatusVars)
+	if err != nil {
+		return fmt.Errorf("failed to parse the session of '%s': %w", query, err)
+	}
+	txn := a.currentTxn()
+	txn.statements = append(txn.statements, applyStatement{
+		query:     query,
+		text:      true,
+		schema:    string(event.Schema),
+		timestamp: header.Timestamp,
+		session:   session,
+	})
+	txn.exclusive = true
+	if !txn.transactional {
+		// DDL is committed implicitly
+		return a.commit()
+	}
+	return nil
+}
+
+func (a *binlogApplier) handleRows(event *replication.RowsEvent) error {
+	txn := a.currentTxn()
+	if txn.skip {
+		return nil
+	}
+	columns, err := a.getTableColumns(event.Table)
+	if err != nil {
+		return err
+	}
+	statements, err := buildRowStatements(event.Type(), event, columns)
+	if err != nil {
+		return err
+	}
+	session := rowSession(event.Flags)
+	for i := range statements {
+		statements[i].session = session
+	}
+	txn.statements = append(txn.statements, statements...)
+	txn.rows += len(statements)
+	table := string(event.Table.Schema) + "." + string(event.Table.Table)
+	if !slices.Contains(txn.tables, table) {
+		txn.tables = append(txn.tables, table)
+	}
+	return nil
+}
+
+func (a *binlogApplier) commit() error {
+	txn := a.txn
+	a.txn = nil
+	if txn == nil {
+		return nil
+	}
+	if txn.skip {
+		a.progress.skipped.Add(1)
+		return nil
+	}
+	if txn.exclusive {
+		// DDL may change the tables, it is applied before the next row events are read
+		clear(a.columns)
+	}
+	err := a.dispatch(txn)
+	if err == nil && txn.exclusive && a.scheduler.byTable() {
+		// DDL may change the foreign keys as well
+		err = a.loadTableGroups()
+	}
+	return err
+}
+
+func (a *binlogApplier) dispatch(txn *binlogTxn) error {
+	worker, waitBefore, waitAfter := a.scheduler.schedule(txn)
+	if waitBefore {
+		a.inflight.Wait()
+	}
+	a.inflight.Add(1)
+	select {
+	case a.workers[worker].txnCh <- txn:
+	case <-a.ctx.Done():
+		a.inflight.Done()
+		return context.Cause(a.ctx)
+	}
+	if waitAfter {
+		a.inflight.Wait()
+	}
+	return context.Cause(a.ctx)
+}
+
+// loadTableGroups reads the foreign keys from the server: the transactions changing the tables linked by them
+// are applied by the same worker, so the referenced rows are changed in the binlog order
+func (a *binlogApplier) loadTableGroups() error {
+	r, err := a.metaConn.Execute("SELECT CONSTRAINT_SCHEMA, TABLE_NAME, UNIQUE_CONSTRAINT_SCHEMA, REFERENCED_TABLE_NAME " +
+		"FROM information_schema.REFERENTIAL_CONSTRAINTS")
+	if err != nil {
+		return fmt.Errorf("failed to get foreign keys: %w", err)
+	}
+	defer r.Close()
+	links := make([][2]string, 0, r.RowNumber())
+	for i := range r.RowNumber() {
+		schema, _ := r.GetString(i, 0)
+		table, _ := r.GetString(i, 1)
+		referencedSchema, _ := r.GetString(i, 2)
+		referencedTable, _ := r.GetString(i, 3)
+		links = append(links, [2]string{schema + "." + table, referencedSchema + "." + referencedTable})
+	}
+	a.scheduler.tableGroups = groupLinkedTables(links)
+	return nil
+}
+
+// getTableColumns reads the columns of the table from the server, the binlog has only their types
+func (a *binlogApplier) getTableColumns(table *replication.TableMapEvent) (*tableColumns, error) {
+	key := string(table.Schema) + "." + string(table.Table)
+	if columns, ok := a.columns[key]; ok {
+		return columns, nil
+	}
+	r, err := a.metaConn.Execute("SELECT COLUMN_NAME, COLUMN_KEY, EXTRA FROM information_schema.COLUMNS "+
+		"WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", string(table.Schema), string(table.Table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns of %s: %w", key, err)
+	}
+	defer r.Close()
+	columns := &tableColumns{}
+	for i := range r.RowNumber() {
+		name, _ := r.GetString(i, 0)
+		columnKey, _ := r.GetString(i, 1)
+		extra, _ := r.GetString(i, 2)
+		if columnKey == "PRI" {
+			columns.primaryKey = append(columns.primaryKey, i)
+		}
+		columns.names = append(columns.names, name)
+		columns.generated = append(columns.generated, strings.Contains(strings.ToUpper(extra), "GENERATED"))
+	}
+	if len(columns.names) != int(table.ColumnCount) {
+		return nil, fmt.Errorf("table %s has %d columns, but the binlog has %d", key, len(columns.names), table.ColumnCount)
+	}
+	a.columns[key] = columns
+	return columns, nil
+}
+
+func (a *binlogApplier) close() {
+	for _, worker := range a.workers {
+		close(worker.txnCh)
+	}
+	a.running.Wait()
+	for _, worker := range a.workers {
+		worker.close()
+	}
+	a.metaConn.Close()
+	a.progress.stop()
+	a.cancel(nil)
+}
+
+// applyScheduler picks the worker for the transaction and decides if it has to wait for the previous ones
+type applyScheduler struct {
+	workers     int
+	parallelism string
+	// tableGroups maps the tables linked by foreign keys to the same group
+	tableGroups map[string]string
+	// groupStart is the sequence number of the first transaction after the last wait,
+	// all transactions before it are applied
+	groupStart int64
+	commitID   uint64
+	next       int
+}
+
+func (s *applyScheduler) reset() {
+	s.groupStart = 0
+	s.commitID = 0
+}
+
+func (s *applyScheduler) schedule(txn *binlogTxn) (worker int, waitBefore, waitAfter bool) {
+	if txn.exclusive {
+		return 0, true, true
+	}
+	if s.workers == 1 {
+		return 0, false, false
+	}
+	if s.parallelism == NativeParallelismTable {
+		if len(txn.tables) == 0 {
+			return 0, true, false
+		}
+		worker = s.tableWorker(txn.tables[0])
+		for _, table := range txn.tables[1:] {
+			if s.tableWorker(table) != worker {
+				return 0, true, true
+			}
+		}
+		return worker, false, false
+	}
+
+	s.next = (s.next + 1) % s.workers
+	switch {
+	case txn.sequenceNumber > 0:
+		// the transactions committed before lastCommitted are applied, so it doesn't conflict with the running ones
+		if txn.lastCommitted < s.groupStart {
+			return s.next, false, false
+		}
+		s.groupStart = txn.sequenceNumber
+	case txn.commitID > 0:
+		// MariaDB transactions of the same group commit don't conflict
+		if txn.commitID == s.commitID {
+			return s.next, false, false
+		}
+		s.commitID = txn.commitID
+	}
+	return s.next, true, false
+}
+
+// byTable tells whether the transactions are split between the workers by table
+func (s *applyScheduler) byTable() bool {
+	return s.parallelism == NativeParallelismTable && s.workers > 1
+}
+
+func (s *applyScheduler) tableWorker(table string) int {
+	if group, ok := s.tableGroups[table]; ok {
+		table = group
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(table))
+	return int(hash.Sum32() % uint32(s.workers))
+}
+
+// groupLinkedTables maps every linked table to the first table of its connected group in the sorted order
+func groupLinkedTables(links [][2]string) map[string]string {
+	parent := make(map[string]string)
+	var find func(table string) string
+	find = func(table string) string {
+		root, ok := parent[table]
+		if !ok || root == table {
+			parent[table] = table
+			return table
+		}
+		root = find(root)
+		parent[table] = root
+		return root
+	}
+	for _, link := range links {
+		first, second := find(link[0]), find(link[1])
+		if first > second {
+			first, second = second, first
+		}
+		parent[second] = first
+	}
+	groups := make(map[string]string, len(parent))
+	for table := range parent {
+		groups[table] = find(table)
+	}
+	return groups
+}
+
+// applyWorker applies transactions over its own connection
+type applyWorker struct {
+	conn      *client.Conn
+	stmts     map[string]*client.Stmt
+	txnCh     chan *binlogTxn
+	session   string
+	schema    string
+	timestamp uint32
+}
+
+func newApplyWorker(ctx context.Context) (*applyWorker, error) {
+	conn, err := getMySQLConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &applyWorker{
+		conn:  conn,
+		stmts: make(map[string]*client.Stmt),
+		txnCh: make(chan *binlogTxn, applyWorkerQueueSize),
+	}, nil
+}
+
+func (w *applyWorker) run(a *binlogApplier) {
+	defer a.running.Done()
+	for txn := range w.txnCh {
+		if a.ctx.Err() == nil {
+			if err := w.apply(txn); err != nil {
+				a.cancel(err)
+			} else {
+				a.progress.applied(txn)
+			}
+		}
+		a.inflight.Done()
+	}
+}
+
+func (w *applyWorker) apply(txn *binlogTxn) error {
+	err := w.applyStatements(txn)
+	if err != nil {
+		_, _ = w.conn.Execute("ROLLBACK")
+		return fmt.Errorf("failed to apply %s: %w", txn, err)
+	}
+	return nil
+}
+
+func (w *applyWorker) applyStatements(txn *binlogTxn) error {
+	switch gtid := txn.gtid.(type) {
+	case *mysql.MysqlGTIDSet:
+		// the server skips the transaction if it is already executed
+		if _, err := w.conn.Execute(fmt.Sprintf("SET GTID_NEXT = '%s'", gtid)); err != nil {
+			return err
+		}
+		defer func() { _, _ = w.conn.Execute("SET GTID_NEXT = 'AUTOMATIC'") }()
+	case *mysql.MariadbGTIDSet:
+		for _, one := range gtid.Sets {
+			_, err := w.conn.Execute(fmt.Sprintf("SET gtid_domain_id = %d, server_id = %d, gtid_seq_no = %d",
+				one.DomainID, one.ServerID, one.SequenceNumber))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if txn.transactional {
+		if _, err := w.conn.Execute("BEGIN"); err != nil {
+			return err
+		}
+	}
+	for _, statement := range txn.statements {
+		if err := w.execute(statement); err != nil {
+			return fmt.Errorf("'%s': %w", statement.query, err)
+		}
+	}
+	if txn.transactional {
+		if _, err := w.conn.Execute("COMMIT"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *applyWorker) execute(statement applyStatement) error {
+	if err := w.setSession(statement.session); err != nil {
+		return err
+	}
+	if !statement.text {
+		stmt, err := w.prepare(statement.query)
+		if err != nil {
+			return err
+		}
+		_, err = stmt.Execute(statement.args...)
+		return err
+	}
+
+	if statement.schema != "" && statement.schema != w.schema {
+		if err := w.conn.UseDB(statement.schema); err != nil {
+			return err
+		}
+		w.schema = statement.schema
+	}
+	if statement.timestamp != 0 && statement.timestamp != w.timestamp {
+		if _, err := w.conn.Execute(fmt.Sprintf("SET TIMESTAMP = %d", statement.timestamp)); err != nil {
+			return err
+		}
+		w.timestamp = statement.timestamp
+	}
+	_, err := w.conn.Execute(statement.query)
+	return err
+}
+
+// setSession sets the session variables of the statement, the statements without them
+// are executed in the session of the previous one
+func (w *applyWorker) setSession(session string) error {
+	if session == "" || w.session == session {
+		return nil
+	}
+	if _, err := w.conn.Execute(session); err != nil {
+		return fmt.Errorf("failed to set session variables: %w", err)
+	}
+	w.session = session
+	return nil
+}
+
+func (w *applyWorker) prepare(query string) (*client.Stmt, error) {
+	if stmt, ok := w.stmts[query]; ok {
+		return stmt, nil
+	}
+	if len(w.stmts) >= applyStmtCacheSize {
+		w.closeStmts()
+	}
+	stmt, err := w.conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	w.stmts[query] = stmt
+	return stmt, nil
+}
+
+func (w *applyWorker) closeStmts() {
+	for query, stmt := range w.stmts {
+		_ = stmt.Close()
+		delete(w.stmts, query)
+	}
+}
+
+func (w *applyWorker) close() {
+	w.closeStmts()
+	w.conn.Close()
+}
+
+// applyProgress periodically reports the position of the replay
+type applyProgress struct {
+	mu           sync.Mutex
+	binlog       string
+	position     atomic.Uint32
+	eventTS      atomic.Uint32
+	transactions atomic.Int64
+	rows         atomic.Int64
+	skipped      atomic.Int64
+	started      time.Time
+	done         chan struct{}
+	stopped      sync.WaitGroup
+}
+
+func newApplyProgress() *applyProgress {
+	p := &applyProgress{started: time.Now(), done: make(chan struct{})}
+	p.stopped.Add(1)
+	go func() {
+		defer p.stopped.Done()
+		ticker := time.NewTicker(applyProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.report()
+			case <-p.done:
+				p.report()
+				return
+			}
+		}
+	}()
+	return p
+}
+
+func (p *applyProgress) setBinlog(binlog string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.binlog = binlog
+}
+
+func (p *applyProgress) setPosition(position, eventTS uint32) {
+	p.position.Store(position)
+	if eventTS != 0 {
+		p.eventTS.Store(eventTS)
+	}
+}
+
+func (p *applyProgress) applied(txn *binlogTxn) {
+	p.transactions.Add(1)
+	p.rows.Add(int64(txn.rows))
+}
+
+func (p *applyProgress) report() {
+	p.mu.Lock()
+	binlog := p.binlog
+	p.mu.Unlock()
+	transactions := p.transactions.Load()
+	tracelog.InfoLogger.Printf("Applied %d transactions (%d rows, %.1f transactions/s), skipped %d, read %s up to %d, event time %s",
+		transactions, p.rows.Load(), float64(transactions)/time.Since(p.started).Seconds(), p.skipped.Load(),
+		binlog, p.position.Load(), time.Unix(int64(p.eventTS.Load()), 0).UTC().Format(time.RFC3339))
+}
+
+func (p *applyProgress) stop() {
+	close(p.done)
+	p.stopped.Wait()
+}
@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type scheduleResult struct {
+	worker     int
+	waitBefore bool
+	waitAfter  bool
+}
+
+func schedule(s *applyScheduler, txn *binlogTxn) scheduleResult {
+	worker, waitBefore, waitAfter := s.schedule(txn)
+	return scheduleResult{worker, waitBefore, waitAfter}
+}
+
+func TestApplyScheduler_LogicalClock(t *testing.T) {
+	s := &applyScheduler{workers: 2, parallelism: NativeParallelismLogicalClock}
+
+	// the first commit group waits for the previous binlog
+	assert.Equal(t, scheduleResult{1, true, false}, schedule(s, &binlogTxn{lastCommitted: 0, sequenceNumber: 1}))
+	assert.Equal(t, scheduleResult{0, false, false}, schedule(s, &binlogTxn{lastCommitted: 0, sequenceNumber: 2}))
+	// depends on the running transactions
+	assert.Equal(t, scheduleResult{1, true, false}, schedule(s, &binlogTxn{lastCommitted: 2, sequenceNumber: 3}))
+	assert.Equal(t, scheduleResult{0, false, false}, schedule(s, &binlogTxn{lastCommitted: 2, sequenceNumber: 4}))
+	// DDL
+	assert.Equal(t, scheduleResult{0, true, true}, schedule(s, &binlogTxn{exclusive: true, lastCommitted: 4, sequenceNumber: 5}))
+	// no logical clock
+	assert.Equal(t, scheduleResult{1, true, false}, schedule(s, &binlogTxn{}))
+
+	s.reset()
+	assert.Equal(t, scheduleResult{0, true, false}, schedule(s, &binlogTxn{commitID: 7}))
+	assert.Equal(t, scheduleResult{1, false, false}, schedule(s, &binlogTxn{commitID: 7}))
+	assert.Equal(t, scheduleResult{0, true, false}, schedule(s, &binlogTxn{commitID: 8}))
+}
+
+func TestApplyScheduler_Table(t *testing.T) {
+	s := &applyScheduler{workers: 4, parallelism: NativeParallelismTable}
+
+	worker := s.tableWorker("shop.orders")
+	assert.Equal(t, scheduleResult{worker, false, false}, schedule(s, &binlogTxn{tables: []string{"shop.orders"}}))
+	assert.Equal(t, scheduleResult{worker, false, false}, schedule(s, &binlogTxn{tables: []string{"shop.orders"}}))
+
+	other := "shop.items"
+	for i := 0; s.tableWorker(other) == worker; i++ {
+		other = "shop.items" + string(rune('a'+i))
+	}
+	assert.Equal(t, scheduleResult{0, true, true}, schedule(s, &binlogTxn{tables: []string{"shop.orders", other}}))
+	assert.Equal(t, scheduleResult{0, true, true}, schedule(s, &binlogTxn{exclusive: true}))
+}
+
+func TestApplyScheduler_TableGroups(t *testing.T) {
+	s := &applyScheduler{workers: 4, parallelism: NativeParallelismTable}
+	s.tableGroups = groupLinkedTables([][2]string{{"shop.orders", "shop.customers"}})
+
+	worker := s.tableWorker("shop.customers")
+	assert.Equal(t, worker, s.tableWorker("shop.orders"))
+	assert.Equal(t, scheduleResult{worker, false, false}, schedule(s, &binlogTxn{tables: []string{"shop.orders", "shop.customers"}}))
+}
+
+func TestGroupLinkedTables(t *testing.T) {
+	groups := groupLinkedTables([][2]string{
+		{"shop.orders", "shop.customers"},
+		{"shop.items", "shop.orders"},
+		{"shop.items", "shop.products"},
+		{"blog.comments", "blog.posts"},
+		{"blog.posts", "blog.posts"},
+	})
+	assert.Equal(t, map[string]string{
+		"shop.customers": "shop.customers",
+		"shop.orders":    "shop.customers",
+		"shop.items":     "shop.customers",
+		"shop.products":  "shop.customers",
+		"blog.comments":  "blog.comments",
+		"blog.posts":     "blog.comments",
+	}, groups)
+}
+
+func TestApplyScheduler_SingleWorker(t *testing.T) {
+	s := &applyScheduler{workers: 1, parallelism: NativeParallelismTable}
+	assert.Equal(t, scheduleResult{0, false, false}, schedule(s, &binlogTxn{tables: []string{"a.b", "c.d"}}))
+	assert.Equal(t, scheduleResult{0, true, true}, schedule(s, &binlogTxn{exclusive: true}))
+}
@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path"
 	"path/filepath"
@@ -15,15 +16,32 @@ import (
 type indexHandler struct {
 	dstDir  string
 	binlogs []string
+	// stopPoint cuts the binlogs at the GTID target
+	stopPoint *gtidStopPoint
+	stopped   bool
 }
 
-func newIndexHandler(dstDir string) *indexHandler {
+func newIndexHandler(dstDir string, gtidTarget *gtidReplayTarget) *indexHandler {
 	ih := new(indexHandler)
 	ih.dstDir = dstDir
+	if gtidTarget != nil {
+		ih.stopPoint = gtidTarget.newStopPoint()
+	}
 	return ih
 }
 
 func (ih *indexHandler) handleBinlog(binlogPath string) error {
+	if ih.stopped {
+		tracelog.InfoLogger.Printf("GTID target is reached, skipping %s", path.Base(binlogPath))
+		return os.Remove(binlogPath)
+	}
+	if ih.stopPoint != nil {
+		stopped, err := truncateBinlogAtStopPoint(binlogPath, ih.stopPoint)
+		if err != nil {
+			return fmt.Errorf("failed to find the GTID stop point in %s: %w", path.Base(binlogPath), err)
+		}
+		ih.stopped = stopped
+	}
 	ih.binlogs = append(ih.binlogs, path.Base(binlogPath))
 	return nil
 }
@@ -43,19 +61,28 @@ func (ih *indexHandler) createIndexFile() error {
 	return nil
 }
 
-func HandleBinlogFetch(ctx context.Context, folder storage.Folder, backupName string, untilTS string, untilBinlogLastModifiedTS string) {
+func HandleBinlogFetch(ctx context.Context, folder storage.Folder, backupName string, untilTS string,
+	untilBinlogLastModifiedTS string, untilGTID string, excludeGTID string) {
 	dstDir, err := internal.GetLogsDstSettings(conf.MysqlBinlogDstSetting)
 	tracelog.ErrorLogger.FatalOnError(err)
 
 	startTS, endTS, endBinlogTS, err := getTimestamps(ctx, folder, backupName, untilTS, untilBinlogLastModifiedTS)
 	tracelog.ErrorLogger.FatalOnError(err)
 
-	handler := newIndexHandler(dstDir)
+	gtidTarget, err := newGtidReplayTarget(untilGTID, excludeGTID)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	handler := newIndexHandler(dstDir, gtidTarget)
 
 	tracelog.InfoLogger.Printf("Fetching binlogs since %s until %s", startTS, endTS)
-	err = fetchLogs(ctx, folder, dstDir, startTS, endTS, endBinlogTS, handler)
+	err = fetchLogs(ctx, folder, dstDir, startTS, endTS, endBinlogTS, gtidTarget, handler)
 	tracelog.ErrorLogger.FatalfOnError("Failed to fetch binlogs: %v", err)
 
 	err = handler.createIndexFile()
 	tracelog.ErrorLogger.FatalfOnError("Failed to create binlog index file: %v", err)
+
+	if gtidTarget != nil && gtidTarget.excludedGTIDs() != "" {
+		err = os.WriteFile(filepath.Join(dstDir, "binlogs_exclude_gtids"), []byte(gtidTarget.excludedGTIDs()+"\n"), 0644)
+		tracelog.ErrorLogger.FatalfOnError("Failed to create binlog exclude GTIDs file: %v", err)
+	}
 }
@@ -0,0 +1,238 @@
+package mysql
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/google/uuid"
+	"github.com/wal-g/tracelog"
+)
+
+const (
+	binlogExcludeGTIDsEnv = "WALG_MYSQL_BINLOG_EXCLUDE_GTIDS"
+	binlogStopGTIDEnv     = "WALG_MYSQL_BINLOG_STOP_GTID"
+)
+
+// gtidReplayTarget bounds the binlog replay by GTIDs: the replay stops right before the first transaction
+// after the untilGTID transactions and the excludeGTID transactions are skipped. Timestamps are ambiguous
+// when many transactions commit in the same second, GTIDs point to the exact transaction.
+//
+// The target is passed once the last transaction of every server UUID (and tag) of the MySQL GTID set,
+// or the position of every domain of the MariaDB GTID list, is executed: "uuid:100" and "uuid:1-100"
+// are the same target. The transactions of other servers logged before that are replayed,
+// the ones after it are not, like START REPLICA UNTIL SQL_AFTER_GTIDS does.
+// wal-g cuts the binlog at the stop point itself, so the replay command does not need to stop at the GTID.
+//
+// MariaDB binlog tools can not skip separate transactions, so excludeGTID is supported only for MySQL.
+type gtidReplayTarget struct {
+	flavor  string
+	until   mysql.GTIDSet
+	exclude mysql.GTIDSet
+}
+
+func newGtidReplayTarget(untilGTID, excludeGTID string) (*gtidReplayTarget, error) {
+	if untilGTID == "" && excludeGTID == "" {
+		return nil, nil
+	}
+	target := &gtidReplayTarget{flavor: detectGTIDFlavor(untilGTID + excludeGTID)}
+	var err error
+	if untilGTID != "" {
+		target.until, err = mysql.ParseGTIDSet(target.flavor, untilGTID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse until GTID set '%s': %w", untilGTID, err)
+		}
+	}
+	if excludeGTID != "" {
+		if target.flavor != mysql.MySQLFlavor {
+			return nil, fmt.Errorf("excluding transactions is supported only for MySQL GTID sets, got '%s'", excludeGTID)
+		}
+		target.exclude, err = mysql.ParseGTIDSet(target.flavor, excludeGTID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse exclude GTID set '%s': %w", excludeGTID, err)
+		}
+	}
+	return target, nil
+}
+
+// detectGTIDFlavor distinguishes MySQL "uuid:1-5" sets from MariaDB "0-1-1011" lists
+func detectGTIDFlavor(gtid string) string {
+	if strings.Contains(gtid, ":") {
+		return mysql.MySQLFlavor
+	}
+	return mysql.MariaDBFlavor
+}
+
+// reached checks if the target transactions were executed before the binlog with the previous GTIDs,
+// so the binlog and the following ones are not needed
+func (t *gtidReplayTarget) reached(previousGTIDs mysql.GTIDSet) bool {
+	if t.until == nil || previousGTIDs == nil {
+		return false
+	}
+	stopPoint := t.newStopPoint()
+	stopPoint.passPrevious(previousGTIDs)
+	return stopPoint.passed()
+}
+
+// skips checks if the transaction with the GTID is excluded
+func (t *gtidReplayTarget) skips(gtid mysql.GTIDSet) bool {
+	return t.exclude != nil && t.exclude.Contain(gtid)
+}
+
+// excludedGTIDs returns the MySQL GTID set to skip, it is empty for MariaDB
+func (t *gtidReplayTarget) excludedGTIDs() string {
+	if t.exclude == nil {
+		return ""
+	}
+	return t.exclude.String()
+}
+
+// env returns the variables passed to the replay command
+func (t *gtidReplayTarget) env() []string {
+	env := []string{fmt.Sprintf("%s=%s", binlogExcludeGTIDsEnv, t.excludedGTIDs())}
+	if t.until != nil {
+		env = append(env, fmt.Sprintf("%s=%s", binlogStopGTIDEnv, t.until.String()))
+	}
+	return env
+}
+
+// gtidStopPoint follows the replayed transactions to find the first one after the target
+type gtidStopPoint struct {
+	target *gtidReplayTarget
+	// pending are the last target transaction numbers of the server UUIDs and tags (MySQL)
+	// or the target sequence numbers of the domains (MariaDB) which are not executed yet
+	pending map[string]int64
+}
+
+func (t *gtidReplayTarget) newStopPoint() *gtidStopPoint {
+	pending := make(map[string]int64)
+	switch until := t.until.(type) {
+	case *mysql.MysqlGTIDSet:
+		for sid, tags := range *until {
+			for tag, intervals := range tags {
+				pending[mysqlGTIDKey(sid, tag)] = lastGno(intervals)
+			}
+		}
+	case *mysql.MariadbGTIDSet:
+		for domain, gtid := range until.Sets {
+			pending[mariadbGTIDKey(domain)] = int64(gtid.SequenceNumber)
+		}
+	}
+	return &gtidStopPoint{target: t, pending: pending}
+}
+
+func mysqlGTIDKey(sid uuid.UUID, tag mysql.Tag) string {
+	return fmt.Sprintf("%s:%v", sid, tag)
+}
+
+func mariadbGTIDKey(domain uint32) string {
+	return strconv.FormatUint(uint64(domain), 10)
+}
+
+// passed checks if every target transaction is executed
+func (p *gtidStopPoint) passed() bool {
+	return len(p.pending) == 0
+}
+
+// passPrevious records the target transactions executed before the binlog
+func (p *gtidStopPoint) passPrevious(previousGTIDs mysql.GTIDSet) {
+	switch previous := previousGTIDs.(type) {
+	case *mysql.MysqlGTIDSet:
+		for sid, tags := range *previous {
+			for tag, intervals := range tags {
+				p.pass(mysqlGTIDKey(sid, tag), lastGno(intervals))
+			}
+		}
+	case *mysql.MariadbGTIDSet:
+		for domain, gtid := range previous.Sets {
+			p.pass(mariadbGTIDKey(domain), int64(gtid.SequenceNumber))
+		}
+	}
+}
+
+// after checks if the transaction comes after the target, the transaction is recorded as executed otherwise
+func (p *gtidStopPoint) after(gtid mysql.GTIDSet) bool {
+	if p.target.until == nil {
+		return false
+	}
+	if p.passed() {
+		return true
+	}
+	p.passPrevious(gtid)
+	return false
+}
+
+func (p *gtidStopPoint) pass(key string, executed int64) {
+	if target, ok := p.pending[key]; ok && executed >= target {
+		delete(p.pending, key)
+	}
+}
+
+// eventGTIDs returns the GTID of the transaction started by the event
+// or the GTIDs executed before the binlog if the event lists them
+func eventGTIDs(event *replication.BinlogEvent) (transaction mysql.GTIDSet, previous mysql.GTIDSet, err error) {
+	switch e := event.Event.(type) {
+	case *replication.GTIDEvent:
+		if event.Header.EventType == replication.ANONYMOUS_GTID_EVENT {
+			return nil, nil, nil
+		}
+		transaction, err = e.GTIDNext()
+	case *replication.GtidTaggedLogEvent:
+		transaction, err = e.GTIDNext()
+	case *replication.MariadbGTIDEvent:
+		transaction, err = e.GTIDNext()
+	case *replication.PreviousGTIDsEvent:
+		previous, err = mysql.ParseMysqlGTIDSet(e.GTIDSets)
+	case *replication.MariadbGTIDListEvent:
+		gtids := make([]string, 0, len(e.GTIDs))
+		for _, gtid := range e.GTIDs {
+			gtids = append(gtids, gtid.String())
+		}
+		previous, err = mysql.ParseMariadbGTIDSet(strings.Join(gtids, ","))
+	}
+	return transaction, previous, err
+}
+
+// truncateBinlogAtStopPoint cuts the binlog right before the first transaction after the GTID target,
+// so the transactions after it are not replayed by the replay command. It reports if the binlog is cut.
+func truncateBinlogAtStopPoint(binlogPath string, stopPoint *gtidStopPoint) (bool, error) {
+	parser := replication.NewBinlogParser()
+	parser.SetFlavor(stopPoint.target.flavor)
+	parser.SetVerifyChecksum(false)
+	// only the GTIDs are needed
+	parser.SetRowsEventDecodeFunc(func(*replication.RowsEvent, []byte) error { return nil })
+
+	stopOffset := int64(-1)
+	err := parser.ParseFile(binlogPath, int64(len(replication.BinLogFileHeader)), func(event *replication.BinlogEvent) error {
+		transaction, previous, err := eventGTIDs(event)
+		if err != nil {
+			return err
+		}
+		if previous != nil {
+			stopPoint.passPrevious(previous)
+		}
+		if transaction != nil && stopPoint.after(transaction) {
+			stopOffset = int64(event.Header.LogPos) - int64(event.Header.EventSize)
+			return errApplyStopped
+		}
+		return nil
+	})
+	if stopOffset < 0 {
+		return false, err
+	}
+	tracelog.InfoLogger.Printf("GTID target is reached in %s at position %d", path.Base(binlogPath), stopOffset)
+	return true, os.Truncate(binlogPath, stopOffset)
+}
+
+// lastGno returns the last transaction number of the half-open intervals
+func lastGno(intervals mysql.IntervalSlice) int64 {
+	var last int64
+	for _, interval := range intervals {
+		last = max(last, interval.Stop-1)
+	}
+	return last
+}
@@ -0,0 +1,166 @@
+package mysql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testUUID      = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	testOtherUUID = "4e11fa47-71ca-11e1-9e33-c80aa9429562"
+)
+
+func TestNewGtidReplayTarget(t *testing.T) {
+	target, err := newGtidReplayTarget("", "")
+	require.NoError(t, err)
+	assert.Nil(t, target)
+
+	target, err = newGtidReplayTarget(testUUID+":100", "")
+	require.NoError(t, err)
+	assert.Equal(t, mysql.MySQLFlavor, target.flavor)
+
+	target, err = newGtidReplayTarget("0-1-100", "")
+	require.NoError(t, err)
+	assert.Equal(t, mysql.MariaDBFlavor, target.flavor)
+
+	_, err = newGtidReplayTarget("0-1-100", "0-1-50")
+	assert.Error(t, err)
+
+	_, err = newGtidReplayTarget("", "not-a-gtid:abc")
+	assert.Error(t, err)
+}
+
+func TestGtidReplayTarget_ExcludedGTIDs(t *testing.T) {
+	target, err := newGtidReplayTarget(testUUID+":100", testUUID+":42,"+testOtherUUID+":7")
+	require.NoError(t, err)
+	assert.Equal(t, testUUID+":42,"+testOtherUUID+":7", target.excludedGTIDs())
+	assert.Equal(t, []string{
+		"WALG_MYSQL_BINLOG_EXCLUDE_GTIDS=" + testUUID + ":42," + testOtherUUID + ":7",
+		"WALG_MYSQL_BINLOG_STOP_GTID=" + testUUID + ":100",
+	}, target.env())
+
+	target, err = newGtidReplayTarget("0-1-100", "")
+	require.NoError(t, err)
+	assert.Equal(t, "", target.excludedGTIDs())
+	assert.Equal(t, []string{"WALG_MYSQL_BINLOG_EXCLUDE_GTIDS=", "WALG_MYSQL_BINLOG_STOP_GTID=0-1-100"}, target.env())
+}
+
+func TestGtidReplayTarget_Reached(t *testing.T) {
+	target, err := newGtidReplayTarget(testUUID+":100", "")
+	require.NoError(t, err)
+
+	previous, err := mysql.ParseMysqlGTIDSet(testUUID + ":1-99," + testOtherUUID + ":1-5")
+	require.NoError(t, err)
+	assert.False(t, target.reached(previous))
+
+	previous, err = mysql.ParseMysqlGTIDSet(testUUID + ":1-50:52-100")
+	require.NoError(t, err)
+	assert.True(t, target.reached(previous))
+
+	previous, err = mysql.ParseMysqlGTIDSet(testOtherUUID + ":1-500")
+	require.NoError(t, err)
+	assert.False(t, target.reached(previous))
+
+	target, err = newGtidReplayTarget("0-1-100", "")
+	require.NoError(t, err)
+	previous, err = mysql.ParseMariadbGTIDSet("0-1-99")
+	require.NoError(t, err)
+	assert.False(t, target.reached(previous))
+	previous, err = mysql.ParseMariadbGTIDSet("0-1-100,1-2-5")
+	require.NoError(t, err)
+	assert.True(t, target.reached(previous))
+
+	target, err = newGtidReplayTarget("", testUUID+":42")
+	require.NoError(t, err)
+	assert.False(t, target.reached(previous))
+}
+
+func TestGtidReplayTarget_Skips(t *testing.T) {
+	target, err := newGtidReplayTarget(testUUID+":100", testUUID+":42")
+	require.NoError(t, err)
+	for gtid, expected := range map[string]bool{
+		testUUID + ":41":       false,
+		testUUID + ":42":       true,
+		testUUID + ":100":      false,
+		testUUID + ":101":      false,
+		testOtherUUID + ":101": false,
+	} {
+		one, err := mysql.ParseMysqlGTIDSet(gtid)
+		require.NoError(t, err)
+		assert.Equal(t, expected, target.skips(one), gtid)
+	}
+
+	target, err = newGtidReplayTarget("0-1-100", "")
+	require.NoError(t, err)
+	one, err := mysql.ParseMariadbGTIDSet("0-2-101")
+	require.NoError(t, err)
+	assert.False(t, target.skips(one))
+}
+
+func TestGtidStopPoint_StopsWholeReplay(t *testing.T) {
+	target, err := newGtidReplayTarget(testUUID+":100,"+testOtherUUID+":5", "")
+	require.NoError(t, err)
+	stopPoint := target.newStopPoint()
+
+	previous, err := mysql.ParseMysqlGTIDSet(testUUID + ":1-98," + testOtherUUID + ":1-3")
+	require.NoError(t, err)
+	stopPoint.passPrevious(previous)
+
+	for _, gtid := range []string{testUUID + ":99", testOtherUUID + ":4", testUUID + ":100", testOtherUUID + ":5"} {
+		one, err := mysql.ParseMysqlGTIDSet(gtid)
+		require.NoError(t, err)
+		assert.False(t, stopPoint.after(one), gtid)
+	}
+	// the transactions of every server are stopped after the target
+	for _, gtid := range []string{"5e11fa47-71ca-11e1-9e33-c80aa9429562:1", testUUID + ":101"} {
+		one, err := mysql.ParseMysqlGTIDSet(gtid)
+		require.NoError(t, err)
+		assert.True(t, stopPoint.after(one), gtid)
+	}
+}
+
+func TestGtidStopPoint_MariaDB(t *testing.T) {
+	target, err := newGtidReplayTarget("0-1-100", "")
+	require.NoError(t, err)
+	stopPoint := target.newStopPoint()
+
+	for gtid, expected := range map[string]bool{
+		"1-1-500": false,
+		"0-1-100": false,
+		"1-1-501": true,
+	} {
+		one, err := mysql.ParseMariadbGTIDSet(gtid)
+		require.NoError(t, err)
+		assert.Equal(t, expected, stopPoint.after(one), gtid)
+	}
+
+	stopPoint = target.newStopPoint()
+	previous, err := mysql.ParseMariadbGTIDSet("0-1-100")
+	require.NoError(t, err)
+	stopPoint.passPrevious(previous)
+	one, err := mysql.ParseMariadbGTIDSet("0-1-101")
+	require.NoError(t, err)
+	assert.True(t, stopPoint.after(one))
+}
+
+func TestTruncateBinlogAtStopPoint_KeepsBinlogBeforeTarget(t *testing.T) {
+	data, err := os.ReadFile(testFilenameSmall)
+	require.NoError(t, err)
+	binlogPath := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	require.NoError(t, os.WriteFile(binlogPath, data, 0600))
+
+	target, err := newGtidReplayTarget(testUUID+":100", "")
+	require.NoError(t, err)
+	stopped, err := truncateBinlogAtStopPoint(binlogPath, target.newStopPoint())
+	require.NoError(t, err)
+	assert.False(t, stopped)
+
+	truncated, err := os.ReadFile(binlogPath)
+	require.NoError(t, err)
+	assert.Equal(t, data, truncated)
+}
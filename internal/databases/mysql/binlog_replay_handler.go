@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -17,15 +18,19 @@ import (
 
 const binlogFetchAhead = 2
 
+type binlogReplayer interface {
+	replayLog(ctx context.Context, binlogPath string) error
+}
+
 type replayHandler struct {
-	logCh chan string
-	errCh chan error
-	endTS string
+	logCh    chan string
+	errCh    chan error
+	replayer binlogReplayer
 }
 
-func newReplayHandler(ctx context.Context, endTS time.Time) *replayHandler {
+func newReplayHandler(ctx context.Context, replayer binlogReplayer) *replayHandler {
 	rh := new(replayHandler)
-	rh.endTS = endTS.Local().Format(TimeMysqlFormat)
+	rh.replayer = replayer
 	rh.logCh = make(chan string, binlogFetchAhead)
 	rh.errCh = make(chan error, 1)
 	go rh.replayLogs(ctx)
@@ -35,7 +40,7 @@ func newReplayHandler(ctx context.Context, endTS time.Time) *replayHandler {
 func (rh *replayHandler) replayLogs(ctx context.Context) {
 	for binlogPath := range rh.logCh {
 		tracelog.InfoLogger.Printf("replaying %s ...", path.Base(binlogPath))
-		err := rh.replayLog(ctx, binlogPath)
+		err := rh.replayer.replayLog(ctx, binlogPath)
 		os.Remove(binlogPath)
 		if err != nil {
 			tracelog.ErrorLogger.Printf("failed to replay %s: %v", path.Base(binlogPath), err)
@@ -46,7 +51,44 @@ func (rh *replayHandler) replayLogs(ctx context.Context) {
 	close(rh.errCh)
 }
 
-func (rh *replayHandler) replayLog(ctx context.Context, binlogPath string) error {
+// commandReplayer passes the binlogs to WALG_MYSQL_BINLOG_REPLAY_COMMAND.
+// The binlogs are cut at the GTID stop point before the command gets them.
+type commandReplayer struct {
+	endTS      string
+	gtidTarget *gtidReplayTarget
+	stopPoint  *gtidStopPoint
+	stopped    bool
+}
+
+func newCommandReplayer(endTS time.Time, gtidTarget *gtidReplayTarget) (*commandReplayer, error) {
+	cr := &commandReplayer{endTS: endTS.Local().Format(TimeMysqlFormat), gtidTarget: gtidTarget}
+	if gtidTarget == nil {
+		return cr, nil
+	}
+	// only the command can skip the separate transactions
+	if gtidTarget.exclude != nil {
+		command, _ := conf.GetSetting(conf.MysqlBinlogReplayCmd)
+		if !strings.Contains(command, binlogExcludeGTIDsEnv) {
+			return nil, fmt.Errorf("%s does not use %s, the excluded GTIDs would be replayed",
+				conf.MysqlBinlogReplayCmd, binlogExcludeGTIDsEnv)
+		}
+	}
+	cr.stopPoint = gtidTarget.newStopPoint()
+	return cr, nil
+}
+
+func (cr *commandReplayer) replayLog(ctx context.Context, binlogPath string) error {
+	if cr.stopped {
+		tracelog.InfoLogger.Printf("GTID target is reached, skipping %s", path.Base(binlogPath))
+		return nil
+	}
+	if cr.stopPoint != nil {
+		stopped, err := truncateBinlogAtStopPoint(binlogPath, cr.stopPoint)
+		if err != nil {
+			return fmt.Errorf("failed to find the GTID stop point in %s: %w", path.Base(binlogPath), err)
+		}
+		cr.stopped = stopped
+	}
 	cmd, err := internal.GetCommandSettingContext(ctx, conf.MysqlBinlogReplayCmd)
 	if err != nil {
 		return err
@@ -54,7 +96,10 @@ func (rh *replayHandler) replayLog(ctx context.Context, binlogPath string) error
 	env := os.Environ()
 	env = append(env,
 		fmt.Sprintf("%s=%s", "WALG_MYSQL_CURRENT_BINLOG", binlogPath),
-		fmt.Sprintf("%s=%s", "WALG_MYSQL_BINLOG_END_TS", rh.endTS))
+		fmt.Sprintf("%s=%s", "WALG_MYSQL_BINLOG_END_TS", cr.endTS))
+	if cr.gtidTarget != nil {
+		env = append(env, cr.gtidTarget.env()...)
+	}
 	cmd.Env = env
 	return cmd.Run()
 }
@@ -73,17 +118,33 @@ func (rh *replayHandler) handleBinlog(binlogPath string) error {
 	}
 }
 
-func HandleBinlogReplay(ctx context.Context, folder storage.Folder, backupName string, untilTS string, untilBinlogLastModifiedTS string) {
+// HandleBinlogReplay replays the binlogs with WALG_MYSQL_BINLOG_REPLAY_COMMAND,
+// or in-process when the native replay options are set
+func HandleBinlogReplay(ctx context.Context, folder storage.Folder, backupName string, untilTS string,
+	untilBinlogLastModifiedTS string, untilGTID string, excludeGTID string, nativeOptions *NativeReplayOptions) {
 	dstDir, err := internal.GetLogsDstSettings(conf.MysqlBinlogDstSetting)
 	tracelog.ErrorLogger.FatalOnError(err)
 
 	startTS, endTS, endBinlogTS, err := getTimestamps(ctx, folder, backupName, untilTS, untilBinlogLastModifiedTS)
 	tracelog.ErrorLogger.FatalOnError(err)
 
-	handler := newReplayHandler(ctx, endTS)
+	gtidTarget, err := newGtidReplayTarget(untilGTID, excludeGTID)
+	tracelog.ErrorLogger.FatalOnError(err)
+
+	var replayer binlogReplayer
+	if nativeOptions == nil {
+		replayer, err = newCommandReplayer(endTS, gtidTarget)
+		tracelog.ErrorLogger.FatalOnError(err)
+	} else {
+		applier, err := newBinlogApplier(ctx, *nativeOptions, endTS, gtidTarget)
+		tracelog.ErrorLogger.FatalfOnError("Failed to start binlog applier: %v", err)
+		defer applier.close()
+		replayer = applier
+	}
+	handler := newReplayHandler(ctx, replayer)
 
 	tracelog.InfoLogger.Printf("Fetching binlogs since %s until %s", startTS, endTS)
-	err = fetchLogs(ctx, folder, dstDir, startTS, endTS, endBinlogTS, handler)
+	err = fetchLogs(ctx, folder, dstDir, startTS, endTS, endBinlogTS, gtidTarget, handler)
 	tracelog.ErrorLogger.FatalfOnError("Failed to fetch binlogs: %v", err)
 
 	err = handler.wait()
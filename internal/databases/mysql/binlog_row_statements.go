@@ -0,0 +1,122 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// tableColumns describes the columns of the table in the order of the row images
+type tableColumns struct {
+	names      []string
+	primaryKey []int
+	// generated columns are computed by the server and can't be set
+	generated []bool
+}
+
+// buildRowStatements turns the row images of the event into statements changing one row each.
+// The rows are matched by the primary key when the before image has it, otherwise by all logged columns.
+func buildRowStatements(action replication.EnumRowsEventType, event *replication.RowsEvent,
+	columns *tableColumns) ([]applyStatement, error) {
+	table := quoteIdentifier(string(event.Table.Schema)) + "." + quoteIdentifier(string(event.Table.Table))
+	var statements []applyStatement
+	switch action {
+	case replication.EnumRowsEventTypeInsert:
+		for _, row := range event.Rows {
+			names, placeholders, args := rowAssignments(event.Table, columns, event.ColumnBitmap1, row)
+			statements = append(statements, applyStatement{
+				query: fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(names, ", "), strings.Join(placeholders, ", ")),
+				args:  args,
+			})
+		}
+	case replication.EnumRowsEventTypeDelete:
+		for _, row := range event.Rows {
+			where, args := rowCondition(event.Table, columns, event.ColumnBitmap1, row)
+			statements = append(statements, applyStatement{
+				query: fmt.Sprintf("DELETE FROM %s WHERE %s LIMIT 1", table, where),
+				args:  args,
+			})
+		}
+	case replication.EnumRowsEventTypeUpdate:
+		if len(event.Rows)%2 != 0 {
+			return nil, fmt.Errorf("update of %s has %d row images, expected pairs of before and after images", table, len(event.Rows))
+		}
+		for i := 0; i < len(event.Rows); i += 2 {
+			names, placeholders, args := rowAssignments(event.Table, columns, event.ColumnBitmap2, event.Rows[i+1])
+			assignments := make([]string, len(names))
+			for j := range names {
+				assignments[j] = names[j] + " = " + placeholders[j]
+			}
+			where, whereArgs := rowCondition(event.Table, columns, event.ColumnBitmap1, event.Rows[i])
+			statements = append(statements, applyStatement{
+				query: fmt.Sprintf("UPDATE %s SET %s WHERE %s LIMIT 1", table, strings.Join(assignments, ", "), where),
+				args:  append(args, whereArgs...),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported rows event for %s, partial JSON updates (binlog_row_value_options) can't be applied", table)
+	}
+	return statements, nil
+}
+
+// rowAssignments returns the logged columns of the row image which can be set
+func rowAssignments(table *replication.TableMapEvent, columns *tableColumns, bitmap []byte,
+	row []any) (names, placeholders []string, args []any) {
+	for i, name := range columns.names {
+		if columns.generated[i] || !isColumnLogged(bitmap, i) {
+			continue
+		}
+		names = append(names, quoteIdentifier(name))
+		placeholders = append(placeholders, columnPlaceholder(table, i))
+		args = append(args, row[i])
+	}
+	return names, placeholders, args
+}
+
+func rowCondition(table *replication.TableMapEvent, columns *tableColumns, bitmap []byte, row []any) (string, []any) {
+	keys := columns.primaryKey
+	for _, key := range keys {
+		if !isColumnLogged(bitmap, key) {
+			keys = nil
+			break
+		}
+	}
+	if len(keys) == 0 {
+		keys = nil
+		for i := range columns.names {
+			if !columns.generated[i] && isColumnLogged(bitmap, i) {
+				keys = append(keys, i)
+			}
+		}
+	}
+	conditions := make([]string, 0, len(keys))
+	args := make([]any, 0, len(keys))
+	for _, key := range keys {
+		// <=> matches NULLs as well
+		conditions = append(conditions, quoteIdentifier(columns.names[key])+" <=> "+columnPlaceholder(table, key))
+		args = append(args, row[key])
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// columnPlaceholder converts JSON values, which are decoded as text, back to JSON
+func columnPlaceholder(table *replication.TableMapEvent, i int) string {
+	if i < len(table.ColumnType) && table.ColumnType[i] == mysql.MYSQL_TYPE_JSON {
+		return "CAST(CONVERT(? USING utf8mb4) AS JSON)"
+	}
+	return "?"
+}
+
+// isColumnLogged checks the column bitmap of the row image, it is empty for the full row image
+func isColumnLogged(bitmap []byte, i int) bool {
+	if len(bitmap) == 0 {
+		return true
+	}
+	return bitmap[i/8]&(1<<(uint(i)%8)) != 0
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
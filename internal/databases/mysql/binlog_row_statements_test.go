@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTableMap() *replication.TableMapEvent {
+	return &replication.TableMapEvent{
+		Schema:      []byte("shop"),
+		Table:       []byte("orders"),
+		ColumnCount: 4,
+		ColumnType:  []byte{mysql.MYSQL_TYPE_LONG, mysql.MYSQL_TYPE_VARCHAR, mysql.MYSQL_TYPE_JSON, mysql.MYSQL_TYPE_LONG},
+	}
+}
+
+func testTableColumns() *tableColumns {
+	return &tableColumns{
+		names:      []string{"id", "name", "doc", "total"},
+		primaryKey: []int{0},
+		generated:  []bool{false, false, false, true},
+	}
+}
+
+func TestBuildRowStatements_Insert(t *testing.T) {
+	event := &replication.RowsEvent{
+		Table: testTableMap(),
+		Rows:  [][]any{{int32(1), "a", `{"k": 1}`, int32(10)}, {int32(2), nil, nil, nil}},
+	}
+	statements, err := buildRowStatements(replication.EnumRowsEventTypeInsert, event, testTableColumns())
+	require.NoError(t, err)
+	require.Len(t, statements, 2)
+	assert.Equal(t, "INSERT INTO `shop`.`orders` (`id`, `name`, `doc`) VALUES (?, ?, CAST(CONVERT(? USING utf8mb4) AS JSON))",
+		statements[0].query)
+	assert.Equal(t, []any{int32(1), "a", `{"k": 1}`}, statements[0].args)
+	assert.Equal(t, []any{int32(2), nil, nil}, statements[1].args)
+	assert.False(t, statements[0].text)
+}
+
+func TestBuildRowStatements_Update(t *testing.T) {
+	event := &replication.RowsEvent{
+		Table: testTableMap(),
+		// minimal row image: the primary key before, the changed column after
+		ColumnBitmap1: []byte{0b0001},
+		ColumnBitmap2: []byte{0b0010},
+		Rows:          [][]any{{int32(1), nil, nil, nil}, {nil, "b", nil, nil}},
+	}
+	statements, err := buildRowStatements(replication.EnumRowsEventTypeUpdate, event, testTableColumns())
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Equal(t, "UPDATE `shop`.`orders` SET `name` = ? WHERE `id` <=> ? LIMIT 1", statements[0].query)
+	assert.Equal(t, []any{"b", int32(1)}, statements[0].args)
+
+	event.Rows = event.Rows[:1]
+	_, err = buildRowStatements(replication.EnumRowsEventTypeUpdate, event, testTableColumns())
+	assert.Error(t, err)
+}
+
+func TestBuildRowStatements_DeleteWithoutPrimaryKey(t *testing.T) {
+	columns := testTableColumns()
+	columns.primaryKey = nil
+	event := &replication.RowsEvent{
+		Table: testTableMap(),
+		Rows:  [][]any{{int32(1), "a`b", nil, int32(10)}},
+	}
+	statements, err := buildRowStatements(replication.EnumRowsEventTypeDelete, event, columns)
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Equal(t, "DELETE FROM `shop`.`orders` WHERE `id` <=> ? AND `name` <=> ? AND "+
+		"`doc` <=> CAST(CONVERT(? USING utf8mb4) AS JSON) LIMIT 1", statements[0].query)
+	assert.Equal(t, []any{int32(1), "a`b", nil}, statements[0].args)
+}
+
+func TestBuildRowStatements_Unsupported(t *testing.T) {
+	event := &replication.RowsEvent{Table: testTableMap(), Rows: [][]any{{int32(1), nil, nil, nil}}}
+	_, err := buildRowStatements(replication.EnumRowsEventTypeUnknown, event, testTableColumns())
+	assert.Error(t, err)
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	assert.Equal(t, "`orders`", quoteIdentifier("orders"))
+	assert.Equal(t, "`a``b`", quoteIdentifier("a`b"))
+}
@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"encoding/binary"
+	"fmt"
 	"hash/crc32"
 	"net"
 	"os"
@@ -21,6 +22,9 @@ import (
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 )
 
+// mariadbSlaveCapabilityGTID is MARIA_SLAVE_CAPABILITY_GTID, replicas with this capability understand MariaDB GTID events
+const mariadbSlaveCapabilityGTID = 4
+
 // Handler is the go-mysql replication handler for one replica connection.
 // It implements both server.ReplicationHandler (go-mysql interface) and
 // the internal binlogHandler interface (fetchLogs callback)
@@ -40,13 +44,20 @@ type Handler struct {
 	// streaming goroutine starts, so there is no concurrent write.
 	streamer *replication.BinlogStreamer
 
+	// flavor of the replica: MariaDB replicas announce themselves with
+	// @mariadb_slave_capability before COM_BINLOG_DUMP.
+	flavor string
+
 	// requiredGTIDs is the replica's already-executed set from
 	// COM_BINLOG_DUMP_GTID; transactions it contains are skipped. This
-	// command is MySQL-only; MariaDB replicas negotiate GTID state via
-	// session variables and COM_BINLOG_DUMP, which is not wired up here.
-	sentGTIDs      mysql.GTIDSet
-	requiredGTIDs  *mysql.MysqlGTIDSet
-	skipCurrentTxn bool
+	// command is MySQL-only; MariaDB replicas pass their GTID position in
+	// @slave_connect_state and then send COM_BINLOG_DUMP, transactions
+	// covered by mariadbConnectState are skipped the same way.
+	sentGTIDs           mysql.GTIDSet
+	requiredGTIDs       *mysql.MysqlGTIDSet
+	mariadbConnectState *mysql.MariadbGTIDSet
+	mariadbCapability   int
+	skipCurrentTxn      bool
 
 	// --- streaming pipeline fields (set by initStreaming, used by fetchLogs) ---
 
@@ -78,6 +89,7 @@ func newHandler(ctx context.Context, replicaSource string, root storage.Folder,
 		startTS:       startTS,
 		untilTS:       untilTS,
 		endBinlogTS:   endBinlogTS,
+		flavor:        mysql.MySQLFlavor,
 		sentGTIDs:     sent,
 	}
 }
@@ -155,6 +167,11 @@ func (h *Handler) waitReplicationIsDoneSafe() {
 	if err != nil {
 		tracelog.ErrorLogger.Fatalf("Failed to parse replica datasource: %v", err)
 	}
+	gtidQuery := "SELECT @@global.gtid_executed"
+	if h.flavor == mysql.MariaDBFlavor {
+		// MariaDB records the replicated transactions in gtid_slave_pos whether the replica uses GTID or not
+		gtidQuery = "SELECT @@global.gtid_slave_pos"
+	}
 	var conn *client.Conn
 	connCount := 0
 	defer func() {
@@ -185,7 +202,7 @@ func (h *Handler) waitReplicationIsDoneSafe() {
 			connCount = 0
 		}
 
-		r, err := conn.Execute("SELECT @@global.gtid_executed")
+		r, err := conn.Execute(gtidQuery)
 		if err != nil {
 			tracelog.WarningLogger.Printf("Failed to query replica GTID state: %v", err)
 			conn.Close()
@@ -196,7 +213,7 @@ func (h *Handler) waitReplicationIsDoneSafe() {
 		executedStr, _ := r.GetString(0, 0)
 		r.Close()
 
-		replicaSet, _ := mysql.ParseGTIDSet("mysql", executedStr)
+		replicaSet, _ := mysql.ParseGTIDSet(h.flavor, executedStr)
 		if replicaSet != nil && replicaSet.Contain(h.sentGTIDs) {
 			tracelog.InfoLogger.Println("Replica has successfully caught up! We are safely done.")
 			os.Exit(0)
@@ -219,8 +236,13 @@ func (h *Handler) handleEvent(e *replication.BinlogEvent) error {
 		if h.decideSkipForGTID(e) {
 			return nil
 		}
+	case replication.MARIADB_GTID_EVENT:
+		if h.decideSkipForMariadbGTID(e) {
+			return nil
+		}
 	case replication.ANONYMOUS_GTID_EVENT, replication.GTID_TAGGED_LOG_EVENT,
 		replication.FORMAT_DESCRIPTION_EVENT, replication.PREVIOUS_GTIDS_EVENT,
+		replication.MARIADB_GTID_LIST_EVENT, replication.MARIADB_BINLOG_CHECKPOINT_EVENT,
 		replication.ROTATE_EVENT, replication.STOP_EVENT, replication.INCIDENT_EVENT:
 		// txn boundary or file-boundary marker; never appears inside a txn
 		h.skipCurrentTxn = false
@@ -255,12 +277,36 @@ func (h *Handler) decideSkipForGTID(e *replication.BinlogEvent) bool {
 	return false
 }
 
+// decideSkipForMariadbGTID is decideSkipForGTID for a MARIADB_GTID_EVENT,
+// checked against the position from @slave_connect_state.
+func (h *Handler) decideSkipForMariadbGTID(e *replication.BinlogEvent) bool {
+	h.skipCurrentTxn = false
+	// sequence number (8) + domain id (4) + flags (1)
+	if len(e.RawData) < replication.EventHeaderSize+13 {
+		return false
+	}
+	ge := &replication.MariadbGTIDEvent{}
+	if ge.Decode(e.RawData[replication.EventHeaderSize:]) != nil {
+		return false
+	}
+	ge.GTID.ServerID = e.Header.ServerID
+	if isMariadbGTIDApplied(h.mariadbConnectState, &ge.GTID) {
+		tracelog.DebugLogger.Printf("Skipping already-applied transaction %s", ge.GTID.String())
+		h.skipCurrentTxn = true
+		return true
+	}
+	if err := h.sentGTIDs.Update(ge.GTID.String()); err != nil {
+		tracelog.WarningLogger.Printf("Failed to record sent GTID %s: %v", ge.GTID.String(), err)
+	}
+	return false
+}
+
 // initStreaming initializes the pipeline fields on h and starts the streamWorker
 // worker goroutine. It must be called once per connection, before fetchLogs.
 func (h *Handler) initStreaming(startPos mysql.Position) {
 	p := replication.NewBinlogParser()
 	p.SetRawMode(true)
-	p.SetFlavor(mysql.MySQLFlavor)
+	p.SetFlavor(h.flavor)
 	p.SetVerifyChecksum(true)
 	h.parser = p
 	h.startPos = startPos
@@ -337,7 +383,7 @@ func (h *Handler) streamBinlogFiles(startPos mysql.Position) {
 	h.initStreaming(startPos)
 	tracelog.InfoLogger.Printf("Start event streaming")
 
-	err = fetchLogs(h.ctx, h.rootFolder, h.dstDir, h.startTS, h.untilTS, h.endBinlogTS, h)
+	err = fetchLogs(h.ctx, h.rootFolder, h.dstDir, h.startTS, h.untilTS, h.endBinlogTS, nil, h)
 	if err != nil {
 		tracelog.ErrorLogger.Printf("Error during logs streaming: %v", err)
 		_ = h.wait()
@@ -362,6 +408,18 @@ func (h *Handler) HandleRegisterSlave(data []byte) error {
 
 func (h *Handler) HandleBinlogDump(pos mysql.Position) (*replication.BinlogStreamer, error) {
 	tracelog.InfoLogger.Printf("HandleBinlogDump: requested position %s:%d", pos.Name, pos.Pos)
+	if h.mariadbCapability >= mariadbSlaveCapabilityGTID {
+		h.flavor = mysql.MariaDBFlavor
+		h.sentGTIDs, _ = mysql.ParseGTIDSet(mysql.MariaDBFlavor, "")
+	}
+	if h.mariadbConnectState != nil {
+		if h.flavor != mysql.MariaDBFlavor {
+			return nil, fmt.Errorf("@slave_connect_state is set, but the replica did not announce MariaDB GTID capability")
+		}
+		tracelog.InfoLogger.Printf("HandleBinlogDump: MariaDB GTID position %s", h.mariadbConnectState.String())
+		// the file and position are ignored when the replica connects by GTID
+		pos = mysql.Position{Name: "host-binlog-file", Pos: 4}
+	}
 	h.streamer = replication.NewBinlogStreamer()
 	go h.streamBinlogFiles(pos)
 	return h.streamer, nil
@@ -376,6 +434,9 @@ func (h *Handler) HandleBinlogDumpGTID(gtidSet *mysql.MysqlGTIDSet) (*replicatio
 }
 
 func (h *Handler) HandleQuery(query string) (*mysql.Result, error) {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(query)), "set ") {
+		return nil, h.handleSetQuery(query)
+	}
 	switch strings.ToLower(query) {
 	case "select @master_binlog_checksum":
 		resultSet, _ := mysql.BuildSimpleTextResultset([]string{"master_binlog_checksum"}, [][]interface{}{{"CRC32"}})
@@ -393,6 +454,11 @@ func (h *Handler) HandleQuery(query string) (*mysql.Result, error) {
 		resultSet, err := mysql.BuildSimpleTextResultset([]string{"SERVER_ID"}, [][]interface{}{{serverID}})
 		tracelog.ErrorLogger.FatalOnError(err)
 		return &mysql.Result{Status: 34, Warnings: 0, InsertId: 0, AffectedRows: 0, Resultset: resultSet}, nil
+	case "select @@global.gtid_domain_id":
+		// MariaDB replicas refuse to use GTID when this query fails. The domains
+		// of the streamed transactions come from the binlog events.
+		resultSet, _ := mysql.BuildSimpleTextResultset([]string{"@@GLOBAL.gtid_domain_id"}, [][]interface{}{{"0"}})
+		return &mysql.Result{Status: 34, Warnings: 0, InsertId: 0, AffectedRows: 0, Resultset: resultSet}, nil
 	case "select @@global.gtid_mode":
 		resultSet, _ := mysql.BuildSimpleTextResultset([]string{"GTID_MODE"}, [][]interface{}{{"ON"}})
 		return &mysql.Result{Status: 34, Warnings: 0, InsertId: 0, AffectedRows: 0, Resultset: resultSet}, nil
@@ -413,6 +479,74 @@ func (h *Handler) HandleQuery(query string) (*mysql.Result, error) {
 	}
 }
 
+// handleSetQuery records the session variables MariaDB replicas use to negotiate GTID replication,
+// other variables are accepted and ignored.
+func (h *Handler) handleSetQuery(query string) error {
+	variables, err := parseUserVariableAssignments(query)
+	if err != nil {
+		tracelog.DebugLogger.Printf("Unhandled query: %s", query)
+		return nil
+	}
+	for name, value := range variables {
+		switch name {
+		case "mariadb_slave_capability":
+			h.mariadbCapability, err = strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid @mariadb_slave_capability '%s': %w", value, err)
+			}
+		case "slave_connect_state":
+			state, err := mysql.ParseMariadbGTIDSet(value)
+			if err != nil {
+				return fmt.Errorf("invalid @slave_connect_state '%s': %w", value, err)
+			}
+			h.mariadbConnectState = state.(*mysql.MariadbGTIDSet)
+		default:
+			tracelog.DebugLogger.Printf("Ignoring session variable @%s = %s", name, value)
+		}
+	}
+	return nil
+}
+
+// parseUserVariableAssignments parses "SET @a = 1, @b = 'x'" into lowercase variable names and unquoted values.
+// Values which are not literals (e.g. @@global.binlog_checksum) are returned as is.
+func parseUserVariableAssignments(query string) (map[string]string, error) {
+	query = strings.TrimSpace(query)
+	if len(query) < 4 || !strings.EqualFold(query[:4], "set ") {
+		return nil, fmt.Errorf("not a SET statement: %s", query)
+	}
+	var assignments []string
+	start, quote := 4, byte(0)
+	for i := 4; i < len(query); i++ {
+		switch c := query[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ',':
+			assignments = append(assignments, query[start:i])
+			start = i + 1
+		}
+	}
+	assignments = append(assignments, query[start:])
+
+	variables := make(map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		name, value, ok := strings.Cut(assignment, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !strings.HasPrefix(name, "@") || strings.HasPrefix(name, "@@") {
+			return nil, fmt.Errorf("not a user variable assignment: %s", assignment)
+		}
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), ";"))
+		if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		variables[strings.ToLower(name[1:])] = value
+	}
+	return variables, nil
+}
+
 func HandleBinlogServer(ctx context.Context, since string, until string, untilBinlogLastModified string) {
 	// get necessary settings
 	st, err := internal.ConfigureStorage(ctx)
@@ -1,13 +1,19 @@
 package mysql
 
 import (
+	"context"
 	"encoding/binary"
+	"net"
 	"testing"
+	"time"
 
+	"github.com/go-mysql-org/go-mysql/client"
 	"github.com/go-mysql-org/go-mysql/mysql"
 	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/go-mysql-org/go-mysql/server"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // gtidEvent builds a BinlogEvent whose RawData decodes to the given (sid, gno).
@@ -65,3 +71,198 @@ func TestDecideSkipForGTID(t *testing.T) {
 		assert.False(t, h.skipCurrentTxn)
 	})
 }
+
+// mariadbGTIDEvent builds a BinlogEvent whose RawData decodes to the given domain-server-sequence.
+func mariadbGTIDEvent(domain, serverID uint32, seq uint64) *replication.BinlogEvent {
+	body := make([]byte, 19) // SequenceNumber(8) + DomainID(4) + Flags(1) + unused(6)
+	binary.LittleEndian.PutUint64(body[0:8], seq)
+	binary.LittleEndian.PutUint32(body[8:12], domain)
+	raw := append(make([]byte, replication.EventHeaderSize), body...)
+	return &replication.BinlogEvent{
+		Header:  &replication.EventHeader{EventType: replication.MARIADB_GTID_EVENT, ServerID: serverID},
+		RawData: raw,
+	}
+}
+
+func TestDecideSkipForMariadbGTID(t *testing.T) {
+	state, _ := mysql.ParseMariadbGTIDSet("0-1-100,1-2-7")
+	newHandler := func() *Handler {
+		empty, _ := mysql.ParseGTIDSet(mysql.MariaDBFlavor, "")
+		return &Handler{
+			flavor:              mysql.MariaDBFlavor,
+			sentGTIDs:           empty,
+			mariadbConnectState: state.(*mysql.MariadbGTIDSet),
+		}
+	}
+
+	t.Run("GTID covered by the position is skipped, not recorded", func(t *testing.T) {
+		h := newHandler()
+		assert.True(t, h.decideSkipForMariadbGTID(mariadbGTIDEvent(0, 1, 100)))
+		// the position is a high-water mark of the domain, the server doesn't matter
+		assert.True(t, h.decideSkipForMariadbGTID(mariadbGTIDEvent(1, 3, 5)))
+		assert.True(t, h.skipCurrentTxn)
+		assert.True(t, h.sentGTIDs.IsEmpty())
+	})
+
+	t.Run("new GTID is forwarded and recorded", func(t *testing.T) {
+		h := newHandler()
+		assert.False(t, h.decideSkipForMariadbGTID(mariadbGTIDEvent(0, 1, 101)))
+		assert.False(t, h.decideSkipForMariadbGTID(mariadbGTIDEvent(2, 1, 1)))
+		assert.False(t, h.skipCurrentTxn)
+		assert.True(t, h.sentGTIDs.Contain(mustParseMariadbGTIDSet(t, "0-1-101,2-1-1")))
+	})
+
+	t.Run("nil connect state forwards everything", func(t *testing.T) {
+		h := newHandler()
+		h.mariadbConnectState = nil
+		assert.False(t, h.decideSkipForMariadbGTID(mariadbGTIDEvent(0, 1, 5)))
+		assert.Equal(t, "0-1-5", h.sentGTIDs.String())
+	})
+}
+
+func mustParseMariadbGTIDSet(t *testing.T, str string) mysql.GTIDSet {
+	t.Helper()
+	set, err := mysql.ParseMariadbGTIDSet(str)
+	require.NoError(t, err)
+	return set
+}
+
+// serveFakeReplica runs the binlog server protocol for h on a local port and connects a client to it,
+// the client plays the replica. The returned channel is closed when the server side finishes.
+func serveFakeReplica(t *testing.T, h *Handler) (*client.Conn, <-chan struct{}) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		authHandler := server.NewInMemoryAuthenticationHandler(mysql.AUTH_NATIVE_PASSWORD)
+		if authHandler.AddUser("replicator", "secret") != nil {
+			return
+		}
+		srv := server.NewServer("5.7.42", mysql.DEFAULT_COLLATION_ID, mysql.AUTH_NATIVE_PASSWORD, nil, nil)
+		conn, err := srv.NewCustomizedConn(c, authHandler, h)
+		if err != nil {
+			return
+		}
+		for conn.HandleCommand() == nil {
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := client.ConnectWithContext(ctx, l.Addr().String(), "replicator", "secret", "", 10*time.Second)
+	require.NoError(t, err)
+	return conn, done
+}
+
+func TestHandler_MariadbReplicaNegotiation(t *testing.T) {
+	h := newHandler(context.Background(), "", nil, "", time.Time{}, time.Time{}, time.Time{})
+	defer h.cancel()
+	replica, done := serveFakeReplica(t, h)
+
+	// the statements a MariaDB replica with MASTER_USE_GTID=slave_pos sends before COM_BINLOG_DUMP
+	for _, query := range []string{
+		"SET @master_heartbeat_period= 30000000000",
+		"SET @master_binlog_checksum= @@global.binlog_checksum",
+		"SET @mariadb_slave_capability=4",
+		"SET @slave_connect_state='0-1-100,1-2-7'",
+		"SET @slave_gtid_strict_mode=0",
+		"SET @slave_gtid_ignore_duplicates=0",
+	} {
+		_, err := replica.Execute(query)
+		require.NoError(t, err, query)
+	}
+	r, err := replica.Execute("SELECT @@GLOBAL.gtid_domain_id")
+	require.NoError(t, err)
+	domainID, _ := r.GetString(0, 0)
+	assert.Equal(t, "0", domainID)
+	r, err = replica.Execute("SELECT @master_binlog_checksum")
+	require.NoError(t, err)
+	checksum, _ := r.GetString(0, 0)
+	assert.Equal(t, "CRC32", checksum)
+
+	_, err = replica.Execute("SET @slave_connect_state='not-a-gtid'")
+	assert.Error(t, err)
+
+	require.NoError(t, replica.Close())
+	<-done
+	assert.Equal(t, mariadbSlaveCapabilityGTID, h.mariadbCapability)
+	require.NotNil(t, h.mariadbConnectState)
+	assert.True(t, h.mariadbConnectState.Equal(mustParseMariadbGTIDSet(t, "0-1-100,1-2-7")))
+}
+
+func TestHandler_HandleBinlogDumpRequiresMariadbCapability(t *testing.T) {
+	h := newHandler(context.Background(), "", nil, "", time.Time{}, time.Time{}, time.Time{})
+	defer h.cancel()
+	require.NoError(t, h.handleSetQuery("SET @slave_connect_state='0-1-100'"))
+	_, err := h.HandleBinlogDump(mysql.Position{})
+	assert.Error(t, err)
+	assert.Equal(t, mysql.MySQLFlavor, h.flavor)
+}
+
+func TestParseUserVariableAssignments(t *testing.T) {
+	variables, err := parseUserVariableAssignments(
+		"SET @master_binlog_checksum='NONE', @Slave_Connect_State = '0-1-100,1-2-7';")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"master_binlog_checksum": "NONE",
+		"slave_connect_state":    "0-1-100,1-2-7",
+	}, variables)
+
+	variables, err = parseUserVariableAssignments("set @master_binlog_checksum= @@global.binlog_checksum")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"master_binlog_checksum": "@@global.binlog_checksum"}, variables)
+
+	for _, query := range []string{"SET NAMES utf8", "SET @@session.sql_mode=''", "SELECT 1"} {
+		_, err = parseUserVariableAssignments(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestHandleEvent_MariadbSkipsAppliedTransactions(t *testing.T) {
+	h := newHandler(context.Background(), "", nil, "", time.Time{}, time.Now().Add(time.Hour), time.Time{})
+	defer h.cancel()
+	h.flavor = mysql.MariaDBFlavor
+	h.sentGTIDs, _ = mysql.ParseGTIDSet(mysql.MariaDBFlavor, "")
+	h.mariadbConnectState = mustParseMariadbGTIDSet(t, "0-1-100").(*mysql.MariadbGTIDSet)
+	h.streamer = replication.NewBinlogStreamer()
+
+	event := func(eventType replication.EventType) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header:  &replication.EventHeader{EventType: eventType},
+			RawData: make([]byte, replication.EventHeaderSize),
+		}
+	}
+	for _, e := range []*replication.BinlogEvent{
+		event(replication.MARIADB_GTID_LIST_EVENT),
+		mariadbGTIDEvent(0, 1, 100),
+		event(replication.WRITE_ROWS_EVENTv1),
+		event(replication.XID_EVENT),
+		mariadbGTIDEvent(0, 1, 101),
+		event(replication.WRITE_ROWS_EVENTv1),
+		event(replication.XID_EVENT),
+	} {
+		require.NoError(t, h.handleEvent(e))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var streamed []replication.EventType
+	for range 4 {
+		e, err := h.streamer.GetEvent(ctx)
+		require.NoError(t, err)
+		streamed = append(streamed, e.Header.EventType)
+	}
+	assert.Equal(t, []replication.EventType{replication.MARIADB_GTID_LIST_EVENT, replication.MARIADB_GTID_EVENT,
+		replication.WRITE_ROWS_EVENTv1, replication.XID_EVENT}, streamed)
+	assert.Empty(t, h.streamer.DumpEvents())
+	assert.Equal(t, "0-1-101", h.sentGTIDs.String())
+}
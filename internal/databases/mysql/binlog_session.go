@@ -0,0 +1,187 @@
+package mysql
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// The codes of the query event status variables
+const (
+	queryFlags2Code                  = 0
+	querySQLModeCode                 = 1
+	queryCatalogCode                 = 2
+	queryAutoIncrementCode           = 3
+	queryCharsetCode                 = 4
+	queryTimeZoneCode                = 5
+	queryCatalogNZCode               = 6
+	queryLCTimeNamesCode             = 7
+	queryCharsetDatabaseCode         = 8
+	queryTableMapForUpdateCode       = 9
+	queryMasterDataWrittenCode       = 10
+	queryInvokerCode                 = 11
+	queryUpdatedDBNamesCode          = 12
+	queryMicrosecondsCode            = 13
+	queryExplicitDefaultsForTSCode   = 16
+	queryDDLLoggedWithXIDCode        = 17
+	queryDefaultCollationUTF8MB4Code = 18
+	querySQLRequirePrimaryKeyCode    = 19
+	queryDefaultTableEncryptionCode  = 20
+	// MariaDB
+	queryHRNowCode = 128
+	queryXIDCode   = 129
+
+	queryOverMaxDBs = 254
+)
+
+// The bits of the flags2 status variable
+const (
+	optionAutoIsNull          = 1 << 14
+	optionNoForeignKeyChecks  = 1 << 26
+	optionRelaxedUniqueChecks = 1 << 27
+)
+
+// querySessionVariables are restored for the query events in this order, the ones which are not logged
+// are reset to the server defaults
+var querySessionVariables = []string{
+	"foreign_key_checks", "unique_checks", "sql_auto_is_null", "sql_mode",
+	"auto_increment_increment", "auto_increment_offset", "time_zone", "lc_time_names", "collation_database",
+}
+
+// querySession returns the SET statement restoring the session of the query event from its status variables
+// the same way as mysqlbinlog does: the character sets, time zone, sql_mode and the checks of the source session
+func querySession(statusVars []byte) (string, error) {
+	values, err := parseQueryStatusVars(statusVars)
+	if err != nil {
+		return "", err
+	}
+	var assignments []string
+	if _, ok := values["character_set_client"]; !ok {
+		assignments = append(assignments, "NAMES utf8mb4")
+	} else {
+		for _, name := range []string{"character_set_client", "collation_connection", "collation_server"} {
+			assignments = append(assignments, "@@session."+name+" = "+values[name])
+		}
+	}
+	for _, name := range querySessionVariables {
+		value, ok := values[name]
+		if !ok {
+			value = "DEFAULT"
+		}
+		assignments = append(assignments, "@@session."+name+" = "+value)
+	}
+	return "SET " + strings.Join(assignments, ", "), nil
+}
+
+// rowSession returns the SET statement of the session the row events are applied in like the replica does:
+// the row images contain the raw bytes and the TIMESTAMP values decoded in UTC, the logged auto increment values
+// are kept and the checks are disabled when the source session disabled them
+func rowSession(flags uint16) string {
+	return fmt.Sprintf("SET NAMES binary, @@session.time_zone = '+00:00', @@session.sql_mode = 'NO_AUTO_VALUE_ON_ZERO', "+
+		"@@session.foreign_key_checks = %s, @@session.unique_checks = %s",
+		sqlBool(flags&replication.NO_FOREIGN_KEY_CHECKS_F == 0), sqlBool(flags&replication.RELAXED_UNIQUE_CHECKS_F == 0))
+}
+
+// parseQueryStatusVars returns the values of the session variables logged in the status variables.
+// The server stops parsing at the unknown code as its length is unknown, so does this function.
+//
+//nolint:funlen,gocyclo
+func parseQueryStatusVars(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	reader := &statusVarsReader{data: data}
+	for reader.pos < len(data) && reader.err == nil {
+		code := reader.bytes(1)[0]
+		switch code {
+		case queryFlags2Code:
+			flags := binary.LittleEndian.Uint32(reader.bytes(4))
+			values["foreign_key_checks"] = sqlBool(flags&optionNoForeignKeyChecks == 0)
+			values["unique_checks"] = sqlBool(flags&optionRelaxedUniqueChecks == 0)
+			values["sql_auto_is_null"] = sqlBool(flags&optionAutoIsNull != 0)
+		case querySQLModeCode:
+			values["sql_mode"] = strconv.FormatUint(binary.LittleEndian.Uint64(reader.bytes(8)), 10)
+		case queryCatalogCode:
+			reader.bytes(int(reader.bytes(1)[0]) + 1)
+		case queryAutoIncrementCode:
+			values["auto_increment_increment"] = strconv.Itoa(int(binary.LittleEndian.Uint16(reader.bytes(2))))
+			values["auto_increment_offset"] = strconv.Itoa(int(binary.LittleEndian.Uint16(reader.bytes(2))))
+		case queryCharsetCode:
+			values["character_set_client"] = strconv.Itoa(int(binary.LittleEndian.Uint16(reader.bytes(2))))
+			values["collation_connection"] = strconv.Itoa(int(binary.LittleEndian.Uint16(reader.bytes(2))))
+			values["collation_server"] = strconv.Itoa(int(binary.LittleEndian.Uint16(reader.bytes(2))))
+		case queryTimeZoneCode:
+			timeZone := string(reader.bytes(int(reader.bytes(1)[0])))
+			values["time_zone"] = "'" + strings.ReplaceAll(timeZone, "'", "''") + "'"
+		case queryCatalogNZCode:
+			reader.bytes(int(reader.bytes(1)[0]))
+		case queryLCTimeNamesCode:
+			values["lc_time_names"] = strconv.Itoa(int(binary.LittleEndian.Uint16(reader.bytes(2))))
+		case queryCharsetDatabaseCode:
+			if collation := binary.LittleEndian.Uint16(reader.bytes(2)); collation != 0 {
+				values["collation_database"] = strconv.Itoa(int(collation))
+			}
+		case queryTableMapForUpdateCode, queryDDLLoggedWithXIDCode, queryXIDCode:
+			reader.bytes(8)
+		case queryMasterDataWrittenCode:
+			reader.bytes(4)
+		case queryInvokerCode:
+			reader.bytes(int(reader.bytes(1)[0]))
+			reader.bytes(int(reader.bytes(1)[0]))
+		case queryUpdatedDBNamesCode:
+			count := int(reader.bytes(1)[0])
+			if count == queryOverMaxDBs {
+				count = 0
+			}
+			for range count {
+				reader.cString()
+			}
+		case queryMicrosecondsCode, queryHRNowCode:
+			reader.bytes(3)
+		case queryExplicitDefaultsForTSCode, querySQLRequirePrimaryKeyCode, queryDefaultTableEncryptionCode:
+			reader.bytes(1)
+		case queryDefaultCollationUTF8MB4Code:
+			reader.bytes(2)
+		default:
+			return values, nil
+		}
+	}
+	return values, reader.err
+}
+
+// statusVarsReader reads the status variables, after the data is exhausted it returns zeroes and keeps the error
+type statusVarsReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *statusVarsReader) bytes(n int) []byte {
+	if r.err == nil && r.pos+n > len(r.data) {
+		r.err = fmt.Errorf("status variables are truncated at %d", r.pos)
+	}
+	if r.err != nil {
+		return make([]byte, n)
+	}
+	value := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return value
+}
+
+func (r *statusVarsReader) cString() {
+	end := slices.Index(r.data[min(r.pos, len(r.data)):], 0)
+	if end < 0 {
+		r.bytes(len(r.data) + 1)
+		return
+	}
+	r.bytes(end + 1)
+}
+
+func sqlBool(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuerySession(t *testing.T) {
+	var statusVars []byte
+	statusVars = append(statusVars, queryFlags2Code)
+	statusVars = binary.LittleEndian.AppendUint32(statusVars, optionNoForeignKeyChecks)
+	statusVars = append(statusVars, querySQLModeCode)
+	statusVars = binary.LittleEndian.AppendUint64(statusVars, 1436549152)
+	statusVars = append(statusVars, queryCharsetCode)
+	statusVars = binary.LittleEndian.AppendUint16(statusVars, 33)
+	statusVars = binary.LittleEndian.AppendUint16(statusVars, 33)
+	statusVars = binary.LittleEndian.AppendUint16(statusVars, 8)
+	statusVars = append(statusVars, queryTimeZoneCode, 6)
+	statusVars = append(statusVars, "SYSTEM"...)
+	statusVars = append(statusVars, queryUpdatedDBNamesCode, 1)
+	statusVars = append(statusVars, "shop\x00"...)
+	// the unknown code stops the parsing
+	statusVars = append(statusVars, 200, 1, 2, 3)
+
+	session, err := querySession(statusVars)
+	require.NoError(t, err)
+	assert.Equal(t, "SET @@session.character_set_client = 33, @@session.collation_connection = 33, @@session.collation_server = 8, "+
+		"@@session.foreign_key_checks = 0, @@session.unique_checks = 1, @@session.sql_auto_is_null = 0, "+
+		"@@session.sql_mode = 1436549152, @@session.auto_increment_increment = DEFAULT, @@session.auto_increment_offset = DEFAULT, "+
+		"@@session.time_zone = 'SYSTEM', @@session.lc_time_names = DEFAULT, @@session.collation_database = DEFAULT", session)
+}
+
+func TestQuerySession_NoStatusVars(t *testing.T) {
+	session, err := querySession(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "SET NAMES utf8mb4, @@session.foreign_key_checks = DEFAULT, @@session.unique_checks = DEFAULT, "+
+		"@@session.sql_auto_is_null = DEFAULT, @@session.sql_mode = DEFAULT, @@session.auto_increment_increment = DEFAULT, "+
+		"@@session.auto_increment_offset = DEFAULT, @@session.time_zone = DEFAULT, @@session.lc_time_names = DEFAULT, "+
+		"@@session.collation_database = DEFAULT", session)
+}
+
+func TestQuerySession_Truncated(t *testing.T) {
+	_, err := querySession([]byte{queryTimeZoneCode, 6, 'U', 'T', 'C'})
+	assert.Error(t, err)
+}
+
+func TestRowSession(t *testing.T) {
+	assert.Contains(t, rowSession(replication.STMT_END_F), "@@session.foreign_key_checks = 1, @@session.unique_checks = 1")
+	assert.Contains(t, rowSession(replication.NO_FOREIGN_KEY_CHECKS_F|replication.RELAXED_UNIQUE_CHECKS_F),
+		"@@session.foreign_key_checks = 0, @@session.unique_checks = 0")
+}
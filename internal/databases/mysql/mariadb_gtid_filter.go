@@ -126,3 +126,14 @@ func (f *mariadbGtidFilter) getArchivedGTIDString() string {
 	}
 	return f.gtidArchived.String()
 }
+
+// isMariadbGTIDApplied checks if the transaction is covered by the replication position of a replica.
+// As with the archived checkpoints, the position is a high-water mark per domain: all transactions
+// of the domain up to its sequence number are applied, whichever server they came from.
+func isMariadbGTIDApplied(position *mysql.MariadbGTIDSet, gtid *mysql.MariadbGTID) bool {
+	if position == nil {
+		return false
+	}
+	applied, ok := position.Sets[gtid.DomainID]
+	return ok && applied.Contain(gtid)
+}
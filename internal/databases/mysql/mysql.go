@@ -495,8 +495,10 @@ type binlogHandler interface {
 	handleBinlog(binlogPath string) error
 }
 
-func fetchLogs(ctx context.Context,
-	folder storage.Folder, dstDir string, startTS, endTS, endBinlogTS time.Time, handler binlogHandler) error {
+// fetchLogs downloads the binlogs since startTS and passes them to the handler until the one started after endTS.
+// If the GTID target is set, the fetching stops before the binlog started after the target transactions.
+func fetchLogs(ctx context.Context, folder storage.Folder, dstDir string, startTS, endTS, endBinlogTS time.Time,
+	gtidTarget *gtidReplayTarget, handler binlogHandler) error {
 	logFolder := folder.GetSubFolder(BinlogPath)
 	includeStart := true
 outer:
@@ -519,6 +521,16 @@ outer:
 			if err != nil {
 				return err
 			}
+			if gtidTarget != nil {
+				previousGTIDs, err := GetBinlogPreviousGTIDs(binlogPath, gtidTarget.flavor)
+				if err != nil {
+					return err
+				}
+				if gtidTarget.reached(previousGTIDs) {
+					tracelog.InfoLogger.Printf("GTID target is reached before %s", binlogName)
+					return os.Remove(binlogPath)
+				}
+			}
 			err = handler.handleBinlog(binlogPath)
 			if err != nil {
 				return err
@@ -12,7 +12,8 @@ import (
 
 // xbstream BackupSink will unpack archive to disk.
 // Note: files may be compressed(quicklz,lz4,zstd) / encrypted("NONE", "AES128", "AES192","AES256")
-func BackupSink(stream *Reader, output string, decompress bool) {
+// When the filter is set, only the matched files are unpacked.
+func BackupSink(stream *Reader, output string, decompress bool, filter *TableFilter) {
 	err := os.MkdirAll(output, 0777) // FIXME: permission & UMASK
 	tracelog.ErrorLogger.FatalOnError(err)
 
@@ -25,6 +26,7 @@ func BackupSink(stream *Reader, output string, decompress bool) {
 		decompress:       decompress,
 		inplace:          false,
 		spaceIDCollector: spaceIDCollector,
+		filter:           filter,
 	}
 
 	sinks := make(map[string]fileSink)
@@ -55,7 +57,7 @@ func BackupSink(stream *Reader, output string, decompress bool) {
 	}
 }
 
-func AsyncBackupSink(wg *sync.WaitGroup, stream *Reader, dataDir string, decompress bool) {
+func AsyncBackupSink(wg *sync.WaitGroup, stream *Reader, dataDir string, decompress bool, filter *TableFilter) {
 	defer wg.Done()
-	BackupSink(stream, dataDir, decompress)
+	BackupSink(stream, dataDir, decompress, filter)
 }
@@ -15,7 +15,8 @@ import (
 // * extract all non-diff files to incrementalDir
 // * apply diff-files to dataDir 'inplace' + add truncated versions of diff-files to incrementalDir
 // * let xtrabackup do its job
-func DiffBackupSink(stream *Reader, dataDir string, incrementalDir string) {
+// When the filter is set, only the matched files are unpacked.
+func DiffBackupSink(stream *Reader, dataDir string, incrementalDir string, filter *TableFilter) {
 	err := os.MkdirAll(dataDir, 0777) // FIXME: permission & UMASK
 	tracelog.ErrorLogger.FatalOnError(err)
 
@@ -28,6 +29,7 @@ func DiffBackupSink(stream *Reader, dataDir string, incrementalDir string) {
 		decompress:       true, // always decompress files when diff-files applied
 		inplace:          true,
 		spaceIDCollector: spaceIDCollector,
+		filter:           filter,
 	}
 
 	sinks := make(map[string]fileSink)
@@ -59,7 +61,7 @@ func DiffBackupSink(stream *Reader, dataDir string, incrementalDir string) {
 }
 
 // Deprecated: name doesnt match actual behavior
-func AsyncDiffBackupSink(wg *sync.WaitGroup, stream *Reader, dataDir string, incrementalDir string) {
+func AsyncDiffBackupSink(wg *sync.WaitGroup, stream *Reader, dataDir string, incrementalDir string, filter *TableFilter) {
 	defer wg.Done()
-	DiffBackupSink(stream, dataDir, incrementalDir)
+	DiffBackupSink(stream, dataDir, incrementalDir, filter)
 }
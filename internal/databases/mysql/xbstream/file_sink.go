@@ -26,6 +26,8 @@ type fileSinkFactory struct {
 	decompress       bool
 	inplace          bool
 	spaceIDCollector innodb.SpaceIDCollector
+	// filter selects the files to extract, all files are extracted when it is nil
+	filter *TableFilter
 }
 
 func (fsf *fileSinkFactory) MapDataSinkKey(chunkPath string) string {
@@ -61,6 +63,10 @@ func (fsf *fileSinkFactory) NewDataSink(chunkPath string) fileSink {
 	}
 
 	filePath := fsf.MapDataSinkPath(chunkPath)
+	if fsf.filter != nil && !fsf.filter.Match(filePath) {
+		tracelog.DebugLogger.Printf("Skipping %v", chunkPath)
+		return newDiscardFileSink()
+	}
 
 	var decompressor compression.Decompressor
 	if fsf.decompress {
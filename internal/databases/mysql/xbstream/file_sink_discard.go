@@ -0,0 +1,22 @@
+package xbstream
+
+import (
+	"io"
+)
+
+// fileSinkDiscard skips the files which are not needed
+type fileSinkDiscard struct{}
+
+var _ fileSink = &fileSinkDiscard{}
+
+func newDiscardFileSink() fileSink {
+	return &fileSinkDiscard{}
+}
+
+func (sink *fileSinkDiscard) Process(chunk *Chunk) error {
+	if chunk.Type == ChunkTypeEOF {
+		return ErrSinkEOF
+	}
+	_, err := io.Copy(io.Discard, chunk)
+	return err
+}
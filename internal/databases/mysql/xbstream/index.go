@@ -0,0 +1,72 @@
+package xbstream
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/wal-g/wal-g/internal"
+)
+
+// Index maps the files of the xbstream to the byte ranges of their chunks in the stream,
+// so the chunks of the separate files can be fetched without reading the whole stream.
+type Index struct {
+	Files map[string][]internal.StreamRange `json:"files"`
+}
+
+// BuildIndex reads the whole xbstream and records the ranges of the chunks of every file
+func BuildIndex(reader io.Reader) (*Index, error) {
+	stream := NewReader(reader, false)
+	index := &Index{Files: make(map[string][]internal.StreamRange)}
+	for {
+		start := stream.readPosition.Load()
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			return index, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if chunk.Reader != nil {
+			_, err = io.Copy(io.Discard, chunk.Reader)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read chunk of %s: %w", chunk.Path, err)
+			}
+		}
+		index.add(chunk.Path, internal.StreamRange{Start: start, End: stream.readPosition.Load()})
+	}
+}
+
+func (index *Index) add(filePath string, chunkRange internal.StreamRange) {
+	ranges := index.Files[filePath]
+	if len(ranges) > 0 && ranges[len(ranges)-1].End == chunkRange.Start {
+		ranges[len(ranges)-1].End = chunkRange.End
+		return
+	}
+	index.Files[filePath] = append(ranges, chunkRange)
+}
+
+// Ranges returns the sorted ranges of the chunks of the files matched by the filter,
+// the chunks of these ranges form the xbstream of the matched files
+func (index *Index) Ranges(filter *TableFilter) []internal.StreamRange {
+	var ranges []internal.StreamRange
+	for filePath, fileRanges := range index.Files {
+		if filter.Match(filePath) {
+			ranges = append(ranges, fileRanges...)
+		}
+	}
+	slices.SortFunc(ranges, func(a, b internal.StreamRange) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+
+	merged := make([]internal.StreamRange, 0, len(ranges))
+	for _, streamRange := range ranges {
+		if len(merged) > 0 && merged[len(merged)-1].End == streamRange.Start {
+			merged[len(merged)-1].End = streamRange.End
+			continue
+		}
+		merged = append(merged, streamRange)
+	}
+	return merged
+}
@@ -0,0 +1,74 @@
+package xbstream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+)
+
+func writeTestChunk(buf *bytes.Buffer, chunkType ChunkType, path string, payload []byte) {
+	buf.Write(chunkMagic)
+	buf.WriteByte(0)
+	buf.WriteByte(byte(chunkType))
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(path)))
+	buf.WriteString(path)
+	if chunkType == ChunkTypeEOF {
+		return
+	}
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(payload)))
+	_ = binary.Write(buf, binary.LittleEndian, uint64(0))
+	_ = binary.Write(buf, binary.LittleEndian, uint32(0))
+	buf.Write(payload)
+}
+
+func TestIndex_Ranges(t *testing.T) {
+	var stream bytes.Buffer
+	writeTestChunk(&stream, ChunkTypePayload, "ibdata1", []byte("system"))
+	writeTestChunk(&stream, ChunkTypePayload, "shop/orders.ibd", []byte("orders"))
+	writeTestChunk(&stream, ChunkTypePayload, "shop/items.ibd", []byte("items"))
+	writeTestChunk(&stream, ChunkTypePayload, "shop/orders.ibd", []byte("more orders"))
+	writeTestChunk(&stream, ChunkTypeEOF, "shop/orders.ibd", nil)
+	writeTestChunk(&stream, ChunkTypeEOF, "shop/items.ibd", nil)
+	writeTestChunk(&stream, ChunkTypeEOF, "ibdata1", nil)
+
+	index, err := BuildIndex(bytes.NewReader(stream.Bytes()))
+	require.NoError(t, err)
+	assert.Len(t, index.Files, 3)
+	assert.Len(t, index.Files["shop/orders.ibd"], 2)
+
+	filter, err := NewTableFilter([]string{"shop.orders"})
+	require.NoError(t, err)
+	ranges := index.Ranges(filter)
+	assert.Empty(t, filter.Missing())
+
+	var selected bytes.Buffer
+	for _, streamRange := range ranges {
+		selected.Write(stream.Bytes()[streamRange.Start:streamRange.End])
+	}
+	reader := NewReader(&selected, false)
+	var paths []string
+	for {
+		chunk, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if chunk.Reader != nil {
+			_, err = io.Copy(io.Discard, chunk.Reader)
+			require.NoError(t, err)
+		}
+		paths = append(paths, chunk.Path)
+	}
+	assert.Equal(t, []string{"ibdata1", "shop/orders.ibd", "shop/orders.ibd", "shop/orders.ibd", "ibdata1"}, paths)
+	// the adjacent chunks of the matched files are merged
+	assert.Equal(t, []internal.StreamRange{
+		{Start: 0, End: index.Files["shop/orders.ibd"][0].End},
+		index.Files["shop/orders.ibd"][1],
+		index.Files["ibdata1"][1],
+	}, ranges)
+}
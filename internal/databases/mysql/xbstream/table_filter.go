@@ -0,0 +1,117 @@
+package xbstream
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// suffixes added to the stored files by xtrabackup: encryption, compression and incremental deltas
+var storedFileSuffixes = []string{".xbcrypt", ".qp", ".lz4", ".zst", ".delta", ".meta"}
+
+var tableFileExtensions = []string{".ibd", ".cfg", ".cfp", ".frm"}
+
+// TableFilter selects the files needed to restore separate tables from the backup:
+// the tablespaces of the tables (and their partitions) plus the files in the data directory root
+// (system and undo tablespaces, redo log and xtrabackup metadata) which are required to prepare them.
+type TableFilter struct {
+	// tables maps "database/table" in MySQL file names to "database.table"
+	tables map[string]string
+	found  map[string]bool
+}
+
+func NewTableFilter(tables []string) (*TableFilter, error) {
+	filter := &TableFilter{tables: make(map[string]string), found: make(map[string]bool)}
+	for _, table := range tables {
+		table = strings.TrimSpace(table)
+		database, name, ok := strings.Cut(table, ".")
+		if !ok || database == "" || name == "" {
+			return nil, fmt.Errorf("table should be specified as 'database.table', got '%s'", table)
+		}
+		filter.tables[database+"/"+name] = table
+		filter.tables[encodeFileName(database)+"/"+encodeFileName(name)] = table
+	}
+	if len(filter.tables) == 0 {
+		return nil, fmt.Errorf("no tables are specified")
+	}
+	return filter, nil
+}
+
+// Match checks if the file from the stream is needed to restore the tables
+func (filter *TableFilter) Match(filePath string) bool {
+	filePath = path.Clean(filePath)
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, suffix := range storedFileSuffixes {
+			if strings.HasSuffix(filePath, suffix) {
+				filePath = strings.TrimSuffix(filePath, suffix)
+				trimmed = true
+			}
+		}
+	}
+	dir, file := path.Split(filePath)
+	switch dir {
+	case "":
+		return true
+	case "mysql/":
+		// MySQL 5.7 system tables
+		return true
+	}
+	ext := path.Ext(file)
+	if !slices.Contains(tableFileExtensions, ext) {
+		return false
+	}
+	name := strings.TrimSuffix(file, ext)
+	// partitions are stored as t#P#p0 (MySQL 5.7) or t#p#p0 (MySQL 8.0)
+	if i := strings.Index(strings.ToLower(name), "#p#"); i >= 0 {
+		name = name[:i]
+	}
+	table, ok := filter.tables[dir+name]
+	if ok {
+		filter.found[table] = true
+	}
+	return ok
+}
+
+// Missing returns the tables without any matched files
+func (filter *TableFilter) Missing() []string {
+	var missing []string
+	for _, table := range filter.tables {
+		if !filter.found[table] && !slices.Contains(missing, table) {
+			missing = append(missing, table)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// Tables returns the tables of the filter
+func (filter *TableFilter) Tables() []string {
+	var tables []string
+	for _, table := range filter.tables {
+		if !slices.Contains(tables, table) {
+			tables = append(tables, table)
+		}
+	}
+	slices.Sort(tables)
+	return tables
+}
+
+// encodeFileName encodes the identifier like MySQL does for file names: the characters
+// other than [0-9A-Za-z_] are stored as @XXXX. The compact forms MySQL uses
+// for some non-ASCII letters are not supported, such names are matched as is.
+func encodeFileName(name string) string {
+	var builder strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			builder.WriteRune(r)
+		case r < 0x80:
+			fmt.Fprintf(&builder, "@%04x", r)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,61 @@
+package xbstream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableFilter_Match(t *testing.T) {
+	filter, err := NewTableFilter([]string{"shop.orders", "my-db.items"})
+	require.NoError(t, err)
+
+	for path, expected := range map[string]bool{
+		"ibdata1":                      true,
+		"undo_001":                     true,
+		"mysql.ibd":                    true,
+		"xtrabackup_logfile":           true,
+		"xtrabackup_checkpoints":       true,
+		"mysql/innodb_table_stats.ibd": true,
+		"shop/orders.ibd":              true,
+		"shop/orders.ibd.zst":          true,
+		"shop/orders.ibd.delta":        true,
+		"shop/orders.ibd.meta":         true,
+		"shop/orders.frm.qp":           true,
+		"shop/orders#p#p0.ibd":         true,
+		"shop/orders#P#p1.ibd.lz4":     true,
+		"shop/orders_archive.ibd":      false,
+		"shop/customers.ibd":           false,
+		"shop/db.opt":                  false,
+		"my@002ddb/items.ibd":          true,
+		"sys/sys_config.ibd":           false,
+	} {
+		assert.Equal(t, expected, filter.Match(path), path)
+	}
+	assert.Empty(t, filter.Missing())
+	assert.Equal(t, []string{"my-db.items", "shop.orders"}, filter.Tables())
+}
+
+func TestTableFilter_Missing(t *testing.T) {
+	filter, err := NewTableFilter([]string{"shop.orders", "shop.items"})
+	require.NoError(t, err)
+	assert.True(t, filter.Match("shop/items.ibd"))
+	assert.Equal(t, []string{"shop.orders"}, filter.Missing())
+}
+
+func TestNewTableFilter_Invalid(t *testing.T) {
+	for _, tables := range [][]string{{"orders"}, {".orders"}, {"shop."}, {}} {
+		_, err := NewTableFilter(tables)
+		assert.Error(t, err, tables)
+	}
+}
+
+func TestFileSinkDiscard(t *testing.T) {
+	sink := newDiscardFileSink()
+	chunk := &Chunk{ChunkHeader: ChunkHeader{Type: ChunkTypePayload, Path: "shop/customers.ibd"}}
+	chunk.Reader = strings.NewReader("payload")
+	assert.NoError(t, sink.Process(chunk))
+	assert.ErrorIs(t, sink.Process(&Chunk{ChunkHeader: ChunkHeader{Type: ChunkTypeEOF}}), ErrSinkEOF)
+}
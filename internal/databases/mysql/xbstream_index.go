@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/spf13/viper"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/databases/mysql/xbstream"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+// XbstreamIndexFileName is the index of the files in the xtrabackup stream, it is stored next to the stream
+// and allows to download only the parts of the split stream with the files of the restored tables
+const XbstreamIndexFileName = "xbstream_index.json"
+
+func xbstreamIndexNameFromBackup(backupName string) string {
+	return backupName + "/" + XbstreamIndexFileName
+}
+
+// xbstreamIndexer builds the index of the stream while the stream is uploaded
+type xbstreamIndexer struct {
+	writer *io.PipeWriter
+	result chan *xbstream.Index
+}
+
+func newXbstreamIndexer(stream io.Reader) (io.Reader, *xbstreamIndexer) {
+	reader, writer := io.Pipe()
+	indexer := &xbstreamIndexer{writer: writer, result: make(chan *xbstream.Index, 1)}
+	go func() {
+		index, err := xbstream.BuildIndex(reader)
+		if err != nil {
+			tracelog.WarningLogger.Printf("Failed to index the backup stream, the tables will be restored from the whole stream: %v", err)
+			_, _ = io.Copy(io.Discard, reader)
+		}
+		indexer.result <- index
+	}()
+	return io.TeeReader(stream, writer), indexer
+}
+
+// upload waits until the uploaded stream is indexed and uploads the index
+func (indexer *xbstreamIndexer) upload(ctx context.Context, uploader internal.Uploader, backupName string) error {
+	_ = indexer.writer.Close()
+	index := <-indexer.result
+	if index == nil {
+		return nil
+	}
+	return internal.UploadDto(ctx, uploader.Folder(), index, xbstreamIndexNameFromBackup(backupName))
+}
+
+// getTablesStreamFetcher returns the fetcher of the files needed to restore the tables.
+// Only the stream split into the files of WALG_STREAM_SPLITTER_MAX_FILE_SIZE can be downloaded partially:
+// the files of the stream which contain the chunks of the tables are found by the index of the backup.
+// Otherwise the whole stream is downloaded.
+func getTablesStreamFetcher(ctx context.Context, backup internal.Backup,
+	tableFilter *xbstream.TableFilter) (internal.StreamFetcher, error) {
+	var metadata internal.BackupStreamMetadata
+	err := internal.FetchDto(ctx, backup.Folder, &metadata, internal.StreamMetadataNameFromBackup(backup.Name))
+	var notFound storage.ObjectNotFoundError
+	if err != nil && !errors.As(err, &notFound) {
+		return nil, err
+	}
+	if metadata.Type != internal.SplitMergeStreamBackup || metadata.MaxFileSize == 0 {
+		tracelog.InfoLogger.Printf("The stream of %s is not split by %s, downloading the whole stream",
+			backup.Name, conf.StreamSplitterMaxFileSize)
+		return internal.GetBackupStreamFetcher(ctx, backup)
+	}
+
+	var index xbstream.Index
+	err = internal.FetchDto(ctx, backup.Folder, &index, xbstreamIndexNameFromBackup(backup.Name))
+	if errors.As(err, &notFound) {
+		tracelog.InfoLogger.Printf("The stream of %s is not indexed, downloading the whole stream", backup.Name)
+		return internal.GetBackupStreamFetcher(ctx, backup)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := index.Ranges(tableFilter)
+	maxDownloadRetry := viper.GetInt(conf.MysqlBackupDownloadMaxRetry)
+	return func(ctx context.Context, backup internal.Backup, writer io.WriteCloser) error {
+		return internal.DownloadSplittedStreamRanges(ctx, backup, metadata, ranges, writer, maxDownloadRetry)
+	}, nil
+}
@@ -3,6 +3,7 @@ package mysql
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
@@ -23,6 +24,7 @@ import (
 const (
 	XtrabackupApplyLogOnly   = "--apply-log-only"
 	XtrabackupIncrementalDir = "--incremental-dir"
+	XtrabackupExport         = "--export"
 )
 
 type XtrabackupInfo struct {
@@ -109,9 +111,12 @@ func enrichBackupArgs(backupCmd *exec.Cmd, xtrabackupExtraDirectory string, isFu
 	}
 }
 
-func GetXtrabackupFetcher(restoreCmd, prepareCmd *exec.Cmd, useXbtoolExtract bool, inplace bool) internal.Fetcher {
+// GetXtrabackupFetcher returns the fetcher of xtrabackup backups.
+// When the table filter is set, only the files of the tables are extracted and they are prepared for import.
+func GetXtrabackupFetcher(restoreCmd, prepareCmd *exec.Cmd, useXbtoolExtract bool, inplace bool,
+	tableFilter *xbstream.TableFilter) internal.Fetcher {
 	return func(ctx context.Context, folder storage.Folder, backup internal.Backup) {
-		err := xtrabackupFetch(ctx, backup.Name, folder, restoreCmd, prepareCmd, useXbtoolExtract, inplace, true)
+		err := xtrabackupFetch(ctx, backup.Name, folder, restoreCmd, prepareCmd, useXbtoolExtract, inplace, tableFilter, true)
 		tracelog.ErrorLogger.FatalfOnError("Failed to fetch backup: %v", err)
 	}
 }
@@ -124,6 +129,7 @@ func xtrabackupFetch(
 	prepareCmd *exec.Cmd,
 	useXbtoolExtract bool,
 	inplace bool,
+	tableFilter *xbstream.TableFilter,
 	isLast bool) error {
 	backup, err := internal.GetBackupByName(ctx, backupName, utility.BaseBackupPath, folder)
 	tracelog.ErrorLogger.FatalfOnError("Failed to fetch backup: %v", err)
@@ -140,14 +146,14 @@ func xtrabackupFetch(
 		tracelog.ErrorLogger.FatalfOnError("%v", err)
 
 		tracelog.InfoLogger.Printf("Delta from %v at LSN %x \n", *sentinel.IncrementFrom, *sentinel.IncrementFromLSN)
-		err = xtrabackupFetch(ctx, *sentinel.IncrementFrom, folder, restoreCmd, prepareCmd, useXbtoolExtract, inplace, false)
+		err = xtrabackupFetch(ctx, *sentinel.IncrementFrom, folder, restoreCmd, prepareCmd, useXbtoolExtract, inplace, tableFilter, false)
 		if err != nil {
 			return err
 		}
 	}
 
-	if useXbtoolExtract {
-		return xtrabackupFetchInhouse(ctx, backup, prepareCmd, inplace, isLast)
+	if useXbtoolExtract || tableFilter != nil {
+		return xtrabackupFetchInhouse(ctx, backup, prepareCmd, inplace, tableFilter, isLast)
 	}
 	return xtrabackupFetchClassic(ctx, backup, restoreCmd, prepareCmd, isLast)
 }
@@ -228,7 +234,8 @@ func xtrabackupFetchClassic(ctx context.Context, backup internal.Backup, restore
 	return os.RemoveAll(tempDeltaDir)
 }
 
-func xtrabackupFetchInhouse(ctx context.Context, backup internal.Backup, prepareCmd *exec.Cmd, inplace bool, isLast bool) error {
+func xtrabackupFetchInhouse(ctx context.Context, backup internal.Backup, prepareCmd *exec.Cmd, inplace bool,
+	tableFilter *xbstream.TableFilter, isLast bool) error {
 	// This is equivalent to:
 	//
 	// wal-g xb [extract|extract-diff] --decompress /var/lib/mysql  < BASE.xbstream
@@ -259,9 +266,18 @@ func xtrabackupFetchInhouse(ctx context.Context, backup internal.Backup, prepare
 	if !isLast {
 		prepareCmd = cloneCommand(ctx, prepareCmd)
 		injectCommandArgument(prepareCmd, XtrabackupApplyLogOnly)
+	} else if tableFilter != nil {
+		// xtrabackup writes .cfg files for ALTER TABLE ... IMPORT TABLESPACE
+		prepareCmd = cloneCommand(ctx, prepareCmd)
+		injectCommandArgument(prepareCmd, XtrabackupExport)
 	}
 
-	fetcher, err := internal.GetBackupStreamFetcher(ctx, backup)
+	var fetcher internal.StreamFetcher
+	if tableFilter != nil {
+		fetcher, err = getTablesStreamFetcher(ctx, backup, tableFilter)
+	} else {
+		fetcher, err = internal.GetBackupStreamFetcher(ctx, backup)
+	}
 	if err != nil {
 		tracelog.ErrorLogger.Printf("Failed to detect backup format: %v\n", err)
 		return err
@@ -274,13 +290,13 @@ func xtrabackupFetchInhouse(ctx context.Context, backup internal.Backup, prepare
 
 	if inplace && sentinel.IsIncremental {
 		// apply diff-files to dataDir inplace (and leave required leftovers incrementalDir)
-		go xbstream.AsyncDiffBackupSink(&wg, streamReader, dataDir, tempDeltaDir)
+		go xbstream.AsyncDiffBackupSink(&wg, streamReader, dataDir, tempDeltaDir, tableFilter)
 	} else {
 		destinationDir := tempDeltaDir
 		if !sentinel.IsIncremental {
 			destinationDir = dataDir
 		}
-		go xbstream.AsyncBackupSink(&wg, streamReader, destinationDir, true)
+		go xbstream.AsyncBackupSink(&wg, streamReader, destinationDir, true, tableFilter)
 	}
 
 	err = fetcher(ctx, backup, writer)
@@ -290,6 +306,11 @@ func xtrabackupFetchInhouse(ctx context.Context, backup internal.Backup, prepare
 	}
 	wg.Wait()
 	tracelog.InfoLogger.Printf("Restored %s", backup.Name)
+	if tableFilter != nil && isLast {
+		if missing := tableFilter.Missing(); len(missing) > 0 {
+			return fmt.Errorf("tables %s are not found in the backup", strings.Join(missing, ", "))
+		}
+	}
 
 	if prepareCmd != nil {
 		tracelog.InfoLogger.Printf("Preparing %s with cmd %v", backup.Name, prepareCmd.Args)
@@ -304,6 +325,19 @@ func xtrabackupFetchInhouse(ctx context.Context, backup internal.Backup, prepare
 	} else {
 		tracelog.InfoLogger.Printf("WALG_MYSQL_BACKUP_PREPARE_COMMAND not configured. Skipping prepare phase")
 	}
+	if tableFilter != nil && isLast {
+		logTableImport(dataDir, tableFilter.Tables())
+	}
 
 	return os.RemoveAll(tempDeltaDir)
 }
+
+func logTableImport(dataDir string, tables []string) {
+	for _, table := range tables {
+		database, name, _ := strings.Cut(table, ".")
+		quoted := quoteIdentifier(database) + "." + quoteIdentifier(name)
+		tracelog.InfoLogger.Printf("Table %s is exported to %s. To import it: ALTER TABLE %s DISCARD TABLESPACE; "+
+			"copy its .ibd and .cfg files to the database directory; ALTER TABLE %s IMPORT TABLESPACE;",
+			table, filepath.Join(dataDir, database), quoted, quoted)
+	}
+}
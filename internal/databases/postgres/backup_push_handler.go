@@ -400,13 +400,56 @@ func (bh *BackupHandler) handleBackupPushRemote(ctx context.Context) {
 	}
 	// If no arg is parsed, try to run remote backup using pglogrepl's BASE_BACKUP functionality
 	tracelog.InfoLogger.Println("Running remote backup through Postgres connection.")
-	tracelog.InfoLogger.Println("Features like delta backup and partial restore are disabled, there might be a performance impact.")
+	if bh.PgInfo.PgVersion < nativeIncrementalMinVersion {
+		tracelog.InfoLogger.Println("Features like delta backup and partial restore are disabled, there might be a performance impact.")
+	} else {
+		tracelog.InfoLogger.Println("Features like partial restore are disabled, there might be a performance impact.")
+	}
 	tracelog.InfoLogger.Println("To run with local backup functionalities, supply [db_directory].")
 	if bh.PgInfo.PgVersion < 110000 && !bh.Arguments.verifyPageChecksums {
 		tracelog.InfoLogger.Println("VerifyPageChecksums=false is only supported for streaming backup since PG11")
 		bh.Arguments.verifyPageChecksums = true
 	}
-	bh.createAndPushRemoteBackup(ctx)
+
+	var parentManifest []byte
+	if bh.Arguments.isFullBackup {
+		tracelog.InfoLogger.Println("Doing full backup.")
+	} else if bh.PgInfo.PgVersion >= nativeIncrementalMinVersion {
+		parentManifest = bh.configureRemoteDelta(ctx)
+	}
+	bh.createAndPushRemoteBackup(ctx, parentManifest)
+}
+
+// configureRemoteDelta selects the parent of the PG17+ incremental backup and downloads its backup manifest.
+// It returns nil if the backup should be full.
+func (bh *BackupHandler) configureRemoteDelta(ctx context.Context) []byte {
+	folder := bh.Arguments.Uploader.Folder()
+	prevBackupInfo, incrementCount, err := bh.Arguments.deltaConfigurator.Configure(ctx, folder, bh.Arguments.isPermanent)
+	tracelog.ErrorLogger.FatalOnError(err)
+	if prevBackupInfo.name == "" {
+		return nil
+	}
+	if prevBackupInfo.sentinelDto.PgVersion/10000 != bh.PgInfo.PgVersion/10000 {
+		tracelog.InfoLogger.Printf("Backup %s was made on other Postgres major version. Doing full backup.",
+			prevBackupInfo.name)
+		return nil
+	}
+	if prevBackupInfo.sentinelDto.SystemIdentifier != nil &&
+		bh.PgInfo.systemIdentifier != nil &&
+		*bh.PgInfo.systemIdentifier != *prevBackupInfo.sentinelDto.SystemIdentifier {
+		tracelog.ErrorLogger.FatalOnError(newBackupFromOtherBD())
+	}
+
+	manifest, err := fetchBackupManifest(ctx, folder.GetSubFolder(utility.BaseBackupPath), prevBackupInfo.name)
+	tracelog.ErrorLogger.FatalOnError(err)
+	if manifest == nil {
+		tracelog.InfoLogger.Printf("Backup %s has no backup manifest, it was not made through "+
+			"the Postgres connection. Doing full backup.", prevBackupInfo.name)
+		return nil
+	}
+	bh.prevBackupInfo = prevBackupInfo
+	bh.CurBackupInfo.incrementCount = incrementCount
+	return manifest
 }
 
 func (bh *BackupHandler) handleBackupPushLocal(ctx context.Context) {
@@ -439,7 +482,7 @@ func (bh *BackupHandler) handleBackupPushLocal(ctx context.Context) {
 	bh.createAndPushBackup(ctx)
 }
 
-func (bh *BackupHandler) createAndPushRemoteBackup(ctx context.Context) {
+func (bh *BackupHandler) createAndPushRemoteBackup(ctx context.Context, parentManifest []byte) {
 	var err error
 	uploader := bh.Arguments.Uploader
 	uploader.ChangeDirectory(utility.BaseBackupPath)
@@ -452,7 +495,7 @@ func (bh *BackupHandler) createAndPushRemoteBackup(ctx context.Context) {
 		tarFileSets = internal.NewRegularTarFileSets()
 	}
 
-	baseBackup := bh.runRemoteBackup(ctx)
+	baseBackup := bh.runRemoteBackup(ctx, parentManifest)
 	tracelog.InfoLogger.Println("Updating metadata")
 	bh.CurBackupInfo.startLSN = LSN(baseBackup.StartLSN)
 	bh.CurBackupInfo.endLSN = LSN(baseBackup.EndLSN)
@@ -482,6 +525,11 @@ func (bh *BackupHandler) uploadMetadata(ctx context.Context, sentinelDto BackupS
 	if err != nil {
 		tracelog.ErrorLogger.Fatalf("Failed to upload files metadata for backup %s: %v", curBackupName, err)
 	}
+	// the manifest goes after all the backup objects but before the sentinel
+	err = internal.UploadChecksumManifest(ctx, bh.Arguments.Uploader, curBackupName)
+	if err != nil {
+		tracelog.ErrorLogger.Fatalf("Failed to upload checksum manifest for backup %s: %v", curBackupName, err)
+	}
 	err = internal.UploadSentinel(ctx, bh.Arguments.Uploader, NewBackupSentinelDtoV2(sentinelDto, meta), bh.CurBackupInfo.Name)
 	if err != nil {
 		tracelog.ErrorLogger.Fatalf("Failed to upload sentinel file for backup %s: %v", curBackupName, err)
@@ -526,7 +574,7 @@ func NewBackupHandler(ctx context.Context, arguments BackupArguments) (bh *Backu
 	return bh, nil
 }
 
-func (bh *BackupHandler) runRemoteBackup(ctx context.Context) *StreamingBaseBackup {
+func (bh *BackupHandler) runRemoteBackup(ctx context.Context, parentManifest []byte) *StreamingBaseBackup {
 	var diskLimit int32
 	if viper.IsSet(conf.DiskRateLimitSetting) {
 		// Note that BASE_BACKUP (pg protocol) allows to limit in kb/sec
@@ -548,6 +596,18 @@ func (bh *BackupHandler) runRemoteBackup(ctx context.Context) *StreamingBaseBack
 	} else {
 		bundleFiles = &internal.RegularBundleFiles{}
 	}
+	if parentManifest != nil {
+		summarizeWal, err := walSummarizationEnabled(ctx, conn)
+		tracelog.ErrorLogger.FatalOnError(err)
+		if summarizeWal {
+			tracelog.InfoLogger.Printf("Incremental backup from %s", bh.prevBackupInfo.name)
+			baseBackup.SetIncrementFrom(bh.prevBackupInfo.name, parentManifest)
+		} else {
+			tracelog.WarningLogger.Println("Incremental backup requires summarize_wal to be enabled. Doing full backup.")
+			bh.prevBackupInfo = PrevBackupInfo{}
+			bh.CurBackupInfo.incrementCount = 0
+		}
+	}
 	tracelog.InfoLogger.Println("Starting remote backup")
 	err = baseBackup.Start(ctx, bh.Arguments.verifyPageChecksums, diskLimit)
 	tracelog.ErrorLogger.FatalOnError(err)
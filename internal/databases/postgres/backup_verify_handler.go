@@ -0,0 +1,400 @@
+package postgres
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	// VerifyReportSuffix is a suffix of the backup verification report stored next to the backup sentinel
+	VerifyReportSuffix = "_backup_verify_report.json"
+
+	consistentRecoveryLogLine = "consistent recovery state reached"
+)
+
+type BackupVerifyStatus string
+
+const (
+	BackupVerifyStatusOk      BackupVerifyStatus = "OK"
+	BackupVerifyStatusFailure BackupVerifyStatus = "FAILURE"
+	// BackupVerifyStatusUnverifiable means that the backup has no files metadata to check the restored files against
+	BackupVerifyStatusUnverifiable BackupVerifyStatus = "UNVERIFIABLE"
+)
+
+// BackupVerifyConfig configures the single backup-verify run
+type BackupVerifyConfig struct {
+	// ScratchDir is the directory to restore the backup to, it is created if empty
+	ScratchDir    string
+	KeepScratch   bool
+	StartPostgres bool
+	PgBinDir      string
+	UploadReport  bool
+}
+
+// BackupVerifyReport is the machine-readable result of backup-verify
+type BackupVerifyReport struct {
+	BackupName   string                     `json:"backup_name"`
+	Status       BackupVerifyStatus         `json:"status"`
+	StartTime    time.Time                  `json:"start_time"`
+	FinishTime   time.Time                  `json:"finish_time"`
+	FetchError   string                     `json:"fetch_error,omitempty"`
+	Unverifiable string                     `json:"unverifiable,omitempty"`
+	FilesChecked int                        `json:"files_checked"`
+	FileProblems []BackupVerifyFileProblem  `json:"file_problems,omitempty"`
+	Startup      *BackupVerifyStartupResult `json:"startup,omitempty"`
+}
+
+type BackupVerifyFileProblem struct {
+	Path          string   `json:"path"`
+	Problem       string   `json:"problem"`
+	CorruptBlocks []uint32 `json:"corrupt_blocks,omitempty"`
+}
+
+// BackupVerifyStartupResult describes the attempt to start a throwaway postgres on the restored backup
+type BackupVerifyStartupResult struct {
+	ReachedConsistency bool   `json:"reached_consistency"`
+	Error              string `json:"error,omitempty"`
+	LogPath            string `json:"log_path,omitempty"`
+}
+
+func (report *BackupVerifyReport) failed() bool {
+	return report.FetchError != "" || len(report.FileProblems) > 0 ||
+		report.Startup != nil && !report.Startup.ReachedConsistency
+}
+
+func (report *BackupVerifyReport) status() BackupVerifyStatus {
+	switch {
+	case report.failed():
+		return BackupVerifyStatusFailure
+	case report.Unverifiable != "":
+		return BackupVerifyStatusUnverifiable
+	default:
+		return BackupVerifyStatusOk
+	}
+}
+
+func HandleBackupVerify(ctx context.Context, rootFolder storage.Folder, targetBackupSelector internal.BackupSelector,
+	config BackupVerifyConfig) {
+	selectedBackup, err := targetBackupSelector.Select(ctx, rootFolder)
+	tracelog.ErrorLogger.FatalfOnError("Failed to select backup: %v\n", err)
+	backup := ToPgBackup(selectedBackup)
+
+	report, err := VerifyBackup(ctx, rootFolder, backup, config)
+	tracelog.ErrorLogger.FatalfOnError("Failed to verify backup: %v\n", err)
+
+	reportBytes, err := json.MarshalIndent(report, "", "    ")
+	tracelog.ErrorLogger.FatalOnError(err)
+	fmt.Println(string(reportBytes))
+
+	if config.UploadReport {
+		reportPath := backup.Name + VerifyReportSuffix
+		err = internal.UploadDto(ctx, backup.Folder, report, reportPath)
+		tracelog.ErrorLogger.FatalfOnError("Failed to upload the verification report: %v\n", err)
+		tracelog.InfoLogger.Printf("Verification report is uploaded to %s\n", reportPath)
+	}
+
+	if report.Status == BackupVerifyStatusUnverifiable {
+		tracelog.ErrorLogger.Fatalf("Backup %s can't be verified: %s\n", backup.Name, report.Unverifiable)
+	}
+	if report.Status != BackupVerifyStatusOk {
+		tracelog.ErrorLogger.Fatalf("Backup %s verification failed\n", backup.Name)
+	}
+}
+
+// VerifyBackup restores the backup to the scratch directory, checks the restored files against the files metadata,
+// validates the page checksums and optionally starts postgres on the restored data to reach consistency.
+// The returned error means that the verification could not be performed, problems of the backup itself are reported.
+func VerifyBackup(ctx context.Context, rootFolder storage.Folder, backup Backup,
+	config BackupVerifyConfig) (report BackupVerifyReport, err error) {
+	report = BackupVerifyReport{BackupName: backup.Name, StartTime: utility.TimeNowCrossPlatformUTC()}
+
+	scratchDir := config.ScratchDir
+	if scratchDir == "" {
+		scratchDir, err = os.MkdirTemp("", "wal-g-verify-")
+		if err != nil {
+			return report, err
+		}
+	}
+	if !config.KeepScratch {
+		defer func() {
+			if err := os.RemoveAll(scratchDir); err != nil {
+				tracelog.WarningLogger.Printf("Failed to remove the scratch directory %s: %v\n", scratchDir, err)
+			}
+		}()
+	}
+	dataDir := filepath.Join(scratchDir, "data")
+	if err = os.MkdirAll(dataDir, 0700); err != nil {
+		return report, err
+	}
+
+	sentinelDto, filesMetaDto, err := backup.GetSentinelAndFilesMetadata(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	tracelog.InfoLogger.Printf("Fetching backup %s to %s\n", backup.Name, dataDir)
+	extractProv := newVerifyExtractProvider(backup.Name)
+	err = fetchBackupToScratch(ctx, rootFolder, backup, sentinelDto, scratchDir, dataDir, extractProv)
+	if err != nil {
+		report.FetchError = err.Error()
+	} else {
+		if len(filesMetaDto.Files) == 0 {
+			// nothing tells which files the backup should restore
+			report.Unverifiable = "the backup has no files metadata, the restored files can't be checked"
+			tracelog.WarningLogger.Printf("Backup %s has no files metadata, the restored files are not checked\n", backup.Name)
+		} else {
+			report.FilesChecked, report.FileProblems, err = checkRestoredFiles(dataDir, filesMetaDto.Files, extractProv.modTimes)
+			if err != nil {
+				return report, err
+			}
+		}
+		if config.StartPostgres {
+			report.Startup = startPostgresToConsistency(ctx, dataDir, scratchDir, sentinelDto.PgVersion, config.PgBinDir)
+		}
+	}
+
+	report.Status = report.status()
+	report.FinishTime = utility.TimeNowCrossPlatformUTC()
+	return report, nil
+}
+
+func fetchBackupToScratch(ctx context.Context, rootFolder storage.Folder, backup Backup,
+	sentinelDto BackupSentinelDto, scratchDir, dataDir string, extractProv ExtractProvider) error {
+	filesToUnwrap, err := backup.GetFilesToUnwrap(ctx, "")
+	if err != nil {
+		return err
+	}
+	// tablespaces are restored into the scratch directory instead of their original locations
+	spec := newScratchTablespaceSpec(sentinelDto.TablespaceSpec, scratchDir, dataDir)
+	return deltaFetchRecursionOld(ctx, backup, rootFolder, dataDir, spec, filesToUnwrap, extractProv)
+}
+
+// verifyExtractProvider remembers the modification times from the tar headers of the verified backup,
+// so the restored files can be compared with the files metadata
+type verifyExtractProvider struct {
+	ExtractProviderImpl
+	backupName string
+	modTimes   *extractedModTimes
+}
+
+type extractedModTimes struct {
+	sync.Mutex
+	times map[string]time.Time
+}
+
+func newVerifyExtractProvider(backupName string) *verifyExtractProvider {
+	return &verifyExtractProvider{
+		backupName: backupName,
+		modTimes:   &extractedModTimes{times: make(map[string]time.Time)},
+	}
+}
+
+func (p *verifyExtractProvider) Get(ctx context.Context, backup Backup, filesToUnwrap map[string]bool, skipRedundantTars bool,
+	dbDataDir string, createNewIncrementalFiles bool) (IncrementalTarInterpreter, []internal.ReaderMaker, []internal.ReaderMaker, error) {
+	interpreter, concurrentTars, sequentialTars, err := p.ExtractProviderImpl.Get(ctx, backup, filesToUnwrap,
+		skipRedundantTars, dbDataDir, createNewIncrementalFiles)
+	// the base backups of a delta backup restore only the files which are skipped in the verified one
+	if err == nil && backup.Name == p.backupName {
+		interpreter = &modTimeRecordingInterpreter{IncrementalTarInterpreter: interpreter, modTimes: p.modTimes}
+	}
+	return interpreter, concurrentTars, sequentialTars, err
+}
+
+type modTimeRecordingInterpreter struct {
+	IncrementalTarInterpreter
+	modTimes *extractedModTimes
+}
+
+func (interpreter *modTimeRecordingInterpreter) Interpret(reader io.Reader, header *tar.Header) error {
+	interpreter.modTimes.Lock()
+	interpreter.modTimes.times[header.Name] = header.ModTime
+	interpreter.modTimes.Unlock()
+	return interpreter.IncrementalTarInterpreter.Interpret(reader, header)
+}
+
+func newScratchTablespaceSpec(sentinelSpec *TablespaceSpec, scratchDir, dataDir string) *TablespaceSpec {
+	spec := NewTablespaceSpec(dataDir)
+	if sentinelSpec == nil {
+		return &spec
+	}
+	for _, name := range sentinelSpec.TablespaceNames() {
+		spec.addTablespace(name, filepath.Join(scratchDir, "tablespaces", name))
+	}
+	return &spec
+}
+
+// checkRestoredFiles checks that every file from the files metadata is restored from the backup tars
+// with the recorded modification time, and validates the page checksums of the restored paged files.
+// The skipped files of a delta backup are restored from its base backups, so they are not checked.
+func checkRestoredFiles(dataDir string, files internal.BackupFileList,
+	modTimes *extractedModTimes) (int, []BackupVerifyFileProblem, error) {
+	fileNames := make([]string, 0, len(files))
+	for fileName, description := range files {
+		if !description.IsSkipped {
+			fileNames = append(fileNames, fileName)
+		}
+	}
+	slices.Sort(fileNames)
+
+	filesChecked := 0
+	problems := make([]BackupVerifyFileProblem, 0)
+	for _, fileName := range fileNames {
+		filePath := filepath.Join(dataDir, fileName)
+		fileInfo, err := os.Lstat(filePath)
+		if os.IsNotExist(err) {
+			problems = append(problems, BackupVerifyFileProblem{Path: fileName, Problem: "missing"})
+			filesChecked++
+			continue
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		// directories and symlinks are recorded in the files metadata too
+		if fileInfo.IsDir() || fileInfo.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		filesChecked++
+		if !fileInfo.Mode().IsRegular() {
+			problems = append(problems, BackupVerifyFileProblem{Path: fileName, Problem: "not a regular file"})
+			continue
+		}
+		if problem := checkRestoredModTime(fileName, files[fileName], modTimes); problem != "" {
+			problems = append(problems, BackupVerifyFileProblem{Path: fileName, Problem: problem})
+			continue
+		}
+
+		corruptBlocks, err := verifyRestoredPagedFile(filePath, fileInfo)
+		if err != nil {
+			return 0, nil, errors.Wrapf(err, "failed to verify pages of %s", fileName)
+		}
+		if len(corruptBlocks) > 0 {
+			problems = append(problems,
+				BackupVerifyFileProblem{Path: fileName, Problem: "corrupt pages", CorruptBlocks: corruptBlocks})
+		}
+	}
+	return filesChecked, problems, nil
+}
+
+func checkRestoredModTime(fileName string, description internal.BackupFileDescription, modTimes *extractedModTimes) string {
+	modTimes.Lock()
+	modTime, ok := modTimes.times[fileName]
+	modTimes.Unlock()
+	if !ok {
+		return "not found in the backup tars"
+	}
+	// the tar headers keep the modification time with the second precision
+	diff := modTime.Sub(description.MTime)
+	if diff <= -time.Second || diff >= time.Second {
+		return fmt.Sprintf("modification time %s differs from the recorded %s",
+			modTime.Format(time.RFC3339), description.MTime.Format(time.RFC3339))
+	}
+	return ""
+}
+
+func verifyRestoredPagedFile(filePath string, fileInfo os.FileInfo) ([]uint32, error) {
+	if !isChecksumValidatableFile(fileInfo, filePath) {
+		return nil, nil
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer utility.LoggedClose(file, "")
+	return VerifyPagedFileBase(filePath, fileInfo, file)
+}
+
+// startPostgresToConsistency starts postgres on the restored data directory with the recovery target
+// set to the consistent state and shutdown as the recovery target action, WAL is fetched by wal-g
+func startPostgresToConsistency(ctx context.Context, dataDir, scratchDir string, pgVersion int,
+	pgBinDir string) *BackupVerifyStartupResult {
+	result := &BackupVerifyStartupResult{LogPath: filepath.Join(scratchDir, "postgres.log")}
+	err := writeVerifyRecoveryConfig(dataDir, pgVersion)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	logFile, err := os.Create(result.LogPath)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer utility.LoggedClose(logFile, "")
+
+	postgresPath := "postgres"
+	if pgBinDir != "" {
+		postgresPath = filepath.Join(pgBinDir, "postgres")
+	}
+	cmd := exec.CommandContext(ctx, postgresPath, "-D", dataDir,
+		"-c", "listen_addresses=",
+		"-c", "unix_socket_directories="+scratchDir,
+		"-c", "archive_mode=off",
+		"-c", "hot_standby=off",
+		"-c", "logging_collector=off",
+		"-c", "shared_preload_libraries=")
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	tracelog.InfoLogger.Printf("Starting postgres on %s, see the log at %s\n", dataDir, result.LogPath)
+	runErr := cmd.Run()
+
+	logBytes, err := os.ReadFile(result.LogPath)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.ReachedConsistency = strings.Contains(string(logBytes), consistentRecoveryLogLine)
+	if runErr != nil {
+		result.Error = runErr.Error()
+	} else if !result.ReachedConsistency {
+		result.Error = "postgres stopped without reaching the consistent recovery state"
+	}
+	return result
+}
+
+func writeVerifyRecoveryConfig(dataDir string, pgVersion int) error {
+	walgPath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	restoreCommand := fmt.Sprintf("%s wal-fetch \"%%f\" \"%%p\"", walgPath)
+	if conf.CfgFile != "" {
+		restoreCommand += fmt.Sprintf(" --config %s", conf.CfgFile)
+	}
+	recoverySettings := fmt.Sprintf("restore_command = '%s'\nrecovery_target = 'immediate'\n"+
+		"recovery_target_action = 'shutdown'\n", restoreCommand)
+
+	// since PostgreSQL 12 the recovery settings are regular settings and recovery.signal triggers the recovery
+	if pgVersion >= 120000 {
+		autoConf, err := os.OpenFile(filepath.Join(dataDir, "postgresql.auto.conf"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		_, err = autoConf.WriteString("\n" + recoverySettings)
+		if closeErr := autoConf.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dataDir, "recovery.signal"), nil, 0600)
+	}
+	return os.WriteFile(filepath.Join(dataDir, "recovery.conf"), []byte(recoverySettings), 0600)
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+)
+
+func TestCheckRestoredFiles(t *testing.T) {
+	dataDir := t.TempDir()
+	mtime := time.Date(2026, 7, 21, 1, 0, 0, 0, time.UTC)
+	for _, name := range []string{"PG_VERSION", "pg_hba.conf", "postgresql.auto.conf", "backup_label"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dataDir, name), []byte("16\n"), 0600))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "global"), 0700))
+	require.NoError(t, os.Symlink("global", filepath.Join(dataDir, "pg_tblspc_link")))
+
+	files := internal.BackupFileList{
+		"PG_VERSION":           internal.BackupFileDescription{MTime: mtime.Add(300 * time.Millisecond)},
+		"pg_hba.conf":          internal.BackupFileDescription{MTime: mtime},
+		"postgresql.auto.conf": internal.BackupFileDescription{MTime: mtime},
+		"backup_label":         internal.BackupFileDescription{MTime: mtime},
+		"global":               internal.BackupFileDescription{MTime: mtime},
+		"pg_tblspc_link":       internal.BackupFileDescription{MTime: mtime},
+		"postgresql.conf":      internal.BackupFileDescription{MTime: mtime},
+		"base/1/1259":          internal.BackupFileDescription{IsSkipped: true, MTime: mtime},
+	}
+	modTimes := &extractedModTimes{times: map[string]time.Time{
+		"PG_VERSION":   mtime,
+		"pg_hba.conf":  mtime.Add(time.Hour),
+		"global":       mtime,
+		"backup_label": mtime,
+	}}
+	filesChecked, problems, err := checkRestoredFiles(dataDir, files, modTimes)
+	require.NoError(t, err)
+	assert.Equal(t, 5, filesChecked)
+	assert.Equal(t, []BackupVerifyFileProblem{
+		{Path: "pg_hba.conf", Problem: "modification time 2026-07-21T02:00:00Z differs from the recorded 2026-07-21T01:00:00Z"},
+		{Path: "postgresql.auto.conf", Problem: "not found in the backup tars"},
+		{Path: "postgresql.conf", Problem: "missing"},
+	}, problems)
+}
+
+func TestBackupVerifyReportFailed(t *testing.T) {
+	assert.False(t, (&BackupVerifyReport{}).failed())
+	assert.False(t, (&BackupVerifyReport{Startup: &BackupVerifyStartupResult{ReachedConsistency: true}}).failed())
+	assert.True(t, (&BackupVerifyReport{Startup: &BackupVerifyStartupResult{}}).failed())
+	assert.True(t, (&BackupVerifyReport{FetchError: "failed"}).failed())
+	assert.True(t, (&BackupVerifyReport{FileProblems: []BackupVerifyFileProblem{{Path: "x", Problem: "missing"}}}).failed())
+}
+
+func TestBackupVerifyReportStatus(t *testing.T) {
+	assert.Equal(t, BackupVerifyStatusOk, (&BackupVerifyReport{FilesChecked: 1}).status())
+	assert.Equal(t, BackupVerifyStatusUnverifiable, (&BackupVerifyReport{Unverifiable: "no files metadata"}).status())
+	assert.Equal(t, BackupVerifyStatusFailure,
+		(&BackupVerifyReport{Unverifiable: "no files metadata", FetchError: "failed"}).status())
+}
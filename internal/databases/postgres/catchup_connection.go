@@ -0,0 +1,270 @@
+package postgres
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/ioextensions"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	catchupHandshakeMagic   = "WGCU"
+	catchupProtocolVersion  = uint32(2)
+	catchupMaxMessageLength = 4096
+	catchupHandshakeTimeout = 30 * time.Second
+)
+
+// newCatchupTLSConfig builds the mutual TLS configuration of the catchup connection:
+// both sides present the certificate and check the certificate of the peer against the CA
+func newCatchupTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" || caFile == "" {
+		return nil, fmt.Errorf("%s, %s and %s must be set together",
+			conf.PgCatchupTLSCertFile, conf.PgCatchupTLSKeyFile, conf.PgCatchupTLSCAFile)
+	}
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load catchup TLS certificate")
+	}
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read catchup TLS CA")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		RootCAs:      pool,
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// configureCatchup returns the TLS configuration and the token of the catchup connection
+func configureCatchup() (*tls.Config, string, error) {
+	certFile, _ := conf.GetSetting(conf.PgCatchupTLSCertFile)
+	keyFile, _ := conf.GetSetting(conf.PgCatchupTLSKeyFile)
+	caFile, _ := conf.GetSetting(conf.PgCatchupTLSCAFile)
+	tlsConfig, err := newCatchupTLSConfig(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, "", err
+	}
+	token, _ := conf.GetSetting(conf.PgCatchupToken)
+	insecure, err := conf.GetBoolSettingDefault(conf.PgCatchupInsecure, false)
+	if err != nil {
+		return nil, "", err
+	}
+	err = checkCatchupProtection(tlsConfig, token, insecure)
+	return tlsConfig, token, err
+}
+
+// checkCatchupProtection refuses the connection which is neither protected by TLS nor authenticated by the token,
+// unless the insecure connection is allowed explicitly
+func checkCatchupProtection(tlsConfig *tls.Config, token string, insecure bool) error {
+	switch {
+	case tlsConfig != nil:
+		return nil
+	case token != "":
+		tracelog.WarningLogger.Printf("%s is not set, the catchup connection and the token are not encrypted by TLS",
+			conf.PgCatchupTLSCertFile)
+		return nil
+	case insecure:
+		tracelog.WarningLogger.Printf("%s is set, the catchup connection is neither encrypted nor authenticated",
+			conf.PgCatchupInsecure)
+		return nil
+	}
+	return fmt.Errorf("the catchup connection is neither encrypted nor authenticated: set %s, %s and %s, "+
+		"or %s, or set %s to allow the unprotected connection",
+		conf.PgCatchupTLSCertFile, conf.PgCatchupTLSKeyFile, conf.PgCatchupTLSCAFile,
+		conf.PgCatchupToken, conf.PgCatchupInsecure)
+}
+
+func dialCatchup(destination string, tlsConfig *tls.Config, token string) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", destination, catchupHandshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		host, _, err := net.SplitHostPort(destination)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		clientConfig := tlsConfig.Clone()
+		clientConfig.ServerName = host
+		conn = tls.Client(conn, clientConfig)
+	}
+	err = withCatchupDeadline(conn, catchupHandshakeTimeout, func() error {
+		return sendCatchupHandshake(conn, token)
+	})
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// acceptCatchupSenders checks the handshakes of the incoming connections concurrently and passes
+// the accepted ones to the senders channel, so the peer which does not complete the handshake
+// neither blocks the other connections nor is kept longer than the handshake timeout
+func acceptCatchupSenders(listener net.Listener, token string, timeout time.Duration,
+	senders chan<- net.Conn, done <-chan struct{}) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			tracelog.ErrorLogger.FatalOnError(err)
+		}
+		go func() {
+			err := withCatchupDeadline(conn, timeout, func() error {
+				return acceptCatchupHandshake(conn, token)
+			})
+			if err != nil {
+				tracelog.WarningLogger.Printf("Rejected the catchup connection from %v: %v", conn.RemoteAddr(), err)
+				utility.LoggedClose(conn, "")
+				return
+			}
+			select {
+			case senders <- conn:
+			case <-done:
+				utility.LoggedClose(conn, "")
+			}
+		}()
+	}
+}
+
+// withCatchupDeadline runs the handshake on the connection with the deadline,
+// the TLS handshake runs with the first read or write, so the deadline covers it too
+func withCatchupDeadline(conn net.Conn, timeout time.Duration, handshake func() error) error {
+	err := conn.SetDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return err
+	}
+	err = handshake()
+	if err != nil {
+		return err
+	}
+	return conn.SetDeadline(time.Time{})
+}
+
+// sendCatchupHandshake sends the protocol version and the token, the receiver answers
+// with an empty message if it accepts the connection and with the reason otherwise
+func sendCatchupHandshake(conn io.ReadWriter, token string) error {
+	hello := append([]byte(catchupHandshakeMagic), binary.BigEndian.AppendUint32(nil, catchupProtocolVersion)...)
+	_, err := conn.Write(hello)
+	if err != nil {
+		return errors.Wrap(err, "failed to send catchup handshake")
+	}
+	err = writeCatchupMessage(conn, token)
+	if err != nil {
+		return errors.Wrap(err, "failed to send catchup handshake")
+	}
+	reason, err := readCatchupMessage(conn)
+	if err != nil {
+		return errors.Wrap(err, "failed to read catchup handshake response")
+	}
+	if reason != "" {
+		return fmt.Errorf("catchup receiver rejected the connection: %s", reason)
+	}
+	return nil
+}
+
+// acceptCatchupHandshake checks the protocol version and the token of the sender
+func acceptCatchupHandshake(conn io.ReadWriter, token string) error {
+	hello := make([]byte, len(catchupHandshakeMagic)+4)
+	_, err := io.ReadFull(conn, hello)
+	if err != nil {
+		return errors.Wrap(err, "failed to read catchup handshake")
+	}
+	if string(hello[:len(catchupHandshakeMagic)]) != catchupHandshakeMagic {
+		return errors.New("the peer is not a catchup sender")
+	}
+	var reason string
+	if version := binary.BigEndian.Uint32(hello[len(catchupHandshakeMagic):]); version != catchupProtocolVersion {
+		reason = fmt.Sprintf("unsupported catchup protocol version %d, expected %d", version, catchupProtocolVersion)
+	}
+	peerToken, err := readCatchupMessage(conn)
+	if err != nil {
+		return errors.Wrap(err, "failed to read catchup handshake")
+	}
+	if reason == "" && subtle.ConstantTimeCompare([]byte(peerToken), []byte(token)) != 1 {
+		reason = "invalid token"
+	}
+	err = writeCatchupMessage(conn, reason)
+	if err != nil {
+		return errors.Wrap(err, "failed to send catchup handshake response")
+	}
+	if reason != "" {
+		return errors.New(reason)
+	}
+	return nil
+}
+
+func writeCatchupMessage(writer io.Writer, message string) error {
+	if len(message) > catchupMaxMessageLength {
+		return fmt.Errorf("catchup handshake message is longer than %d bytes", catchupMaxMessageLength)
+	}
+	_, err := writer.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(message))), message...))
+	return err
+}
+
+func readCatchupMessage(reader io.Reader) (string, error) {
+	var length uint32
+	err := binary.Read(reader, binary.BigEndian, &length)
+	if err != nil {
+		return "", err
+	}
+	if length > catchupMaxMessageLength {
+		return "", fmt.Errorf("catchup handshake message is longer than %d bytes", catchupMaxMessageLength)
+	}
+	message := make([]byte, length)
+	_, err = io.ReadFull(reader, message)
+	return string(message), err
+}
+
+// newCatchupCodec compresses and encrypts the gob stream of catchup commands
+func newCatchupCodec(conn net.Conn) (ioextensions.WriteFlushCloser, *gob.Decoder, *gob.Encoder, error) {
+	cmpr, decmpr := chooseCompression()
+
+	writer := cmpr.NewWriter(conn)
+	reader, err := decmpr.Decompress(conn)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	crypter := internal.ConfigureCrypter()
+	if crypter == nil {
+		return writer, gob.NewDecoder(reader), gob.NewEncoder(writer), nil
+	}
+	decrypt, err := crypter.Decrypt(reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	encrypt, err := crypter.Encrypt(writer)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return writer, gob.NewDecoder(decrypt), gob.NewEncoder(encrypt), nil
+}
@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/gob"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatchupHandshake(t *testing.T) {
+	tests := []struct {
+		name          string
+		senderToken   string
+		receiverToken string
+		senderError   string
+		receiverError string
+	}{
+		{name: "matching tokens", senderToken: "secret", receiverToken: "secret"},
+		{name: "no tokens"},
+		{
+			name: "wrong token", senderToken: "guess", receiverToken: "secret",
+			senderError:   "catchup receiver rejected the connection: invalid token",
+			receiverError: "invalid token",
+		},
+		{
+			name: "missing token", receiverToken: "secret",
+			senderError:   "catchup receiver rejected the connection: invalid token",
+			receiverError: "invalid token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, receiver := net.Pipe()
+			defer sender.Close()
+			defer receiver.Close()
+			receiverErr := make(chan error, 1)
+			go func() {
+				receiverErr <- acceptCatchupHandshake(receiver, tt.receiverToken)
+			}()
+			err := sendCatchupHandshake(sender, tt.senderToken)
+			if tt.senderError == "" {
+				require.NoError(t, err)
+				require.NoError(t, <-receiverErr)
+				return
+			}
+			require.EqualError(t, err, tt.senderError)
+			require.EqualError(t, <-receiverErr, tt.receiverError)
+		})
+	}
+}
+
+func TestCatchupHandshake_NotSender(t *testing.T) {
+	sender, receiver := net.Pipe()
+	defer receiver.Close()
+	go func() {
+		_, _ = sender.Write([]byte("GET / HTTP/1.1\r\n"))
+		_ = sender.Close()
+	}()
+	require.EqualError(t, acceptCatchupHandshake(receiver, ""), "the peer is not a catchup sender")
+}
+
+func TestAcceptCatchupSenders_HandshakeTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	senders := make(chan net.Conn)
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		_ = listener.Close()
+	}()
+	go acceptCatchupSenders(listener, "secret", 100*time.Millisecond, senders, done)
+
+	// the silent peer neither blocks the sender nor is kept after the handshake timeout
+	silent, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer silent.Close()
+	conn, err := dialCatchup(listener.Addr().String(), nil, "secret")
+	require.NoError(t, err)
+	defer conn.Close()
+	accepted := <-senders
+	require.NoError(t, accepted.Close())
+
+	require.NoError(t, silent.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = silent.Read(make([]byte, 1))
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestCheckCatchupProtection(t *testing.T) {
+	require.NoError(t, checkCatchupProtection(&tls.Config{}, "", false))
+	require.NoError(t, checkCatchupProtection(nil, "secret", false))
+	require.NoError(t, checkCatchupProtection(nil, "", true))
+	require.ErrorContains(t, checkCatchupProtection(nil, "", false), "WALG_CATCHUP_INSECURE")
+}
+
+type testCertificateAuthority struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	pemFile     string
+}
+
+func newTestCertificateAuthority(t *testing.T, dir string) *testCertificateAuthority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "catchup CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pemFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(pemFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return &testCertificateAuthority{certificate: certificate, key: key, pemFile: pemFile}
+}
+
+// issue writes the certificate for 127.0.0.1 signed by the CA and its key to the dir
+func (ca *testCertificateAuthority) issue(t *testing.T, dir, name string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certFile = filepath.Join(dir, name+".pem")
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+func TestCatchupMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCertificateAuthority(t, dir)
+	receiverCert, receiverKey := ca.issue(t, dir, "receiver")
+	senderCert, senderKey := ca.issue(t, dir, "sender")
+	receiverConfig, err := newCatchupTLSConfig(receiverCert, receiverKey, ca.pemFile)
+	require.NoError(t, err)
+	senderConfig, err := newCatchupTLSConfig(senderCert, senderKey, ca.pemFile)
+	require.NoError(t, err)
+
+	otherDir := t.TempDir()
+	otherCA := newTestCertificateAuthority(t, otherDir)
+	strangerCert, strangerKey := otherCA.issue(t, otherDir, "stranger")
+	strangerConfig, err := newCatchupTLSConfig(strangerCert, strangerKey, ca.pemFile)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener = tls.NewListener(listener, receiverConfig)
+	defer listener.Close()
+	receiverErrs := make(chan error)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			receiverErrs <- acceptCatchupHandshake(conn, "secret")
+			_ = conn.Close()
+		}
+	}()
+
+	conn, err := dialCatchup(listener.Addr().String(), senderConfig, "secret")
+	require.NoError(t, err)
+	require.NoError(t, <-receiverErrs)
+	require.NoError(t, conn.Close())
+
+	_, err = dialCatchup(listener.Addr().String(), strangerConfig, "secret")
+	require.Error(t, err)
+	require.ErrorContains(t, <-receiverErrs, "certificate")
+}
+
+func TestNewCatchupTLSConfig_PartialSettings(t *testing.T) {
+	config, err := newCatchupTLSConfig("", "", "")
+	require.NoError(t, err)
+	require.Nil(t, config)
+
+	_, err = newCatchupTLSConfig("cert.pem", "", "ca.pem")
+	require.EqualError(t, err,
+		"WALG_CATCHUP_TLS_CERT_FILE, WALG_CATCHUP_TLS_KEY_FILE and WALG_CATCHUP_TLS_CA_FILE must be set together")
+}
+
+func TestDoRcvCommand_ResumesInterruptedFile(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Date(2026, 7, 21, 12, 0, 0, 123456789, time.UTC)
+	cmd := CatchupCommandDto{FileName: "PG_VERSION", IsFull: true, FileSize: 6, MTime: mtime}
+
+	// the connection drops in the middle of the file
+	var stream bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&stream).Encode([]byte("17")))
+	require.Error(t, doRcvCommand(cmd, dir, gob.NewDecoder(&stream)))
+	require.NoFileExists(t, filepath.Join(dir, "PG_VERSION"))
+	require.FileExists(t, filepath.Join(dir, "PG_VERSION"+catchupPartialFileSuffix))
+
+	files := receiveFileList(dir)
+	require.NotContains(t, files, "PG_VERSION"+catchupPartialFileSuffix)
+	require.NoFileExists(t, filepath.Join(dir, "PG_VERSION"+catchupPartialFileSuffix))
+
+	stream.Reset()
+	encoder := gob.NewEncoder(&stream)
+	require.NoError(t, encoder.Encode([]byte("17")))
+	require.NoError(t, encoder.Encode([]byte("\nabc")))
+	require.NoError(t, doRcvCommand(cmd, dir, gob.NewDecoder(&stream)))
+	content, err := os.ReadFile(filepath.Join(dir, "PG_VERSION"))
+	require.NoError(t, err)
+	require.Equal(t, "17\nabc", string(content))
+
+	// the file keeps the modification time of the sent file, so the sender skips it after reconnecting
+	files = receiveFileList(dir)
+	require.True(t, files["PG_VERSION"].MTime.Equal(mtime))
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/gob"
 	"fmt"
 	"io"
@@ -12,6 +13,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
@@ -21,6 +23,8 @@ import (
 	"github.com/wal-g/wal-g/utility"
 )
 
+const catchupPartialFileSuffix = ".walg_catchup_partial"
+
 func HandleCatchupSend(ctx context.Context, pgDataDirectory string, destination string) {
 	pgDataDirectory = utility.ResolveSymlink(pgDataDirectory)
 	tracelog.InfoLogger.Printf("Sending %v to %v\n", pgDataDirectory, destination)
@@ -83,29 +87,13 @@ func HandleCatchupSend(ctx context.Context, pgDataDirectory string, destination
 }
 
 func startSendConnection(destination string) (ioextensions.WriteFlushCloser, *gob.Decoder, *gob.Encoder) {
-	dial, err := net.Dial("tcp", destination)
+	tlsConfig, token, err := configureCatchup()
+	tracelog.ErrorLogger.FatalOnError(err)
+	conn, err := dialCatchup(destination, tlsConfig, token)
 	tracelog.ErrorLogger.FatalOnError(err)
 
-	crypter := internal.ConfigureCrypter()
-
-	cmpr, decmpr := chooseCompression()
-
-	writer := cmpr.NewWriter(dial)
-	reader, err := decmpr.Decompress(dial)
+	writer, decoder, encoder, err := newCatchupCodec(conn)
 	tracelog.ErrorLogger.FatalOnError(err)
-	var decoder *gob.Decoder
-	var encoder *gob.Encoder
-	if crypter != nil {
-		decrypt, err := crypter.Decrypt(reader)
-		tracelog.ErrorLogger.FatalOnError(err)
-		decoder = gob.NewDecoder(decrypt)
-		encrypt, err := crypter.Encrypt(writer)
-		tracelog.ErrorLogger.FatalOnError(err)
-		encoder = gob.NewEncoder(encrypt)
-	} else {
-		decoder = gob.NewDecoder(reader)
-		encoder = gob.NewEncoder(writer)
-	}
 	return writer, decoder, encoder
 }
 
@@ -217,8 +205,9 @@ func sendOneFile(ctx context.Context, path string, info fs.FileInfo, wasInBase b
 		}
 	}
 
-	err = encoder.Encode(
-		CatchupCommandDto{FileName: fullFileName, IsFull: !increment, FileSize: uint64(size), IsIncremental: increment})
+	err = encoder.Encode(CatchupCommandDto{
+		FileName: fullFileName, IsFull: !increment, FileSize: uint64(size), IsIncremental: increment, MTime: info.ModTime(),
+	})
 	tracelog.ErrorLogger.FatalOnError(err)
 	reader := io.MultiReader(fd, &ioextensions.ZeroReader{})
 
@@ -237,48 +226,64 @@ func sendOneFile(ctx context.Context, path string, info fs.FileInfo, wasInBase b
 	tracelog.ErrorLogger.FatalOnError(err)
 }
 
+// HandleCatchupReceive waits for the catchup sender. If the connection drops, the received files are kept
+// and the receiver waits for the sender to reconnect: the files received completely are not sent again.
 func HandleCatchupReceive(pgDataDirectory string, port int) {
 	pgDataDirectory = utility.ResolveSymlink(pgDataDirectory)
 	tracelog.InfoLogger.Printf("Receiving %v on port %v\n", pgDataDirectory, port)
-	listen, err := net.Listen("tcp", fmt.Sprintf(":%v", port))
-	tracelog.ErrorLogger.FatalOnError(err)
-	conn, err := listen.Accept()
+	tlsConfig, token, err := configureCatchup()
 	tracelog.ErrorLogger.FatalOnError(err)
-
-	cmpr, decmpr := chooseCompression()
-
-	writer := cmpr.NewWriter(conn)
-	reader, err := decmpr.Decompress(conn)
+	listen, err := net.Listen("tcp", fmt.Sprintf(":%v", port))
 	tracelog.ErrorLogger.FatalOnError(err)
+	if tlsConfig != nil {
+		listen = tls.NewListener(listen, tlsConfig)
+	}
+	defer utility.LoggedClose(listen, "")
+
+	senders := make(chan net.Conn)
+	done := make(chan struct{})
+	defer close(done)
+	go acceptCatchupSenders(listen, token, catchupHandshakeTimeout, senders, done)
+	for conn := range senders {
+		err = receiveCatchup(conn, pgDataDirectory)
+		utility.LoggedClose(conn, "")
+		if err == nil {
+			break
+		}
+		tracelog.WarningLogger.Printf("Catchup from %v failed: %v. Waiting for the sender to reconnect",
+			conn.RemoteAddr(), err)
+	}
+	tracelog.InfoLogger.Printf("Receive done")
+}
 
-	crypter := internal.ConfigureCrypter()
-
-	var decoder *gob.Decoder
-	var encoder *gob.Encoder
-	if crypter != nil {
-		decrypt, err := crypter.Decrypt(reader)
-		tracelog.ErrorLogger.FatalOnError(err)
-		decoder = gob.NewDecoder(decrypt)
-		encrypt, err := crypter.Encrypt(writer)
-		tracelog.ErrorLogger.FatalOnError(err)
-		encoder = gob.NewEncoder(encrypt)
-	} else {
-		decoder = gob.NewDecoder(reader)
-		encoder = gob.NewEncoder(writer)
+func receiveCatchup(conn net.Conn, pgDataDirectory string) error {
+	tracelog.InfoLogger.Printf("Catchup sender %v connected", conn.RemoteAddr())
+	writer, decoder, encoder, err := newCatchupCodec(conn)
+	if err != nil {
+		return err
+	}
+	err = sendControlAndFileList(pgDataDirectory, encoder)
+	if err != nil {
+		return err
 	}
-	sendControlAndFileList(pgDataDirectory, encoder)
 	err = writer.Flush()
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return err
+	}
 	for {
 		var cmd CatchupCommandDto
 		err := decoder.Decode(&cmd)
-		tracelog.ErrorLogger.FatalOnError(err)
+		if err != nil {
+			return err
+		}
 		if cmd.IsDone {
-			break
+			return nil
+		}
+		err = doRcvCommand(cmd, pgDataDirectory, decoder)
+		if err != nil {
+			return err
 		}
-		doRcvCommand(cmd, pgDataDirectory, decoder)
 	}
-	tracelog.InfoLogger.Printf("Receive done")
 }
 
 type DecoderReader struct {
@@ -293,60 +298,89 @@ func (d *DecoderReader) Read(bytes []byte) (n int, err error) {
 	}
 	if len(d.buf) == 0 {
 		err := d.Decode(&d.buf)
-		tracelog.ErrorLogger.FatalOnError(err)
+		if err != nil {
+			return 0, err
+		}
 	}
 	i := copy(bytes, d.buf)
 	i = utility.Min(i, int(d.size))
 	d.buf = d.buf[i:]
 	d.size -= int64(i)
-	return i, err
+	return i, nil
 }
 
-func doRcvCommand(cmd CatchupCommandDto, directory string, decoder *gob.Decoder) {
+// doRcvCommand applies the command, it returns the errors of the connection,
+// the local errors are fatal
+func doRcvCommand(cmd CatchupCommandDto, directory string, decoder *gob.Decoder) error {
 	if cmd.IsBinContents {
 		tracelog.InfoLogger.Printf("Writing file %v", cmd.FileName)
 		err := os.WriteFile(path.Join(directory, cmd.FileName), cmd.BinaryContents, 0666)
 		tracelog.ErrorLogger.FatalOnError(err)
-		return
+		return nil
 	}
 
 	if cmd.IsFull {
 		tracelog.InfoLogger.Printf("Full file %v", cmd.FileName)
-		fd, err := os.Create(path.Join(directory, cmd.FileName))
+		// the file is written under a temporary name, so an interrupted catchup doesn't leave it half written
+		filePath := path.Join(directory, cmd.FileName)
+		fd, err := os.Create(filePath + catchupPartialFileSuffix)
 		tracelog.ErrorLogger.FatalOnError(err)
 		size := int64(cmd.FileSize)
 		for size != 0 {
 			var bytes []byte
 			err := decoder.Decode(&bytes)
-			tracelog.ErrorLogger.FatalOnError(err)
+			if err != nil {
+				utility.LoggedClose(fd, "")
+				return err
+			}
 			_, err = fd.Write(bytes)
 			tracelog.ErrorLogger.FatalOnError(err)
 			size -= int64(len(bytes))
 		}
 		tracelog.InfoLogger.Printf("Received %v bytes", cmd.FileSize)
-		tracelog.ErrorLogger.FatalOnError(err)
 		err = fd.Close()
 		tracelog.ErrorLogger.FatalOnError(err)
-		return
+		err = os.Rename(filePath+catchupPartialFileSuffix, filePath)
+		tracelog.ErrorLogger.FatalOnError(err)
+		setCatchupFileMTime(filePath, cmd.MTime)
+		return nil
 	}
 
 	if cmd.IsIncremental {
 		tracelog.InfoLogger.Printf("Incremental file %v", cmd.FileName)
 
-		err := ApplyFileIncrement(path.Join(directory, cmd.FileName),
-			&DecoderReader{decoder, nil, int64(cmd.FileSize)}, true, false)
-		tracelog.ErrorLogger.FatalOnError(err)
-		return
+		filePath := path.Join(directory, cmd.FileName)
+		err := ApplyFileIncrement(filePath, &DecoderReader{decoder, nil, int64(cmd.FileSize)}, true, false)
+		if err != nil {
+			return err
+		}
+		setCatchupFileMTime(filePath, cmd.MTime)
+		return nil
 	}
 	if cmd.IsDelete {
 		tracelog.InfoLogger.Printf("Deleting files %v", cmd.FilesToDelete)
 		for _, f := range cmd.FilesToDelete {
 			err := os.Remove(path.Join(directory, f))
+			if os.IsNotExist(err) {
+				// deleted before the connection dropped
+				continue
+			}
 			tracelog.ErrorLogger.FatalOnError(err)
 		}
-		return
+		return nil
 	}
 	tracelog.ErrorLogger.Fatal("Unknown command")
+	return nil
+}
+
+// setCatchupFileMTime sets the modification time of the sent file, so the file is not sent again
+// if the sender reconnects
+func setCatchupFileMTime(filePath string, mtime time.Time) {
+	if mtime.IsZero() {
+		return
+	}
+	err := os.Chtimes(filePath, mtime, mtime)
+	tracelog.ErrorLogger.FatalOnError(err)
 }
 
 type CatchupCommandDto struct {
@@ -359,19 +393,20 @@ type CatchupCommandDto struct {
 	FileName       string
 	BinaryContents []byte
 	FilesToDelete  []string
+	MTime          time.Time
 }
 
-func sendControlAndFileList(pgDataDirectory string, encoder *gob.Encoder) {
+func sendControlAndFileList(pgDataDirectory string, encoder *gob.Encoder) error {
 	control, err := ExtractPgControl(pgDataDirectory)
 	tracelog.ErrorLogger.FatalOnError(err)
 	tracelog.InfoLogger.Printf("Our system id %v, need catchup from %v",
 		control.SystemIdentifier, control.Checkpoint)
-	tracelog.ErrorLogger.FatalOnError(err)
 	err = encoder.Encode(control)
-	tracelog.ErrorLogger.FatalOnError(err)
+	if err != nil {
+		return err
+	}
 	rcvFileList := receiveFileList(pgDataDirectory)
-	err = encoder.Encode(rcvFileList)
-	tracelog.ErrorLogger.FatalOnError(err)
+	return encoder.Encode(rcvFileList)
 }
 
 func receiveFileList(directory string) internal.BackupFileList {
@@ -384,6 +419,10 @@ func receiveFileList(directory string) internal.BackupFileList {
 		if info.Name() == PgControl {
 			return nil
 		}
+		if !info.IsDir() && strings.HasSuffix(path, catchupPartialFileSuffix) {
+			tracelog.InfoLogger.Printf("Removing %v left by the interrupted catchup", path)
+			return os.Remove(path)
+		}
 		fileName := info.Name()
 		_, excluded := ExcludedFilenames[fileName]
 		isDir := info.IsDir()
@@ -0,0 +1,210 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	PITRWindowFlag        = "pitr-window"
+	PITRWindowDescription = "Keep the backups and WALs needed to restore to any moment inside the window, e.g. 14d or 36h"
+)
+
+// HandleDeleteRetainWithPITRWindow works like the delete retain but never deletes the backups and WAL segments
+// needed to restore to any moment of the PITR window. Retention count is optional if the window is set.
+func (dh *DeleteHandler) HandleDeleteRetainWithPITRWindow(ctx context.Context, args []string, afterStr string,
+	window time.Duration, confirmed bool) {
+	target, err := dh.FindTargetRetainWithPITRWindow(ctx, args, afterStr, time.Now().Add(-window))
+	tracelog.ErrorLogger.FatalOnError(err)
+	if target == nil {
+		tracelog.InfoLogger.Printf("No backup found for deletion")
+		os.Exit(0)
+	}
+	err = dh.DeleteBeforeTarget(ctx, target, confirmed)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
+// HandleDeleteAuto works like the generic delete auto, but WALG_RETENTION_PITR_WINDOW is evaluated
+// by FindPITRWindowTarget, so nothing is deleted if the WAL chain or the timeline history of the window is broken
+func (dh *DeleteHandler) HandleDeleteAuto(ctx context.Context, retention internal.RetentionConfig, confirmed bool) {
+	err := dh.DeleteAuto(ctx, retention, confirmed)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
+func (dh *DeleteHandler) DeleteAuto(ctx context.Context, retention internal.RetentionConfig, confirmed bool) error {
+	retained, err := dh.PlanAutoRetentionWithPITRWindow(retention,
+		func(windowStart time.Time) (internal.BackupObject, error) {
+			return dh.FindPITRWindowTarget(ctx, windowStart)
+		})
+	if err != nil || retained == nil {
+		return err
+	}
+	folderFilter := func(string) bool { return true }
+	return dh.DeleteNotRetained(ctx, retained, confirmed, folderFilter)
+}
+
+// FindTargetRetainWithPITRWindow returns the older one of the retain target and the PITR window target
+func (dh *DeleteHandler) FindTargetRetainWithPITRWindow(ctx context.Context, args []string, afterStr string,
+	windowStart time.Time) (internal.BackupObject, error) {
+	pitrTarget, err := dh.FindPITRWindowTarget(ctx, windowStart)
+	if err != nil || pitrTarget == nil || len(args) == 0 {
+		return pitrTarget, err
+	}
+
+	modifier, retentionStr := internal.ExtractDeleteModifierFromArgs(args)
+	retentionCount, err := strconv.Atoi(retentionStr)
+	if err != nil {
+		return nil, err
+	}
+	var retainTarget internal.BackupObject
+	if afterStr == "" {
+		retainTarget, err = dh.FindTargetRetain(retentionCount, modifier)
+	} else {
+		retainTarget, err = dh.FindTargetRetainAfter(retentionCount, afterStr, modifier)
+	}
+	if err != nil || retainTarget == nil {
+		return nil, err
+	}
+
+	if dh.Less(pitrTarget, retainTarget) {
+		tracelog.InfoLogger.Printf("Backup %s is needed for the PITR window, the backups after it will be kept\n",
+			pitrTarget.GetBackupName())
+		return pitrTarget, nil
+	}
+	return retainTarget, nil
+}
+
+// FindPITRWindowTarget returns the full backup which everything before can be deleted
+// while keeping every moment after windowStart restorable. It is the base of the newest backup
+// of the current timeline history which had finished before the window start.
+// Returns nil if there is no such backup, so nothing can be deleted.
+// It fails if the WAL segments from that backup to the latest archived segment are not continuous.
+func (dh *DeleteHandler) FindPITRWindowTarget(ctx context.Context, windowStart time.Time) (internal.BackupObject, error) {
+	baseBackupFolder := dh.Folder.GetSubFolder(utility.BaseBackupPath)
+	walFolder := dh.Folder.GetSubFolder(utility.WalPath)
+
+	walFilenames, err := getFolderFilenames(ctx, walFolder)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to list the WAL folder")
+	}
+	storageSegments := getSegmentsFromFiles(walFilenames)
+	latestSegment, ok := findLatestWalSegment(storageSegments)
+	if !ok {
+		return nil, utility.NewForbiddenActionError("PITR window cannot be guaranteed: no WAL segments found in storage")
+	}
+
+	timelineSwitchMap, err := createTimelineSwitchMap(ctx, latestSegment.Timeline, walFolder)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to initialize timeline history map")
+	}
+	switchSegNoByTimeline := make(map[uint32]WalSegmentNo, len(timelineSwitchMap))
+	for _, historyRecord := range timelineSwitchMap {
+		switchSegNoByTimeline[historyRecord.timeline] = NewWalSegmentNo(historyRecord.lsn)
+	}
+
+	backups, err := internal.GetBackups(ctx, baseBackupFolder)
+	if err != nil {
+		return nil, err
+	}
+	backupDetails, err := GetBackupsDetails(ctx, baseBackupFolder, backups)
+	if err != nil {
+		return nil, err
+	}
+	// newest first
+	slices.SortFunc(backupDetails, func(a, b BackupDetail) int {
+		return b.FinishTime.Compare(a.FinishTime)
+	})
+
+	for i := range backupDetails {
+		backupDetail := &backupDetails[i]
+		if backupDetail.FinishTime.After(windowStart) {
+			continue
+		}
+		backupTimeline, backupSegNo, err := ParseWALFilename(backupDetail.WalFileName)
+		if err != nil {
+			return nil, err
+		}
+		if !checkBackupIsCorrect(latestSegment.Timeline, backupDetail, backupTimeline, WalSegmentNo(backupSegNo),
+			switchSegNoByTimeline, true) {
+			continue
+		}
+
+		err = checkWalChainIsContinuous(latestSegment, WalSegmentNo(backupSegNo), storageSegments, timelineSwitchMap)
+		if err != nil {
+			return nil, utility.NewForbiddenActionError(fmt.Sprintf(
+				"PITR window cannot be guaranteed from backup %s: %v", backupDetail.BackupName, err))
+		}
+		tracelog.InfoLogger.Printf("Backup %s is the base for the PITR window starting at %s\n",
+			backupDetail.BackupName, windowStart.Format(time.RFC3339))
+
+		target, err := dh.FindTargetByName(backupDetail.BackupName)
+		if err != nil {
+			return nil, err
+		}
+		if !target.IsFullBackup() {
+			return dh.FindTargetByName(target.GetBaseBackupName())
+		}
+		return target, nil
+	}
+
+	tracelog.InfoLogger.Printf("No backup had finished before the PITR window start %s\n",
+		windowStart.Format(time.RFC3339))
+	return nil, nil
+}
+
+// checkWalChainIsContinuous scans the WAL segments backwards from the latest one to the stop segment
+// and fails if some of them are lost
+func checkWalChainIsContinuous(
+	latestSegment WalSegmentDescription,
+	stopSegmentNo WalSegmentNo,
+	storageSegments map[WalSegmentDescription]bool,
+	timelineSwitchMap map[WalSegmentNo]*TimelineHistoryRecord,
+) error {
+	uploadingSegmentRangeSize, err := conf.GetMaxUploadConcurrency()
+	if err != nil {
+		return errors.Wrap(err, "Failed to resolve MaxUploadConcurrency")
+	}
+	walSegmentRunner := NewWalSegmentRunner(latestSegment, storageSegments, stopSegmentNo, timelineSwitchMap)
+	segmentScanner := NewWalSegmentScanner(walSegmentRunner)
+	err = runWalIntegrityScan(segmentScanner, uploadingSegmentRangeSize, viper.GetInt(conf.MaxDelayedSegmentsCount))
+	if err != nil {
+		return err
+	}
+
+	lostSequences := make([]string, 0)
+	for _, sequence := range collapseSegmentsByStatusAndTimeline(segmentScanner.ScannedSegments) {
+		if sequence.Status == Lost {
+			lostSequences = append(lostSequences, sequence.StartSegment+"-"+sequence.EndSegment)
+		}
+	}
+	if len(lostSequences) > 0 {
+		return fmt.Errorf("WAL segments are missing: %s", strings.Join(lostSequences, ", "))
+	}
+	return nil
+}
+
+// findLatestWalSegment returns the segment with the highest number on the highest timeline
+func findLatestWalSegment(segments map[WalSegmentDescription]bool) (WalSegmentDescription, bool) {
+	var latest WalSegmentDescription
+	found := false
+	for segment := range segments {
+		if !found || segment.Timeline > latest.Timeline ||
+			segment.Timeline == latest.Timeline && segment.Number > latest.Number {
+			latest = segment
+			found = true
+		}
+	}
+	return latest, found
+}
@@ -0,0 +1,115 @@
+package postgres_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/databases/postgres"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+func TestFindPITRWindowTarget(t *testing.T) {
+	now := time.Now()
+	folder := setupPITRWindowTestFolder(t, now, "")
+	deleteHandler, err := postgres.NewDeleteHandler(t.Context(), folder, nil, nil, false)
+	require.NoError(t, err)
+
+	target, err := deleteHandler.FindPITRWindowTarget(t.Context(), now.Add(-14*24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "base_000000010000000000000002", target.GetBackupName())
+
+	target, err = deleteHandler.FindPITRWindowTarget(t.Context(), now.Add(-5*24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "base_000000010000000000000005", target.GetBackupName())
+
+	target, err = deleteHandler.FindPITRWindowTarget(t.Context(), now.Add(-30*24*time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, target)
+}
+
+func TestFindPITRWindowTarget_FailsOnLostWal(t *testing.T) {
+	now := time.Now()
+	folder := setupPITRWindowTestFolder(t, now, "000000010000000000000003")
+	deleteHandler, err := postgres.NewDeleteHandler(t.Context(), folder, nil, nil, false)
+	require.NoError(t, err)
+
+	_, err = deleteHandler.FindPITRWindowTarget(t.Context(), now.Add(-14*24*time.Hour))
+	assert.IsType(t, utility.ForbiddenActionError{}, err)
+
+	// the lost segment is before the backup the window starts from
+	target, err := deleteHandler.FindPITRWindowTarget(t.Context(), now.Add(-5*24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "base_000000010000000000000005", target.GetBackupName())
+}
+
+func TestDeleteAuto_PITRWindow(t *testing.T) {
+	now := time.Now()
+	folder := setupPITRWindowTestFolder(t, now, "")
+	deleteHandler, err := postgres.NewDeleteHandler(t.Context(), folder, nil, nil, false)
+	require.NoError(t, err)
+
+	err = deleteHandler.DeleteAuto(t.Context(), internal.RetentionConfig{PITRWindow: 5 * 24 * time.Hour}, true)
+	require.NoError(t, err)
+
+	backups, err := internal.GetBackups(t.Context(), folder.GetSubFolder(utility.BaseBackupPath))
+	require.NoError(t, err)
+	backupNames := make([]string, 0, len(backups))
+	for _, backup := range backups {
+		backupNames = append(backupNames, backup.BackupName)
+	}
+	assert.ElementsMatch(t, []string{"base_000000010000000000000005", "base_000000010000000000000008"}, backupNames)
+}
+
+func TestDeleteAuto_PITRWindowRefusesOnLostWal(t *testing.T) {
+	now := time.Now()
+	folder := setupPITRWindowTestFolder(t, now, "000000010000000000000006")
+	deleteHandler, err := postgres.NewDeleteHandler(t.Context(), folder, nil, nil, false)
+	require.NoError(t, err)
+
+	err = deleteHandler.DeleteAuto(t.Context(), internal.RetentionConfig{PITRWindow: 5 * 24 * time.Hour}, true)
+	assert.IsType(t, utility.ForbiddenActionError{}, err)
+
+	backups, err := internal.GetBackups(t.Context(), folder.GetSubFolder(utility.BaseBackupPath))
+	require.NoError(t, err)
+	assert.Len(t, backups, 3)
+}
+
+// setupPITRWindowTestFolder puts three backups finished 20, 10 and 1 days ago
+// and the WAL segments from 1 to 16 except the missing one, if any
+func setupPITRWindowTestFolder(t *testing.T, now time.Time, missingSegment string) storage.Folder {
+	folder := setupTestStorageFolder()
+	backupsFinishTime := map[string]time.Time{
+		"000000010000000000000002": now.Add(-20 * 24 * time.Hour),
+		"000000010000000000000005": now.Add(-10 * 24 * time.Hour),
+		"000000010000000000000008": now.Add(-24 * time.Hour),
+	}
+	for walName, finishTime := range backupsFinishTime {
+		backupName := utility.BackupNamePrefix + walName
+		meta := newMockExtendedMetadataDto(false)
+		meta.StartTime = finishTime.Add(-time.Hour)
+		meta.FinishTime = finishTime
+		metaBytes, err := json.Marshal(meta)
+		require.NoError(t, err)
+		require.NoError(t, folder.PutObject(t.Context(),
+			utility.BaseBackupPath+backupName+utility.SentinelSuffix, bytes.NewBufferString("{}")))
+		require.NoError(t, folder.PutObject(t.Context(),
+			utility.BaseBackupPath+backupName+"/"+utility.MetadataFileName, bytes.NewBuffer(metaBytes)))
+	}
+
+	walFilenames := make([]string, 0)
+	for segNo := 1; segNo <= 16; segNo++ {
+		walName := fmt.Sprintf("%08X%08X%08X", 1, 0, segNo)
+		if walName != missingSegment {
+			walFilenames = append(walFilenames, walName+".lz4")
+		}
+	}
+	putWalSegments(t.Context(), walFilenames, folder.GetSubFolder(utility.WalPath))
+	return folder
+}
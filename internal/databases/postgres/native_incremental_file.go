@@ -0,0 +1,109 @@
+//
+// This file converts the files of PostgreSQL 17 incremental backups (BASE_BACKUP ... INCREMENTAL)
+// to the wal-g increment format, so such backups are stored and restored as regular wal-g deltas.
+// PostgreSQL incremental file format is:
+// 4 bytes magic number
+// 4 bytes uint changed pages count N
+// 4 bytes uint truncation block length, the file is truncated to this number of blocks
+// (N * 4) bytes for Block Numbers of changed pages
+// padding to a multiple of DatabasePageSize if N > 0
+// (N * DatabasePageSize) bytes for changed page data
+//
+
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	nativeIncrementalFilePrefix        = "INCREMENTAL."
+	nativeIncrementalMagic      uint32 = 0xd3ae1f0d
+	nativeIncrementalMinVersion        = 170000
+
+	backupManifestName = "backup_manifest"
+	// backup_label lines which make PostgreSQL refuse to start from an incremental backup
+	backupLabelIncrementalPrefix = "INCREMENTAL FROM "
+)
+
+// isNativeIncrementalFile checks if the tar entry is a PostgreSQL 17 incremental file
+func isNativeIncrementalFile(filePath string) bool {
+	return strings.HasPrefix(path.Base(filePath), nativeIncrementalFilePrefix)
+}
+
+// nativeIncrementalTarget returns the path of the file reconstructed from the incremental file
+func nativeIncrementalTarget(filePath string) string {
+	dir, name := path.Split(filePath)
+	return dir + strings.TrimPrefix(name, nativeIncrementalFilePrefix)
+}
+
+// convertNativeIncrementalHeader reads the header of a PostgreSQL incremental file with the given size
+// and returns the wal-g increment header, the changed pages follow both headers in the same order.
+// The increment size is the size of the wal-g header plus the changed pages.
+func convertNativeIncrementalHeader(reader io.Reader, fileSize int64) (header []byte, incrementSize int64, err error) {
+	var magic, blockCount, truncationBlockLength uint32
+	for _, field := range []*uint32{&magic, &blockCount, &truncationBlockLength} {
+		err = binary.Read(reader, binary.LittleEndian, field)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read incremental file header: %w", err)
+		}
+	}
+	if magic != nativeIncrementalMagic {
+		return nil, 0, fmt.Errorf("invalid incremental file magic number %x", magic)
+	}
+	headerSize := int64(3*sizeofInt32) + int64(blockCount)*sizeofInt32
+	if blockCount > 0 && headerSize%DatabasePageSize != 0 {
+		headerSize += DatabasePageSize - headerSize%DatabasePageSize
+	}
+	pagesSize := int64(blockCount) * DatabasePageSize
+	if headerSize+pagesSize != fileSize {
+		return nil, 0, fmt.Errorf("incremental file has %d blocks, its size should be %d, but it is %d",
+			blockCount, headerSize+pagesSize, fileSize)
+	}
+
+	blocks := make([]uint32, blockCount)
+	err = binary.Read(reader, binary.LittleEndian, blocks)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read incremental file block numbers: %w", err)
+	}
+	_, err = io.CopyN(io.Discard, reader, headerSize-int64(3*sizeofInt32)-int64(blockCount)*sizeofInt32)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read incremental file header padding: %w", err)
+	}
+
+	// the file is truncated to the truncation block length, but it is extended up to the changed blocks
+	blockLength := truncationBlockLength
+	for _, blockNo := range blocks {
+		blockLength = max(blockLength, blockNo+1)
+	}
+
+	var headerBuffer bytes.Buffer
+	headerBuffer.Write(IncrementFileHeader)
+	headerBuffer.Write(utility.ToBytes(uint64(blockLength) * uint64(DatabasePageSize)))
+	headerBuffer.Write(utility.ToBytes(blockCount))
+	_ = binary.Write(&headerBuffer, binary.LittleEndian, blocks)
+	return headerBuffer.Bytes(), int64(headerBuffer.Len()) + pagesSize, nil
+}
+
+// removeIncrementalBackupLabelLines drops the lines describing the parent backup from the backup_label,
+// PostgreSQL doesn't start from the incremental backup label, as pg_combinebackup we remove them
+func removeIncrementalBackupLabelLines(reader io.Reader) ([]byte, error) {
+	var label bytes.Buffer
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), backupLabelIncrementalPrefix) {
+			continue
+		}
+		label.Write(scanner.Bytes())
+		label.WriteByte('\n')
+	}
+	return label.Bytes(), scanner.Err()
+}
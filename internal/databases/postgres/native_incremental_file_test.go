@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+)
+
+func makePage(fill byte) []byte {
+	return bytes.Repeat([]byte{fill}, int(DatabasePageSize))
+}
+
+// makeNativeIncrementalFile builds a PG17 incremental file with the given blocks filled with their numbers
+func makeNativeIncrementalFile(truncationBlockLength uint32, blocks ...uint32) []byte {
+	var file bytes.Buffer
+	_ = binary.Write(&file, binary.LittleEndian, []uint32{nativeIncrementalMagic, uint32(len(blocks)), truncationBlockLength})
+	_ = binary.Write(&file, binary.LittleEndian, blocks)
+	if len(blocks) > 0 {
+		file.Write(make([]byte, DatabasePageSize-int64(file.Len())%DatabasePageSize))
+	}
+	for _, blockNo := range blocks {
+		file.Write(makePage(byte(blockNo)))
+	}
+	return file.Bytes()
+}
+
+func TestConvertNativeIncrementalHeader(t *testing.T) {
+	file := makeNativeIncrementalFile(6, 3, 1)
+	reader := bytes.NewReader(file)
+	header, size, err := convertNativeIncrementalHeader(reader, int64(len(file)))
+	require.NoError(t, err)
+
+	fileSize, blockCount, diffMap, err := GetIncrementHeaderFields(bytes.NewReader(header))
+	require.NoError(t, err)
+	require.Equal(t, uint64(6*DatabasePageSize), fileSize)
+	require.Equal(t, uint32(2), blockCount)
+	require.Equal(t, []byte{3, 0, 0, 0, 1, 0, 0, 0}, diffMap)
+	require.Equal(t, int64(len(header))+2*DatabasePageSize, size)
+	require.Equal(t, int64(2*DatabasePageSize), int64(reader.Len()))
+
+	// blocks beyond the truncation block length extend the file
+	file = makeNativeIncrementalFile(1, 4)
+	header, _, err = convertNativeIncrementalHeader(bytes.NewReader(file), int64(len(file)))
+	require.NoError(t, err)
+	fileSize, _, _, err = GetIncrementHeaderFields(bytes.NewReader(header))
+	require.NoError(t, err)
+	require.Equal(t, uint64(5*DatabasePageSize), fileSize)
+
+	// a file without blocks has no padding
+	file = makeNativeIncrementalFile(0)
+	require.Len(t, file, 3*sizeofInt32)
+	header, size, err = convertNativeIncrementalHeader(bytes.NewReader(file), int64(len(file)))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(header)), size)
+
+	_, _, err = convertNativeIncrementalHeader(bytes.NewReader(file), int64(len(file))+1)
+	require.EqualError(t, err, "incremental file has 0 blocks, its size should be 12, but it is 13")
+	_, _, err = convertNativeIncrementalHeader(bytes.NewReader(make([]byte, 12)), 12)
+	require.EqualError(t, err, "invalid incremental file magic number 0")
+}
+
+func TestTarballStreamerConvertsIncrementalBackup(t *testing.T) {
+	incrementalFile := makeNativeIncrementalFile(6, 3, 1)
+	label := "START WAL LOCATION: 0/2000028 (file 000000010000000000000002)\n" +
+		"INCREMENTAL FROM LSN: 0/1000028\n" +
+		"INCREMENTAL FROM TLI: 1\n" +
+		"LABEL: wal-g\n"
+	var input bytes.Buffer
+	inputTar := tar.NewWriter(&input)
+	for _, file := range []struct {
+		name    string
+		content []byte
+	}{
+		{"backup_label", []byte(label)},
+		{"base/5/INCREMENTAL.16384", incrementalFile},
+		{"base/5/PG_VERSION", []byte("17\n")},
+	} {
+		require.NoError(t, inputTar.WriteHeader(&tar.Header{
+			Name: file.name, Typeflag: tar.TypeReg, Mode: 0600, Size: int64(len(file.content)),
+		}))
+		_, err := inputTar.Write(file.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, inputTar.Close())
+
+	bundleFiles := &internal.RegularBundleFiles{}
+	streamer := NewTarballStreamer(&input, 1<<30, bundleFiles)
+	output, err := io.ReadAll(streamer)
+	require.NoError(t, err)
+
+	contents := make(map[string][]byte)
+	outputTar := tar.NewReader(bytes.NewReader(output))
+	for {
+		header, err := outputTar.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		contents[header.Name], err = io.ReadAll(outputTar)
+		require.NoError(t, err)
+	}
+	require.Equal(t, "START WAL LOCATION: 0/2000028 (file 000000010000000000000002)\nLABEL: wal-g\n",
+		string(contents["backup_label"]))
+	require.Equal(t, "17\n", string(contents["base/5/PG_VERSION"]))
+	require.NotContains(t, contents, "base/5/INCREMENTAL.16384")
+
+	files := bundleFiles.GetUnderlyingMap()
+	description, ok := files.Load("base/5/16384")
+	require.True(t, ok)
+	require.True(t, description.(internal.BackupFileDescription).IsIncremented)
+	description, ok = files.Load("base/5/PG_VERSION")
+	require.True(t, ok)
+	require.False(t, description.(internal.BackupFileDescription).IsIncremented)
+
+	// the increment reconstructs the file on top of the parent backup as pg_combinebackup does
+	target := filepath.Join(t.TempDir(), "16384")
+	var parent []byte
+	for i := 0; i < 8; i++ {
+		parent = append(parent, makePage(0xF0+byte(i))...)
+	}
+	require.NoError(t, os.WriteFile(target, parent, 0600))
+	require.NoError(t, ApplyFileIncrement(target, bytes.NewReader(contents["base/5/16384"]), false, false))
+	restored, err := os.ReadFile(target)
+	require.NoError(t, err)
+	expected := bytes.Join([][]byte{
+		makePage(0xF0), makePage(1), makePage(0xF2), makePage(3), makePage(0xF4), makePage(0xF5),
+	}, nil)
+	require.Equal(t, expected, restored)
+}
@@ -221,7 +221,7 @@ func prefetchFile(ctx context.Context, location string,
 	}
 
 	tracelog.DebugLogger.Printf("File prefetched to %s", oldPath)
-	err = internal.DownloadFileTo(ctx, reader, walFileName, oldPath)
+	err = downloadWALFileTo(ctx, reader, walFileName, oldPath)
 	if err != nil {
 		tracelog.ErrorLogger.Printf("WAL-prefetch %s, download: %v", walFileName, err)
 	} else {
@@ -22,6 +22,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression"
 	"github.com/wal-g/wal-g/internal/ioextensions"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
@@ -30,7 +31,9 @@ import (
 // The StreamingBaseBackup object represents a Postgres BASE_BACKUP, connecting to Postgres, and streaming backup data.
 // On PG14 and earlier, every tablespace is sent in its own CopyOut session.
 // On PG15+, all archives plus optional manifest live in a single CopyOut session
-// with one-byte-tagged CopyData payloads
+// with one-byte-tagged CopyData payloads.
+// On PG17+, the backup manifest is stored with the backup, and it is uploaded
+// back to Postgres to take an incremental backup on top of this one
 type StreamingBaseBackup struct {
 	TimeLine         uint32
 	StartLSN         pglogrepl.LSN
@@ -44,6 +47,9 @@ type StreamingBaseBackup struct {
 	uploader         internal.Uploader
 	fileNo           int
 	pgVersion        int
+	// the backup and its manifest the incremental backup is taken from
+	incrementFrom  string
+	parentManifest []byte
 }
 
 // NewStreamingBaseBackup will define a new StreamingBaseBackup object
@@ -56,6 +62,12 @@ func NewStreamingBaseBackup(pgDataDir string, maxTarSize int64, pgVersion int, p
 	}
 }
 
+// SetIncrementFrom makes the backup incremental on top of the given backup with the given backup manifest
+func (bb *StreamingBaseBackup) SetIncrementFrom(backupName string, manifest []byte) {
+	bb.incrementFrom = backupName
+	bb.parentManifest = manifest
+}
+
 // Start will start a base_backup read the backup info, and prepare for uploading tar files
 func (bb *StreamingBaseBackup) Start(ctx context.Context, verifyChecksum bool, diskLimit int32) (err error) {
 	options := pglogrepl.BaseBackupOptions{
@@ -65,6 +77,14 @@ func (bb *StreamingBaseBackup) Start(ctx context.Context, verifyChecksum bool, d
 		Label:             "wal-g",
 		NoVerifyChecksums: !verifyChecksum,
 		MaxRate:           diskLimit,
+		Manifest:          bb.pgVersion >= nativeIncrementalMinVersion,
+	}
+	if bb.parentManifest != nil {
+		err = pglogrepl.UploadManifest(ctx, bb.pgConn, bytes.NewReader(bb.parentManifest))
+		if err != nil {
+			return
+		}
+		options.Incremental = true
 	}
 	result, err := pglogrepl.StartBaseBackup(ctx, bb.pgConn, options)
 	if err != nil {
@@ -91,12 +111,13 @@ func (bb *StreamingBaseBackup) Finish(ctx context.Context) (err error) {
 // reader yields the archive's tar bytes (including its 1024-byte trailer)
 // and is valid only during the iteration that produced it.
 type archive struct {
-	name   string // "base.tar" or "<oid>.tar"
-	oid    int32  // 0 for data dir
-	reader io.Reader
+	name     string // "base.tar" or "<oid>.tar"
+	oid      int32  // 0 for data dir
+	manifest bool   // the backup manifest, not a tar
+	reader   io.Reader
 }
 
-func (a *archive) isDataDir() bool { return a.oid == 0 }
+func (a *archive) isDataDir() bool { return a.oid == 0 && !a.manifest }
 
 // Archives streams the archives produced by the running BASE_BACKUP command,
 // dispatching on bb.pgVersion. PG14- yields one archive per tablespace driven
@@ -137,6 +158,12 @@ func (bb *StreamingBaseBackup) Upload(ctx context.Context, uploader internal.Upl
 		if err != nil {
 			return err
 		}
+		if arch.manifest {
+			if err := bb.uploadManifest(ctx, arch.reader); err != nil {
+				return err
+			}
+			continue
+		}
 		streamer := NewTarballStreamer(arch.reader, bb.maxTarSize, bundleFiles)
 		remaps, tee, err := remapsForArchive(arch)
 		if err != nil {
@@ -182,9 +209,57 @@ func (bb *StreamingBaseBackup) Upload(ctx context.Context, uploader internal.Upl
 	return nil
 }
 
+// uploadManifest stores the backup manifest, it is needed to take the next incremental backup
+func (bb *StreamingBaseBackup) uploadManifest(ctx context.Context, manifest io.Reader) error {
+	compressedManifest := internal.CompressAndEncrypt(manifest, bb.uploader.Compression(), internal.ConfigureCrypter())
+	manifestPath := utility.AddFileExtension(storage.JoinPath(bb.BackupName(), backupManifestName),
+		bb.uploader.Compression().FileExtension())
+	return bb.uploader.Upload(ctx, manifestPath, compressedManifest)
+}
+
+// fetchBackupManifest downloads the backup manifest stored with the streaming backup,
+// it returns nil if the backup has no manifest
+func fetchBackupManifest(ctx context.Context, baseBackupFolder storage.Folder, backupName string) ([]byte, error) {
+	folderReader := internal.NewFolderReader(baseBackupFolder)
+	for _, decompressor := range compression.Decompressors {
+		manifestPath := storage.JoinPath(backupName, utility.AddFileExtension(backupManifestName, decompressor.FileExtension()))
+		compressedManifest, exists, err := internal.TryDownloadFile(ctx, folderReader, manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		defer utility.LoggedClose(compressedManifest, "")
+		manifest, err := internal.DecompressDecryptBytes(compressedManifest, decompressor)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decompress and decrypt %s", manifestPath)
+		}
+		defer utility.LoggedClose(manifest, "")
+		return io.ReadAll(manifest)
+	}
+	return nil, nil
+}
+
+// walSummarizationEnabled checks that the server summarizes WAL, it is required to take incremental backups
+func walSummarizationEnabled(ctx context.Context, conn *pgconn.PgConn) (bool, error) {
+	results, err := conn.Exec(ctx, "SHOW summarize_wal").ReadAll()
+	if err != nil {
+		return false, err
+	}
+	if len(results) != 1 || len(results[0].Rows) != 1 || len(results[0].Rows[0]) != 1 {
+		return false, errors.New("unexpected result of SHOW summarize_wal")
+	}
+	return string(results[0].Rows[0][0]) == "on", nil
+}
+
 // BackupName returns the name of the folder where the backup should be stored.
 func (bb *StreamingBaseBackup) BackupName() string {
-	return "base_" + formatWALFileName(bb.TimeLine, uint64(bb.StartLSN)/WalSegmentSize)
+	name := "base_" + formatWALFileName(bb.TimeLine, uint64(bb.StartLSN)/WalSegmentSize)
+	if bb.incrementFrom != "" {
+		name += "_D_" + utility.StripWalFileName(bb.incrementFrom)
+	}
+	return name
 }
 
 // FileName returns the filename of a tablespace backup file.
@@ -360,7 +435,7 @@ type streamPump struct {
 	chunkPos   int
 	archiveEnd bool     // current archive done (boundary tag seen on wire)
 	streamEnd  bool     // CopyDone seen
-	pendingArc *archive // 'n' parsed but not yet yielded
+	pendingArc *archive // 'n' or 'm' parsed but not yet yielded
 	inManifest bool     // 'm' seen, swallowing 'd' until CopyDone
 }
 
@@ -446,9 +521,13 @@ func (s *streamPump) handleCopyData(data []byte) error {
 		s.archiveEnd = true
 		s.pendingArc = arch
 	case 'm':
+		s.archiveEnd = true
+		if s.bb.pgVersion >= nativeIncrementalMinVersion {
+			s.pendingArc = &archive{name: backupManifestName, manifest: true}
+			return nil
+		}
 		tracelog.WarningLogger.Print("BASE_BACKUP: manifest stream received but not requested; dropping")
 		s.inManifest = true
-		s.archiveEnd = true
 	default:
 		return errors.Errorf("BASE_BACKUP: unexpected CopyData tag %q", tag)
 	}
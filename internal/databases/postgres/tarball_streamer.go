@@ -78,6 +78,10 @@ type TarballStreamer struct {
 	// set when inputTar.Next() returns io.EOF, distinguishing natural archive
 	// end from errTarStreamerOutputEOF part rotation
 	inputExhausted bool
+	// reader of the current file data, differs from inputTar when the file is converted
+	fileReader io.Reader
+	// status if current file is converted to wal-g increment
+	incremented bool
 }
 
 // ArchiveDone reports whether the input tar archive has been fully consumed
@@ -126,6 +130,10 @@ func (streamer *TarballStreamer) NextInputFile() (err error) {
 	streamer.fileReadIndex = 0
 
 	streamer.remap()
+	err = streamer.convertIncrementalFile()
+	if err != nil {
+		return err
+	}
 
 	return streamer.addFile()
 }
@@ -162,12 +170,39 @@ func (streamer *TarballStreamer) addFile() (err error) {
 	if !streamer.curHeader.FileInfo().IsDir() {
 		filePath := streamer.curHeader.Name
 		filePath = strings.TrimPrefix(filePath, "./")
-		streamer.Files.AddFileDescription(filePath, internal.BackupFileDescription{MTime: streamer.curHeader.ModTime})
+		streamer.Files.AddFileDescription(filePath,
+			internal.BackupFileDescription{MTime: streamer.curHeader.ModTime, IsIncremented: streamer.incremented})
 		streamer.tarFileReadIndex += streamer.curHeader.Size
 	}
 	return nil
 }
 
+// convertIncrementalFile converts the files of PG17 incremental backups: incremental files are turned into
+// wal-g increments of the files they reconstruct, and the parent backup is removed from the backup_label
+func (streamer *TarballStreamer) convertIncrementalFile() error {
+	streamer.fileReader = streamer.inputTar
+	streamer.incremented = false
+	switch {
+	case isNativeIncrementalFile(streamer.curHeader.Name) && streamer.curHeader.Typeflag == tar.TypeReg:
+		header, size, err := convertNativeIncrementalHeader(streamer.inputTar, streamer.curHeader.Size)
+		if err != nil {
+			return errors.Wrapf(err, "failed to convert %s", streamer.curHeader.Name)
+		}
+		streamer.curHeader.Name = nativeIncrementalTarget(streamer.curHeader.Name)
+		streamer.curHeader.Size = size
+		streamer.fileReader = io.MultiReader(bytes.NewReader(header), streamer.inputTar)
+		streamer.incremented = true
+	case strings.TrimPrefix(streamer.curHeader.Name, "./") == backupLabelFileName:
+		label, err := removeIncrementalBackupLabelLines(streamer.inputTar)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", streamer.curHeader.Name)
+		}
+		streamer.curHeader.Size = int64(len(label))
+		streamer.fileReader = bytes.NewReader(label)
+	}
+	return nil
+}
+
 // remap rebuilds the name of the file according to remapping rules
 func (streamer *TarballStreamer) remap() {
 	for _, remap := range streamer.Remaps {
@@ -189,7 +224,7 @@ func (streamer *TarballStreamer) readFileData() (err error) {
 		return nil
 	}
 	// read index is at last byte. All is read. Read next block.
-	streamer.bufDataSize, err = streamer.inputTar.Read(streamer.inputBuf)
+	streamer.bufDataSize, err = streamer.fileReader.Read(streamer.inputBuf)
 	streamer.bufReadIndex = 0
 	// Update index as read from file
 	streamer.fileReadIndex += int64(streamer.bufDataSize)
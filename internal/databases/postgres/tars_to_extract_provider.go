@@ -4,8 +4,11 @@ import (
 	"context"
 	"regexp"
 
+	"github.com/spf13/viper"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
 )
 
 type FilesToExtractProvider interface {
@@ -27,6 +30,10 @@ func (t FilesToExtractProviderImpl) Get(ctx context.Context, backup Backup, file
 	if err != nil {
 		return nil, nil, err
 	}
+	tarChecksums, err := getTarChecksums(ctx, backup)
+	if err != nil {
+		return nil, nil, err
+	}
 	tracelog.DebugLogger.Printf("Tars to extract: '%+v'\n", tarNames)
 	concurrentTarsToExtract = make([]internal.ReaderMaker, 0, len(tarNames))
 	sequentialTarsToExtract = make([]internal.ReaderMaker, 0, 2)
@@ -40,7 +47,7 @@ func (t FilesToExtractProviderImpl) Get(ctx context.Context, backup Backup, file
 		// exists: it won't in the case of WAL-E backup
 		// backwards compatibility.
 		if pgControlRe.MatchString(tarName) {
-			tarToExtract := internal.NewStorageReaderMaker(backup.GetTarPartitionFolder(), tarName)
+			tarToExtract := newTarReaderMaker(backup, tarName, tarChecksums)
 			sequentialTarsToExtract = append(sequentialTarsToExtract, tarToExtract)
 			continue
 		}
@@ -51,7 +58,7 @@ func (t FilesToExtractProviderImpl) Get(ctx context.Context, backup Backup, file
 		// We should override it in order to reach correct end of backup point.
 		// so, we should extract our `backup_label` after extracting regular tars.
 		if backupLabelRe.MatchString(tarName) {
-			tarToExtract := internal.NewStorageReaderMaker(backup.GetTarPartitionFolder(), tarName)
+			tarToExtract := newTarReaderMaker(backup, tarName, tarChecksums)
 			sequentialTarsToExtract = append(sequentialTarsToExtract, tarToExtract)
 			continue
 		}
@@ -60,8 +67,28 @@ func (t FilesToExtractProviderImpl) Get(ctx context.Context, backup Backup, file
 			continue
 		}
 
-		tarToExtract := internal.NewStorageReaderMaker(backup.GetTarPartitionFolder(), tarName)
+		tarToExtract := newTarReaderMaker(backup, tarName, tarChecksums)
 		concurrentTarsToExtract = append(concurrentTarsToExtract, tarToExtract)
 	}
 	return concurrentTarsToExtract, sequentialTarsToExtract, nil
 }
+
+func newTarReaderMaker(backup Backup, tarName string, tarChecksums map[string]string) *internal.StorageReaderMaker {
+	tarToExtract := internal.NewStorageReaderMaker(backup.GetTarPartitionFolder(), tarName)
+	tarToExtract.ExpectedChecksum = tarChecksums[storage.JoinPath(internal.TarPartitionFolderName, tarName)]
+	return tarToExtract
+}
+
+// getTarChecksums returns the checksums of the backup objects recorded at upload time.
+// The map is empty if the verification is disabled or the backup has no checksum manifest.
+func getTarChecksums(ctx context.Context, backup Backup) (map[string]string, error) {
+	if !viper.GetBool(conf.VerifyChecksumsSetting) {
+		return nil, nil
+	}
+	manifest, err := backup.FetchChecksumManifest(ctx)
+	if err != nil || manifest == nil {
+		return nil, err
+	}
+	tracelog.InfoLogger.Printf("Tars of backup %s will be verified against the recorded checksums\n", backup.Name)
+	return manifest.Checksums, nil
+}
@@ -9,8 +9,11 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/spf13/viper"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/checksum"
+	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/utility"
 )
 
@@ -94,7 +97,26 @@ func HandleWALFetch(ctx context.Context,
 	}
 
 	tracelog.DebugLogger.Printf("Statring external storage download for file %s at %v", walFileName, time.Now())
-	return internal.DownloadFileTo(ctx, reader, walFileName, location)
+	return downloadWALFileTo(ctx, reader, walFileName, location)
+}
+
+// downloadWALFileTo downloads the WAL file and verifies it against the checksum recorded at upload time, if any.
+// The downloaded file is removed if the checksum does not match.
+func downloadWALFileTo(ctx context.Context, reader internal.StorageFolderReader, walFileName, location string) error {
+	if viper.GetBool(conf.VerifyChecksumsSetting) {
+		manifest, err := internal.ReadChecksumManifest(ctx, reader, checksum.ObjectManifestName(walFileName))
+		if err != nil {
+			return err
+		}
+		reader = internal.NewChecksumVerifyingFolderReader(reader, manifest)
+	}
+
+	err := internal.DownloadFileTo(ctx, reader, walFileName, location)
+	var mismatchErr checksum.MismatchError
+	if errors.As(err, &mismatchErr) {
+		_ = os.Remove(location)
+	}
+	return err
 }
 
 // TODO : unit tests
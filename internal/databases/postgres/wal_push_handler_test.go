@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/asm"
+	"github.com/wal-g/wal-g/internal/checksum"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/databases/postgres"
 	"github.com/wal-g/wal-g/testtools"
@@ -73,3 +74,25 @@ func TestWalPush_BulkMetadataUploaderWithUploadConcurrency(t *testing.T) {
 	_, err := uploader.Folder().ReadObject(t.Context(), testFileName[0:len(testFileName)-1]+".json")
 	assert.NoError(t, err)
 }
+
+func TestWalPush_ChecksumManifest(t *testing.T) {
+	viper.Set(conf.UploadWalMetadata, postgres.WalNoMetadataLevel)
+	dir, _ := setupArchiveStatus(t, "")
+	dirName := path.Join(dir, "pg_wal")
+	defer testtools.Cleanup(t, dir)
+	addTestDataFile(t, dirName, "1")
+	viper.Set(conf.PgDataSetting, dir)
+	testFileName := testFilename("1")
+	uploader := testtools.NewMockWalDirUploader(false, false)
+	uploader.ArchiveStatusManager = asm.NewFakeASM()
+	uploader.EnableChecksums()
+
+	err := postgres.HandleWALPush(t.Context(), uploader, path.Join(dirName, testFileName))
+	assert.NoError(t, err)
+	manifest, err := internal.ReadChecksumManifest(t.Context(), internal.NewFolderReader(uploader.Folder()),
+		checksum.ObjectManifestName(testFileName))
+	assert.NoError(t, err)
+	assert.Contains(t, manifest.Checksums, testFileName+".mock")
+	_, recorded := uploader.Checksums().Get(path.Join(uploader.Folder().GetPath(), testFileName+".mock"))
+	assert.False(t, recorded)
+}
@@ -6,9 +6,12 @@ import (
 	"io"
 	"path"
 
+	"github.com/spf13/viper"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/asm"
+	"github.com/wal-g/wal-g/internal/checksum"
+	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/ioextensions"
 	"github.com/wal-g/wal-g/internal/multistorage"
 	"github.com/wal-g/wal-g/internal/multistorage/policies"
@@ -65,7 +68,31 @@ func (walUploader *WalUploader) UploadWalFile(ctx context.Context, file ioextens
 		walFileReader = file
 	}
 
-	return walUploader.UploadFile(ctx, ioextensions.NewNamedReaderImpl(walFileReader, file.Name()))
+	err := walUploader.UploadFile(ctx, ioextensions.NewNamedReaderImpl(walFileReader, file.Name()))
+	if err != nil {
+		return err
+	}
+	return walUploader.uploadChecksumManifest(ctx, filename)
+}
+
+// uploadChecksumManifest uploads the checksum of the WAL file next to it, so wal-fetch can verify it
+func (walUploader *WalUploader) uploadChecksumManifest(ctx context.Context, filename string) error {
+	recorder := walUploader.Checksums()
+	if recorder == nil {
+		return nil
+	}
+	dstPath := utility.SanitizePath(utility.AddFileExtension(filename, walUploader.Compression().FileExtension()))
+	objectPath := storage.JoinPath(walUploader.Folder().GetPath(), dstPath)
+	objectChecksum, ok := recorder.Get(objectPath)
+	if !ok {
+		return nil
+	}
+	manifestPath := checksum.ObjectManifestName(filename)
+	// wal-push daemon uploads with the same recorder for its whole life
+	defer recorder.Remove(objectPath, storage.JoinPath(walUploader.Folder().GetPath(), manifestPath))
+	manifest := checksum.NewManifest()
+	manifest.Checksums[dstPath] = objectChecksum
+	return walUploader.UploadJSON(ctx, manifestPath, manifest)
 }
 
 func (walUploader *WalUploader) FlushFiles(ctx context.Context) {
@@ -89,6 +116,13 @@ func PrepareMultiStorageWalUploader(ctx context.Context, folder storage.Folder,
 	if err != nil {
 		return nil, fmt.Errorf("configure base uploader: %w", err)
 	}
+	if viper.GetBool(conf.UploadChecksumsSetting) {
+		baseUploader.EnableChecksums()
+	}
+	err = internal.ConfigureZstdDictionary(ctx, folder, baseUploader)
+	if err != nil {
+		return nil, fmt.Errorf("configure zstd dictionary: %w", err)
+	}
 
 	walUploader, err := ConfigureWalUploader(baseUploader)
 	if err != nil {
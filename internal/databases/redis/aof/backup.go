@@ -43,7 +43,9 @@ func CreateBackupService(diskWatcher *diskwatcher.DiskWatcher, uploader *interna
 }
 
 type DoBackupArgs struct {
-	BackupName    string
+	BackupName string
+	// Timeline is the AOF timeline of the backed up files
+	Timeline      string
 	Sharded       bool
 	DeferSentinel bool
 }
@@ -105,10 +107,10 @@ func (bs *BackupService) DoBackup(ctx context.Context, args DoBackupArgs) error
 		return err
 	}
 
-	return bs.Finalize(ctx, args.BackupName, args.DeferSentinel)
+	return bs.Finalize(ctx, args.BackupName, args.Timeline, args.DeferSentinel)
 }
 
-func (bs *BackupService) Finalize(ctx context.Context, backupName string, deferSentinel bool) error {
+func (bs *BackupService) Finalize(ctx context.Context, backupName, timeline string, deferSentinel bool) error {
 	if err := bs.metaConstructor.Finalize(ctx, backupName); err != nil {
 		return fmt.Errorf("can not finalize meta provider: %+v", err)
 	}
@@ -116,6 +118,7 @@ func (bs *BackupService) Finalize(ctx context.Context, backupName string, deferS
 	backupSentinelInfo := bs.metaConstructor.MetaInfo()
 	backup := backupSentinelInfo.(*archive.Backup)
 	backup.BackupName = backupName
+	backup.AOFTimeline = timeline
 	backup.BackupSize = bs.concurrentUploader.CompressedSize
 	backup.DataSize = bs.concurrentUploader.UncompressedSize
 	if !deferSentinel {
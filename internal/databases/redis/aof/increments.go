@@ -0,0 +1,95 @@
+package aof
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wal-g/wal-g/utility"
+)
+
+// IncrementsPath is the storage folder of the continuously archived incremental AOF files
+const IncrementsPath = "aof_" + utility.VersionStr + "/"
+
+const (
+	ManifestTypeBase    = "b"
+	ManifestTypeIncr    = "i"
+	ManifestTypeHistory = "h"
+
+	incrSuffix = ".incr.aof"
+)
+
+// ManifestEntry is a line of the multi part AOF manifest:
+// file appendonly.aof.1.incr.aof seq 1 type i
+type ManifestEntry struct {
+	Name string
+	Seq  int
+	Type string
+}
+
+func (e ManifestEntry) String() string {
+	return fmt.Sprintf("file %s seq %d type %s", e.Name, e.Seq, e.Type)
+}
+
+// IsIncr checks if the file is an incremental AOF file, history files keep the names they had
+func (e ManifestEntry) IsIncr() bool {
+	return e.Type == ManifestTypeIncr || e.Type == ManifestTypeHistory && strings.HasSuffix(e.Name, incrSuffix)
+}
+
+func ParseManifestEntries(lines []string) ([]ManifestEntry, error) {
+	entries := make([]ManifestEntry, 0, len(lines))
+	for _, line := range lines {
+		chunks := strings.Fields(line)
+		if len(chunks) == 0 {
+			continue
+		}
+		if len(chunks) != 6 || chunks[0] != "file" || chunks[2] != "seq" || chunks[4] != "type" {
+			return nil, fmt.Errorf("unexpected line format in manifest file: %s", line)
+		}
+		seq, err := strconv.Atoi(chunks[3])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected sequence number in manifest line %s: %w", line, err)
+		}
+		entries = append(entries, ManifestEntry{Name: chunks[1], Seq: seq, Type: chunks[5]})
+	}
+	return entries, nil
+}
+
+// SealedIncrements returns the incremental files Redis no longer writes to, ordered by sequence number.
+// Redis appends to the incremental file with the highest sequence number only,
+// the previous ones are sealed when an AOF rewrite starts.
+func SealedIncrements(entries []ManifestEntry) []ManifestEntry {
+	var incrs []ManifestEntry
+	for _, entry := range entries {
+		if entry.IsIncr() {
+			incrs = append(incrs, entry)
+		}
+	}
+	if len(incrs) == 0 {
+		return nil
+	}
+	sortBySeq(incrs)
+	return incrs[:len(incrs)-1]
+}
+
+// ParseIncrementName splits the incremental file name like appendonly.aof.5.incr.aof
+// into the AOF base name and the sequence number
+func ParseIncrementName(name string) (baseName string, seq int, err error) {
+	trimmed, ok := strings.CutSuffix(name, incrSuffix)
+	dot := strings.LastIndex(trimmed, ".")
+	if !ok || dot < 0 {
+		return "", 0, fmt.Errorf("%s is not an incremental AOF file name", name)
+	}
+	seq, err = strconv.Atoi(trimmed[dot+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("%s is not an incremental AOF file name: %w", name, err)
+	}
+	return trimmed[:dot], seq, nil
+}
+
+func sortBySeq(entries []ManifestEntry) {
+	slices.SortFunc(entries, func(a, b ManifestEntry) int {
+		return a.Seq - b.Seq
+	})
+}
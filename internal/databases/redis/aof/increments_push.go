@@ -0,0 +1,219 @@
+package aof
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// HistoryKeeper controls the removal of the AOF history files: Redis keeps them with aof-disable-auto-gc set,
+// until all the increments among them are uploaded
+type HistoryKeeper interface {
+	// RewriteInProgress tells whether the AOF rewrite, which turns the increments into the history files, runs
+	RewriteInProgress(ctx context.Context) (bool, error)
+	// RemoveHistory makes Redis remove the history files listed in the manifest
+	RemoveHistory(ctx context.Context) error
+}
+
+// IncrementsPushService uploads the sealed incremental AOF files to the folder of the AOF timeline under IncrementsPath
+type IncrementsPushService struct {
+	uploader      internal.Uploader
+	aofFolder     string
+	manifestPath  string
+	filesPinner   *FilesPinner
+	historyKeeper HistoryKeeper
+	// timeline is the AOF timeline of the uploaded increments
+	timeline         string
+	timelineUploader internal.Uploader
+	// uploaded is the names of the increments of the timeline which are in the storage
+	uploaded map[string]bool
+}
+
+// NewIncrementsPushService creates the service, the uploader should point to IncrementsPath
+func NewIncrementsPushService(uploader internal.Uploader, aofFolder, manifestName string,
+	filesPinner *FilesPinner) *IncrementsPushService {
+	return &IncrementsPushService{
+		uploader:     uploader,
+		aofFolder:    aofFolder,
+		manifestPath: filepath.Join(aofFolder, manifestName),
+		filesPinner:  filesPinner,
+	}
+}
+
+// SetHistoryKeeper makes the service let Redis remove the history files after they are uploaded
+func (s *IncrementsPushService) SetHistoryKeeper(historyKeeper HistoryKeeper) {
+	s.historyKeeper = historyKeeper
+}
+
+// Run uploads the sealed increments every interval until the context is canceled
+func (s *IncrementsPushService) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.PushSealed(ctx); err != nil {
+			if ctx.Err() != nil {
+				// the interrupted upload is retried on the next run
+				return nil
+			}
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// PushSealed uploads the sealed increments which are not in the storage yet,
+// it fails if an increment was removed before the upload, since the archive would have a gap
+func (s *IncrementsPushService) PushSealed(ctx context.Context) error {
+	timeline, err := ReadOrCreateTimeline(s.aofFolder)
+	if err != nil {
+		return err
+	}
+	if err := s.loadUploaded(ctx, timeline); err != nil {
+		return err
+	}
+	entries, err := s.readManifest()
+	if err != nil {
+		return err
+	}
+	sealed := SealedIncrements(entries)
+	if err := s.checkContinuity(sealed); err != nil {
+		return err
+	}
+	for _, entry := range sealed {
+		if s.uploaded[entry.Name] {
+			continue
+		}
+		err := s.push(ctx, entry)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("incremental AOF file %s was removed before upload, point in time recovery is not "+
+				"possible over its interval: %w", entry.Name, s.gapError())
+		}
+		if err != nil {
+			return err
+		}
+		s.uploaded[entry.Name] = true
+	}
+	if s.historyKeeper != nil {
+		return s.releaseHistory(ctx)
+	}
+	return nil
+}
+
+// checkContinuity fails if the increments following the uploaded ones are no longer in the manifest
+func (s *IncrementsPushService) checkContinuity(sealed []ManifestEntry) error {
+	for _, entry := range sealed {
+		if s.uploaded[entry.Name] {
+			continue
+		}
+		baseName, seq, err := ParseIncrementName(entry.Name)
+		if err != nil {
+			return err
+		}
+		lastUploaded := s.lastUploadedSeq(baseName)
+		if lastUploaded > 0 && seq > lastUploaded+1 {
+			return fmt.Errorf("incremental AOF files with sequence numbers from %d to %d were removed before upload, "+
+				"point in time recovery is not possible over their interval: %w", lastUploaded+1, seq-1, s.gapError())
+		}
+		return nil
+	}
+	return nil
+}
+
+func (s *IncrementsPushService) lastUploadedSeq(baseName string) int {
+	last := 0
+	for name := range s.uploaded {
+		uploadedBaseName, seq, err := ParseIncrementName(name)
+		if err == nil && uploadedBaseName == baseName {
+			last = max(last, seq)
+		}
+	}
+	return last
+}
+
+func (s *IncrementsPushService) gapError() error {
+	return fmt.Errorf("set aof-disable-auto-gc yes, remove %s and take a new backup to start a new timeline",
+		filepath.Join(s.aofFolder, TimelineFileName))
+}
+
+// releaseHistory lets Redis remove the history files if the increments among them are uploaded.
+// The manifest is read after the rewrite is checked, so the history of the rewrite finished meanwhile is kept.
+func (s *IncrementsPushService) releaseHistory(ctx context.Context) error {
+	inProgress, err := s.historyKeeper.RewriteInProgress(ctx)
+	if err != nil || inProgress {
+		return err
+	}
+	entries, err := s.readManifest()
+	if err != nil {
+		return err
+	}
+	hasHistory := false
+	for _, entry := range entries {
+		if entry.Type != ManifestTypeHistory {
+			continue
+		}
+		if entry.IsIncr() && !s.uploaded[entry.Name] {
+			return nil
+		}
+		hasHistory = true
+	}
+	if !hasHistory {
+		return nil
+	}
+	tracelog.InfoLogger.Println("Removing the uploaded AOF history files")
+	return s.historyKeeper.RemoveHistory(ctx)
+}
+
+func (s *IncrementsPushService) readManifest() ([]ManifestEntry, error) {
+	manifest, err := os.ReadFile(s.manifestPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read manifest file %s", s.manifestPath)
+	}
+	return ParseManifestEntries(strings.Split(string(manifest), "\n"))
+}
+
+func (s *IncrementsPushService) push(ctx context.Context, entry ManifestEntry) error {
+	pinned, err := s.filesPinner.Pin([]string{filepath.Join(s.aofFolder, entry.Name)})
+	defer s.filesPinner.Unpin()
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(pinned[0])
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", pinned[0])
+	}
+	defer utility.LoggedClose(file, "")
+
+	tracelog.InfoLogger.Printf("Archiving incremental AOF file %s to timeline %s", entry.Name, s.timeline)
+	return errors.Wrapf(s.timelineUploader.UploadFile(ctx, file), "failed to upload %s", entry.Name)
+}
+
+// loadUploaded lists the increments of the timeline when it is pushed to for the first time
+func (s *IncrementsPushService) loadUploaded(ctx context.Context, timeline string) error {
+	if s.uploaded != nil && s.timeline == timeline {
+		return nil
+	}
+	uploader := s.uploader.Clone()
+	uploader.ChangeDirectory(timeline + "/")
+	objects, _, err := uploader.Folder().ListFolder(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list archived increments")
+	}
+	uploaded := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		uploaded[utility.TrimFileExtension(object.GetName())] = true
+	}
+	s.timeline, s.timelineUploader, s.uploaded = timeline, uploader, uploaded
+	return nil
+}
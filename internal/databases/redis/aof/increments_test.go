@@ -0,0 +1,164 @@
+package aof
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+)
+
+func TestParseManifestEntries(t *testing.T) {
+	entries, err := ParseManifestEntries([]string{
+		"file appendonly.aof.2.base.rdb seq 2 type b",
+		"file appendonly.aof.1.incr.aof seq 1 type h",
+		"file appendonly.aof.3.incr.aof seq 3 type i",
+		"file appendonly.aof.2.incr.aof seq 2 type i",
+		"",
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, ManifestEntry{Name: "appendonly.aof.2.base.rdb", Seq: 2, Type: ManifestTypeBase}, entries[0])
+	assert.Equal(t, "file appendonly.aof.3.incr.aof seq 3 type i", entries[2].String())
+
+	sealed := SealedIncrements(entries)
+	assert.Equal(t, []ManifestEntry{
+		{Name: "appendonly.aof.1.incr.aof", Seq: 1, Type: ManifestTypeHistory},
+		{Name: "appendonly.aof.2.incr.aof", Seq: 2, Type: ManifestTypeIncr},
+	}, sealed)
+
+	_, err = ParseManifestEntries([]string{"file appendonly.aof.1.incr.aof seq x type i"})
+	assert.Error(t, err)
+	_, err = ParseManifestEntries([]string{"appendonly.aof.1.incr.aof"})
+	assert.Error(t, err)
+}
+
+func TestParseIncrementName(t *testing.T) {
+	baseName, seq, err := ParseIncrementName("appendonly.aof.12.incr.aof")
+	require.NoError(t, err)
+	assert.Equal(t, "appendonly.aof", baseName)
+	assert.Equal(t, 12, seq)
+
+	for _, name := range []string{"appendonly.aof.1.base.rdb", "appendonly.aof.x.incr.aof", "incr.aof"} {
+		_, _, err = ParseIncrementName(name)
+		assert.Error(t, err, name)
+	}
+}
+
+type testHistoryKeeper struct {
+	rewriteInProgress bool
+	removed           int
+}
+
+func (k *testHistoryKeeper) RewriteInProgress(context.Context) (bool, error) {
+	return k.rewriteInProgress, nil
+}
+
+func (k *testHistoryKeeper) RemoveHistory(context.Context) error {
+	k.removed++
+	return nil
+}
+
+func TestIncrementsPushService_PushSealed(t *testing.T) {
+	aofFolder := t.TempDir()
+	writeFile := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(aofFolder, name), []byte(content), 0o600))
+	}
+	writeManifest := func(lines ...string) {
+		writeFile("appendonly.aof.manifest", strings.Join(lines, "\n"))
+	}
+	writeManifest(
+		"file appendonly.aof.2.base.rdb seq 2 type b",
+		"file appendonly.aof.1.incr.aof seq 1 type h",
+		"file appendonly.aof.2.incr.aof seq 2 type i",
+		"file appendonly.aof.3.incr.aof seq 3 type i",
+	)
+	writeFile("appendonly.aof.2.incr.aof", "sealed")
+	writeFile("appendonly.aof.3.incr.aof", "current")
+
+	folder := memory.NewFolder("", memory.NewKVS())
+	uploader := internal.NewRegularUploader(lz4.Compressor{}, folder.GetSubFolder(IncrementsPath))
+	newService := func() *IncrementsPushService {
+		return NewIncrementsPushService(uploader, aofFolder, "appendonly.aof.manifest",
+			NewFilesPinner(filepath.Join(t.TempDir(), "pin")))
+	}
+	service := newService()
+	listTimeline := func() []string {
+		timeline, err := ReadOrCreateTimeline(aofFolder)
+		require.NoError(t, err)
+		objects, _, err := folder.GetSubFolder(TimelineIncrementsPath(timeline)).ListFolder(context.Background())
+		require.NoError(t, err)
+		names := make([]string, 0, len(objects))
+		for _, object := range objects {
+			names = append(names, object.GetName())
+		}
+		return names
+	}
+
+	// appendonly.aof.1.incr.aof was already removed by Redis, the archive would have a gap
+	require.ErrorContains(t, service.PushSealed(context.Background()), "appendonly.aof.1.incr.aof was removed before upload")
+
+	writeFile("appendonly.aof.1.incr.aof", "history")
+	require.NoError(t, service.PushSealed(context.Background()))
+	assert.ElementsMatch(t, []string{"appendonly.aof.1.incr.aof.lz4", "appendonly.aof.2.incr.aof.lz4"}, listTimeline())
+
+	// the uploaded increments are not uploaded again, also after the restart
+	require.NoError(t, os.Remove(filepath.Join(aofFolder, "appendonly.aof.2.incr.aof")))
+	require.NoError(t, service.PushSealed(context.Background()))
+	service = newService()
+	require.NoError(t, service.PushSealed(context.Background()))
+
+	// the uploaded history is released unless the rewrite runs
+	keeper := &testHistoryKeeper{rewriteInProgress: true}
+	service.SetHistoryKeeper(keeper)
+	require.NoError(t, service.PushSealed(context.Background()))
+	assert.Equal(t, 0, keeper.removed)
+	keeper.rewriteInProgress = false
+	require.NoError(t, service.PushSealed(context.Background()))
+	assert.Equal(t, 1, keeper.removed)
+
+	// the increments removed from the manifest before the upload are reported as the gap
+	writeManifest(
+		"file appendonly.aof.5.base.rdb seq 5 type b",
+		"file appendonly.aof.5.incr.aof seq 5 type i",
+		"file appendonly.aof.6.incr.aof seq 6 type i",
+	)
+	writeFile("appendonly.aof.5.incr.aof", "sealed")
+	require.ErrorContains(t, service.PushSealed(context.Background()), "sequence numbers from 3 to 4 were removed")
+
+	// the new timeline starts from scratch, the increments with the same names are kept in both
+	require.NoError(t, RemoveTimeline(aofFolder))
+	require.NoError(t, service.PushSealed(context.Background()))
+	assert.Equal(t, []string{"appendonly.aof.5.incr.aof.lz4"}, listTimeline())
+	objects, folders, err := folder.GetSubFolder(IncrementsPath).ListFolder(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+	assert.Len(t, folders, 2)
+}
+
+func TestReadOrCreateTimeline(t *testing.T) {
+	aofFolder := t.TempDir()
+	timeline, err := ReadOrCreateTimeline(aofFolder)
+	require.NoError(t, err)
+	again, err := ReadOrCreateTimeline(aofFolder)
+	require.NoError(t, err)
+	assert.Equal(t, timeline, again)
+
+	require.NoError(t, RemoveTimeline(aofFolder))
+	require.NoError(t, RemoveTimeline(aofFolder))
+	next, err := ReadOrCreateTimeline(aofFolder)
+	require.NoError(t, err)
+	assert.NotEqual(t, timeline, next)
+	entries, err := os.ReadDir(aofFolder)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.Equal(t, IncrementsPath, TimelineIncrementsPath(""))
+	assert.Equal(t, IncrementsPath+next+"/", TimelineIncrementsPath(next))
+}
@@ -3,6 +3,7 @@ package aof
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
@@ -20,6 +21,9 @@ type RestoreService struct {
 type RestoreArgs struct {
 	BackupName     string
 	RestoreVersion string
+	// Until is the point in time to restore to with the archived increments, the backup state if zero
+	Until        time.Time
+	ManifestName string
 
 	SkipChecks         bool
 	SkipBackupDownload bool
@@ -41,6 +45,11 @@ func (r *RestoreService) DoRestore(ctx context.Context, args RestoreArgs) error
 		return err
 	}
 
+	if !args.Until.IsZero() && args.Until.Before(sentinel.StartLocalTime) {
+		return fmt.Errorf("backup %s was started at %s, after the target time %s",
+			sentinel.Name(), sentinel.StartLocalTime.Format(time.RFC3339), args.Until.Format(time.RFC3339))
+	}
+
 	if !args.SkipChecks {
 		ok, err := archive.EnsureRestoreCompatibility(sentinel.Version, args.RestoreVersion)
 		if err != nil {
@@ -73,7 +82,15 @@ func (r *RestoreService) DoRestore(ctx context.Context, args RestoreArgs) error
 		tracelog.InfoLogger.Println("Skipped download redis backup files")
 	}
 
-	return nil
+	if !args.Until.IsZero() {
+		tracelog.InfoLogger.Printf("Restore to %s with the archived incremental AOF files\n", args.Until.Format(time.RFC3339))
+		err = r.restoreUntil(ctx, args.ManifestName, sentinel.AOFTimeline, args.Until)
+		if err != nil {
+			return err
+		}
+	}
+	// the restored instance writes its own history, which must not continue the one of the backup in the archive
+	return RemoveTimeline(r.TargetDiskFolder.Path)
 }
 
 func (r *RestoreService) downloadFromTarArchives(ctx context.Context, backupName string) error {
@@ -0,0 +1,231 @@
+package aof
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// restoreUntil rolls the downloaded backup forward to the until time: the incremental files archived
+// in the timeline of the backup are downloaded, the commands after until are cut off and the manifest is rewritten.
+func (r *RestoreService) restoreUntil(ctx context.Context, manifestName, timeline string, until time.Time) error {
+	manifestPath := filepath.Join(r.TargetDiskFolder.Path, manifestName)
+	manifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest file %s: %w", manifestPath, err)
+	}
+	entries, err := ParseManifestEntries(strings.Split(string(manifest), "\n"))
+	if err != nil {
+		return err
+	}
+	var incrs, others []ManifestEntry
+	for _, entry := range entries {
+		if entry.Type == ManifestTypeIncr {
+			incrs = append(incrs, entry)
+		} else {
+			others = append(others, entry)
+		}
+	}
+	if len(incrs) == 0 {
+		return fmt.Errorf("backup manifest %s has no incremental files", manifestName)
+	}
+	sortBySeq(incrs)
+	baseName, _, err := ParseIncrementName(incrs[0].Name)
+	if err != nil {
+		return err
+	}
+	incrementsFolder := r.SourceStorageFolder.GetSubFolder(TimelineIncrementsPath(timeline))
+	archived, err := listArchivedIncrements(ctx, incrementsFolder, baseName)
+	if err != nil {
+		return err
+	}
+
+	restorer := &untilRestorer{
+		folder: r.TargetDiskFolder.Path,
+		reader: internal.NewFolderReader(incrementsFolder),
+		until:  until.Unix(),
+	}
+	kept, err := restorer.restore(ctx, incrs, archived)
+	if err != nil {
+		return err
+	}
+
+	var content strings.Builder
+	for _, entry := range append(others, kept...) {
+		content.WriteString(entry.String() + "\n")
+	}
+	return os.WriteFile(manifestPath, []byte(content.String()), 0644)
+}
+
+// listArchivedIncrements returns the names of the archived increments of the AOF by sequence number
+func listArchivedIncrements(ctx context.Context, folder storage.Folder, baseName string) (map[int]string, error) {
+	objects, _, err := folder.ListFolder(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived increments: %w", err)
+	}
+	archived := make(map[int]string)
+	for _, object := range objects {
+		name := utility.TrimFileExtension(object.GetName())
+		objectBaseName, seq, err := ParseIncrementName(name)
+		if err != nil || objectBaseName != baseName {
+			continue
+		}
+		archived[seq] = name
+	}
+	return archived, nil
+}
+
+type untilRestorer struct {
+	folder    string
+	reader    internal.StorageFolderReader
+	until     int64
+	annotated bool
+}
+
+// restore cuts the backup increments or the archived increments continuing them at the until time
+// and returns the increments to keep in the manifest
+func (u *untilRestorer) restore(ctx context.Context, incrs []ManifestEntry, archived map[int]string) ([]ManifestEntry, error) {
+	var kept []ManifestEntry
+	for i, incr := range incrs {
+		if i == len(incrs)-1 && archived[incr.Seq] != "" {
+			// the backup has the beginning of the file Redis was writing to only
+			if err := u.replaceWithArchived(ctx, incr.Name); err != nil {
+				return nil, err
+			}
+		}
+		kept = append(kept, incr)
+		found, err := u.cut(incr.Name)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			for _, dropped := range incrs[i+1:] {
+				if err := os.Remove(filepath.Join(u.folder, dropped.Name)); err != nil {
+					return nil, err
+				}
+			}
+			return kept, nil
+		}
+	}
+
+	for seq := incrs[len(incrs)-1].Seq + 1; ; seq++ {
+		name, ok := archived[seq]
+		if !ok {
+			for archivedSeq := range archived {
+				if archivedSeq > seq {
+					return nil, fmt.Errorf("incremental file with sequence number %d is missing in the archive", seq)
+				}
+			}
+			break
+		}
+		tracelog.InfoLogger.Printf("Download archived incremental AOF file %s", name)
+		if err := internal.DownloadFileTo(ctx, u.reader, name, filepath.Join(u.folder, name)); err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", name, err)
+		}
+		kept = append(kept, ManifestEntry{Name: name, Seq: seq, Type: ManifestTypeIncr})
+		found, err := u.cut(name)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return kept, nil
+		}
+	}
+
+	if !u.annotated {
+		return nil, fmt.Errorf("incremental AOF files have no timestamp annotations, " +
+			"point in time recovery requires aof-timestamp-enabled")
+	}
+	tracelog.WarningLogger.Printf("The archived incremental AOF files end before %s, restored up to the end of %s",
+		time.Unix(u.until, 0).UTC().Format(time.RFC3339), kept[len(kept)-1].Name)
+	return kept, nil
+}
+
+// replaceWithArchived replaces the backup copy of the increment with the archived one,
+// which has to continue it
+func (u *untilRestorer) replaceWithArchived(ctx context.Context, name string) error {
+	localPath := filepath.Join(u.folder, name)
+	archivedPath := localPath + ".archived"
+	_ = os.Remove(archivedPath)
+	tracelog.InfoLogger.Printf("Download archived incremental AOF file %s", name)
+	if err := internal.DownloadFileTo(ctx, u.reader, name, archivedPath); err != nil {
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	continues, err := hasPrefix(archivedPath, localPath)
+	if err != nil {
+		return err
+	}
+	if !continues {
+		_ = os.Remove(archivedPath)
+		return fmt.Errorf("archived %s doesn't continue the one from the backup, "+
+			"it was written by a different Redis instance or after a restore", name)
+	}
+	return os.Rename(archivedPath, localPath)
+}
+
+// cut truncates the increment at the first commands executed after the until time
+func (u *untilRestorer) cut(name string) (bool, error) {
+	path := filepath.Join(u.folder, name)
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	scan, err := scanTimestamps(file, u.until)
+	utility.LoggedClose(file, "")
+	if err != nil {
+		return false, fmt.Errorf("failed to scan %s: %w", name, err)
+	}
+	u.annotated = u.annotated || scan.annotated
+	if !scan.found {
+		return false, nil
+	}
+	tracelog.InfoLogger.Printf("Truncate %s at offset %d", name, scan.cutOffset)
+	return true, os.Truncate(path, scan.cutOffset)
+}
+
+// hasPrefix checks if the content of the file starts with the content of the prefix file
+func hasPrefix(path, prefixPath string) (bool, error) {
+	prefix, err := os.Open(prefixPath)
+	if err != nil {
+		return false, err
+	}
+	defer utility.LoggedClose(prefix, "")
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer utility.LoggedClose(file, "")
+
+	prefixChunk := make([]byte, 1<<20)
+	chunk := make([]byte, len(prefixChunk))
+	for {
+		n, err := io.ReadFull(prefix, prefixChunk)
+		if n > 0 {
+			if _, fileErr := io.ReadFull(file, chunk[:n]); fileErr != nil {
+				if fileErr == io.ErrUnexpectedEOF || fileErr == io.EOF {
+					return false, nil
+				}
+				return false, fileErr
+			}
+			if !bytes.Equal(chunk[:n], prefixChunk[:n]) {
+				return false, nil
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package aof
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/internal/databases/redis/archive"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+)
+
+const (
+	testManifestName = "appendonly.aof.manifest"
+	testTimeline     = "20260721T120000Z_0a1b2c3d"
+)
+
+var (
+	incr1Head = "#TS:90\r\n" + aofCommand("SET", "z", "0")
+	incr1     = incr1Head + "#TS:95\r\n" + aofCommand("SET", "y", "0")
+	// the increment Redis was writing to during the backup, the backup has its beginning only
+	incr2Head = "#TS:100\r\n" + aofCommand("SET", "a", "1")
+	incr2     = incr2Head + "#TS:110\r\n" + aofCommand("SET", "b", "2")
+	incr3Head = "#TS:120\r\n" + aofCommand("SET", "c", "3")
+	incr3     = incr3Head + "#TS:130\r\n" + aofCommand("SET", "d", "4")
+)
+
+func prepareUntilRestore(t *testing.T, archived map[string]string) (*RestoreService, string) {
+	dir := t.TempDir()
+	files := map[string]string{
+		testManifestName: strings.Join([]string{
+			"file appendonly.aof.2.base.rdb seq 2 type b",
+			"file appendonly.aof.1.incr.aof seq 1 type i",
+			"file appendonly.aof.2.incr.aof seq 2 type i",
+		}, "\n") + "\n",
+		"appendonly.aof.2.base.rdb": "REDIS",
+		"appendonly.aof.1.incr.aof": incr1,
+		"appendonly.aof.2.incr.aof": incr2Head,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	folder := memory.NewFolder("", memory.NewKVS())
+	uploader := internal.NewRegularUploader(lz4.Compressor{}, folder.GetSubFolder(TimelineIncrementsPath(testTimeline)))
+	uploadDir := t.TempDir()
+	for name, content := range archived {
+		path := filepath.Join(uploadDir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		require.NoError(t, uploader.UploadFile(context.Background(), file))
+		require.NoError(t, file.Close())
+	}
+	return &RestoreService{SourceStorageFolder: folder, TargetDiskFolder: archive.CreateAofFolderInfo(dir)}, dir
+}
+
+func readTestFile(t *testing.T, dir, name string) string {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestRestoreUntil_ArchivedIncrements(t *testing.T) {
+	service, dir := prepareUntilRestore(t, map[string]string{
+		"appendonly.aof.2.incr.aof": incr2,
+		"appendonly.aof.3.incr.aof": incr3,
+		"other.aof.4.incr.aof":      "unrelated",
+	})
+	require.NoError(t, service.restoreUntil(context.Background(), testManifestName, testTimeline, time.Unix(125, 0)))
+
+	assert.Equal(t, incr2, readTestFile(t, dir, "appendonly.aof.2.incr.aof"))
+	assert.Equal(t, incr3Head, readTestFile(t, dir, "appendonly.aof.3.incr.aof"))
+	assert.Equal(t, strings.Join([]string{
+		"file appendonly.aof.2.base.rdb seq 2 type b",
+		"file appendonly.aof.1.incr.aof seq 1 type i",
+		"file appendonly.aof.2.incr.aof seq 2 type i",
+		"file appendonly.aof.3.incr.aof seq 3 type i",
+	}, "\n")+"\n", readTestFile(t, dir, testManifestName))
+}
+
+func TestRestoreUntil_CutInBackup(t *testing.T) {
+	service, dir := prepareUntilRestore(t, nil)
+	require.NoError(t, service.restoreUntil(context.Background(), testManifestName, testTimeline, time.Unix(92, 0)))
+
+	assert.Equal(t, incr1Head, readTestFile(t, dir, "appendonly.aof.1.incr.aof"))
+	assert.NoFileExists(t, filepath.Join(dir, "appendonly.aof.2.incr.aof"))
+	assert.Equal(t, strings.Join([]string{
+		"file appendonly.aof.2.base.rdb seq 2 type b",
+		"file appendonly.aof.1.incr.aof seq 1 type i",
+	}, "\n")+"\n", readTestFile(t, dir, testManifestName))
+}
+
+func TestRestoreUntil_ArchiveEndsBeforeTarget(t *testing.T) {
+	service, dir := prepareUntilRestore(t, map[string]string{"appendonly.aof.2.incr.aof": incr2})
+	require.NoError(t, service.restoreUntil(context.Background(), testManifestName, testTimeline, time.Unix(1000, 0)))
+	assert.Equal(t, incr2, readTestFile(t, dir, "appendonly.aof.2.incr.aof"))
+}
+
+func TestRestoreUntil_Errors(t *testing.T) {
+	for name, archived := range map[string]map[string]string{
+		"different timeline": {"appendonly.aof.2.incr.aof": "#TS:100\r\n" + aofCommand("SET", "a", "other")},
+		"gap":                {"appendonly.aof.2.incr.aof": incr2, "appendonly.aof.4.incr.aof": incr3},
+	} {
+		t.Run(name, func(t *testing.T) {
+			service, _ := prepareUntilRestore(t, archived)
+			assert.Error(t, service.restoreUntil(context.Background(), testManifestName, testTimeline, time.Unix(1000, 0)))
+		})
+	}
+
+	t.Run("no timestamps", func(t *testing.T) {
+		service, dir := prepareUntilRestore(t, nil)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "appendonly.aof.1.incr.aof"), []byte(aofCommand("SET", "z", "0")), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "appendonly.aof.2.incr.aof"), []byte(aofCommand("SET", "a", "1")), 0o600))
+		assert.Error(t, service.restoreUntil(context.Background(), testManifestName, testTimeline, time.Unix(1000, 0)))
+	})
+}
@@ -13,6 +13,9 @@ func SentinelWithExistenceCheck(ctx context.Context, folder storage.Folder, back
 	if err != nil {
 		return archive.Backup{}, err
 	}
+	if err := archive.CheckNotClusterBackup(sentinel); err != nil {
+		return archive.Backup{}, err
+	}
 	if sentinel.Version == "" {
 		return archive.Backup{}, fmt.Errorf("expecting sentinel file for aof backup with always filled version: %+v", sentinel)
 	}
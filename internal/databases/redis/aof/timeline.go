@@ -0,0 +1,77 @@
+package aof
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wal-g/wal-g/utility"
+)
+
+// TimelineFileName is the file in the AOF folder with the ID of the AOF history written there.
+// The increments are archived under the timeline, so the increments of another instance,
+// of the wiped data folder or of the restored backup never take the place of each other
+// even though Redis gives them the same names.
+const TimelineFileName = "walg_aof_timeline"
+
+// TimelineIncrementsPath returns the storage folder of the increments of the timeline,
+// the increments archived before the timelines were introduced are in the IncrementsPath root
+func TimelineIncrementsPath(timeline string) string {
+	if timeline == "" {
+		return IncrementsPath
+	}
+	return IncrementsPath + timeline + "/"
+}
+
+// ReadOrCreateTimeline returns the timeline of the AOF folder, a new one is started if there is none
+func ReadOrCreateTimeline(aofFolder string) (string, error) {
+	path := filepath.Join(aofFolder, TimelineFileName)
+	timeline, err := readTimeline(path)
+	if !errors.Is(err, os.ErrNotExist) {
+		return timeline, err
+	}
+	random := make([]byte, 4)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	timeline = utility.TimeNowCrossPlatformUTC().Format("20060102T150405Z") + "_" + hex.EncodeToString(random)
+	// the file is linked in place complete, so the concurrent backup or increments push reads either none or all of it
+	tmpPath := path + "." + hex.EncodeToString(random)
+	if err := os.WriteFile(tmpPath, []byte(timeline+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to write AOF timeline file %s: %w", tmpPath, err)
+	}
+	defer os.Remove(tmpPath)
+	err = os.Link(tmpPath, path)
+	if errors.Is(err, os.ErrExist) {
+		return readTimeline(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create AOF timeline file %s: %w", path, err)
+	}
+	return timeline, nil
+}
+
+// RemoveTimeline makes the next backup or increments push start a new timeline in the AOF folder
+func RemoveTimeline(aofFolder string) error {
+	err := os.Remove(filepath.Join(aofFolder, TimelineFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func readTimeline(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	timeline := strings.TrimSpace(string(content))
+	if timeline == "" || strings.ContainsAny(timeline, "/\\") {
+		return "", fmt.Errorf("unexpected AOF timeline %q in %s", timeline, path)
+	}
+	return timeline, nil
+}
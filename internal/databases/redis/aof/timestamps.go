@@ -0,0 +1,87 @@
+package aof
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// timestampAnnotation is written before the commands of a new second when aof-timestamp-enabled is set
+const timestampAnnotation = "#TS:"
+
+type timestampScan struct {
+	// cutOffset is the offset of the first timestamp annotation later than the target time
+	cutOffset int64
+	found     bool
+	annotated bool
+}
+
+// scanTimestamps scans the commands of the AOF file for the first timestamp annotation later than until,
+// the commands before it were executed not later than until.
+// A partially written command at the end of the file is ignored as Redis does with aof-load-truncated.
+func scanTimestamps(file io.Reader, until int64) (timestampScan, error) {
+	var scan timestampScan
+	var offset int64
+	reader := bufio.NewReader(file)
+	readLine := func() (string, error) {
+		line, err := reader.ReadString('\n')
+		offset += int64(len(line))
+		return strings.TrimRight(line, "\r\n"), err
+	}
+
+	for {
+		lineStart := offset
+		line, err := readLine()
+		if err == io.EOF {
+			return scan, nil
+		}
+		if err != nil {
+			return scan, err
+		}
+		switch {
+		case strings.HasPrefix(line, timestampAnnotation):
+			ts, err := strconv.ParseInt(line[len(timestampAnnotation):], 10, 64)
+			if err != nil {
+				return scan, fmt.Errorf("invalid timestamp annotation '%s' at offset %d", line, lineStart)
+			}
+			scan.annotated = true
+			if ts > until {
+				scan.cutOffset, scan.found = lineStart, true
+				return scan, nil
+			}
+		case strings.HasPrefix(line, "#"):
+			// other annotations
+		case strings.HasPrefix(line, "*"):
+			args, err := strconv.Atoi(line[1:])
+			if err != nil {
+				return scan, fmt.Errorf("invalid command header '%s' at offset %d", line, lineStart)
+			}
+			for i := 0; i < args; i++ {
+				headerStart := offset
+				line, err = readLine()
+				if err == io.EOF {
+					return scan, nil
+				}
+				if err != nil {
+					return scan, err
+				}
+				size, convErr := strconv.Atoi(strings.TrimPrefix(line, "$"))
+				if !strings.HasPrefix(line, "$") || convErr != nil {
+					return scan, fmt.Errorf("invalid bulk string header '%s' at offset %d", line, headerStart)
+				}
+				skipped, err := reader.Discard(size + 2)
+				offset += int64(skipped)
+				if err == io.EOF {
+					return scan, nil
+				}
+				if err != nil {
+					return scan, err
+				}
+			}
+		default:
+			return scan, fmt.Errorf("unexpected AOF content '%s' at offset %d", line, lineStart)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package aof
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func aofCommand(args ...string) string {
+	command := fmt.Sprintf("*%d\r\n", len(args))
+	for _, arg := range args {
+		command += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return command
+}
+
+func TestScanTimestamps(t *testing.T) {
+	head := "#TS:100\r\n" + aofCommand("SET", "a", "1") +
+		// the annotation inside the value is not a timestamp
+		aofCommand("SET", "b", "x\r\n#TS:300\r\n") +
+		"#TS:101\r\n" + aofCommand("DEL", "a")
+	content := head + "#TS:200\r\n" + aofCommand("SET", "c", "2")
+
+	scan, err := scanTimestamps(strings.NewReader(content), 150)
+	require.NoError(t, err)
+	assert.Equal(t, timestampScan{cutOffset: int64(len(head)), found: true, annotated: true}, scan)
+
+	scan, err = scanTimestamps(strings.NewReader(content), 200)
+	require.NoError(t, err)
+	assert.Equal(t, timestampScan{annotated: true}, scan)
+
+	scan, err = scanTimestamps(strings.NewReader(content), 50)
+	require.NoError(t, err)
+	assert.Equal(t, timestampScan{cutOffset: 0, found: true, annotated: true}, scan)
+}
+
+func TestScanTimestamps_TruncatedTail(t *testing.T) {
+	content := aofCommand("SET", "a", "1") + "*3\r\n$3\r\nSET\r\n$1\r\nb\r\n$10\r\n12"
+	scan, err := scanTimestamps(strings.NewReader(content), 100)
+	require.NoError(t, err)
+	assert.Equal(t, timestampScan{}, scan)
+}
+
+func TestScanTimestamps_Invalid(t *testing.T) {
+	for _, content := range []string{"#TS:abc\r\n", "SET a 1\r\n", "*1\r\nSET\r\n"} {
+		_, err := scanTimestamps(strings.NewReader(content), 100)
+		assert.Error(t, err, content)
+	}
+}
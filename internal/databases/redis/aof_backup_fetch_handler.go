@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"path/filepath"
+	"time"
 
 	"github.com/wal-g/wal-g/internal"
 	conf "github.com/wal-g/wal-g/internal/config"
@@ -15,6 +16,7 @@ func HandleAofFetchPush(
 	ctx context.Context,
 	sourceStorageFolder storage.Folder, uploader internal.Uploader,
 	backupName, restoreVersion string,
+	until time.Time,
 	skipBackupDownload, skipChecks bool,
 ) error {
 	dataFolder, _ := conf.GetSetting(conf.RedisDataPath)
@@ -30,9 +32,12 @@ func HandleAofFetchPush(
 		return err
 	}
 
+	manifestName, _ := conf.GetSetting(conf.RedisAppendonlyManifest)
 	return restoreService.DoRestore(ctx, aof.RestoreArgs{
 		BackupName:     backupName,
 		RestoreVersion: restoreVersion,
+		Until:          until,
+		ManifestName:   manifestName,
 
 		SkipChecks:         skipChecks,
 		SkipBackupDownload: skipBackupDownload,
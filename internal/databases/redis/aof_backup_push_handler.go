@@ -47,6 +47,12 @@ func HandleAOFBackupPush(ctx context.Context, args AOFBackupPushArgs) error {
 		return err
 	}
 
+	// the increments archived after the backup continue it in the timeline of the AOF folder
+	timeline, err := aof.ReadOrCreateTimeline(aofPath)
+	if err != nil {
+		return err
+	}
+
 	manifestName, _ := conf.GetSetting(conf.RedisAppendonlyManifest)
 	backupFilesListProvider := aof.NewBackupFilesListProvider(aofPath, tmpPath, manifestName)
 
@@ -65,6 +71,7 @@ func HandleAOFBackupPush(ctx context.Context, args AOFBackupPushArgs) error {
 
 	doBackupArgs := aof.DoBackupArgs{
 		BackupName:    backupName,
+		Timeline:      timeline,
 		Sharded:       args.Sharded,
 		DeferSentinel: args.DeferSentinel,
 	}
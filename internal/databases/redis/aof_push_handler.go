@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/databases/redis/aof"
+	client "github.com/wal-g/wal-g/internal/databases/redis/client"
+)
+
+type AOFPushArgs struct {
+	Uploader   internal.Uploader
+	Continuous bool
+	Interval   time.Duration
+}
+
+const aofDisableAutoGC = "aof-disable-auto-gc"
+
+// HandleAOFPush uploads the sealed incremental AOF files, continuously if requested.
+// Redis keeps the history files while they are uploaded: the continuous push sets aof-disable-auto-gc
+// until it stops, the single push requires it, and both let Redis remove the uploaded history.
+func HandleAOFPush(ctx context.Context, args AOFPushArgs) error {
+	dataFolder, _ := conf.GetSetting(conf.RedisDataPath)
+	aofFolder, _ := conf.GetSetting(conf.RedisAppendonlyFolder)
+	aofPath := filepath.Join(dataFolder, aofFolder)
+	manifestName, _ := conf.GetSetting(conf.RedisAppendonlyManifest)
+	tmpPath, _ := conf.GetSetting(conf.RedisAppendonlyTmpFolder)
+
+	args.Uploader.ChangeDirectory(aof.IncrementsPath)
+	// AOF backups pin the files to the temp folder root, so the increments use a subfolder
+	filesPinner := aof.NewFilesPinner(filepath.Join(tmpPath, "increments"))
+	pushService := aof.NewIncrementsPushService(args.Uploader, aofPath, manifestName, filesPinner)
+	pushService.SetHistoryKeeper(aofHistoryKeeper{})
+
+	autoGCDisabled, err := client.GetConfig(ctx, aofDisableAutoGC)
+	if err != nil {
+		return err
+	}
+	if !args.Continuous {
+		if autoGCDisabled != "yes" {
+			return fmt.Errorf("the AOF history files are removed by Redis before they are uploaded, "+
+				"set %s yes or run aof-push --continuous", aofDisableAutoGC)
+		}
+		return pushService.PushSealed(ctx)
+	}
+
+	if autoGCDisabled != "yes" {
+		tracelog.InfoLogger.Printf("Setting %s yes while the increments are pushed", aofDisableAutoGC)
+		if err := client.SetConfig(ctx, aofDisableAutoGC, "yes"); err != nil {
+			return err
+		}
+		defer func() {
+			// the context is canceled by now
+			err := client.SetConfig(context.Background(), aofDisableAutoGC, autoGCDisabled)
+			if err != nil {
+				tracelog.ErrorLogger.Printf("Failed to restore %s: %v", aofDisableAutoGC, err)
+			}
+		}()
+	}
+	return pushService.Run(ctx, args.Interval)
+}
+
+// aofHistoryKeeper removes the history files by enabling the automatic removal for a moment
+type aofHistoryKeeper struct{}
+
+func (aofHistoryKeeper) RewriteInProgress(ctx context.Context) (bool, error) {
+	return client.IsAOFRewriteInProgress(ctx)
+}
+
+func (aofHistoryKeeper) RemoveHistory(ctx context.Context) error {
+	err := client.SetConfig(ctx, aofDisableAutoGC, "no")
+	if err != nil {
+		return err
+	}
+	return client.SetConfig(ctx, aofDisableAutoGC, "yes")
+}
@@ -55,6 +55,10 @@ type Backup struct {
 	TSFileCount     int64       `json:"TSFileCount,omitempty"`
 	TSStartTime     time.Time   `json:"TSStartTime,omitempty"`
 	TSFinishTime    time.Time   `json:"TSFinishTime,omitempty"`
+	// AOFTimeline is the AOF timeline the increments continuing the AOF backup are archived under
+	AOFTimeline string `json:"AOFTimeline,omitempty"`
+	// Shards are the backups of the Redis Cluster masters referenced by the cluster backup
+	Shards []ShardBackup `json:"Shards,omitempty"`
 }
 
 func (b *Backup) Name() string {
@@ -65,6 +69,10 @@ func (b *Backup) StartTime() time.Time {
 	return b.StartLocalTime
 }
 
+func (b *Backup) FinishTime() time.Time {
+	return b.FinishLocalTime
+}
+
 func (b *Backup) IsPermanent() bool {
 	return b.Permanent
 }
@@ -77,6 +85,10 @@ func (b *Backup) IsRDB() bool {
 	return b.BackupType == RDBBackupType
 }
 
+func (b *Backup) IsCluster() bool {
+	return b.BackupType == ClusterBackupType
+}
+
 func (b *Backup) VersionStr() string {
 	return b.Version
 }
@@ -0,0 +1,184 @@
+package archive
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// ClusterBackupType is the type of the backup of all masters of the Redis Cluster
+const ClusterBackupType = "cluster"
+
+func GenerateNewClusterBackupName() string {
+	return ClusterBackupType + "_" + utility.TimeNowCrossPlatformUTC().Format(utility.BackupTimeFormat)
+}
+
+// CheckNotClusterBackup rejects the cluster backup in the commands handling the backup of a single node,
+// the cluster backup has no data of its own: its shards are restored with cluster-backup-fetch
+func CheckNotClusterBackup(backup Backup) error {
+	if backup.IsCluster() {
+		return fmt.Errorf("backup %s is a Redis Cluster backup, restore its shards with cluster-backup-fetch",
+			backup.BackupName)
+	}
+	return nil
+}
+
+// SlotRange is the inclusive range of the Redis Cluster hash slots
+type SlotRange struct {
+	Start int `json:"Start"`
+	End   int `json:"End"`
+}
+
+// ShardBackup is the RDB backup of the Redis Cluster master,
+// it is stored as the stream in the cluster backup folder
+type ShardBackup struct {
+	Name       string      `json:"Name"`
+	NodeID     string      `json:"NodeID"`
+	Address    string      `json:"Address"`
+	Slots      []SlotRange `json:"Slots"`
+	DataSize   int64       `json:"DataSize,omitempty"`
+	BackupSize int64       `json:"BackupSize,omitempty"`
+}
+
+// ShardStreamName returns the name of the shard backup stream inside the cluster backup folder
+func ShardStreamName(clusterBackupName, shardName string) string {
+	return path.Join(clusterBackupName, shardName)
+}
+
+// ClusterMaster is the master of the Redis Cluster serving the slots
+type ClusterMaster struct {
+	ID    string
+	Host  string
+	Port  string
+	Slots []SlotRange
+}
+
+func (m ClusterMaster) Address() string {
+	return m.Host + ":" + m.Port
+}
+
+// ParseClusterMasters parses the CLUSTER NODES output and returns the healthy masters serving slots
+// ordered by the first slot. The host of the node without an address is the host of the node we are connected to.
+func ParseClusterMasters(clusterNodes, connectedHost string) ([]ClusterMaster, error) {
+	var masters []ClusterMaster
+	for _, line := range strings.Split(clusterNodes, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			continue
+		}
+		flags := strings.Split(fields[2], ",")
+		if !slices.Contains(flags, "master") {
+			continue
+		}
+		if slices.Contains(flags, "fail") || slices.Contains(flags, "fail?") || slices.Contains(flags, "noaddr") ||
+			slices.Contains(flags, "handshake") {
+			tracelog.WarningLogger.Printf("Master %s is skipped, its flags are %s", fields[0], fields[2])
+			continue
+		}
+
+		intervals, err := getIntervals(line)
+		if err != nil {
+			return nil, err
+		}
+		if len(intervals) == 0 {
+			continue
+		}
+		master := ClusterMaster{ID: fields[0]}
+		master.Host, master.Port, err = parseNodeAddress(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		if master.Host == "" {
+			master.Host = connectedHost
+		}
+		for _, interval := range intervals {
+			slotRange, err := parseSlotRange(interval)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slots of node %s: %w", master.ID, err)
+			}
+			master.Slots = append(master.Slots, slotRange)
+		}
+		masters = append(masters, master)
+	}
+	if len(masters) == 0 {
+		return nil, fmt.Errorf("no masters serving slots are found in the cluster nodes")
+	}
+	slices.SortFunc(masters, func(a, b ClusterMaster) int {
+		return a.Slots[0].Start - b.Slots[0].Start
+	})
+	return masters, nil
+}
+
+// parseNodeAddress parses the node address like <ip>:6379@16379,<hostname>, the hostname is preferred
+func parseNodeAddress(address string) (host, port string, err error) {
+	parts := strings.Split(address, ",")
+	ipWithPorts := strings.Split(parts[0], "@")[0]
+	colon := strings.LastIndex(ipWithPorts, ":")
+	if colon < 0 {
+		return "", "", fmt.Errorf("invalid node address %s", address)
+	}
+	host, port = ipWithPorts[:colon], ipWithPorts[colon+1:]
+	if len(parts) > 1 && parts[1] != "" && !strings.Contains(parts[1], "=") {
+		host = parts[1]
+	}
+	return host, port, nil
+}
+
+func parseSlotRange(interval []string) (SlotRange, error) {
+	start, err := strconv.Atoi(interval[0])
+	if err != nil {
+		return SlotRange{}, err
+	}
+	end, err := strconv.Atoi(interval[1])
+	if err != nil {
+		return SlotRange{}, err
+	}
+	return SlotRange{Start: start, End: end}, nil
+}
+
+// MatchShardsToMasters finds the master serving all the slots of every shard backup, by shard name.
+// The shard data can't be restored to the master serving the slots of several shards
+// or to several masters, so the slot layout of the cluster has to be the same as at the backup time.
+func MatchShardsToMasters(shards []ShardBackup, masters []ClusterMaster) (map[string]ClusterMaster, error) {
+	slotOwners := make(map[int]int)
+	for i, master := range masters {
+		for _, slots := range master.Slots {
+			for slot := slots.Start; slot <= slots.End; slot++ {
+				slotOwners[slot] = i
+			}
+		}
+	}
+
+	matched := make(map[string]ClusterMaster, len(shards))
+	shardOfMaster := make(map[int]string)
+	for _, shard := range shards {
+		owner := -1
+		for _, slots := range shard.Slots {
+			for slot := slots.Start; slot <= slots.End; slot++ {
+				slotOwner, ok := slotOwners[slot]
+				if !ok {
+					return nil, fmt.Errorf("slot %d of shard %s is not served by any master", slot, shard.Name)
+				}
+				if owner != -1 && owner != slotOwner {
+					return nil, fmt.Errorf("slots of shard %s are served by masters %s and %s",
+						shard.Name, masters[owner].ID, masters[slotOwner].ID)
+				}
+				owner = slotOwner
+			}
+		}
+		if owner == -1 {
+			return nil, fmt.Errorf("shard %s has no slots", shard.Name)
+		}
+		if other, ok := shardOfMaster[owner]; ok {
+			return nil, fmt.Errorf("master %s serves the slots of shards %s and %s", masters[owner].ID, other, shard.Name)
+		}
+		shardOfMaster[owner] = shard.Name
+		matched[shard.Name] = masters[owner]
+	}
+	return matched, nil
+}
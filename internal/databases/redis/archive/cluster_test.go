@@ -0,0 +1,119 @@
+package archive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testClusterNodes = `
+07c37dfeb235213a872192d90877d0cd55635b91 127.0.0.1:30004@31004,,tls-port=0,shard-id=69bc slave e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 0 1426238317239 4 connected
+67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 127.0.0.1:30002@31002,redis2.example.net master - 0 1426238316232 2 connected 5461-10922
+292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f 127.0.0.1:30003@31003 master - 0 1426238318243 3 connected 10923-16383
+e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca :30001@31001 myself,master - 0 0 1 connected 0-5460
+824fe116063bc5fcf9f4ffd895bc17aee7731ac3 127.0.0.1:30006@31006 master,fail - 1426238316232 0 6 connected
+6ec23923021cf3ffec47632106199cb7f496ce01 127.0.0.1:30005@31005 master - 0 1426238316232 5 connected
+`
+
+func TestParseClusterMasters(t *testing.T) {
+	masters, err := ParseClusterMasters(testClusterNodes, "redis1.example.net")
+	require.NoError(t, err)
+	require.Equal(t, []ClusterMaster{
+		{
+			ID:    "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca",
+			Host:  "redis1.example.net",
+			Port:  "30001",
+			Slots: []SlotRange{{Start: 0, End: 5460}},
+		},
+		{
+			ID:    "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1",
+			Host:  "redis2.example.net",
+			Port:  "30002",
+			Slots: []SlotRange{{Start: 5461, End: 10922}},
+		},
+		{
+			ID:    "292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f",
+			Host:  "127.0.0.1",
+			Port:  "30003",
+			Slots: []SlotRange{{Start: 10923, End: 16383}},
+		},
+	}, masters)
+}
+
+func TestParseClusterMasters_MigratingSlots(t *testing.T) {
+	nodes := "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:30001@31001 myself,master - 0 0 1 connected " +
+		"0-5460 [5460->-292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f]"
+	_, err := ParseClusterMasters(nodes, "localhost")
+	require.ErrorAs(t, err, &MigratingSlotsError{})
+}
+
+func TestParseClusterMasters_NoMasters(t *testing.T) {
+	_, err := ParseClusterMasters("", "localhost")
+	require.Error(t, err)
+}
+
+func TestMatchShardsToMasters(t *testing.T) {
+	masters := []ClusterMaster{
+		{ID: "new1", Host: "host1", Port: "6379", Slots: []SlotRange{{0, 100}, {301, 400}}},
+		{ID: "new2", Host: "host2", Port: "6379", Slots: []SlotRange{{101, 300}}},
+	}
+	tests := []struct {
+		name     string
+		shards   []ShardBackup
+		expected map[string]string
+		errorMsg string
+	}{
+		{
+			name: "same layout",
+			shards: []ShardBackup{
+				{Name: "shard_0", Slots: []SlotRange{{0, 100}, {301, 400}}},
+				{Name: "shard_1", Slots: []SlotRange{{101, 300}}},
+			},
+			expected: map[string]string{"shard_0": "new1", "shard_1": "new2"},
+		},
+		{
+			name: "master serves more slots than the shard",
+			shards: []ShardBackup{
+				{Name: "shard_0", Slots: []SlotRange{{0, 50}}},
+			},
+			expected: map[string]string{"shard_0": "new1"},
+		},
+		{
+			name: "shard is split between masters",
+			shards: []ShardBackup{
+				{Name: "shard_0", Slots: []SlotRange{{0, 200}}},
+			},
+			errorMsg: "slots of shard shard_0 are served by masters new1 and new2",
+		},
+		{
+			name: "master serves several shards",
+			shards: []ShardBackup{
+				{Name: "shard_0", Slots: []SlotRange{{0, 100}}},
+				{Name: "shard_1", Slots: []SlotRange{{301, 400}}},
+			},
+			errorMsg: "master new1 serves the slots of shards shard_0 and shard_1",
+		},
+		{
+			name: "slot is not served",
+			shards: []ShardBackup{
+				{Name: "shard_0", Slots: []SlotRange{{401, 500}}},
+			},
+			errorMsg: "slot 401 of shard shard_0 is not served by any master",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := MatchShardsToMasters(tt.shards, masters)
+			if tt.errorMsg != "" {
+				require.EqualError(t, err, tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			matchedIDs := make(map[string]string)
+			for shard, master := range matched {
+				matchedIDs[shard] = master.ID
+			}
+			require.Equal(t, tt.expected, matchedIDs)
+		})
+	}
+}
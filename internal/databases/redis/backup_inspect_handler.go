@@ -0,0 +1,117 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/databases/redis/archive"
+	"github.com/wal-g/wal-g/internal/databases/redis/rdb"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+type BackupInspectArgs struct {
+	Pattern string
+	// Output gets the matching keys with their types, TTLs and memory usage estimations
+	Output io.Writer
+	// Export gets the RESTORE commands of the matching keys, it is not used if nil
+	Export io.Writer
+	// Replace adds REPLACE to the exported RESTORE commands
+	Replace bool
+}
+
+// HandleBackupInspect streams the RDB backup from the storage and parses it,
+// the keys matching the pattern are printed and exported as RESTORE commands
+func HandleBackupInspect(ctx context.Context, folder storage.Folder, backupName string, args BackupInspectArgs) error {
+	backup, err := archive.SentinelWithExistenceCheck(ctx, folder, backupName)
+	if err != nil {
+		return err
+	}
+	if err := archive.CheckNotClusterBackup(backup); err != nil {
+		return err
+	}
+	if !backup.IsRDB() {
+		return fmt.Errorf("backup %s is not an RDB backup, its type is %s", backup.BackupName, backup.BackupType)
+	}
+
+	reader, writer := io.Pipe()
+	downloadErrCh := make(chan error, 1)
+	go func() {
+		err := internal.DownloadAndDecompressStream(ctx, backup.ToInternal(folder), writer)
+		writer.CloseWithError(err)
+		downloadErrCh <- err
+	}()
+
+	inspectErr := inspectRDB(reader, args, backup.StartLocalTime.UnixMilli())
+	reader.CloseWithError(inspectErr)
+	downloadErr := <-downloadErrCh
+	if downloadErr != nil && (inspectErr == nil || errors.Is(inspectErr, io.ErrUnexpectedEOF)) {
+		return downloadErr
+	}
+	return inspectErr
+}
+
+// inspectRDB prints and exports the matching keys, the TTLs are counted from the RDB creation time
+// or from the backup start time if the RDB doesn't have it
+func inspectRDB(reader io.Reader, args BackupInspectArgs, backupTimeMs int64) error {
+	pattern := []byte(args.Pattern)
+	parser := rdb.NewParser(reader)
+	if args.Export != nil {
+		parser.Capture = func(key []byte) bool {
+			return rdb.MatchPattern(pattern, key)
+		}
+	}
+
+	if _, err := fmt.Fprintln(args.Output, "db\tkey\ttype\tttl\tmemory"); err != nil {
+		return err
+	}
+	exportedDB := -1
+	var matched, total int
+	for {
+		entry, err := parser.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		total++
+		if !rdb.MatchPattern(pattern, entry.Key) {
+			continue
+		}
+		matched++
+
+		ttl := int64(-1)
+		if entry.ExpireAt != 0 {
+			createdMs := backupTimeMs
+			if ctime, err := strconv.ParseInt(parser.Aux("ctime"), 10, 64); err == nil {
+				createdMs = ctime * 1000
+			}
+			ttl = max((entry.ExpireAt-createdMs+999)/1000, 0)
+		}
+		_, err = fmt.Fprintf(args.Output, "%d\t%s\t%s\t%d\t%d\n",
+			entry.DB, strconv.Quote(string(entry.Key)), entry.Type, ttl, entry.Size)
+		if err != nil {
+			return err
+		}
+
+		if args.Export == nil {
+			continue
+		}
+		if entry.DB != exportedDB {
+			if _, err := args.Export.Write(rdb.RESPCommand([]byte("SELECT"), []byte(strconv.Itoa(entry.DB)))); err != nil {
+				return err
+			}
+			exportedDB = entry.DB
+		}
+		if _, err := args.Export.Write(rdb.RestoreCommand(entry, parser.Version(), args.Replace)); err != nil {
+			return err
+		}
+	}
+	tracelog.InfoLogger.Printf("%d of %d keys match the pattern %q", matched, total, args.Pattern)
+	return nil
+}
@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/internal/databases/redis/aof"
+	"github.com/wal-g/wal-g/internal/databases/redis/archive"
+	"github.com/wal-g/wal-g/internal/databases/redis/rdb"
+	"github.com/wal-g/wal-g/testtools"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// testRDB has string user:1 with value alice, string session:1 with value 42 expiring 5 seconds after ctime
+// and string user:2 with value bob in database 1
+const testRDB = "REDIS0011" +
+	"\xfa\x05ctime\xc2\x00\xe1\xf5\x05" +
+	"\xfe\x00" +
+	"\x00\x06user:1\x05alice" +
+	"\xfc\x88\xfb\x76\x48\x17\x00\x00\x00\x00\x09session:1\xc0\x2a" +
+	"\xfe\x01" +
+	"\x00\x06user:2\x03bob" +
+	"\xff"
+
+func TestHandleBackupInspect(t *testing.T) {
+	folder := testtools.MakeDefaultInMemoryStorageFolder()
+	backup := archive.Backup{BackupName: "stream_20260721T120000Z", BackupType: archive.RDBBackupType}
+	serialized, err := json.Marshal(backup)
+	require.NoError(t, err)
+	backupFolder := folder.GetSubFolder(utility.BaseBackupPath)
+	require.NoError(t, backupFolder.PutObject(
+		t.Context(), backup.BackupName+utility.SentinelSuffix, bytes.NewReader(serialized),
+	))
+	uploader := internal.NewRegularUploader(lz4.Compressor{}, backupFolder)
+	_, err = uploader.PushStreamWithName(t.Context(), bytes.NewReader([]byte(testRDB)), backup.BackupName)
+	require.NoError(t, err)
+
+	output := new(bytes.Buffer)
+	export := new(bytes.Buffer)
+	err = HandleBackupInspect(t.Context(), folder, backup.BackupName, BackupInspectArgs{
+		Pattern: "user:*",
+		Output:  output,
+		Export:  export,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "db\tkey\ttype\tttl\tmemory\n"+
+		"0\t\"user:1\"\tstring\t-1\t67\n"+
+		"1\t\"user:2\"\tstring\t-1\t65\n", output.String())
+	expectedExport := string(rdb.RESPCommand([]byte("SELECT"), []byte("0"))) +
+		string(rdb.RestoreCommand(&rdb.Entry{Key: []byte("user:1"), Value: []byte("\x00\x05alice")}, 11, false)) +
+		string(rdb.RESPCommand([]byte("SELECT"), []byte("1"))) +
+		string(rdb.RestoreCommand(&rdb.Entry{Key: []byte("user:2"), Value: []byte("\x00\x03bob")}, 11, false))
+	require.Equal(t, expectedExport, export.String())
+
+	output.Reset()
+	err = HandleBackupInspect(t.Context(), folder, backup.BackupName, BackupInspectArgs{
+		Pattern: "session:*",
+		Output:  output,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "db\tkey\ttype\tttl\tmemory\n0\t\"session:1\"\tstring\t5\t65\n", output.String())
+}
+
+func TestHandleBackupInspectRejectsAOFBackup(t *testing.T) {
+	folder := testtools.MakeDefaultInMemoryStorageFolder()
+	backup := archive.Backup{BackupName: "aof_20260721T120000Z", BackupType: archive.AOFBackupType}
+	serialized, err := json.Marshal(backup)
+	require.NoError(t, err)
+	require.NoError(t, folder.GetSubFolder(utility.BaseBackupPath).PutObject(
+		t.Context(), backup.BackupName+utility.SentinelSuffix, bytes.NewReader(serialized),
+	))
+
+	err = HandleBackupInspect(t.Context(), folder, backup.BackupName, BackupInspectArgs{Pattern: "*"})
+	require.EqualError(t, err, "backup aof_20260721T120000Z is not an RDB backup, its type is aof")
+}
+
+func TestClusterBackupRejectedBySingleNodeCommands(t *testing.T) {
+	folder := testtools.MakeDefaultInMemoryStorageFolder()
+	backup := archive.Backup{BackupName: "cluster_20260721T120000Z", BackupType: archive.ClusterBackupType}
+	serialized, err := json.Marshal(backup)
+	require.NoError(t, err)
+	require.NoError(t, folder.GetSubFolder(utility.BaseBackupPath).PutObject(
+		t.Context(), backup.BackupName+utility.SentinelSuffix, bytes.NewReader(serialized),
+	))
+	expected := "backup cluster_20260721T120000Z is a Redis Cluster backup, restore its shards with cluster-backup-fetch"
+
+	err = HandleBackupInspect(t.Context(), folder, backup.BackupName, BackupInspectArgs{Pattern: "*"})
+	require.EqualError(t, err, expected)
+	err = HandleBackupFetch(t.Context(), folder, backup.BackupName, exec.Command("true"), true)
+	require.EqualError(t, err, expected)
+	_, err = aof.SentinelWithExistenceCheck(t.Context(), folder, backup.BackupName)
+	require.EqualError(t, err, expected)
+}
@@ -0,0 +1,191 @@
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/wal-g/tracelog"
+	conf "github.com/wal-g/wal-g/internal/config"
+)
+
+// rdbEOFMarkLength is the length of the mark ending the RDB sent by the node with the diskless replication
+const rdbEOFMarkLength = 40
+
+// OpenRDBStream requests the RDB snapshot from the node the way the replica does: the node forks,
+// saves the snapshot like BGSAVE and sends it over the connection, the node keeps serving the clients meanwhile.
+// The stream fails with io.ErrUnexpectedEOF if the connection drops before the end of the snapshot.
+func OpenRDBStream(ctx context.Context, host, port string) (io.ReadCloser, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", net.JoinHostPort(host, port), err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	username := GetSettingWithLocalDefault(conf.RedisUsername, "default")
+	password := GetSettingWithLocalDefault(conf.RedisPassword, "")
+	stream, err := requestRDB(conn, username, password)
+	if err != nil {
+		stop()
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to request the RDB from %s: %w", net.JoinHostPort(host, port), err)
+	}
+	return &rdbStream{Reader: stream, conn: conn, stop: stop}, nil
+}
+
+type rdbStream struct {
+	io.Reader
+	conn net.Conn
+	stop func() bool
+}
+
+func (s *rdbStream) Close() error {
+	s.stop()
+	return s.conn.Close()
+}
+
+// requestRDB authenticates, sends SYNC and returns the reader of the RDB payload
+func requestRDB(conn io.ReadWriter, username, password string) (io.Reader, error) {
+	reader := bufio.NewReader(conn)
+	if password != "" {
+		args := []string{"AUTH", password}
+		if username != "default" {
+			args = []string{"AUTH", username, password}
+		}
+		if err := callSimple(conn, reader, args...); err != nil {
+			return nil, fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+	// since Redis 7.0 the node closes the connection after the RDB instead of streaming the commands after it
+	if err := callSimple(conn, reader, "REPLCONF", "rdb-only", "1"); err != nil {
+		tracelog.DebugLogger.Printf("REPLCONF rdb-only is not supported: %v", err)
+	}
+	if _, err := conn.Write(respCommand("SYNC")); err != nil {
+		return nil, err
+	}
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case line == "":
+			// the node sends the newlines while it saves the snapshot
+			continue
+		case strings.HasPrefix(line, "-"):
+			return nil, errors.New(line[1:])
+		case strings.HasPrefix(line, "$EOF:"):
+			mark := strings.TrimPrefix(line, "$EOF:")
+			if len(mark) != rdbEOFMarkLength {
+				return nil, fmt.Errorf("unexpected RDB EOF mark %q", mark)
+			}
+			return &eofMarkReader{reader: reader, mark: []byte(mark), buffer: make([]byte, 32*1024)}, nil
+		case strings.HasPrefix(line, "$"):
+			size, err := strconv.ParseInt(line[1:], 10, 64)
+			if err != nil || size < 0 {
+				return nil, fmt.Errorf("unexpected RDB size %q", line[1:])
+			}
+			return &sizedReader{reader: reader, left: size}, nil
+		default:
+			return nil, fmt.Errorf("unexpected reply to SYNC: %q", line)
+		}
+	}
+}
+
+func callSimple(writer io.Writer, reader *bufio.Reader, args ...string) error {
+	if _, err := writer.Write(respCommand(args...)); err != nil {
+		return err
+	}
+	line, err := readLine(reader)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(line, "-") {
+		return errors.New(line[1:])
+	}
+	return nil
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func respCommand(args ...string) []byte {
+	var command bytes.Buffer
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return command.Bytes()
+}
+
+// sizedReader reads the RDB of the known size
+type sizedReader struct {
+	reader io.Reader
+	left   int64
+}
+
+func (r *sizedReader) Read(p []byte) (int, error) {
+	if r.left <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.left {
+		p = p[:r.left]
+	}
+	n, err := r.reader.Read(p)
+	r.left -= int64(n)
+	if err == io.EOF && r.left > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// eofMarkReader reads the RDB sent by the diskless replication until the EOF mark,
+// the last bytes are held back until it is clear they are not the mark
+type eofMarkReader struct {
+	reader  io.Reader
+	mark    []byte
+	buffer  []byte
+	tail    []byte
+	pending []byte
+	ended   bool
+}
+
+func (r *eofMarkReader) Read(p []byte) (int, error) {
+	for {
+		if len(r.pending) > 0 {
+			n := copy(p, r.pending)
+			r.pending = r.pending[n:]
+			return n, nil
+		}
+		if r.ended {
+			return 0, io.EOF
+		}
+		n, err := r.reader.Read(r.buffer)
+		r.tail = append(r.tail, r.buffer[:n]...)
+		if bytes.HasSuffix(r.tail, r.mark) {
+			r.pending, r.tail, r.ended = r.tail[:len(r.tail)-len(r.mark)], nil, true
+			continue
+		}
+		if err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, err
+		}
+		if len(r.tail) > len(r.mark) {
+			split := len(r.tail) - len(r.mark)
+			r.pending, r.tail = r.tail[:split], append([]byte(nil), r.tail[split:]...)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEOFMark = "0123456789abcdef0123456789abcdef01234567"
+
+// fakeMaster answers the commands of the RDB request, the SYNC reply is sent as is
+func fakeMaster(conn net.Conn, syncReply string) <-chan []string {
+	commands := make(chan []string, 4)
+	go func() {
+		defer close(commands)
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			command, err := readTestCommand(reader)
+			if err != nil {
+				return
+			}
+			commands <- command
+			var reply string
+			switch command[0] {
+			case "AUTH":
+				reply = "+OK\r\n"
+			case "REPLCONF":
+				reply = "-ERR Unrecognized REPLCONF option: rdb-only\r\n"
+			case "SYNC":
+				reply = syncReply
+			}
+			if _, err := io.WriteString(conn, reply); err != nil || command[0] == "SYNC" {
+				return
+			}
+		}
+	}()
+	return commands
+}
+
+func readTestCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimPrefix(line, "*"))
+	if err != nil {
+		return nil, err
+	}
+	command := make([]string, count)
+	for i := range command {
+		if _, err := readLine(reader); err != nil {
+			return nil, err
+		}
+		if command[i], err = readLine(reader); err != nil {
+			return nil, err
+		}
+	}
+	return command, nil
+}
+
+func TestRequestRDB(t *testing.T) {
+	rdb := "REDIS0011" + strings.Repeat("x", 100000) + "\xff"
+	tests := []struct {
+		name      string
+		syncReply string
+		expected  string
+		err       error
+	}{
+		{name: "sized", syncReply: "\n\n$" + strconv.Itoa(len(rdb)) + "\r\n" + rdb, expected: rdb},
+		{name: "diskless", syncReply: "\n$EOF:" + testEOFMark + "\r\n" + rdb + testEOFMark, expected: rdb},
+		{name: "sized truncated", syncReply: "$" + strconv.Itoa(len(rdb)) + "\r\n" + rdb[:100], err: io.ErrUnexpectedEOF},
+		{name: "diskless truncated", syncReply: "$EOF:" + testEOFMark + "\r\n" + rdb[:100], err: io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			commands := fakeMaster(server, tt.syncReply)
+
+			stream, err := requestRDB(client, "backup", "secret")
+			require.NoError(t, err)
+			content, err := io.ReadAll(stream)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(content))
+			assert.Equal(t, []string{"AUTH", "backup", "secret"}, <-commands)
+			assert.Equal(t, []string{"REPLCONF", "rdb-only", "1"}, <-commands)
+			assert.Equal(t, []string{"SYNC"}, <-commands)
+		})
+	}
+}
+
+func TestRequestRDB_Error(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	fakeMaster(server, "-NOPERM this user has no permissions to run the 'sync' command\r\n")
+
+	_, err := requestRDB(client, "default", "")
+	require.EqualError(t, err, "NOPERM this user has no permissions to run the 'sync' command")
+}
@@ -47,6 +47,60 @@ func getRedisConnection(strict bool) *redis.Client {
 	})
 }
 
+// GetClusterNodes returns the CLUSTER NODES output of the node set with WALG_REDIS_HOST and WALG_REDIS_PORT
+// and the host of the node
+func GetClusterNodes(ctx context.Context) (nodes, host string, err error) {
+	conn := getRedisConnection(dontPanic)
+	defer conn.Close()
+	nodes, err = conn.ClusterNodes(ctx).Result()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+	return nodes, GetSettingWithLocalDefault("WALG_REDIS_HOST", "localhost"), nil
+}
+
+// GetConfig returns the value of the config parameter of the node set with WALG_REDIS_HOST and WALG_REDIS_PORT
+func GetConfig(ctx context.Context, parameter string) (string, error) {
+	conn := getRedisConnection(dontPanic)
+	defer conn.Close()
+	values, err := conn.ConfigGet(ctx, parameter).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config %s: %w", parameter, err)
+	}
+	value, ok := values[parameter]
+	if !ok {
+		return "", fmt.Errorf("config %s is not supported by the server", parameter)
+	}
+	return value, nil
+}
+
+// SetConfig sets the config parameter of the node set with WALG_REDIS_HOST and WALG_REDIS_PORT
+func SetConfig(ctx context.Context, parameter, value string) error {
+	conn := getRedisConnection(dontPanic)
+	defer conn.Close()
+	if err := conn.ConfigSet(ctx, parameter, value).Err(); err != nil {
+		return fmt.Errorf("failed to set config %s to %s: %w", parameter, value, err)
+	}
+	return nil
+}
+
+// IsAOFRewriteInProgress tells whether the AOF rewrite runs or is scheduled
+func IsAOFRewriteInProgress(ctx context.Context) (bool, error) {
+	conn := getRedisConnection(dontPanic)
+	defer conn.Close()
+	data, err := conn.Info(ctx, "persistence").Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to get persistence info: %w", err)
+	}
+	data = strings.ReplaceAll(data, "\r", "")
+	for _, line := range strings.Split(data, "\n") {
+		if parseInfoLine(line, "aof_rewrite_in_progress") != 0 || parseInfoLine(line, "aof_rewrite_scheduled") != 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 type ServerData struct {
 	UsedMemory    int64 `json:"used_memory"`
 	UsedMemoryRss int64 `json:"used_memory_rss"`
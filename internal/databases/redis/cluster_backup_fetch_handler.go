@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/databases/redis/archive"
+	client "github.com/wal-g/wal-g/internal/databases/redis/client"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"golang.org/x/sync/errgroup"
+)
+
+// HandleClusterBackupFetch restores the shard backups of the cluster backup to the masters of the Redis Cluster
+// found through the node set with WALG_REDIS_HOST. Every shard is restored to the master serving its slots
+// with WALG_STREAM_RESTORE_COMMAND, the command gets the master address with WALG_REDIS_HOST and WALG_REDIS_PORT.
+func HandleClusterBackupFetch(ctx context.Context, folder storage.Folder, backupName string) error {
+	backup, err := archive.SentinelWithExistenceCheck(ctx, folder, backupName)
+	if err != nil {
+		return err
+	}
+	if !backup.IsCluster() {
+		return fmt.Errorf("backup %s is not a cluster backup, its type is %s", backup.BackupName, backup.BackupType)
+	}
+
+	nodes, connectedHost, err := client.GetClusterNodes(ctx)
+	if err != nil {
+		return err
+	}
+	masters, err := archive.ParseClusterMasters(nodes, connectedHost)
+	if err != nil {
+		return err
+	}
+	targets, err := archive.MatchShardsToMasters(backup.Shards, masters)
+	if err != nil {
+		return fmt.Errorf("can not restore backup %s to the cluster: %w", backup.BackupName, err)
+	}
+	if len(masters) > len(backup.Shards) {
+		tracelog.WarningLogger.Printf("The cluster has %d masters and the backup has %d shards, "+
+			"the masters without a shard are left as is", len(masters), len(backup.Shards))
+	}
+
+	internalFolder := backup.ToInternal(folder).Folder
+	errGroup, groupCtx := errgroup.WithContext(ctx)
+	for _, shard := range backup.Shards {
+		master := targets[shard.Name]
+		errGroup.Go(func() error {
+			tracelog.InfoLogger.Printf("Restoring %s backed up from %s to master %s",
+				shard.Name, shard.Address, master.Address())
+			restoreCmd, err := clusterNodeCommand(groupCtx, conf.NameStreamRestoreCmd, master)
+			if err != nil {
+				return err
+			}
+			restoreCmd.Stdout = os.Stdout
+			shardBackup := internal.Backup{
+				Folder: internalFolder,
+				Name:   archive.ShardStreamName(backup.BackupName, shard.Name),
+			}
+			if err := internal.StreamBackupToCommandStdin(groupCtx, restoreCmd, shardBackup); err != nil {
+				return fmt.Errorf("failed to restore %s to master %s: %w", shard.Name, master.Address(), err)
+			}
+			return nil
+		})
+	}
+	return errGroup.Wait()
+}
@@ -0,0 +1,154 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/databases/redis/archive"
+	client "github.com/wal-g/wal-g/internal/databases/redis/client"
+	"github.com/wal-g/wal-g/utility"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	redisHostEnv = "WALG_REDIS_HOST"
+	redisPortEnv = "WALG_REDIS_PORT"
+)
+
+type ClusterBackupPushArgs struct {
+	Permanent bool
+}
+
+// HandleClusterBackupPush finds the masters of the Redis Cluster through the node set with WALG_REDIS_HOST,
+// requests the RDB snapshot from every master in parallel and uploads the RDB streams
+// into the cluster backup folder. The cluster sentinel references the shard backups with their slots.
+func HandleClusterBackupPush(ctx context.Context, args ClusterBackupPushArgs) error {
+	nodes, connectedHost, err := client.GetClusterNodes(ctx)
+	if err != nil {
+		return err
+	}
+	masters, err := archive.ParseClusterMasters(nodes, connectedHost)
+	if err != nil {
+		return err
+	}
+
+	userData, err := internal.GetSentinelUserData()
+	if err != nil {
+		return err
+	}
+	sentinel := archive.Backup{
+		BackupName:     archive.GenerateNewClusterBackupName(),
+		BackupType:     archive.ClusterBackupType,
+		StartLocalTime: utility.TimeNowCrossPlatformLocal(),
+		Permanent:      args.Permanent,
+		UserData:       userData,
+		Shards:         make([]archive.ShardBackup, len(masters)),
+	}
+
+	errGroup, groupCtx := errgroup.WithContext(ctx)
+	for i, master := range masters {
+		sentinel.Shards[i] = archive.ShardBackup{
+			Name:    fmt.Sprintf("shard_%d", i),
+			NodeID:  master.ID,
+			Address: master.Address(),
+			Slots:   master.Slots,
+		}
+		shard := &sentinel.Shards[i]
+		errGroup.Go(func() error {
+			if err := pushShardBackup(groupCtx, sentinel.BackupName, shard, master); err != nil {
+				return fmt.Errorf("failed to backup master %s: %w", master.Address(), err)
+			}
+			return nil
+		})
+	}
+	if err := errGroup.Wait(); err != nil {
+		return err
+	}
+
+	for _, shard := range sentinel.Shards {
+		sentinel.DataSize += shard.DataSize
+		sentinel.BackupSize += shard.BackupSize
+	}
+	sentinel.FinishLocalTime = utility.TimeNowCrossPlatformLocal()
+
+	uploader, err := internal.ConfigureUploader(ctx)
+	if err != nil {
+		return err
+	}
+	uploader.ChangeDirectory(utility.BaseBackupPath)
+	tracelog.InfoLogger.Printf("Uploading the cluster backup sentinel %s", sentinel.BackupName)
+	return internal.UploadSentinel(ctx, uploader, &sentinel, sentinel.BackupName)
+}
+
+func pushShardBackup(ctx context.Context, clusterBackupName string, shard *archive.ShardBackup,
+	master archive.ClusterMaster) error {
+	uploader, err := internal.ConfigureUploader(ctx)
+	if err != nil {
+		return err
+	}
+	uploader.ChangeDirectory(utility.BaseBackupPath)
+
+	stream, wait, err := openShardStream(ctx, master)
+	if err != nil {
+		return err
+	}
+	defer utility.LoggedClose(stream, "")
+
+	tracelog.InfoLogger.Printf("Uploading backup of master %s as %s", master.Address(), shard.Name)
+	streamName := archive.ShardStreamName(clusterBackupName, shard.Name)
+	dstPath := internal.GetStreamName(streamName, uploader.Compression().FileExtension())
+	if err := uploader.PushStreamToDestination(ctx, stream, dstPath); err != nil {
+		return fmt.Errorf("can not upload backup: %w", err)
+	}
+	if err := wait(); err != nil {
+		return fmt.Errorf("backup command failed: %w", err)
+	}
+
+	shard.BackupSize, err = uploader.UploadedDataSize()
+	if err != nil {
+		return err
+	}
+	shard.DataSize, err = uploader.RawDataSize()
+	return err
+}
+
+// openShardStream requests the RDB snapshot from the master like a replica does,
+// or runs WALG_STREAM_CREATE_COMMAND for the master if it is set
+func openShardStream(ctx context.Context, master archive.ClusterMaster) (io.ReadCloser, func() error, error) {
+	if _, ok := conf.GetSetting(conf.NameStreamCreateCmd); !ok {
+		tracelog.InfoLogger.Printf("Requesting the RDB snapshot from master %s", master.Address())
+		stream, err := client.OpenRDBStream(ctx, master.Host, master.Port)
+		return stream, func() error { return nil }, err
+	}
+	backupCmd, err := clusterNodeCommand(ctx, conf.NameStreamCreateCmd, master)
+	if err != nil {
+		return nil, nil, err
+	}
+	stdout, err := utility.StartCommandWithStdoutPipe(backupCmd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start backup create command: %w", err)
+	}
+	// the pipe is closed by Wait
+	return io.NopCloser(stdout), backupCmd.Wait, nil
+}
+
+// clusterNodeCommand creates the command from the setting, the command gets the node address
+// with WALG_REDIS_HOST and WALG_REDIS_PORT
+func clusterNodeCommand(ctx context.Context, settingName string, master archive.ClusterMaster) (*exec.Cmd, error) {
+	cmd, err := internal.GetCommandSettingContext(ctx, settingName)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = append(os.Environ(), redisHostEnv+"="+master.Host, redisPortEnv+"="+master.Port)
+	redisPassword, ok := conf.GetSetting(conf.RedisPassword)
+	if ok && redisPassword != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("REDISCLI_AUTH=%s", redisPassword))
+	}
+	return cmd, nil
+}
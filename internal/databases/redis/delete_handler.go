@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"os"
 	"slices"
 	"time"
 
@@ -14,6 +15,9 @@ import (
 type PurgeSettings struct {
 	retainCount  *int
 	retainAfter  *time.Time
+	retainPolicy *internal.GFSRetentionPolicy
+	// retention replaces the other retain settings
+	retention    *internal.RetentionConfig
 	purgeGarbage bool
 	dryRun       bool
 }
@@ -34,6 +38,20 @@ func PurgeRetainCount(retainCount int) PurgeOption {
 	}
 }
 
+// PurgeRetainPolicy ...
+func PurgeRetainPolicy(retainPolicy internal.GFSRetentionPolicy) PurgeOption {
+	return func(args *PurgeSettings) {
+		args.retainPolicy = &retainPolicy
+	}
+}
+
+// PurgeRetention ...
+func PurgeRetention(retention internal.RetentionConfig) PurgeOption {
+	return func(args *PurgeSettings) {
+		args.retention = &retention
+	}
+}
+
 // PurgeGarbage ...
 func PurgeGarbage(purgeGarbage bool) PurgeOption {
 	return func(args *PurgeSettings) {
@@ -94,7 +112,13 @@ func HandleBackupsDelete(ctx context.Context, backupTimes []internal.BackupTime,
 	timedBackup := archive.RedisModelToTimedBackup(backups)
 
 	internal.SortTimedBackup(timedBackup)
-	purgeBackups, retainBackups, err := internal.SplitPurgingBackups(timedBackup, opts.retainCount, opts.retainAfter)
+	var purgeBackups, retainBackups map[string]bool
+	if opts.retention != nil {
+		purgeBackups, retainBackups, err = internal.SplitAutoRetainedBackups(os.Stdout, timedBackup, *opts.retention, time.Now())
+	} else {
+		purgeBackups, retainBackups, err = internal.SplitPurgingBackupsWithPolicy(timedBackup, opts.retainCount, opts.retainAfter,
+			opts.retainPolicy)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
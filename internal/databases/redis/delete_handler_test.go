@@ -86,3 +86,30 @@ func TestPurgeRetainCountsAttachedTSWithMainBackup(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, standaloneExists)
 }
+
+func TestPurgeRetention(t *testing.T) {
+	folder := testtools.MakeDefaultInMemoryStorageFolder()
+	now := time.Now()
+	backups := []archive.Backup{
+		{BackupName: "stream_permanent", StartLocalTime: now.Add(-72 * time.Hour), Permanent: true},
+		{BackupName: "stream_old", StartLocalTime: now.Add(-48 * time.Hour)},
+		{BackupName: "stream_new", StartLocalTime: now.Add(-time.Hour)},
+	}
+	for _, backup := range backups {
+		backup.BackupType = archive.RDBBackupType
+		backup.FinishLocalTime = backup.StartLocalTime
+		serialized, err := json.Marshal(backup)
+		require.NoError(t, err)
+		require.NoError(t, folder.PutObject(t.Context(), backup.BackupName+utility.SentinelSuffix, bytes.NewReader(serialized)))
+	}
+
+	require.NoError(t, redisdb.HandlePurge(t.Context(), folder,
+		redisdb.PurgeRetention(internal.RetentionConfig{BackupAge: 24 * time.Hour}), redisdb.PurgeDryRun(false)))
+
+	backupTimes, err := internal.GetBackups(t.Context(), folder)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"stream_permanent", "stream_new"}, redisdb.BackupNamesFromBackupTimes(backupTimes))
+
+	err = redisdb.HandlePurge(t.Context(), folder, redisdb.PurgeRetention(internal.RetentionConfig{}))
+	assert.ErrorContains(t, err, "retention is not configured")
+}
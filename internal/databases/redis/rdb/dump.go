@@ -0,0 +1,49 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"hash/crc64"
+	"strconv"
+)
+
+// crc64Jones is the reflected Jones polynomial Redis checksums the DUMP payloads with
+var crc64Jones = crc64.MakeTable(0x95AC9329AC4BC9B5)
+
+// DumpPayload returns the payload of the RESTORE command: the serialized value,
+// the RDB version and the CRC64 checksum
+func DumpPayload(value []byte, rdbVersion int) []byte {
+	payload := make([]byte, 0, len(value)+10)
+	payload = append(payload, value...)
+	payload = binary.LittleEndian.AppendUint16(payload, uint16(rdbVersion))
+	return binary.LittleEndian.AppendUint64(payload, crc64Checksum(payload))
+}
+
+func crc64Checksum(data []byte) uint64 {
+	// Redis checksum has no initial and final inversion which hash/crc64 does
+	return ^crc64.Update(^uint64(0), crc64Jones, data)
+}
+
+// RestoreCommand returns the RESP encoded RESTORE command creating the key,
+// the expire time is absolute so that the key expires at the same moment as in the backup
+func RestoreCommand(entry *Entry, rdbVersion int, replace bool) []byte {
+	args := [][]byte{[]byte("RESTORE"), entry.Key, []byte(strconv.FormatInt(entry.ExpireAt, 10)),
+		DumpPayload(entry.Value, rdbVersion)}
+	if entry.ExpireAt != 0 {
+		args = append(args, []byte("ABSTTL"))
+	}
+	if replace {
+		args = append(args, []byte("REPLACE"))
+	}
+	return RESPCommand(args...)
+}
+
+// RESPCommand encodes the command as a RESP array of bulk strings
+func RESPCommand(args ...[]byte) []byte {
+	command := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		command = append(command, "$"+strconv.Itoa(len(arg))+"\r\n"...)
+		command = append(command, arg...)
+		command = append(command, "\r\n"...)
+	}
+	return command
+}
@@ -0,0 +1,46 @@
+package rdb
+
+import "fmt"
+
+// lzfDecompress decompresses the LZF compressed strings of the RDB file
+func lzfDecompress(compressed []byte, size int) ([]byte, error) {
+	out := make([]byte, 0, size)
+	for in := 0; in < len(compressed); {
+		ctrl := int(compressed[in])
+		in++
+		if ctrl < 1<<5 {
+			// the literal run
+			length := ctrl + 1
+			if in+length > len(compressed) {
+				return nil, fmt.Errorf("invalid LZF data: literal run is out of input")
+			}
+			out = append(out, compressed[in:in+length]...)
+			in += length
+			continue
+		}
+		// the back reference
+		length := ctrl >> 5
+		if length == 7 {
+			if in >= len(compressed) {
+				return nil, fmt.Errorf("invalid LZF data: back reference is out of input")
+			}
+			length += int(compressed[in])
+			in++
+		}
+		if in >= len(compressed) {
+			return nil, fmt.Errorf("invalid LZF data: back reference is out of input")
+		}
+		ref := len(out) - (ctrl&0x1f)<<8 - int(compressed[in]) - 1
+		in++
+		if ref < 0 {
+			return nil, fmt.Errorf("invalid LZF data: back reference is out of output")
+		}
+		for i := 0; i < length+2; i++ {
+			out = append(out, out[ref+i])
+		}
+	}
+	if len(out) != size {
+		return nil, fmt.Errorf("invalid LZF data: decompressed %d bytes instead of %d", len(out), size)
+	}
+	return out, nil
+}
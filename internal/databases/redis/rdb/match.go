@@ -0,0 +1,83 @@
+package rdb
+
+// MatchPattern checks if the key matches the glob style pattern as KEYS and SCAN MATCH do:
+// * matches any sequence, ? matches any byte, [abc], [^abc] and [a-z] match the byte classes
+// and \ escapes the special characters
+func MatchPattern(pattern, key []byte) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(key); i++ {
+				if MatchPattern(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(key) == 0 {
+				return false
+			}
+			key = key[1:]
+		case '[':
+			if len(key) == 0 {
+				return false
+			}
+			var matched bool
+			matched, pattern = matchClass(pattern[1:], key[0])
+			if !matched {
+				return false
+			}
+			key = key[1:]
+			continue
+		case '\\':
+			if len(pattern) >= 2 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(key) == 0 || pattern[0] != key[0] {
+				return false
+			}
+			key = key[1:]
+		}
+		pattern = pattern[1:]
+	}
+	return len(key) == 0
+}
+
+// matchClass matches the byte against the class after '[' and returns the pattern after the class
+func matchClass(pattern []byte, b byte) (bool, []byte) {
+	negate := len(pattern) > 0 && pattern[0] == '^'
+	if negate {
+		pattern = pattern[1:]
+	}
+	matched := false
+	for len(pattern) > 0 && pattern[0] != ']' {
+		switch {
+		case pattern[0] == '\\' && len(pattern) >= 2:
+			matched = matched || pattern[1] == b
+			pattern = pattern[2:]
+		case len(pattern) >= 3 && pattern[1] == '-':
+			start, end := pattern[0], pattern[2]
+			if start > end {
+				start, end = end, start
+			}
+			matched = matched || b >= start && b <= end
+			pattern = pattern[3:]
+		default:
+			matched = matched || pattern[0] == b
+			pattern = pattern[1:]
+		}
+	}
+	if len(pattern) > 0 {
+		// skip ']'
+		pattern = pattern[1:]
+	}
+	return matched != negate, pattern
+}
@@ -0,0 +1,642 @@
+package rdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	opcodeSlotInfo        = 0xF4
+	opcodeFunction2       = 0xF5
+	opcodeFunctionPreGA   = 0xF6
+	opcodeModuleAux       = 0xF7
+	opcodeIdle            = 0xF8
+	opcodeFreq            = 0xF9
+	opcodeAux             = 0xFA
+	opcodeResizeDB        = 0xFB
+	opcodeExpireTimeMs    = 0xFC
+	opcodeExpireTime      = 0xFD
+	opcodeSelectDB        = 0xFE
+	opcodeEOF             = 0xFF
+	moduleOpcodeEOF       = 0
+	moduleOpcodeSInt      = 1
+	moduleOpcodeUInt      = 2
+	moduleOpcodeFloat     = 3
+	moduleOpcodeDouble    = 4
+	moduleOpcodeString    = 5
+	lengthEncoded         = 3
+	encodingInt8          = 0
+	encodingInt16         = 1
+	encodingInt32         = 2
+	encodingLZF           = 3
+	length32Bit           = 0x80
+	length64Bit           = 0x81
+	keyOverheadEstimation = 56
+)
+
+const (
+	typeString           = 0
+	typeList             = 1
+	typeSet              = 2
+	typeZSet             = 3
+	typeHash             = 4
+	typeZSet2            = 5
+	typeModule2          = 7
+	typeHashZipmap       = 9
+	typeListZiplist      = 10
+	typeSetIntset        = 11
+	typeZSetZiplist      = 12
+	typeHashZiplist      = 13
+	typeListQuicklist    = 14
+	typeStreamListpacks  = 15
+	typeHashListpack     = 16
+	typeZSetListpack     = 17
+	typeListQuicklist2   = 18
+	typeStreamListpacks2 = 19
+	typeSetListpack      = 20
+	typeStreamListpacks3 = 21
+	typeHashMetadata     = 24
+	typeHashListpackEx   = 25
+)
+
+// Entry is the key read from the RDB file
+type Entry struct {
+	DB  int
+	Key []byte
+	// Type is the Redis type of the key as TYPE command reports it
+	Type string
+	// ExpireAt is the expire time of the key in unix milliseconds, zero if the key doesn't expire
+	ExpireAt int64
+	// Size is the memory usage estimation: the size of the key and the value decoded from the RDB
+	Size int64
+	// Value is the serialized value as DUMP returns it without the RDB version and checksum,
+	// it is set only for the keys selected by Parser.Capture
+	Value []byte
+}
+
+// Parser reads the keys of the RDB file one by one
+type Parser struct {
+	reader  *bufio.Reader
+	version int
+	db      int
+	aux     map[string]string
+	// record collects the bytes of the value being captured
+	record *bytes.Buffer
+	// decodedSize is the decoded size of the value being read
+	decodedSize int64
+	// Capture selects the keys to keep the serialized values of
+	Capture func(key []byte) bool
+}
+
+func NewParser(reader io.Reader) *Parser {
+	return &Parser{
+		reader: bufio.NewReaderSize(reader, 1<<20),
+		aux:    make(map[string]string),
+	}
+}
+
+// Version returns the RDB version of the file, it is known after the first Next call
+func (p *Parser) Version() int {
+	return p.version
+}
+
+// Aux returns the auxiliary field of the RDB file like redis-ver or ctime, read before the current key
+func (p *Parser) Aux(name string) string {
+	return p.aux[name]
+}
+
+// Next reads the next key, it returns io.EOF after the last one
+func (p *Parser) Next() (*Entry, error) {
+	if p.version == 0 {
+		if err := p.readHeader(); err != nil {
+			return nil, err
+		}
+	}
+	var expireAt int64
+	for {
+		opcode, err := p.readByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		switch opcode {
+		case opcodeEOF:
+			return nil, io.EOF
+		case opcodeSelectDB:
+			db, err := p.readLength()
+			if err != nil {
+				return nil, err
+			}
+			p.db = int(db)
+		case opcodeResizeDB:
+			if err := p.skipLengths(2); err != nil {
+				return nil, err
+			}
+		case opcodeSlotInfo:
+			if err := p.skipLengths(3); err != nil {
+				return nil, err
+			}
+		case opcodeExpireTime:
+			seconds, err := p.read(4)
+			if err != nil {
+				return nil, err
+			}
+			expireAt = int64(binary.LittleEndian.Uint32(seconds)) * 1000
+		case opcodeExpireTimeMs:
+			millis, err := p.read(8)
+			if err != nil {
+				return nil, err
+			}
+			expireAt = int64(binary.LittleEndian.Uint64(millis))
+		case opcodeIdle:
+			if _, err := p.readLength(); err != nil {
+				return nil, err
+			}
+		case opcodeFreq:
+			if err := p.skip(1); err != nil {
+				return nil, err
+			}
+		case opcodeAux:
+			name, err := p.readString()
+			if err != nil {
+				return nil, err
+			}
+			value, err := p.readString()
+			if err != nil {
+				return nil, err
+			}
+			p.aux[string(name)] = string(value)
+		case opcodeModuleAux:
+			if err := p.skipModuleAux(); err != nil {
+				return nil, err
+			}
+		case opcodeFunction2:
+			if _, err := p.readString(); err != nil {
+				return nil, err
+			}
+		case opcodeFunctionPreGA:
+			return nil, fmt.Errorf("pre-GA function opcode is not supported")
+		default:
+			return p.readEntry(opcode, expireAt)
+		}
+	}
+}
+
+func (p *Parser) readHeader() error {
+	magic, err := p.read(5)
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	var versionDigits []byte
+	switch string(magic) {
+	case "REDIS":
+		versionDigits, err = p.read(4)
+	case "VALKE":
+		versionDigits, err = p.read(4)
+		if err == nil && versionDigits[0] != 'Y' {
+			return fmt.Errorf("invalid RDB file signature")
+		}
+		versionDigits = versionDigits[1:]
+	default:
+		return fmt.Errorf("invalid RDB file signature")
+	}
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	p.version, err = strconv.Atoi(string(versionDigits))
+	if err != nil || p.version <= 0 {
+		return fmt.Errorf("invalid RDB version %q", versionDigits)
+	}
+	return nil
+}
+
+func (p *Parser) readEntry(valueType byte, expireAt int64) (*Entry, error) {
+	key, err := p.readString()
+	if err != nil {
+		return nil, err
+	}
+	entry := &Entry{DB: p.db, Key: key, ExpireAt: expireAt}
+	if p.Capture != nil && p.Capture(key) {
+		p.record = bytes.NewBuffer([]byte{valueType})
+		defer func() { p.record = nil }()
+	}
+	p.decodedSize = 0
+	entry.Type, err = p.skipValue(valueType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the value of key %q: %w", key, err)
+	}
+	entry.Size = int64(len(key)) + p.decodedSize + keyOverheadEstimation
+	if p.record != nil {
+		entry.Value = p.record.Bytes()
+	}
+	return entry, nil
+}
+
+// skipValue reads the value and returns its Redis type
+func (p *Parser) skipValue(valueType byte) (string, error) {
+	switch valueType {
+	case typeString:
+		return "string", p.skipStrings(1)
+	case typeList, typeSet, typeHash:
+		count, err := p.readLength()
+		if err != nil {
+			return "", err
+		}
+		if valueType == typeHash {
+			count *= 2
+		}
+		return typeName(valueType), p.skipStrings(count)
+	case typeZSet, typeZSet2:
+		count, err := p.readLength()
+		if err != nil {
+			return "", err
+		}
+		for i := uint64(0); i < count; i++ {
+			if err := p.skipStrings(1); err != nil {
+				return "", err
+			}
+			if err := p.skipScore(valueType); err != nil {
+				return "", err
+			}
+		}
+		return "zset", nil
+	case typeHashZipmap, typeListZiplist, typeSetIntset, typeZSetZiplist, typeHashZiplist, typeHashListpack,
+		typeZSetListpack, typeSetListpack:
+		return typeName(valueType), p.skipStrings(1)
+	case typeListQuicklist:
+		count, err := p.readLength()
+		if err != nil {
+			return "", err
+		}
+		return "list", p.skipStrings(count)
+	case typeListQuicklist2:
+		count, err := p.readLength()
+		if err != nil {
+			return "", err
+		}
+		for i := uint64(0); i < count; i++ {
+			// the node container is plain or packed
+			if _, err := p.readLength(); err != nil {
+				return "", err
+			}
+			if err := p.skipStrings(1); err != nil {
+				return "", err
+			}
+		}
+		return "list", nil
+	case typeHashMetadata:
+		if err := p.skip(8); err != nil {
+			return "", err
+		}
+		count, err := p.readLength()
+		if err != nil {
+			return "", err
+		}
+		for i := uint64(0); i < count; i++ {
+			if _, err := p.readLength(); err != nil {
+				return "", err
+			}
+			if err := p.skipStrings(2); err != nil {
+				return "", err
+			}
+		}
+		return "hash", nil
+	case typeHashListpackEx:
+		if err := p.skip(8); err != nil {
+			return "", err
+		}
+		return "hash", p.skipStrings(1)
+	case typeStreamListpacks, typeStreamListpacks2, typeStreamListpacks3:
+		return "stream", p.skipStream(valueType)
+	case typeModule2:
+		if _, err := p.readLength(); err != nil {
+			return "", err
+		}
+		return "module", p.skipModuleValue()
+	default:
+		return "", fmt.Errorf("unsupported value type %d", valueType)
+	}
+}
+
+func typeName(valueType byte) string {
+	switch valueType {
+	case typeList, typeListZiplist:
+		return "list"
+	case typeSet, typeSetIntset, typeSetListpack:
+		return "set"
+	case typeZSetZiplist, typeZSetListpack:
+		return "zset"
+	default:
+		return "hash"
+	}
+}
+
+func (p *Parser) skipScore(valueType byte) error {
+	if valueType == typeZSet2 {
+		return p.skip(8)
+	}
+	size, err := p.readByte()
+	if err != nil {
+		return err
+	}
+	// 253, 254 and 255 are NaN, +inf and -inf
+	if size < 253 {
+		err = p.skip(uint64(size))
+	}
+	return err
+}
+
+func (p *Parser) skipStream(valueType byte) error {
+	listpacks, err := p.readLength()
+	if err != nil {
+		return err
+	}
+	// the master ID and the listpack of every node
+	if err := p.skipStrings(listpacks * 2); err != nil {
+		return err
+	}
+	// the length and the last ID
+	if err := p.skipLengths(3); err != nil {
+		return err
+	}
+	if valueType >= typeStreamListpacks2 {
+		// the first ID, the max deleted ID and the number of added entries
+		if err := p.skipLengths(5); err != nil {
+			return err
+		}
+	}
+	groups, err := p.readLength()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < groups; i++ {
+		if err := p.skipStrings(1); err != nil {
+			return err
+		}
+		if err := p.skipLengths(2); err != nil {
+			return err
+		}
+		if valueType >= typeStreamListpacks2 {
+			// the entries read
+			if err := p.skipLengths(1); err != nil {
+				return err
+			}
+		}
+		pending, err := p.readLength()
+		if err != nil {
+			return err
+		}
+		for j := uint64(0); j < pending; j++ {
+			// the raw ID and the delivery time
+			if err := p.skip(16 + 8); err != nil {
+				return err
+			}
+			if err := p.skipLengths(1); err != nil {
+				return err
+			}
+		}
+		consumers, err := p.readLength()
+		if err != nil {
+			return err
+		}
+		for j := uint64(0); j < consumers; j++ {
+			if err := p.skipStrings(1); err != nil {
+				return err
+			}
+			timesSize := uint64(8)
+			if valueType >= typeStreamListpacks3 {
+				// the active time
+				timesSize += 8
+			}
+			if err := p.skip(timesSize); err != nil {
+				return err
+			}
+			consumerPending, err := p.readLength()
+			if err != nil {
+				return err
+			}
+			if err := p.skip(consumerPending * 16); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Parser) skipModuleAux() error {
+	// the module ID, the when opcode and the when value
+	if err := p.skipLengths(3); err != nil {
+		return err
+	}
+	return p.skipModuleValue()
+}
+
+func (p *Parser) skipModuleValue() error {
+	for {
+		opcode, err := p.readLength()
+		if err != nil {
+			return err
+		}
+		switch opcode {
+		case moduleOpcodeEOF:
+			return nil
+		case moduleOpcodeSInt, moduleOpcodeUInt:
+			_, err = p.readLength()
+		case moduleOpcodeFloat:
+			err = p.skip(4)
+		case moduleOpcodeDouble:
+			err = p.skip(8)
+		case moduleOpcodeString:
+			err = p.skipStrings(1)
+		default:
+			return fmt.Errorf("unknown module opcode %d", opcode)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *Parser) skipLengths(count int) error {
+	for i := 0; i < count; i++ {
+		if _, err := p.readLength(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Parser) skipStrings(count uint64) error {
+	for i := uint64(0); i < count; i++ {
+		if err := p.skipString(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipString reads the string skipping its content
+func (p *Parser) skipString() error {
+	length, encoded, err := p.readLengthWithEncoding()
+	if err != nil {
+		return err
+	}
+	if !encoded {
+		p.decodedSize += int64(length)
+		return p.skip(length)
+	}
+	switch length {
+	case encodingInt8:
+		return p.skip(1)
+	case encodingInt16:
+		return p.skip(2)
+	case encodingInt32:
+		return p.skip(4)
+	case encodingLZF:
+		compressedSize, err := p.readLength()
+		if err != nil {
+			return err
+		}
+		size, err := p.readLength()
+		if err != nil {
+			return err
+		}
+		p.decodedSize += int64(size)
+		return p.skip(compressedSize)
+	default:
+		return fmt.Errorf("unknown string encoding %d", length)
+	}
+}
+
+// readString reads and decodes the string
+func (p *Parser) readString() ([]byte, error) {
+	length, encoded, err := p.readLengthWithEncoding()
+	if err != nil {
+		return nil, err
+	}
+	if !encoded {
+		return p.read(int(length))
+	}
+	switch length {
+	case encodingInt8:
+		value, err := p.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(int(int8(value[0])))), nil
+	case encodingInt16:
+		value, err := p.read(2)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(int(int16(binary.LittleEndian.Uint16(value))))), nil
+	case encodingInt32:
+		value, err := p.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(int(int32(binary.LittleEndian.Uint32(value))))), nil
+	case encodingLZF:
+		compressedSize, err := p.readLength()
+		if err != nil {
+			return nil, err
+		}
+		size, err := p.readLength()
+		if err != nil {
+			return nil, err
+		}
+		compressed, err := p.read(int(compressedSize))
+		if err != nil {
+			return nil, err
+		}
+		return lzfDecompress(compressed, int(size))
+	default:
+		return nil, fmt.Errorf("unknown string encoding %d", length)
+	}
+}
+
+func (p *Parser) readLength() (uint64, error) {
+	length, encoded, err := p.readLengthWithEncoding()
+	if err == nil && encoded {
+		err = fmt.Errorf("unexpected encoded length")
+	}
+	return length, err
+}
+
+// readLengthWithEncoding reads the length, encoded is set if it is the encoding of the string instead
+func (p *Parser) readLengthWithEncoding() (length uint64, encoded bool, err error) {
+	first, err := p.readByte()
+	if err != nil {
+		return 0, false, err
+	}
+	switch first >> 6 {
+	case 0:
+		return uint64(first & 0x3F), false, nil
+	case 1:
+		second, err := p.readByte()
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(first&0x3F)<<8 | uint64(second), false, nil
+	case lengthEncoded:
+		return uint64(first & 0x3F), true, nil
+	}
+	switch first {
+	case length32Bit:
+		value, err := p.read(4)
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(binary.BigEndian.Uint32(value)), false, nil
+	case length64Bit:
+		value, err := p.read(8)
+		if err != nil {
+			return 0, false, err
+		}
+		return binary.BigEndian.Uint64(value), false, nil
+	default:
+		return 0, false, fmt.Errorf("unknown length encoding %#x", first)
+	}
+}
+
+func (p *Parser) readByte() (byte, error) {
+	value, err := p.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return value[0], nil
+}
+
+// skip reads the bytes without keeping them unless the value is captured
+func (p *Parser) skip(size uint64) error {
+	if p.record != nil {
+		_, err := io.CopyN(p.record, p.reader, int64(size))
+		return unexpectedEOF(err)
+	}
+	_, err := p.reader.Discard(int(size))
+	return unexpectedEOF(err)
+}
+
+func (p *Parser) read(size int) ([]byte, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid size %d", size)
+	}
+	buffer := make([]byte, size)
+	if _, err := io.ReadFull(p.reader, buffer); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if p.record != nil {
+		p.record.Write(buffer)
+	}
+	return buffer, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,188 @@
+package rdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type rdbBuilder struct {
+	bytes.Buffer
+}
+
+func newRDBBuilder(version string) *rdbBuilder {
+	builder := &rdbBuilder{}
+	builder.WriteString("REDIS" + version)
+	return builder
+}
+
+func (b *rdbBuilder) length(length int) *rdbBuilder {
+	switch {
+	case length < 1<<6:
+		b.WriteByte(byte(length))
+	case length < 1<<14:
+		b.WriteByte(byte(length>>8) | 0x40)
+		b.WriteByte(byte(length))
+	default:
+		b.WriteByte(length32Bit)
+		b.Write(binary.BigEndian.AppendUint32(nil, uint32(length)))
+	}
+	return b
+}
+
+func (b *rdbBuilder) str(value string) *rdbBuilder {
+	b.length(len(value))
+	b.WriteString(value)
+	return b
+}
+
+func (b *rdbBuilder) op(opcode byte) *rdbBuilder {
+	b.WriteByte(opcode)
+	return b
+}
+
+func (b *rdbBuilder) raw(data ...byte) *rdbBuilder {
+	b.Write(data)
+	return b
+}
+
+func TestParser(t *testing.T) {
+	builder := newRDBBuilder("0011").
+		op(opcodeAux).str("redis-ver").str("7.2.4").
+		op(opcodeAux).str("ctime").raw(0xC0|encodingInt32, 0x00, 0xE1, 0xF5, 0x05).
+		op(opcodeFunction2).str("#!lua name=lib").
+		op(opcodeSelectDB).length(0).
+		op(opcodeResizeDB).length(3).length(1).
+		op(typeString).str("user:1").str("alice").
+		op(opcodeExpireTimeMs).raw(binary.LittleEndian.AppendUint64(nil, 100_005_000)...).
+		op(typeString).str("session:1").raw(0xC0|encodingInt8, 42).
+		op(typeSet).str("user:tags").length(2).str("a").str("bb").
+		op(opcodeSelectDB).length(1).
+		op(opcodeIdle).length(10).
+		op(typeZSet2).str("rank").length(1).str("x").raw(make([]byte, 8)...).
+		op(opcodeFreq).raw(5).
+		op(typeListQuicklist2).str("queue").length(1).length(2).str("packed-listpack").
+		op(typeHashListpack).raw(0xC0|encodingLZF).length(5).length(10).
+		raw(0x00, 'k', 0xE0, 0x00, 0x00).str("listpack").
+		op(opcodeEOF).raw(make([]byte, 8)...)
+
+	parser := NewParser(bytes.NewReader(builder.Bytes()))
+	parser.Capture = func(key []byte) bool {
+		return MatchPattern([]byte("user:*"), key)
+	}
+	var entries []*Entry
+	for {
+		entry, err := parser.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+
+	require.Equal(t, 11, parser.Version())
+	require.Equal(t, "7.2.4", parser.Aux("redis-ver"))
+	require.Equal(t, "100000000", parser.Aux("ctime"))
+	require.Equal(t, []*Entry{
+		{DB: 0, Key: []byte("user:1"), Type: "string", Size: 6 + 5 + keyOverheadEstimation,
+			Value: []byte("\x00\x05alice")},
+		{DB: 0, Key: []byte("session:1"), Type: "string", ExpireAt: 100_005_000, Size: 9 + keyOverheadEstimation},
+		{DB: 0, Key: []byte("user:tags"), Type: "set", Size: 9 + 3 + keyOverheadEstimation,
+			Value: []byte("\x02\x02\x01a\x02bb")},
+		{DB: 1, Key: []byte("rank"), Type: "zset", Size: 4 + 1 + keyOverheadEstimation},
+		{DB: 1, Key: []byte("queue"), Type: "list", Size: 5 + 15 + keyOverheadEstimation},
+		{DB: 1, Key: []byte("kkkkkkkkkk"), Type: "hash", Size: 10 + 8 + keyOverheadEstimation},
+	}, entries)
+}
+
+func TestParser_Stream(t *testing.T) {
+	builder := newRDBBuilder("0011").
+		op(typeStreamListpacks3).str("events").
+		length(1).str("master-id-of-node").str("listpack").
+		length(1).length(1).length(0).
+		length(1).length(0).length(0).length(0).length(1).
+		length(1).str("group").length(1).length(0).length(1).
+		length(1).raw(make([]byte, 16+8)...).length(1).
+		length(1).str("consumer").raw(make([]byte, 8+8)...).length(1).raw(make([]byte, 16)...).
+		op(typeString).str("after").str("stream").
+		op(opcodeEOF)
+
+	parser := NewParser(bytes.NewReader(builder.Bytes()))
+	entry, err := parser.Next()
+	require.NoError(t, err)
+	require.Equal(t, "events", string(entry.Key))
+	require.Equal(t, "stream", entry.Type)
+	entry, err = parser.Next()
+	require.NoError(t, err)
+	require.Equal(t, "after", string(entry.Key))
+	_, err = parser.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestParser_Errors(t *testing.T) {
+	_, err := NewParser(bytes.NewReader([]byte("RESID0011"))).Next()
+	require.EqualError(t, err, "invalid RDB file signature")
+
+	truncated := newRDBBuilder("0011").op(typeString).str("key").length(10).raw('v')
+	_, err = NewParser(bytes.NewReader(truncated.Bytes())).Next()
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	unsupported := newRDBBuilder("0011").op(42).str("key")
+	_, err = NewParser(bytes.NewReader(unsupported.Bytes())).Next()
+	require.EqualError(t, err, `failed to read the value of key "key": unsupported value type 42`)
+}
+
+func TestLZFDecompress(t *testing.T) {
+	decompressed, err := lzfDecompress([]byte{0x02, 'a', 'b', 'c', 0x20, 0x02}, 6)
+	require.NoError(t, err)
+	require.Equal(t, "abcabc", string(decompressed))
+
+	_, err = lzfDecompress([]byte{0x20, 0x05}, 3)
+	require.Error(t, err)
+}
+
+func TestCRC64Checksum(t *testing.T) {
+	require.Equal(t, uint64(0xe9c6d914c4b8d9ca), crc64Checksum([]byte("123456789")))
+}
+
+func TestRestoreCommand(t *testing.T) {
+	entry := &Entry{Key: []byte("k"), ExpireAt: 1700000000000, Value: []byte("\x00\x01v")}
+	payload := DumpPayload(entry.Value, 11)
+	require.Equal(t, []byte("\x00\x01v\x0b\x00"), payload[:5])
+	require.Equal(t, crc64Checksum(payload[:5]), binary.LittleEndian.Uint64(payload[5:]))
+
+	expected := "*6\r\n$7\r\nRESTORE\r\n$1\r\nk\r\n$13\r\n1700000000000\r\n$13\r\n" + string(payload) +
+		"\r\n$6\r\nABSTTL\r\n$7\r\nREPLACE\r\n"
+	require.Equal(t, expected, string(RestoreCommand(entry, 11, true)))
+
+	entry.ExpireAt = 0
+	require.Equal(t, "*4\r\n$7\r\nRESTORE\r\n$1\r\nk\r\n$1\r\n0\r\n$13\r\n"+string(payload)+"\r\n",
+		string(RestoreCommand(entry, 11, false)))
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		matched bool
+	}{
+		{"*", "", true},
+		{"user:*", "user:1", true},
+		{"user:*", "users", false},
+		{"user:?", "user:12", false},
+		{"*:1", "a/b:1", true},
+		{"h[ae]llo", "hello", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-b]llo", "hbllo", true},
+		{"h\\*llo", "h*llo", true},
+		{"h\\*llo", "hello", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.matched, MatchPattern([]byte(tt.pattern), []byte(tt.key)), "%s %s", tt.pattern, tt.key)
+	}
+}
@@ -16,6 +16,9 @@ func HandleBackupFetch(ctx context.Context, folder storage.Folder, backupName st
 	if err != nil {
 		return err
 	}
+	if err := archive.CheckNotClusterBackup(backup); err != nil {
+		return err
+	}
 
 	if !skipClean {
 		dataFolder, _ := conf.GetSetting(conf.RedisDataPath)
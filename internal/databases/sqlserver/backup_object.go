@@ -0,0 +1,93 @@
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// BackupObject links the differential backups to their base full backups,
+// so delete keeps the bases which differentials depend on
+type BackupObject struct {
+	internal.BackupObject
+	differentialBase string
+}
+
+func (o BackupObject) IsFullBackup() bool {
+	return o.differentialBase == ""
+}
+
+func (o BackupObject) GetBaseBackupName() string {
+	if o.IsFullBackup() {
+		return o.GetBackupName()
+	}
+	return o.differentialBase
+}
+
+func (o BackupObject) GetIncrementFromName() string {
+	return o.GetBaseBackupName()
+}
+
+func MakeBackupObjects(ctx context.Context, folder storage.Folder, sentinelObjects []storage.Object) ([]internal.BackupObject, error) {
+	backupObjects := make([]internal.BackupObject, 0, len(sentinelObjects))
+	for _, object := range sentinelObjects {
+		backupObject := BackupObject{BackupObject: internal.NewDefaultBackupObject(object)}
+		sentinel, err := fetchSentinel(ctx, folder, backupObject.GetBackupName())
+		if err != nil {
+			return nil, err
+		}
+		if sentinel.IsDifferential {
+			backupObject.differentialBase = sentinel.DifferentialBase
+		}
+		backupObjects = append(backupObjects, backupObject)
+	}
+	return backupObjects, nil
+}
+
+func fetchSentinel(ctx context.Context, folder storage.Folder, backupName string) (*SentinelDto, error) {
+	backup, err := internal.GetBackupByName(ctx, backupName, utility.BaseBackupPath, folder)
+	if err != nil {
+		return nil, err
+	}
+	sentinel := new(SentinelDto)
+	err = backup.FetchSentinel(ctx, sentinel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sentinel of backup %s: %v", backupName, err)
+	}
+	return sentinel, nil
+}
+
+// findDifferentialBases finds the base of every database: the latest full backup of the database
+// which is not copy-only, only such backups reset the differential base of the database
+func findDifferentialBases(ctx context.Context, folder storage.Folder, dbnames []string) (map[string]string, error) {
+	backupTimes, err := internal.GetBackups(ctx, folder.GetSubFolder(utility.BaseBackupPath))
+	if err != nil {
+		return nil, err
+	}
+	internal.SortBackupTimeSlices(backupTimes)
+	bases := make(map[string]string, len(dbnames))
+	for i := len(backupTimes) - 1; i >= 0 && len(bases) < len(dbnames); i-- {
+		sentinel, err := fetchSentinel(ctx, folder, backupTimes[i].BackupName)
+		if err != nil {
+			return nil, err
+		}
+		if sentinel.IsDifferential || sentinel.IsCopyOnly {
+			continue
+		}
+		for _, dbname := range dbnames {
+			if _, ok := bases[dbname]; !ok && slices.Contains(sentinel.Databases, dbname) {
+				bases[dbname] = backupTimes[i].BackupName
+			}
+		}
+	}
+	missing := exclude(dbnames, slices.Collect(maps.Keys(bases)))
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("databases %v are missing from full backups, take a full backup of them first", missing)
+	}
+	return bases, nil
+}
@@ -0,0 +1,69 @@
+package sqlserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/testtools"
+)
+
+func TestBuildBackupOptions(t *testing.T) {
+	require.Equal(t, " WITH FORMAT, MAXTRANSFERSIZE=4194304",
+		buildBackupOptions(&SentinelDto{}, false))
+	require.Equal(t, " WITH FORMAT, MAXTRANSFERSIZE=4194304, DIFFERENTIAL, COMPRESSION",
+		buildBackupOptions(&SentinelDto{IsDifferential: true}, true))
+	require.Equal(t, " WITH FORMAT, MAXTRANSFERSIZE=4194304, COPY_ONLY",
+		buildBackupOptions(&SentinelDto{IsCopyOnly: true}, false))
+}
+
+func TestBackupObject(t *testing.T) {
+	full := BackupObject{BackupObject: internal.NewDefaultBackupObject(
+		storage.NewLocalObject("base_20260721T010000Z_backup_stop_sentinel.json", time.Now(), 1))}
+	require.True(t, full.IsFullBackup())
+	require.Equal(t, "base_20260721T010000Z", full.GetBaseBackupName())
+
+	differential := BackupObject{
+		BackupObject: internal.NewDefaultBackupObject(
+			storage.NewLocalObject("base_20260722T010000Z_backup_stop_sentinel.json", time.Now(), 1)),
+		differentialBase: "base_20260721T010000Z",
+	}
+	require.False(t, differential.IsFullBackup())
+	require.Equal(t, "base_20260721T010000Z", differential.GetBaseBackupName())
+	require.Equal(t, "base_20260721T010000Z", differential.GetIncrementFromName())
+}
+
+func TestFindDifferentialBases(t *testing.T) {
+	folder := testtools.MakeDefaultInMemoryStorageFolder()
+	putSQLServerBackup(t, folder, "base_20260720T010000Z", SentinelDto{Databases: []string{"db1", "db2"}})
+	putSQLServerBackup(t, folder, "base_20260721T010000Z", SentinelDto{Databases: []string{"db1"}})
+	putSQLServerBackup(t, folder, "base_20260722T010000Z", SentinelDto{Databases: []string{"db1", "db2"}, IsCopyOnly: true})
+	putSQLServerBackup(t, folder, "base_20260723T010000Z", SentinelDto{
+		Databases:        []string{"db1"},
+		IsDifferential:   true,
+		DifferentialBase: "base_20260721T010000Z",
+	})
+
+	bases, err := findDifferentialBases(t.Context(), folder, []string{"db1", "db2"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"db1": "base_20260721T010000Z", "db2": "base_20260720T010000Z"}, bases)
+
+	_, err = findDifferentialBases(t.Context(), folder, []string{"db1", "db3"})
+	require.EqualError(t, err, "databases [db3] are missing from full backups, take a full backup of them first")
+}
+
+func TestSentinelDifferentialBases(t *testing.T) {
+	sentinel := &SentinelDto{
+		DifferentialBase:  "base_20260720T010000Z",
+		DifferentialBases: map[string]string{"db1": "base_20260721T010000Z", "db2": "base_20260720T010000Z"},
+	}
+	require.Equal(t, "base_20260721T010000Z", sentinel.differentialBaseOf("db1"))
+	require.Equal(t, []string{"base_20260720T010000Z", "base_20260721T010000Z"}, sentinel.differentialBaseNames())
+
+	// the sentinels of older wal-g versions have a single base
+	sentinel = &SentinelDto{DifferentialBase: "base_20260720T010000Z"}
+	require.Equal(t, "base_20260720T010000Z", sentinel.differentialBaseOf("db1"))
+	require.Equal(t, []string{"base_20260720T010000Z"}, sentinel.differentialBaseNames())
+}
@@ -4,18 +4,29 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"sync"
 
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/databases/sqlserver/blob"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
 )
 
-func HandleBackupPush(ctx context.Context, dbnames []string, updateLatest bool) {
+func HandleBackupPush(ctx context.Context, dbnames []string, updateLatest, differential, copyOnly bool) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	if differential && copyOnly {
+		tracelog.ErrorLogger.Fatal("--differential and --copy-only can't be used together")
+	}
+	if updateLatest && (differential || copyOnly) {
+		tracelog.ErrorLogger.Fatal("--update-latest can't be used with --differential or --copy-only")
+	}
+
 	storage, err := internal.ConfigureStorage(ctx)
 	tracelog.ErrorLogger.FatalOnError(err)
 
@@ -23,8 +34,6 @@ func HandleBackupPush(ctx context.Context, dbnames []string, updateLatest bool)
 	tracelog.ErrorLogger.FatalfOnError("failed to connect to SQLServer: %v", err)
 
 	dbnames, err = getDatabasesToBackup(ctx, db, dbnames)
-	tracelog.ErrorLogger.FatalOnError(err)
-
 	tracelog.ErrorLogger.FatalfOnError("failed to list databases to backup: %v", err)
 
 	lock, err := RunOrReuseProxy(ctx, cancel, storage.RootFolder())
@@ -42,6 +51,9 @@ func HandleBackupPush(ctx context.Context, dbnames []string, updateLatest bool)
 		sentinel = new(SentinelDto)
 		err = backup.FetchSentinel(ctx, sentinel)
 		tracelog.ErrorLogger.FatalOnError(err)
+		if sentinel.IsDifferential || sentinel.IsCopyOnly {
+			tracelog.ErrorLogger.Fatalf("latest backup %s is not a regular full backup and can't be updated", backupName)
+		}
 		sentinel.Databases = uniq(append(sentinel.Databases, dbnames...))
 	} else {
 		backupName = generateDatabaseBackupName()
@@ -49,11 +61,40 @@ func HandleBackupPush(ctx context.Context, dbnames []string, updateLatest bool)
 			Server:         server,
 			Databases:      dbnames,
 			StartLocalTime: timeStart,
+			IsCopyOnly:     copyOnly,
+			IsDifferential: differential,
 		}
 	}
+	if differential {
+		sentinel.DifferentialBases, err = findDifferentialBases(ctx, storage.RootFolder(), dbnames)
+		tracelog.ErrorLogger.FatalOnError(err)
+		sentinel.DifferentialBase = slices.Min(slices.Collect(maps.Values(sentinel.DifferentialBases)))
+		tracelog.InfoLogger.Printf("differential backup is based on %v", sentinel.DifferentialBases)
+	}
+	if sentinel.LSNs == nil {
+		sentinel.LSNs = make(map[string]*BackupLSNs, len(dbnames))
+	}
+	var lsnsMutex sync.Mutex
 	builtinCompression := blob.UseBuiltinCompression()
 	err = runParallel(func(i int) error {
-		return backupSingleDatabase(ctx, db, backupName, dbnames[i], builtinCompression)
+		if differential {
+			err := checkDifferentialBase(ctx, db, storage.RootFolder(), sentinel.differentialBaseOf(dbnames[i]), dbnames[i])
+			if err != nil {
+				return err
+			}
+		}
+		err := backupSingleDatabase(ctx, db, backupName, dbnames[i], builtinCompression, sentinel)
+		if err != nil {
+			return err
+		}
+		lsns, err := GetBackupLSNs(ctx, db, storage.RootFolder(), false, backupName, dbnames[i])
+		if err != nil {
+			return fmt.Errorf("failed to read LSNs of database [%s] backup: %v", dbnames[i], err)
+		}
+		lsnsMutex.Lock()
+		sentinel.LSNs[dbnames[i]] = lsns
+		lsnsMutex.Unlock()
+		return nil
 	}, len(dbnames), getDBConcurrency())
 	tracelog.ErrorLogger.FatalfOnError("overall backup failed: %v", err)
 
@@ -68,19 +109,25 @@ func HandleBackupPush(ctx context.Context, dbnames []string, updateLatest bool)
 	tracelog.InfoLogger.Printf("backup finished")
 }
 
-func backupSingleDatabase(ctx context.Context, db *sql.DB, backupName string, dbname string, builtinCompression bool) error {
+func backupSingleDatabase(ctx context.Context,
+	db *sql.DB,
+	backupName string,
+	dbname string,
+	builtinCompression bool,
+	sentinel *SentinelDto) error {
 	baseURL := getDatabaseBackupURL(backupName, dbname)
-	size, blobCount, err := estimateDBSize(ctx, db, dbname)
+	estimate := estimateDBSize
+	if sentinel.IsDifferential {
+		estimate = estimateDiffSize
+	}
+	size, blobCount, err := estimate(ctx, db, dbname)
 	if err != nil {
 		return err
 	}
 	tracelog.InfoLogger.Printf("database [%s] size is %d, required blob count %d", dbname, size, blobCount)
 	urls := buildBackupUrls(baseURL, blobCount)
 	sql := fmt.Sprintf("BACKUP DATABASE %s TO %s", quoteName(dbname), urls)
-	sql += fmt.Sprintf(" WITH FORMAT, MAXTRANSFERSIZE=%d", MaxTransferSize)
-	if builtinCompression {
-		sql += ", COMPRESSION"
-	}
+	sql += buildBackupOptions(sentinel, builtinCompression)
 	tracelog.InfoLogger.Printf("starting backup database [%s] to %s", dbname, urls)
 	tracelog.DebugLogger.Printf("SQL: %s", sql)
 	_, err = db.ExecContext(ctx, sql)
@@ -91,3 +138,37 @@ func backupSingleDatabase(ctx context.Context, db *sql.DB, backupName string, db
 	}
 	return err
 }
+
+func buildBackupOptions(sentinel *SentinelDto, builtinCompression bool) string {
+	res := fmt.Sprintf(" WITH FORMAT, MAXTRANSFERSIZE=%d", MaxTransferSize)
+	if sentinel.IsDifferential {
+		res += ", DIFFERENTIAL"
+	}
+	if sentinel.IsCopyOnly {
+		res += ", COPY_ONLY"
+	}
+	if builtinCompression {
+		res += ", COMPRESSION"
+	}
+	return res
+}
+
+// checkDifferentialBase verifies that the last full backup of the database known to SQL Server
+// is the one from the base backup, otherwise the differential backup can't be restored on top of it
+func checkDifferentialBase(ctx context.Context, db *sql.DB, folder storage.Folder, baseBackupName, dbname string) error {
+	baseLSN, err := GetDBDifferentialBaseLSN(ctx, db, dbname)
+	if err != nil {
+		return err
+	}
+	properties, err := GetBackupProperties(ctx, db, folder, false, baseBackupName, dbname)
+	if err != nil {
+		return err
+	}
+	for _, property := range properties {
+		if property.DatabaseName == dbname && property.CheckpointLSN == baseLSN {
+			return nil
+		}
+	}
+	return fmt.Errorf("database [%s] has a full backup taken after %s outside of wal-g, "+
+		"take a full backup or use --copy-only for the backups taken outside of wal-g", dbname, baseBackupName)
+}
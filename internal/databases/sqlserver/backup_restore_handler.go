@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
@@ -11,7 +12,12 @@ import (
 	"github.com/wal-g/wal-g/utility"
 )
 
-func HandleBackupRestore(ctx context.Context, backupName string, dbnames []string, fromnames []string, noRecovery bool) {
+func HandleBackupRestore(ctx context.Context,
+	backupName string,
+	untilTS string,
+	dbnames []string,
+	fromnames []string,
+	noRecovery bool) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -37,17 +43,32 @@ func HandleBackupRestore(ctx context.Context, backupName string, dbnames []strin
 	tracelog.ErrorLogger.FatalOnError(err)
 	defer lock.Close()
 
-	backupName = backup.Name
+	var stopAt *time.Time
+	if untilTS != "" {
+		until, err := utility.ParseUntilTS(untilTS)
+		tracelog.ErrorLogger.FatalfOnError("invalid until timestamp: %v", err)
+		stopAt = &until
+	}
+
+	// the chains of all databases are checked before anything is restored
+	chains := make([]*restoreChain, len(dbnames))
+	err = runParallel(func(i int) error {
+		chain, err := buildRestoreChain(ctx, db, folder, backup.Name, sentinel, dbnames[i], fromnames[i], stopAt)
+		chains[i] = chain
+		return err
+	}, len(dbnames), getDBConcurrency())
+	tracelog.ErrorLogger.FatalfOnError("can't restore databases:\n%v", err)
+	for _, chain := range chains {
+		tracelog.InfoLogger.Println(chain)
+	}
 
 	err = runParallel(func(i int) error {
-		dbname := dbnames[i]
-		fromname := fromnames[i]
-		err := restoreSingleDatabase(ctx, db, folder, backupName, dbname, fromname)
+		err := chains[i].restore(ctx, db, folder, stopAt)
 		if err != nil {
 			return err
 		}
 		if !noRecovery {
-			return recoverSingleDatabase(ctx, db, dbname)
+			return recoverSingleDatabase(ctx, db, dbnames[i])
 		}
 		return nil
 	}, len(dbnames), getDBConcurrency())
@@ -94,6 +115,31 @@ func restoreSingleDatabase(ctx context.Context,
 	return err
 }
 
+func restoreDifferential(ctx context.Context,
+	db *sql.DB,
+	folder storage.Folder,
+	backupName string,
+	dbname string,
+	fromName string) error {
+	baseURL := getDatabaseBackupURL(backupName, fromName)
+	basePath := getDatabaseBackupPath(backupName, fromName)
+	blobs, err := listBackupBlobs(ctx, folder.GetSubFolder(basePath))
+	if err != nil {
+		return err
+	}
+	urls := buildRestoreUrls(baseURL, blobs)
+	sql := fmt.Sprintf("RESTORE DATABASE %s FROM %s WITH NORECOVERY", quoteName(dbname), urls)
+	tracelog.InfoLogger.Printf("starting restore database [%s] differential from %s", dbname, urls)
+	tracelog.DebugLogger.Printf("SQL: %s", sql)
+	_, err = db.ExecContext(ctx, sql)
+	if err != nil {
+		tracelog.ErrorLogger.Printf("database [%s] differential restore failed: %v", dbname, err)
+	} else {
+		tracelog.InfoLogger.Printf("database [%s] differential restore successfully finished", dbname)
+	}
+	return err
+}
+
 func recoverSingleDatabase(ctx context.Context, db *sql.DB, dbname string) error {
 	sql := fmt.Sprintf("RESTORE DATABASE %s WITH RECOVERY", quoteName(dbname))
 	tracelog.InfoLogger.Printf("recovering database [%s]", dbname)
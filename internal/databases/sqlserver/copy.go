@@ -0,0 +1,268 @@
+package sqlserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/copy"
+	"github.com/wal-g/wal-g/internal/crypto"
+	"github.com/wal-g/wal-g/internal/databases/sqlserver/blob"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+// the settings which tell apart the keys of crypters with the same name
+var crypterKeySettings = []string{
+	conf.PgpKeySetting,
+	conf.PgpKeyPathSetting,
+	conf.GpgKeyIDSetting,
+	conf.PgpEnvelopeKeySetting,
+	conf.PgpEnvelopKeyPathSetting,
+	conf.PgpEnvelopeYcKmsKeyIDSetting,
+	conf.CseKmsIDSetting,
+	conf.YcKmsKeyIDSetting,
+	conf.LibsodiumKeySetting,
+	conf.LibsodiumKeyPathSetting,
+	conf.LibsodiumKeyTransform,
+}
+
+// blobCodec is the compression and encryption the proxy applies to blob blocks with a configuration
+type blobCodec struct {
+	compression string
+	compressor  compression.Compressor
+	encryption  string
+	crypter     crypto.Crypter
+	keys        map[string]string
+}
+
+func newBlobCodecFromConfig(configFile string) (*blobCodec, error) {
+	config := viper.New()
+	conf.SetDefaultValues(config)
+	conf.ReadConfigFromFile(config, configFile)
+	conf.CheckAllowedSettings(config)
+
+	codec := &blobCodec{keys: make(map[string]string, len(crypterKeySettings))}
+	method := config.GetString(conf.CompressionMethodSetting)
+	if !strings.EqualFold(method, blob.SQLServerCompressionMethod) {
+		compressor, err := internal.ConfigureCompressorForSpecificConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		codec.compression = compressor.FileExtension()
+		codec.compressor = compressor
+	}
+	crypter, err := internal.ConfigureCrypterForSpecificConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if crypter != nil {
+		codec.encryption = crypter.Name()
+		codec.crypter = crypter
+	}
+	for _, setting := range crypterKeySettings {
+		codec.keys[setting] = config.GetString(setting)
+	}
+	return codec, nil
+}
+
+// recodes reports whether blocks written with the index compression and encryption
+// have to be rewritten for the codec
+func (c *blobCodec) recodes(index *blob.Index, source *blobCodec) bool {
+	if index.Compression != c.compression || index.Encryption != c.encryption {
+		return true
+	}
+	return index.Encryption != "" && !maps.Equal(source.keys, c.keys)
+}
+
+// recode decrypts and decompresses the block with the source configuration,
+// then compresses and encrypts it with the codec
+func (c *blobCodec) recode(index *blob.Index, source *blobCodec) copy.SourceTransformerFunc {
+	compressionMethod, encryption := index.Compression, index.Encryption
+	return func(r io.Reader) (io.Reader, error) {
+		var err error
+		if encryption != "" {
+			r, err = source.crypter.Decrypt(r)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if compressionMethod != "" {
+			r, err = compression.FindDecompressor(compressionMethod).Decompress(r)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return internal.CompressAndEncrypt(r, c.compressor, c.crypter), nil
+	}
+}
+
+// HandleCopy copies backups with the full backups their differential backups are based on
+// and the log backups taken since them, re-encrypting or recompressing the blocks
+// when the destination configuration differs
+func HandleCopy(ctx context.Context, fromConfigFile, toConfigFile, backupName string) {
+	from, err := internal.StorageFromConfig(ctx, fromConfigFile)
+	tracelog.ErrorLogger.FatalOnError(err)
+	to, err := internal.StorageFromConfig(ctx, toConfigFile)
+	tracelog.ErrorLogger.FatalOnError(err)
+	source, err := newBlobCodecFromConfig(fromConfigFile)
+	tracelog.ErrorLogger.FatalfOnError("failed to configure source: %v", err)
+	destination, err := newBlobCodecFromConfig(toConfigFile)
+	tracelog.ErrorLogger.FatalfOnError("failed to configure destination: %v", err)
+	plan, err := buildCopyPlan(ctx, from.RootFolder(), to.RootFolder(), backupName, source, destination)
+	tracelog.ErrorLogger.FatalOnError(err)
+	tracelog.ErrorLogger.FatalOnError(copy.ExecuteRaw(ctx, plan))
+	tracelog.InfoLogger.Printf("copy finished")
+}
+
+func buildCopyPlan(ctx context.Context,
+	from, to storage.Folder,
+	backupName string,
+	source, destination *blobCodec,
+) (*copy.Plan, error) {
+	plan, err := copy.NewPlan(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	names, err := plan.ResolveBackupNames(ctx, backupName)
+	if err != nil {
+		return nil, err
+	}
+
+	oldest := ""
+	for _, name := range names {
+		sentinel, err := fetchSentinel(ctx, from, name)
+		if err != nil {
+			return nil, err
+		}
+		chain := []string{name}
+		if sentinel.IsDifferential {
+			chain = append(sentinel.differentialBaseNames(), name)
+		}
+		for sequence, chainName := range chain {
+			if err := plan.AddBackup(chainName, chainName); err != nil {
+				return nil, err
+			}
+			// the differential backup is published after its base
+			sentinelPath := path.Join(utility.BaseBackupPath, internal.SentinelNameFromBackup(chainName))
+			if err := plan.SetOrder(sentinelPath, copy.BackupCommitPhase, sequence); err != nil {
+				return nil, err
+			}
+			if oldest == "" || chainName < oldest {
+				oldest = chainName
+			}
+		}
+	}
+
+	err = addLogsSinceBackup(plan, oldest)
+	if err != nil {
+		return nil, err
+	}
+	err = addBlobIndexes(ctx, plan, source, destination)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// addLogsSinceBackup adds the log backups which log-restore may apply on top of the backup
+func addLogsSinceBackup(plan *copy.Plan, backupName string) error {
+	if !strings.HasPrefix(backupName, utility.BackupNamePrefix) {
+		return fmt.Errorf("unexpected backup name: %s", backupName)
+	}
+	startTS := backupName[len(utility.BackupNamePrefix):]
+	walPath := strings.TrimSuffix(utility.WalPath, "/") + "/"
+	for _, object := range plan.SourceObjects() {
+		name := object.GetName()
+		if !strings.HasPrefix(name, walPath) {
+			continue
+		}
+		logBackupName, _, _ := strings.Cut(strings.TrimPrefix(name, walPath), "/")
+		if !strings.HasPrefix(logBackupName, LogNamePrefix) || logBackupName[len(LogNamePrefix):] < startTS {
+			continue
+		}
+		phase := copy.PayloadPhase
+		if strings.HasSuffix(logBackupName, utility.SentinelSuffix) {
+			// the log sentinel is published after the log backup blobs
+			phase = copy.BackupCommitPhase
+		}
+		if err := plan.AddObject(name, name, phase, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addBlobIndexes publishes every blob index after the blocks of the blob,
+// and recodes the blocks if the destination compresses or encrypts them differently
+func addBlobIndexes(ctx context.Context, plan *copy.Plan, source, destination *blobCodec) error {
+	blocks := make(map[string][]copy.Entry)
+	var indexes []copy.Entry
+	for _, entry := range plan.Entries() {
+		switch {
+		case entry.SourcePath == "":
+			continue
+		case path.Base(entry.SourcePath) == blob.IndexFileName:
+			indexes = append(indexes, entry)
+		default:
+			blobPath := path.Dir(entry.SourcePath)
+			blocks[blobPath] = append(blocks[blobPath], entry)
+		}
+	}
+
+	for _, entry := range indexes {
+		index, err := readBlobIndex(ctx, plan.From, entry.SourcePath)
+		if err != nil {
+			return err
+		}
+		if !destination.recodes(index, source) {
+			if err := plan.SetOrder(entry.TargetPath, copy.MetadataPhase, 0); err != nil {
+				return err
+			}
+			continue
+		}
+		if index.Encryption != "" && source.crypter == nil {
+			return fmt.Errorf("blob %s is encrypted with %s, but the source configuration has no key",
+				path.Dir(entry.SourcePath), index.Encryption)
+		}
+		if index.Compression != "" && compression.FindDecompressor(index.Compression) == nil {
+			return fmt.Errorf("blob %s is compressed with unknown method %s", path.Dir(entry.SourcePath), index.Compression)
+		}
+		for _, block := range blocks[path.Dir(entry.SourcePath)] {
+			if err := plan.SetTransform(block.TargetPath, destination.recode(index, source)); err != nil {
+				return err
+			}
+		}
+		index.Compression = destination.compression
+		index.Encryption = destination.encryption
+		data, err := json.Marshal(index)
+		if err != nil {
+			return err
+		}
+		plan.AddInline(entry.TargetPath, data, copy.MetadataPhase, false)
+	}
+	return nil
+}
+
+func readBlobIndex(ctx context.Context, folder storage.Folder, indexPath string) (*blob.Index, error) {
+	reader, err := folder.ReadObject(ctx, indexPath)
+	if err != nil {
+		return nil, err
+	}
+	defer utility.LoggedClose(reader, "failed to close blob index")
+	index := new(blob.Index)
+	err = json.NewDecoder(reader).Decode(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob index %s: %v", indexPath, err)
+	}
+	return index, nil
+}
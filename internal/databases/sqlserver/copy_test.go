@@ -0,0 +1,115 @@
+package sqlserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/internal/copy"
+	"github.com/wal-g/wal-g/internal/databases/sqlserver/blob"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/testtools"
+	"github.com/wal-g/wal-g/utility"
+)
+
+func putBlob(t *testing.T, folder storage.Folder, blobPath string, content string) {
+	t.Helper()
+	index, err := json.Marshal(&blob.Index{
+		Size:   uint64(len(content)),
+		Blocks: []*blob.Block{{ID: "block", CommittedSize: uint64(len(content)), CommittedRev: 1}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, folder.PutObject(t.Context(), path.Join(blobPath, blob.IndexFileName), bytes.NewReader(index)))
+	require.NoError(t, folder.PutObject(t.Context(), path.Join(blobPath, "block.1"), bytes.NewBufferString(content)))
+}
+
+func putSQLServerBackup(t *testing.T, folder storage.Folder, name string, sentinel SentinelDto) {
+	t.Helper()
+	putBlob(t, folder, path.Join(getDatabaseBackupPath(name, "db1"), "blob_000"), "backup-"+name)
+	data, err := json.Marshal(&sentinel)
+	require.NoError(t, err)
+	require.NoError(t, folder.PutObject(t.Context(),
+		path.Join(utility.BaseBackupPath, internal.SentinelNameFromBackup(name)), bytes.NewReader(data)))
+}
+
+func sqlserverCopyFixture(t *testing.T) storage.Folder {
+	t.Helper()
+	from := testtools.MakeDefaultInMemoryStorageFolder()
+	putSQLServerBackup(t, from, "base_20260720T010000Z", SentinelDto{Databases: []string{"db1"}})
+	putSQLServerBackup(t, from, "base_20260721T010000Z", SentinelDto{Databases: []string{"db1"}})
+	putSQLServerBackup(t, from, "base_20260722T010000Z", SentinelDto{
+		Databases:        []string{"db1"},
+		IsDifferential:   true,
+		DifferentialBase: "base_20260721T010000Z",
+	})
+	for _, logName := range []string{"wal_20260720T020000Z", "wal_20260721T020000Z", "wal_20260722T020000Z"} {
+		putBlob(t, from, path.Join(getLogBackupPath(logName, "db1"), "blob_000"), "log-"+logName)
+	}
+	return from
+}
+
+func TestBuildCopyPlanIncludesDifferentialBaseAndLogs(t *testing.T) {
+	from := sqlserverCopyFixture(t)
+	to := testtools.MakeDefaultInMemoryStorageFolder()
+	plan, err := buildCopyPlan(t.Context(), from, to, "base_20260722T010000Z", &blobCodec{}, &blobCodec{})
+	require.NoError(t, err)
+
+	targets := make(map[string]copy.Entry)
+	for _, entry := range plan.Entries() {
+		targets[entry.TargetPath] = entry
+	}
+	require.Contains(t, targets, "basebackups_005/base_20260721T010000Z/db1/blob_000/block.1")
+	require.Contains(t, targets, "basebackups_005/base_20260722T010000Z/db1/blob_000/block.1")
+	require.NotContains(t, targets, "basebackups_005/base_20260720T010000Z/db1/blob_000/block.1")
+	require.Contains(t, targets, "wal_005/wal_20260721T020000Z/db1/blob_000/block.1")
+	require.Contains(t, targets, "wal_005/wal_20260722T020000Z/db1/blob_000/block.1")
+	require.NotContains(t, targets, "wal_005/wal_20260720T020000Z/db1/blob_000/block.1")
+
+	require.Equal(t, copy.MetadataPhase, targets["wal_005/wal_20260721T020000Z/db1/blob_000/"+blob.IndexFileName].Phase)
+	base := targets["basebackups_005/base_20260721T010000Z"+utility.SentinelSuffix]
+	differential := targets["basebackups_005/base_20260722T010000Z"+utility.SentinelSuffix]
+	require.Equal(t, copy.BackupCommitPhase, base.Phase)
+	require.Less(t, base.Sequence, differential.Sequence)
+}
+
+func TestCopyRecompressesBlobs(t *testing.T) {
+	from := sqlserverCopyFixture(t)
+	to := testtools.MakeDefaultInMemoryStorageFolder()
+	destination := &blobCodec{compression: lz4.FileExtension, compressor: compression.Compressors[lz4.AlgorithmName]}
+	plan, err := buildCopyPlan(t.Context(), from, to, "base_20260721T010000Z", &blobCodec{}, destination)
+	require.NoError(t, err)
+	require.NoError(t, copy.ExecuteRaw(t.Context(), plan))
+
+	blobPath := path.Join(getDatabaseBackupPath("base_20260721T010000Z", "db1"), "blob_000")
+	index, err := readBlobIndex(t.Context(), to, path.Join(blobPath, blob.IndexFileName))
+	require.NoError(t, err)
+	require.Equal(t, lz4.FileExtension, index.Compression)
+	require.Len(t, index.Blocks, 1)
+
+	reader, err := to.ReadObject(t.Context(), path.Join(blobPath, "block.1"))
+	require.NoError(t, err)
+	defer reader.Close()
+	decompressed, err := lz4.Decompressor{}.Decompress(reader)
+	require.NoError(t, err)
+	content, err := io.ReadAll(decompressed)
+	require.NoError(t, err)
+	require.Equal(t, "backup-base_20260721T010000Z", string(content))
+}
+
+func TestCopyRejectsEncryptedBlobsWithoutKey(t *testing.T) {
+	from := testtools.MakeDefaultInMemoryStorageFolder()
+	putSQLServerBackup(t, from, "base_20260721T010000Z", SentinelDto{Databases: []string{"db1"}})
+	index, err := json.Marshal(&blob.Index{Encryption: "libsodium"})
+	require.NoError(t, err)
+	require.NoError(t, from.PutObject(t.Context(), path.Join(getDatabaseBackupPath("base_20260721T010000Z", "db1"),
+		"blob_000", blob.IndexFileName), bytes.NewReader(index)))
+
+	_, err = buildCopyPlan(t.Context(), from, testtools.MakeDefaultInMemoryStorageFolder(), "", &blobCodec{}, &blobCodec{})
+	require.ErrorContains(t, err, "is encrypted with libsodium, but the source configuration has no key")
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
+	"sync"
 
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/databases/sqlserver/blob"
+	"github.com/wal-g/wal-g/utility"
 )
 
 func HandleLogPush(ctx context.Context, dbnames []string, norecovery bool) {
@@ -29,13 +32,39 @@ func HandleLogPush(ctx context.Context, dbnames []string, norecovery bool) {
 	tracelog.ErrorLogger.FatalOnError(err)
 	defer lock.Close()
 
+	server, _ := os.Hostname()
 	builtinCompression := blob.UseBuiltinCompression()
 	logBackupName := generateLogBackupName()
+	sentinel := &LogSentinelDto{
+		Server:         server,
+		Databases:      dbnames,
+		StartLocalTime: utility.TimeNowCrossPlatformLocal(),
+		LSNs:           make(map[string]*BackupLSNs, len(dbnames)),
+	}
+	var lsnsMutex sync.Mutex
 	err = runParallel(func(i int) error {
-		return backupSingleLog(ctx, db, logBackupName, dbnames[i], builtinCompression, norecovery)
+		err := backupSingleLog(ctx, db, logBackupName, dbnames[i], builtinCompression, norecovery)
+		if err != nil {
+			return err
+		}
+		lsns, err := GetBackupLSNs(ctx, db, folder.RootFolder(), true, logBackupName, dbnames[i])
+		if err != nil {
+			tracelog.WarningLogger.Printf("failed to read LSNs of database [%s] log backup: %v", dbnames[i], err)
+			return nil
+		}
+		lsnsMutex.Lock()
+		sentinel.LSNs[dbnames[i]] = lsns
+		lsnsMutex.Unlock()
+		return nil
 	}, len(dbnames), getDBConcurrency())
 	tracelog.ErrorLogger.FatalfOnError("overall log backup failed: %v", err)
 
+	sentinel.StopLocalTime = utility.TimeNowCrossPlatformLocal()
+	uploader := internal.NewRegularUploader(nil, folder.RootFolder().GetSubFolder(utility.WalPath))
+	tracelog.InfoLogger.Printf("uploading log sentinel: %s", sentinel)
+	err = internal.UploadSentinel(ctx, uploader, sentinel, logBackupName)
+	tracelog.ErrorLogger.FatalfOnError("failed to save log sentinel: %v", err)
+
 	tracelog.InfoLogger.Printf("log backup finished")
 }
 
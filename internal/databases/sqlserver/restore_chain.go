@@ -0,0 +1,286 @@
+package sqlserver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	fullChainLink         = "full"
+	differentialChainLink = "differential"
+	logChainLink          = "log"
+)
+
+// chainLink is a backup or a log backup to restore
+type chainLink struct {
+	kind   string
+	name   string
+	lsns   BackupLSNs
+	stopAt bool
+}
+
+// restoreChain is the backups and the log backups which restore the database to the point in time
+type restoreChain struct {
+	dbname   string
+	fromname string
+	links    []*chainLink
+}
+
+func (c *restoreChain) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "restore chain of database [%s] from [%s]:", c.dbname, c.fromname)
+	for _, link := range c.links {
+		fmt.Fprintf(&b, "\n\t%-12s %s", link.kind, link.name)
+		if link.lsns.LastLSN != "" {
+			fmt.Fprintf(&b, " LSN %s - %s, finished at %s",
+				link.lsns.FirstLSN, link.lsns.LastLSN, link.lsns.BackupFinishDate.Format(time.RFC3339))
+		}
+		if link.stopAt {
+			b.WriteString(", STOPAT")
+		}
+	}
+	return b.String()
+}
+
+func parseLSN(lsn string) (*big.Int, error) {
+	res, ok := new(big.Int).SetString(lsn, 10)
+	if !ok {
+		return nil, fmt.Errorf("LSN '%s' not recognized", lsn)
+	}
+	return res, nil
+}
+
+// selectLogChain selects the log backups which continue the data backup up to stopAt,
+// every log backup has to start at or before the end of the previous one.
+// Without stopAt the chain goes on up to the latest log backup which continues it.
+// The log backups are ordered by name, the ones before the data backup are skipped.
+func selectLogChain(dataBackup *chainLink, logs []*chainLink, stopAt *time.Time) ([]*chainLink, error) {
+	if stopAt != nil && dataBackup.lsns.BackupFinishDate.After(*stopAt) {
+		return nil, fmt.Errorf("%s backup %s finished at %s, after %s", dataBackup.kind, dataBackup.name,
+			dataBackup.lsns.BackupFinishDate.Format(time.RFC3339), stopAt.Format(time.RFC3339))
+	}
+	end, err := parseLSN(dataBackup.lsns.LastLSN)
+	if err != nil {
+		return nil, err
+	}
+	var res []*chainLink
+	var missing []string
+	reached := false
+	for _, log := range logs {
+		first, err := parseLSN(log.lsns.FirstLSN)
+		if err != nil {
+			return nil, err
+		}
+		last, err := parseLSN(log.lsns.LastLSN)
+		if err != nil {
+			return nil, err
+		}
+		if last.Cmp(end) <= 0 {
+			// the log backup is already covered by the previous backup
+			continue
+		}
+		if first.Cmp(end) > 0 {
+			if stopAt == nil {
+				tracelog.WarningLogger.Printf("log chain of %s backup %s ends at LSN %s, the next log backup %s starts at %s",
+					dataBackup.kind, dataBackup.name, end, log.name, first)
+				break
+			}
+			missing = append(missing, fmt.Sprintf("%s - %s", end, first))
+		}
+		link := *log
+		res = append(res, &link)
+		end = last
+		if stopAt != nil && !log.lsns.BackupFinishDate.Before(*stopAt) {
+			link.stopAt = true
+			reached = true
+			break
+		}
+	}
+	if stopAt != nil && !reached {
+		missing = append(missing, fmt.Sprintf("%s - the log backup finished after %s", end, stopAt.Format(time.RFC3339)))
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("log chain is broken, missing LSN ranges: %s", strings.Join(missing, ", "))
+	}
+	return res, nil
+}
+
+// buildRestoreChain computes the chain of the database from the LSNs in the sentinels:
+// the full backup, the differential backup and the log backups up to stopAt, or up to the latest
+// available point without it. The LSNs of the backups made before LSNs were stored
+// are read from their headers through the proxy.
+func buildRestoreChain(ctx context.Context,
+	db *sql.DB,
+	folder storage.Folder,
+	backupName string,
+	sentinel *SentinelDto,
+	dbname string,
+	fromname string,
+	stopAt *time.Time,
+) (*restoreChain, error) {
+	chain := &restoreChain{dbname: dbname, fromname: fromname}
+	if sentinel.IsDifferential {
+		baseName := sentinel.differentialBaseOf(fromname)
+		baseSentinel, err := fetchSentinel(ctx, folder, baseName)
+		if err != nil {
+			return nil, fmt.Errorf("base backup of differential backup %s is not available: %v", backupName, err)
+		}
+		full, err := newDataChainLink(ctx, db, folder, fullChainLink, baseName, baseSentinel, fromname)
+		if err != nil {
+			return nil, err
+		}
+		chain.links = append(chain.links, full)
+	}
+	kind := fullChainLink
+	if sentinel.IsDifferential {
+		kind = differentialChainLink
+	}
+	dataBackup, err := newDataChainLink(ctx, db, folder, kind, backupName, sentinel, fromname)
+	if err != nil {
+		return nil, err
+	}
+	chain.links = append(chain.links, dataBackup)
+
+	logsUntil := utility.MaxTime
+	if stopAt != nil {
+		logsUntil = *stopAt
+	}
+	logNames, err := getLogsSinceBackup(ctx, folder, backupName, logsUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log backups: %v", err)
+	}
+	var logs []*chainLink
+	for _, logName := range logNames {
+		lsns, err := getLogBackupLSNs(ctx, db, folder, logName, fromname)
+		if err != nil {
+			return nil, err
+		}
+		if lsns != nil {
+			logs = append(logs, &chainLink{kind: logChainLink, name: logName, lsns: *lsns})
+		}
+	}
+	logs, err = selectLogChain(dataBackup, logs, stopAt)
+	if err != nil {
+		return nil, fmt.Errorf("database [%s]: %v", fromname, err)
+	}
+	chain.links = append(chain.links, logs...)
+	return chain, nil
+}
+
+func newDataChainLink(ctx context.Context,
+	db *sql.DB,
+	folder storage.Folder,
+	kind string,
+	backupName string,
+	sentinel *SentinelDto,
+	fromname string,
+) (*chainLink, error) {
+	if !slices.Contains(sentinel.Databases, fromname) {
+		return nil, fmt.Errorf("database [%s] was not found in backup %s", fromname, backupName)
+	}
+	lsns := sentinel.LSNs[fromname]
+	if lsns == nil {
+		var err error
+		lsns, err = GetBackupLSNs(ctx, db, folder, false, backupName, fromname)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read LSNs of database [%s] backup %s: %v", fromname, backupName, err)
+		}
+	}
+	return &chainLink{kind: kind, name: backupName, lsns: *lsns}, nil
+}
+
+// getLogBackupLSNs returns the LSNs of the database log backup, or nil if the log backup has no such database
+func getLogBackupLSNs(ctx context.Context, db *sql.DB, folder storage.Folder, logBackupName, dbname string) (*BackupLSNs, error) {
+	walFolder := folder.GetSubFolder(utility.WalPath)
+	sentinelName := internal.SentinelNameFromBackup(logBackupName)
+	exists, err := walFolder.Exists(ctx, sentinelName)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		sentinel := new(LogSentinelDto)
+		err = internal.FetchDto(ctx, walFolder, sentinel, sentinelName)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(sentinel.Databases, dbname) {
+			return nil, nil
+		}
+		if lsns := sentinel.LSNs[dbname]; lsns != nil {
+			return lsns, nil
+		}
+	} else {
+		ok, err := doesLogBackupContainDB(ctx, folder, logBackupName, dbname)
+		if err != nil || !ok {
+			return nil, err
+		}
+	}
+	tracelog.DebugLogger.Printf("reading LSNs of database [%s] log backup %s from its header", dbname, logBackupName)
+	lsns, err := GetBackupLSNs(ctx, db, folder, true, logBackupName, dbname)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LSNs of database [%s] log backup %s: %v", dbname, logBackupName, err)
+	}
+	return lsns, nil
+}
+
+func (c *restoreChain) restore(ctx context.Context, db *sql.DB, folder storage.Folder, stopAt *time.Time) error {
+	for _, link := range c.links {
+		var err error
+		switch link.kind {
+		case fullChainLink:
+			err = restoreSingleDatabase(ctx, db, folder, link.name, c.dbname, c.fromname)
+		case differentialChainLink:
+			err = restoreDifferential(ctx, db, folder, link.name, c.dbname, c.fromname)
+		case logChainLink:
+			var logStopAt *time.Time
+			if link.stopAt {
+				logStopAt = stopAt
+			}
+			err = restoreLogBackup(ctx, db, folder, link.name, c.dbname, c.fromname, logStopAt)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreLogBackup(ctx context.Context,
+	db *sql.DB,
+	folder storage.Folder,
+	logBackupName string,
+	dbname string,
+	fromname string,
+	stopAt *time.Time,
+) error {
+	baseURL := getLogBackupURL(logBackupName, fromname)
+	basePath := getLogBackupPath(logBackupName, fromname)
+	blobs, err := listBackupBlobs(ctx, folder.GetSubFolder(basePath))
+	if err != nil {
+		return err
+	}
+	urls := buildRestoreUrls(baseURL, blobs)
+	sql := fmt.Sprintf("RESTORE LOG %s FROM %s WITH NORECOVERY", quoteName(dbname), urls)
+	if stopAt != nil {
+		sql += fmt.Sprintf(", STOPAT = '%s'", stopAt.Format(TimeSQLServerFormat))
+	}
+	tracelog.InfoLogger.Printf("starting restore database [%s] log from %s", dbname, urls)
+	tracelog.DebugLogger.Printf("SQL: %s", sql)
+	_, err = db.ExecContext(ctx, sql)
+	if err != nil {
+		tracelog.ErrorLogger.Printf("database [%s] log restore failed: %v", dbname, err)
+	} else {
+		tracelog.InfoLogger.Printf("database [%s] log restore successfully finished", dbname)
+	}
+	return err
+}
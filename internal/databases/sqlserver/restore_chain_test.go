@@ -0,0 +1,80 @@
+package sqlserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testChainLink(kind, name, first, last string, finish time.Time) *chainLink {
+	return &chainLink{kind: kind, name: name, lsns: BackupLSNs{FirstLSN: first, LastLSN: last, BackupFinishDate: finish}}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func testLogChain(start time.Time) (*chainLink, []*chainLink) {
+	full := testChainLink(fullChainLink, "base_20260721T010000Z", "100", "200", start)
+	logs := []*chainLink{
+		testChainLink(logChainLink, "wal_20260721T003000Z", "50", "150", start.Add(-30*time.Minute)),
+		testChainLink(logChainLink, "wal_20260721T020000Z", "150", "300", start.Add(time.Hour)),
+		testChainLink(logChainLink, "wal_20260721T030000Z", "300", "400", start.Add(2*time.Hour)),
+		testChainLink(logChainLink, "wal_20260721T040000Z", "400", "500", start.Add(3*time.Hour)),
+	}
+	return full, logs
+}
+
+func TestSelectLogChain(t *testing.T) {
+	start := time.Date(2026, 7, 21, 1, 0, 0, 0, time.UTC)
+	full, logs := testLogChain(start)
+
+	chain, err := selectLogChain(full, logs, timePtr(start.Add(90*time.Minute)))
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	require.Equal(t, "wal_20260721T020000Z", chain[0].name)
+	require.False(t, chain[0].stopAt)
+	require.Equal(t, "wal_20260721T030000Z", chain[1].name)
+	require.True(t, chain[1].stopAt)
+	require.False(t, logs[2].stopAt)
+}
+
+func TestSelectLogChainReportsMissingRanges(t *testing.T) {
+	start := time.Date(2026, 7, 21, 1, 0, 0, 0, time.UTC)
+	full, logs := testLogChain(start)
+
+	_, err := selectLogChain(full, []*chainLink{logs[0], logs[1], logs[3]}, timePtr(start.Add(150*time.Minute)))
+	require.EqualError(t, err, "log chain is broken, missing LSN ranges: 300 - 400")
+
+	_, err = selectLogChain(full, logs[:3], timePtr(start.Add(4*time.Hour)))
+	require.EqualError(t, err,
+		"log chain is broken, missing LSN ranges: 400 - the log backup finished after 2026-07-21T05:00:00Z")
+}
+
+func TestSelectLogChainRejectsBackupAfterUntil(t *testing.T) {
+	start := time.Date(2026, 7, 21, 1, 0, 0, 0, time.UTC)
+	full, logs := testLogChain(start)
+
+	_, err := selectLogChain(full, logs, timePtr(start.Add(-time.Minute)))
+	require.ErrorContains(t, err, "full backup base_20260721T010000Z finished at 2026-07-21T01:00:00Z, after")
+}
+
+func TestSelectLogChainWithoutUntil(t *testing.T) {
+	start := time.Date(2026, 7, 21, 1, 0, 0, 0, time.UTC)
+	full, logs := testLogChain(start)
+
+	chain, err := selectLogChain(full, logs, nil)
+	require.NoError(t, err)
+	require.Len(t, chain, 3)
+	require.Equal(t, "wal_20260721T040000Z", chain[2].name)
+	for _, link := range chain {
+		require.False(t, link.stopAt)
+	}
+
+	// the chain ends at the first missing LSN range
+	chain, err = selectLogChain(full, []*chainLink{logs[0], logs[1], logs[3]}, nil)
+	require.NoError(t, err)
+	require.Len(t, chain, 1)
+	require.Equal(t, "wal_20260721T020000Z", chain[0].name)
+}
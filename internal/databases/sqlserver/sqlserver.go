@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"math"
 	"math/big"
 	"net/url"
@@ -53,6 +54,15 @@ type SentinelDto struct {
 	Databases      []string
 	StartLocalTime time.Time `json:"StartLocalTime,omitempty"`
 	StopLocalTime  time.Time `json:"StopLocalTime,omitempty"`
+	IsCopyOnly     bool      `json:"IsCopyOnly,omitempty"`
+	IsDifferential bool      `json:"IsDifferential,omitempty"`
+	// DifferentialBase is the name of the full backup the differential backup is based on,
+	// the oldest one if the databases are based on different full backups
+	DifferentialBase string `json:"DifferentialBase,omitempty"`
+	// DifferentialBases are the full backups the databases of the differential backup are based on
+	DifferentialBases map[string]string `json:"DifferentialBases,omitempty"`
+	// LSNs are the LSN ranges of the backups by database name
+	LSNs map[string]*BackupLSNs `json:"LSNs,omitempty"`
 }
 
 func (s *SentinelDto) String() string {
@@ -63,6 +73,45 @@ func (s *SentinelDto) String() string {
 	return string(b)
 }
 
+// differentialBaseOf returns the full backup the database of the differential backup is based on
+func (s *SentinelDto) differentialBaseOf(dbname string) string {
+	if base, ok := s.DifferentialBases[dbname]; ok {
+		return base
+	}
+	return s.DifferentialBase
+}
+
+// differentialBaseNames returns the full backups the differential backup is based on, the oldest first
+func (s *SentinelDto) differentialBaseNames() []string {
+	names := uniq(append([]string{s.DifferentialBase}, slices.Collect(maps.Values(s.DifferentialBases))...))
+	slices.Sort(names)
+	return names
+}
+
+// LogSentinelDto describes the log backup, it's stored next to the log backup folder
+type LogSentinelDto struct {
+	Server         string
+	Databases      []string
+	StartLocalTime time.Time              `json:"StartLocalTime,omitempty"`
+	StopLocalTime  time.Time              `json:"StopLocalTime,omitempty"`
+	LSNs           map[string]*BackupLSNs `json:"LSNs,omitempty"`
+}
+
+func (s *LogSentinelDto) String() string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+// BackupLSNs is the LSN range of a database backup or log backup from its header
+type BackupLSNs struct {
+	FirstLSN         string
+	LastLSN          string
+	BackupFinishDate time.Time
+}
+
 type DatabaseFile struct {
 	LogicalName  string
 	PhysicalName string
@@ -173,6 +222,16 @@ func estimateDBSize(ctx context.Context, db *sql.DB, dbname string) (int64, int,
 	return estimateSize(ctx, db, query)
 }
 
+func estimateDiffSize(ctx context.Context, db *sql.DB, dbname string) (int64, int, error) {
+	query := fmt.Sprintf(`
+		USE %s; 
+		SELECT (SELECT SUM(used_log_space_in_bytes) FROM sys.dm_db_log_space_usage) 
+			 + (SELECT SUM(modified_extent_page_count)*8*1024 FROM sys.dm_db_file_space_usage)
+		USE master;
+	`, quoteName(dbname))
+	return estimateSize(ctx, db, query)
+}
+
 func estimateLogSize(ctx context.Context, db *sql.DB, dbname string) (int64, int, error) {
 	query := fmt.Sprintf(`
 		USE %s; 
@@ -573,6 +632,34 @@ func RunOrReuseProxy(ctx context.Context, cancel context.CancelFunc, folder stor
 	return &LockWrapper{lock}, nil
 }
 
+// GetBackupLSNs reads the LSN range of the database from the backup header
+func GetBackupLSNs(ctx context.Context,
+	db *sql.DB,
+	folder storage.Folder,
+	logBackup bool,
+	backupName string,
+	databaseName string,
+) (*BackupLSNs, error) {
+	properties, err := GetBackupProperties(ctx, db, folder, logBackup, backupName, databaseName)
+	if err != nil {
+		return nil, err
+	}
+	if len(properties) == 0 {
+		return nil, fmt.Errorf("backup %s of database [%s] has no header", backupName, databaseName)
+	}
+	header := properties[0]
+	for _, property := range properties {
+		if property.DatabaseName == databaseName {
+			header = property
+		}
+	}
+	return &BackupLSNs{
+		FirstLSN:         header.FirstLSN,
+		LastLSN:          header.LastLSN,
+		BackupFinishDate: header.BackupFinishDate,
+	}, nil
+}
+
 func GetDBRestoreLSN(ctx context.Context, db *sql.DB, databaseName string) (string, error) {
 	query := `SELECT MAX(redo_start_lsn) 
         FROM sys.master_files
@@ -605,6 +692,19 @@ func IsLogAlreadyApplied(ctx context.Context, db *sql.DB, databaseName string, l
 	return true, nil
 }
 
+// GetDBDifferentialBaseLSN returns the checkpoint LSN of the full backup which the next differential backup
+// of the database is based on, it's empty if the database has never been backed up
+func GetDBDifferentialBaseLSN(ctx context.Context, db *sql.DB, databaseName string) (string, error) {
+	query := `SELECT differential_base_lsn
+        FROM sys.master_files
+        WHERE database_id=DB_ID(@dbname) AND file_id=1`
+	var res sql.NullString
+	if err := db.QueryRowContext(ctx, query, sql.Named("dbname", databaseName)).Scan(&res); err != nil {
+		return "", err
+	}
+	return res.String, nil
+}
+
 func GetDefaultDataLogDirs(ctx context.Context, db *sql.DB) (string, string, error) {
 	var datadir, logdir string
 	query := `SELECT serverproperty('InstanceDefaultDataPath'), serverproperty('InstanceDefaultLogPath')`
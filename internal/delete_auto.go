@@ -0,0 +1,281 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/wal-g/tracelog"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	DeleteAutoUsage            = "auto"
+	DeleteAutoShortDescription = "Deletes the backups according to the retention settings"
+	DeleteAutoLongDescription  = `Evaluates the retention settings against the backups in storage and prints the plan.
+The backups are deleted only with the --confirm flag. A backup is kept if any of the rules keeps it:
+  WALG_RETENTION_BACKUP_COUNT	keep the given number of the most recent backups
+  WALG_RETENTION_BACKUP_AGE	keep the backups younger than the given age, e.g. 72h or 30d
+  WALG_RETENTION_DAILY, _WEEKLY, _MONTHLY, _YEARLY	keep the newest backup of the given number of the most recent periods
+  WALG_RETENTION_PITR_WINDOW	keep the backups needed to restore to any moment inside the window, e.g. 14d`
+)
+
+// RetentionConfig is the declarative retention configuration.
+// The zero value of a field disables the corresponding rule.
+type RetentionConfig struct {
+	BackupCount int
+	BackupAge   time.Duration
+	GFS         GFSRetentionPolicy
+	PITRWindow  time.Duration
+}
+
+func (c RetentionConfig) IsEmpty() bool {
+	return c.BackupCount == 0 && c.BackupAge == 0 && c.GFS.IsEmpty() && c.PITRWindow == 0
+}
+
+func (c RetentionConfig) Validate() error {
+	if c.BackupCount < 0 {
+		return fmt.Errorf("%s cannot be negative: %d", conf.RetentionBackupCountSetting, c.BackupCount)
+	}
+	if c.BackupAge < 0 {
+		return fmt.Errorf("%s cannot be negative: %v", conf.RetentionBackupAgeSetting, c.BackupAge)
+	}
+	if c.PITRWindow < 0 {
+		return fmt.Errorf("%s cannot be negative: %v", conf.RetentionPITRWindowSetting, c.PITRWindow)
+	}
+	if !c.GFS.IsEmpty() {
+		if err := c.GFS.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.IsEmpty() {
+		return errors.New("retention is not configured: set at least one of the WALG_RETENTION_* settings")
+	}
+	return nil
+}
+
+// GetRetentionConfig reads the retention settings from the config
+func GetRetentionConfig() (RetentionConfig, error) {
+	var err error
+	retention := RetentionConfig{
+		BackupCount: viper.GetInt(conf.RetentionBackupCountSetting),
+		GFS: GFSRetentionPolicy{
+			Daily:   viper.GetInt(conf.RetentionDailySetting),
+			Weekly:  viper.GetInt(conf.RetentionWeeklySetting),
+			Monthly: viper.GetInt(conf.RetentionMonthlySetting),
+			Yearly:  viper.GetInt(conf.RetentionYearlySetting),
+		},
+	}
+	if retention.BackupAge, err = getRetentionDurationSetting(conf.RetentionBackupAgeSetting); err != nil {
+		return RetentionConfig{}, err
+	}
+	if retention.PITRWindow, err = getRetentionDurationSetting(conf.RetentionPITRWindowSetting); err != nil {
+		return RetentionConfig{}, err
+	}
+	return retention, nil
+}
+
+func getRetentionDurationSetting(setting string) (time.Duration, error) {
+	value, ok := conf.GetSetting(setting)
+	if !ok {
+		return 0, nil
+	}
+	duration, err := utility.ParseDurationWithDays(value)
+	if err != nil {
+		return 0, fmt.Errorf("duration expected for %s setting but given '%s': %w", setting, value, err)
+	}
+	return duration, nil
+}
+
+// FindAutoRetainedBackups returns the names of the backups kept by any of the retention rules
+// mapped to the reasons of the retention. Increment chains of the kept backups are kept as well.
+func (h *DeleteHandler) FindAutoRetainedBackups(retention RetentionConfig, now time.Time) map[string]string {
+	retained := SelectAutoRetainedBackups(h.timedBackups(), retention, now)
+	h.retainIncrementChains(retained)
+	return retained
+}
+
+// SelectAutoRetainedBackups returns the names of the backups kept by any of the retention rules
+// mapped to the reasons of the retention
+func SelectAutoRetainedBackups(backups []TimedBackup, retention RetentionConfig, now time.Time) map[string]string {
+	timedBackups := slices.Clone(backups)
+	// newest first
+	SortTimedBackup(timedBackups)
+
+	reasons := make(map[string][]string)
+	for i, backup := range timedBackups {
+		if i < retention.BackupCount {
+			reasons[backup.Name()] = append(reasons[backup.Name()], fmt.Sprintf("count %d", retention.BackupCount))
+		}
+		if retention.BackupAge > 0 && backup.StartTime().After(now.Add(-retention.BackupAge)) {
+			reasons[backup.Name()] = append(reasons[backup.Name()], fmt.Sprintf("age %v", retention.BackupAge))
+		}
+	}
+	if !retention.GFS.IsEmpty() {
+		for name, reason := range SelectGFSRetainedBackups(timedBackups, retention.GFS) {
+			reasons[name] = append(reasons[name], reason)
+		}
+	}
+	if retention.PITRWindow > 0 {
+		windowStart := now.Add(-retention.PITRWindow)
+		var base TimedBackup
+		for _, backup := range timedBackups {
+			if backup.StartTime().After(windowStart) {
+				reasons[backup.Name()] = append(reasons[backup.Name()], "pitr window")
+			}
+			// the newest backup finished before the window is needed to restore to the start of the window
+			if !backup.FinishTime().After(windowStart) && (base == nil || backup.FinishTime().After(base.FinishTime())) {
+				base = backup
+			}
+		}
+		if base != nil {
+			reasons[base.Name()] = append(reasons[base.Name()], "pitr window base")
+		}
+	}
+
+	retained := make(map[string]string, len(reasons))
+	for name, backupReasons := range reasons {
+		retained[name] = strings.Join(backupReasons, ",")
+	}
+	return retained
+}
+
+// SplitAutoRetainedBackups validates the retention config, evaluates it against the backups and prints the plan.
+// It partitions the backups to delete and retain like SplitPurgingBackups, the permanent backups are retained.
+func SplitAutoRetainedBackups(output io.Writer,
+	backups []TimedBackup,
+	retention RetentionConfig,
+	now time.Time) (purge, retain map[string]bool, err error) {
+	if err := retention.Validate(); err != nil {
+		return nil, nil, err
+	}
+	retained := SelectAutoRetainedBackups(backups, retention, now)
+	if err := printRetentionPlan(output, backups, retained); err != nil {
+		return nil, nil, err
+	}
+	purge = make(map[string]bool)
+	retain = make(map[string]bool)
+	for _, backup := range backups {
+		if _, ok := retained[backup.Name()]; ok || backup.IsPermanent() {
+			retain[backup.Name()] = true
+		} else {
+			purge[backup.Name()] = true
+		}
+	}
+	return purge, retain, nil
+}
+
+// PrintRetentionPlan prints the table of the backups with the action and the reason for each of them
+func (h *DeleteHandler) PrintRetentionPlan(output io.Writer, retained map[string]string) error {
+	return printRetentionPlan(output, h.timedBackups(), retained)
+}
+
+func printRetentionPlan(output io.Writer, backups []TimedBackup, retained map[string]string) error {
+	backups = slices.Clone(backups)
+	slices.SortFunc(backups, func(a, b TimedBackup) int {
+		return a.StartTime().Compare(b.StartTime())
+	})
+
+	writer := tabwriter.NewWriter(output, 0, 0, 1, ' ', 0)
+	defer func() { _ = writer.Flush() }()
+
+	if _, err := fmt.Fprintln(writer, "name\ttime\taction\treason"); err != nil {
+		return err
+	}
+	for _, backup := range backups {
+		action, reason := "delete", "not retained"
+		if retainReason, ok := retained[backup.Name()]; ok {
+			action, reason = "keep", retainReason
+		} else if backup.IsPermanent() {
+			action, reason = "keep", "permanent"
+		}
+		_, err := fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n",
+			backup.Name(), backup.StartTime().Format(time.RFC3339), action, reason)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *DeleteHandler) timedBackups() []TimedBackup {
+	timedBackups := make([]TimedBackup, 0, len(h.backups))
+	for _, backup := range h.backups {
+		timedBackups = append(timedBackups, timedBackupObject{backup, h.isPermanent(backup)})
+	}
+	return timedBackups
+}
+
+func (h *DeleteHandler) HandleDeleteAuto(ctx context.Context, retention RetentionConfig, confirmed bool) {
+	err := h.DeleteAuto(ctx, retention, confirmed)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
+// DeleteAuto prints the retention plan and deletes the backups not retained by it
+// along with the WALs (binlogs, etc.) older than the oldest retained backup
+func (h *DeleteHandler) DeleteAuto(ctx context.Context, retention RetentionConfig, confirmed bool) error {
+	retained, err := h.PlanAutoRetention(retention)
+	if err != nil || retained == nil {
+		return err
+	}
+	folderFilter := func(string) bool { return true }
+	return h.DeleteNotRetained(ctx, retained, confirmed, folderFilter)
+}
+
+// PlanAutoRetention validates the retention config, evaluates it and prints the plan.
+// It returns nil if there are no backups to evaluate the config against.
+func (h *DeleteHandler) PlanAutoRetention(retention RetentionConfig) (map[string]string, error) {
+	return h.PlanAutoRetentionWithPITRWindow(retention, nil)
+}
+
+// PITRWindowTargetFinder returns the oldest backup needed to restore to any moment after the window start,
+// or nil if every backup is needed. It fails if the window cannot be guaranteed.
+type PITRWindowTargetFinder func(windowStart time.Time) (BackupObject, error)
+
+// PlanAutoRetentionWithPITRWindow works like PlanAutoRetention, but the PITR window rule keeps
+// the backup returned by findTarget and everything after it. The generic rule is used if findTarget is nil.
+func (h *DeleteHandler) PlanAutoRetentionWithPITRWindow(retention RetentionConfig,
+	findTarget PITRWindowTargetFinder) (map[string]string, error) {
+	if err := retention.Validate(); err != nil {
+		return nil, err
+	}
+	if len(h.backups) == 0 {
+		tracelog.InfoLogger.Printf("No backup found for deletion")
+		return nil, nil
+	}
+	now := time.Now()
+	rules := retention
+	if findTarget != nil {
+		rules.PITRWindow = 0
+	}
+	retained := SelectAutoRetainedBackups(h.timedBackups(), rules, now)
+	if findTarget != nil && retention.PITRWindow > 0 {
+		target, err := findTarget(now.Add(-retention.PITRWindow))
+		if err != nil {
+			return nil, err
+		}
+		for _, backup := range h.backups {
+			if target != nil && h.less(backup, target) {
+				continue
+			}
+			if reason, ok := retained[backup.GetBackupName()]; ok {
+				retained[backup.GetBackupName()] = reason + ",pitr window"
+			} else {
+				retained[backup.GetBackupName()] = "pitr window"
+			}
+		}
+	}
+	h.retainIncrementChains(retained)
+	if err := h.PrintRetentionPlan(os.Stdout, retained); err != nil {
+		return nil, err
+	}
+	return retained, nil
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+)
+
+func TestFindAutoRetainedBackups(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 12, 0, 0, 0, time.UTC) }
+	backups := []BackupObject{
+		newTestRetainPolicyBackup("base_01", "", day(1)),
+		newTestRetainPolicyBackup("base_05", "", day(5)),
+		newTestRetainPolicyBackup("base_10", "", day(10)),
+		newTestRetainPolicyBackup("base_11", "base_10", day(11)),
+		newTestRetainPolicyBackup("base_15", "", day(15)),
+		newTestRetainPolicyBackup("base_20", "", day(20)),
+	}
+	deleteHandler := CreateMockDeleteHandler(backups, memory.NewFolder("in_memory/", memory.NewKVS()))
+	now := day(21)
+
+	retained := deleteHandler.FindAutoRetainedBackups(RetentionConfig{BackupCount: 1, PITRWindow: 8 * 24 * time.Hour}, now)
+	assert.Equal(t, map[string]string{
+		"base_20": "count 1,pitr window",
+		"base_15": "pitr window",
+		"base_11": "pitr window base",
+		"base_10": "increment base of base_11",
+	}, retained)
+
+	retained = deleteHandler.FindAutoRetainedBackups(RetentionConfig{
+		BackupAge: 3 * 24 * time.Hour,
+		GFS:       GFSRetentionPolicy{Monthly: 2},
+	}, now)
+	assert.Equal(t, map[string]string{
+		"base_20": "age 72h0m0s,monthly",
+	}, retained)
+
+	var plan bytes.Buffer
+	require.NoError(t, deleteHandler.PrintRetentionPlan(&plan, retained))
+	assert.Equal(t, `name    time                 action reason
+base_01 2024-06-01T12:00:00Z delete not retained
+base_05 2024-06-05T12:00:00Z delete not retained
+base_10 2024-06-10T12:00:00Z delete not retained
+base_11 2024-06-11T12:00:00Z delete not retained
+base_15 2024-06-15T12:00:00Z delete not retained
+base_20 2024-06-20T12:00:00Z keep   age 72h0m0s,monthly
+`, plan.String())
+}
+
+type testTimedBackup struct {
+	name       string
+	startTime  time.Time
+	finishTime time.Time
+}
+
+func (b testTimedBackup) Name() string          { return b.name }
+func (b testTimedBackup) StartTime() time.Time  { return b.startTime }
+func (b testTimedBackup) FinishTime() time.Time { return b.finishTime }
+func (b testTimedBackup) IsPermanent() bool     { return false }
+
+func TestSelectAutoRetainedBackups_PITRWindowBaseByFinishTime(t *testing.T) {
+	hour := func(h int) time.Time { return time.Date(2024, 6, 10, h, 0, 0, 0, time.UTC) }
+	backups := []TimedBackup{
+		testTimedBackup{name: "base_01", startTime: hour(1), finishTime: hour(3)},
+		// started before the window start but finished after it
+		testTimedBackup{name: "base_04", startTime: hour(4), finishTime: hour(8)},
+		testTimedBackup{name: "base_09", startTime: hour(9), finishTime: hour(10)},
+	}
+
+	// the window starts at 06:00
+	retained := SelectAutoRetainedBackups(backups, RetentionConfig{PITRWindow: 6 * time.Hour}, hour(12))
+	assert.Equal(t, map[string]string{
+		"base_09": "pitr window",
+		"base_01": "pitr window base",
+	}, retained)
+}
+
+func TestRetentionConfigValidate(t *testing.T) {
+	assert.Error(t, RetentionConfig{}.Validate())
+	assert.Error(t, RetentionConfig{BackupCount: -1}.Validate())
+	assert.Error(t, RetentionConfig{BackupCount: 3, GFS: GFSRetentionPolicy{Daily: -1}}.Validate())
+	assert.NoError(t, RetentionConfig{PITRWindow: time.Hour}.Validate())
+}
@@ -112,6 +112,11 @@ type DeleteHandler struct {
 	isPermanent func(object storage.Object) bool
 }
 
+// Less reports whether the object1 is ordered before the object2 by the handler
+func (h *DeleteHandler) Less(object1, object2 storage.Object) bool {
+	return h.less(object1, object2)
+}
+
 func (h *DeleteHandler) HandleDeleteBefore(ctx context.Context, args []string, confirmed bool) {
 	modifier, beforeStr := ExtractDeleteModifierFromArgs(args)
 
@@ -158,6 +163,11 @@ func (h *DeleteHandler) HandleDeleteRetainAfter(ctx context.Context, args []stri
 	tracelog.ErrorLogger.FatalOnError(err)
 }
 
+func (h *DeleteHandler) HandleDeleteRetainPolicy(ctx context.Context, policy GFSRetentionPolicy, confirmed bool) {
+	err := h.DeleteRetainPolicy(ctx, policy, confirmed)
+	tracelog.ErrorLogger.FatalOnError(err)
+}
+
 func (h *DeleteHandler) HandleDeleteTarget(ctx context.Context, targetSelector BackupSelector, confirmed, findFull bool) {
 	target, err := h.FindTargetBySelector(ctx, targetSelector)
 	tracelog.ErrorLogger.FatalOnError(err)
@@ -352,6 +362,124 @@ func (h *DeleteHandler) FindTargetRetainAfterTime(retentionCount int, timeLine t
 	return target2, nil
 }
 
+// FindRetainPolicyBackups returns the names of the backups which should be kept according to the GFS policy
+// mapped to the reason of the retention. Increment chains of the selected backups are kept
+// and every delta is kept whenever its base is kept.
+func (h *DeleteHandler) FindRetainPolicyBackups(policy GFSRetentionPolicy) map[string]string {
+	retained := SelectGFSRetainedBackups(h.timedBackups(), policy)
+	h.retainIncrementChains(retained)
+	return retained
+}
+
+// retainIncrementChains adds the bases of the retained deltas and the deltas of every retained backup
+// to the retained set
+func (h *DeleteHandler) retainIncrementChains(retained map[string]string) {
+	backupsByName := make(map[string]BackupObject, len(h.backups))
+	for _, backup := range h.backups {
+		backupsByName[backup.GetBackupName()] = backup
+	}
+
+	selected := make([]string, 0, len(retained))
+	for name := range retained {
+		selected = append(selected, name)
+	}
+	slices.Sort(selected)
+
+	// keep the backups the selected deltas are built on
+	for _, name := range selected {
+		current := backupsByName[name]
+		for !current.IsFullBackup() {
+			parent, ok := backupsByName[current.GetIncrementFromName()]
+			if !ok {
+				break
+			}
+			if _, ok := retained[parent.GetBackupName()]; !ok {
+				retained[parent.GetBackupName()] = fmt.Sprintf("increment base of %s", name)
+			}
+			current = parent
+		}
+	}
+
+	// keep the deltas of every retained backup
+	kept := make([]string, 0, len(retained))
+	for name := range retained {
+		kept = append(kept, name)
+	}
+	slices.Sort(kept)
+	for _, name := range kept {
+		for _, dependant := range h.findDependantBackups(backupsByName[name]) {
+			if _, ok := retained[dependant.GetBackupName()]; !ok {
+				retained[dependant.GetBackupName()] = fmt.Sprintf("delta of %s", name)
+			}
+		}
+	}
+}
+
+// DeleteRetainPolicy deletes the backups not retained by the GFS policy
+// along with the WALs (binlogs, etc.) older than the oldest retained backup
+func (h *DeleteHandler) DeleteRetainPolicy(ctx context.Context, policy GFSRetentionPolicy, confirmed bool) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	retained := h.FindRetainPolicyBackups(policy)
+	if len(retained) == 0 {
+		tracelog.InfoLogger.Printf("No backup found for deletion")
+		return nil
+	}
+	folderFilter := func(string) bool { return true }
+	return h.DeleteNotRetained(ctx, retained, confirmed, folderFilter)
+}
+
+// SplitRetainedBackups returns the backups which are neither retained nor permanent
+// along with the oldest retained backup
+func (h *DeleteHandler) SplitRetainedBackups(retained map[string]string) (toDelete []BackupObject, oldestRetained BackupObject) {
+	for _, backup := range h.backups {
+		if reason, ok := retained[backup.GetBackupName()]; ok {
+			tracelog.InfoLogger.Printf("Backup %s will be retained: %s\n", backup.GetBackupName(), reason)
+			if oldestRetained == nil || h.less(backup, oldestRetained) {
+				oldestRetained = backup
+			}
+			continue
+		}
+		if h.isPermanent(backup) {
+			tracelog.InfoLogger.Printf("Backup %s will be retained: permanent\n", backup.GetBackupName())
+			continue
+		}
+		toDelete = append(toDelete, backup)
+	}
+	return toDelete, oldestRetained
+}
+
+// DeleteNotRetained deletes all the backups which names are not in the retained set
+// and all the other objects older than the oldest retained backup
+func (h *DeleteHandler) DeleteNotRetained(
+	ctx context.Context,
+	retained map[string]string,
+	confirmed bool,
+	folderFilter func(name string) bool,
+) error {
+	toDelete, oldestRetained := h.SplitRetainedBackups(retained)
+	if oldestRetained == nil {
+		return utility.NewForbiddenActionError("Retention policy would delete every backup. Check out delete everything")
+	}
+	backupNamesToDelete := make(map[string]bool, len(toDelete))
+	for _, backup := range toDelete {
+		backupNamesToDelete[backup.GetBackupName()] = true
+	}
+	tracelog.InfoLogger.Println("Start delete")
+
+	return DeleteObjectsWhere(ctx, h.Folder, confirmed, func(object storage.Object) bool {
+		if h.isPermanent(object) {
+			return false
+		}
+		if strings.HasPrefix(object.GetName(), utility.BaseBackupPath) {
+			backupName := utility.StripLeftmostBackupName(strings.TrimPrefix(object.GetName(), utility.BaseBackupPath))
+			return backupNamesToDelete[backupName]
+		}
+		return h.less(object, oldestRetained)
+	}, folderFilter)
+}
+
 func (h *DeleteHandler) DeleteEverything(ctx context.Context, confirmed bool) {
 	filter := func(object storage.Object) bool { return true }
 	folderFilter := func(path string) bool { return true }
@@ -497,6 +625,11 @@ func DeleteObjectsWhere(
 	markedForDeletion := make([]storage.Object, 0, len(relativePathObjects))
 	tracelog.InfoLogger.Println("Evaluating objects for deletion...")
 	for _, object := range relativePathObjects {
+		if isZstdDictionary(object) {
+			// the dictionaries are not ordered with the backups, and the kept objects may be compressed with any of them
+			tracelog.DebugLogger.Printf("Object skipped: %s is the zstd dictionary\n", object.GetName())
+			continue
+		}
 		if objFilter(object) {
 			tracelog.InfoLogger.Printf("Object marked for deletion: %s storage=%s\n", object.GetName(), multistorage.GetStorage(object))
 			markedForDeletion = append(markedForDeletion, object)
@@ -520,6 +653,10 @@ func DeleteObjectsWhere(
 	return nil
 }
 
+func isZstdDictionary(object storage.Object) bool {
+	return strings.HasPrefix(object.GetName(), utility.ZstdDictsPath)
+}
+
 func findTarget(objects []BackupObject,
 	compare func(object1, object2 storage.Object) bool,
 	isTarget func(object BackupObject) bool) (BackupObject, error) {
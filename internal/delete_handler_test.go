@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/wal-g/wal-g/pkg/storages/memory"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
 )
 
 func CreateMockStorageFolder(ctx context.Context) storage.Folder {
@@ -117,3 +120,41 @@ func TestFindTargetByNameEmpty(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, nil, actual)
 }
+
+func TestDeleteKeepsZstdDictionaries(t *testing.T) {
+	dictionary := utility.ZstdDictsPath + "dict_0001.zstd"
+	newFolder := func() storage.Folder {
+		folder := memory.NewFolder("in_memory/", memory.NewKVS())
+		require.NoError(t, folder.PutObject(t.Context(), dictionary, strings.NewReader("dictionary")))
+		require.NoError(t, folder.PutObject(t.Context(), "binlogs_005/mysql-bin.000001.zst", &bytes.Buffer{}))
+		require.NoError(t, folder.PutObject(t.Context(), "basebackups_005/base_01/stream.zst", &bytes.Buffer{}))
+		return folder
+	}
+	// the objects are ordered by time as the mysql, etcd, fdb and sqlserver handlers do
+	lessByTime := func(object1, object2 storage.Object) bool {
+		return object1.GetLastModified().Before(object2.GetLastModified())
+	}
+	oldBackup := newTestRetainPolicyBackup("base_01", "", time.Now().Add(-time.Hour))
+	target := newTestRetainPolicyBackup("base_02", "", time.Now().Add(time.Hour))
+	backups := []BackupObject{oldBackup, target}
+	assertOnlyDictionaryKept := func(folder storage.Folder) {
+		objects, err := storage.ListFolderRecursively(t.Context(), folder)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		assert.Equal(t, dictionary, objects[0].GetName())
+	}
+
+	folder := newFolder()
+	require.NoError(t, NewDeleteHandler(folder, backups, lessByTime).DeleteBeforeTarget(t.Context(), target, true))
+	assertOnlyDictionaryKept(folder)
+
+	folder = newFolder()
+	err := NewDeleteHandler(folder, backups, lessByTime).
+		DeleteNotRetained(t.Context(), map[string]string{"base_02": "count 1"}, true, func(string) bool { return true })
+	require.NoError(t, err)
+	assertOnlyDictionaryKept(folder)
+
+	folder = newFolder()
+	NewDeleteHandler(folder, backups, lessByTime).DeleteEverything(t.Context(), true)
+	assertOnlyDictionaryKept(folder)
+}
@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/wal-g/tracelog"
+)
+
+const (
+	DeleteRetainPolicyUsageExample = "retain-policy [--daily N] [--weekly N] [--monthly N] [--yearly N]"
+	DeleteRetainPolicyExamples     = `  retain-policy --daily 7 --weekly 4 --monthly 12 --yearly 3	keep the newest backup of each of the last 7 days, 4 weeks, 12 months and 3 years
+  retain-policy --daily 14	keep the newest backup of each of the last 14 days` //nolint:lll
+
+	RetainDailyFlag   = "daily"
+	RetainWeeklyFlag  = "weekly"
+	RetainMonthlyFlag = "monthly"
+	RetainYearlyFlag  = "yearly"
+)
+
+// GFSRetentionPolicy is a grandfather-father-son retention policy.
+// For each period it keeps the newest backup of the given number of the most recent
+// days, ISO weeks, months and years which have at least one backup.
+type GFSRetentionPolicy struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+	Yearly  int
+}
+
+type gfsPeriod struct {
+	name  string
+	count int
+	key   func(t time.Time) string
+}
+
+func (p GFSRetentionPolicy) periods() []gfsPeriod {
+	return []gfsPeriod{
+		{"daily", p.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{"weekly", p.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{"monthly", p.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+		{"yearly", p.Yearly, func(t time.Time) string { return t.Format("2006") }},
+	}
+}
+
+func (p GFSRetentionPolicy) IsEmpty() bool {
+	return p.Daily == 0 && p.Weekly == 0 && p.Monthly == 0 && p.Yearly == 0
+}
+
+func (p GFSRetentionPolicy) Validate() error {
+	for _, period := range p.periods() {
+		if period.count < 0 {
+			return fmt.Errorf("%s backups count cannot be negative: %d", period.name, period.count)
+		}
+	}
+	if p.IsEmpty() {
+		return fmt.Errorf("at least one of %s, %s, %s or %s backups count should be set",
+			RetainDailyFlag, RetainWeeklyFlag, RetainMonthlyFlag, RetainYearlyFlag)
+	}
+	return nil
+}
+
+func (p GFSRetentionPolicy) String() string {
+	return fmt.Sprintf("daily=%d weekly=%d monthly=%d yearly=%d", p.Daily, p.Weekly, p.Monthly, p.Yearly)
+}
+
+// SelectGFSRetainedBackups returns the names of the backups retained by the policy
+// mapped to the reason of the retention. The backups are bucketed by their UTC start time.
+func SelectGFSRetainedBackups(backups []TimedBackup, policy GFSRetentionPolicy) map[string]string {
+	sorted := make([]TimedBackup, len(backups))
+	copy(sorted, backups)
+	SortTimedBackup(sorted)
+
+	reasons := make(map[string][]string)
+	for _, period := range policy.periods() {
+		if period.count <= 0 {
+			continue
+		}
+		seenBuckets := make(map[string]bool)
+		for _, backup := range sorted {
+			bucket := period.key(backup.StartTime().UTC())
+			if seenBuckets[bucket] {
+				continue
+			}
+			seenBuckets[bucket] = true
+			if len(seenBuckets) > period.count {
+				break
+			}
+			tracelog.DebugLogger.Printf("Backup %s is the newest in the %s bucket %s", backup.Name(), period.name, bucket)
+			reasons[backup.Name()] = append(reasons[backup.Name()], period.name)
+		}
+	}
+
+	retained := make(map[string]string, len(reasons))
+	for name, backupReasons := range reasons {
+		retained[name] = strings.Join(backupReasons, ",")
+	}
+	return retained
+}
+
+// AddGFSRetentionPolicyFlags binds the GFS retention policy settings to the flags with the given name prefix
+func AddGFSRetentionPolicyFlags(flags *pflag.FlagSet, policy *GFSRetentionPolicy, prefix string) {
+	flags.IntVar(&policy.Daily, prefix+RetainDailyFlag, 0, "Number of most recent days to keep the newest backup of")
+	flags.IntVar(&policy.Weekly, prefix+RetainWeeklyFlag, 0, "Number of most recent weeks to keep the newest backup of")
+	flags.IntVar(&policy.Monthly, prefix+RetainMonthlyFlag, 0, "Number of most recent months to keep the newest backup of")
+	flags.IntVar(&policy.Yearly, prefix+RetainYearlyFlag, 0, "Number of most recent years to keep the newest backup of")
+}
+
+func DeleteRetainPolicyArgsValidator(cmd *cobra.Command, args []string) error {
+	if err := cobra.NoArgs(cmd, args); err != nil {
+		return err
+	}
+	var policy GFSRetentionPolicy
+	var err error
+	if policy.Daily, err = cmd.Flags().GetInt(RetainDailyFlag); err != nil {
+		return err
+	}
+	if policy.Weekly, err = cmd.Flags().GetInt(RetainWeeklyFlag); err != nil {
+		return err
+	}
+	if policy.Monthly, err = cmd.Flags().GetInt(RetainMonthlyFlag); err != nil {
+		return err
+	}
+	if policy.Yearly, err = cmd.Flags().GetInt(RetainYearlyFlag); err != nil {
+		return err
+	}
+	return policy.Validate()
+}
+
+// timedBackupObject adapts BackupObject to the TimedBackup interface
+type timedBackupObject struct {
+	BackupObject
+	isPermanent bool
+}
+
+func (o timedBackupObject) Name() string {
+	return o.GetBackupName()
+}
+
+func (o timedBackupObject) StartTime() time.Time {
+	return o.GetBackupTime()
+}
+
+// FinishTime is the backup time as well: the sentinel is uploaded when the backup finishes
+func (o timedBackupObject) FinishTime() time.Time {
+	return o.GetBackupTime()
+}
+
+func (o timedBackupObject) IsPermanent() bool {
+	return o.isPermanent
+}
@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/multistorage/consts"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+type testRetainPolicyBackup struct {
+	storage.Object
+	name          string
+	incrementFrom string
+	backupTime    time.Time
+}
+
+func (o testRetainPolicyBackup) GetBackupName() string        { return o.name }
+func (o testRetainPolicyBackup) GetBackupTime() time.Time     { return o.backupTime }
+func (o testRetainPolicyBackup) IsFullBackup() bool           { return o.incrementFrom == "" }
+func (o testRetainPolicyBackup) GetIncrementFromName() string { return o.incrementFrom }
+func (o testRetainPolicyBackup) GetStorage() string           { return consts.DefaultStorage }
+
+func (o testRetainPolicyBackup) GetBaseBackupName() string {
+	if o.incrementFrom == "" {
+		return o.name
+	}
+	return o.incrementFrom
+}
+
+func newTestRetainPolicyBackup(name, incrementFrom string, backupTime time.Time) testRetainPolicyBackup {
+	return testRetainPolicyBackup{
+		Object:        storage.NewLocalObject(name+utility.SentinelSuffix, backupTime, 0),
+		name:          name,
+		incrementFrom: incrementFrom,
+		backupTime:    backupTime,
+	}
+}
+
+func TestSelectGFSRetainedBackups(t *testing.T) {
+	// one backup per day from 2024-01-01 to 2024-03-31
+	start := time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)
+	backups := make([]TimedBackup, 0)
+	for day := 0; day < 91; day++ {
+		backupTime := start.AddDate(0, 0, day)
+		backups = append(backups, timedBackupObject{
+			BackupObject: newTestRetainPolicyBackup("base_"+backupTime.Format("20060102"), "", backupTime),
+		})
+	}
+
+	retained := SelectGFSRetainedBackups(backups, GFSRetentionPolicy{Daily: 3, Weekly: 2, Monthly: 3, Yearly: 1})
+
+	assert.Equal(t, map[string]string{
+		"base_20240331": "daily,weekly,monthly,yearly",
+		"base_20240330": "daily",
+		"base_20240329": "daily",
+		"base_20240324": "weekly",
+		"base_20240229": "monthly",
+		"base_20240131": "monthly",
+	}, retained)
+}
+
+func TestDeleteRetainPolicyKeepsIncrementChains(t *testing.T) {
+	folder := memory.NewFolder("in_memory/", memory.NewKVS())
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 12, 0, 0, 0, time.UTC) }
+	backups := []BackupObject{
+		newTestRetainPolicyBackup("base_01", "", day(1)),
+		newTestRetainPolicyBackup("base_02", "base_01", day(2)),
+		newTestRetainPolicyBackup("base_03", "", day(3)),
+		newTestRetainPolicyBackup("base_04", "base_03", day(4)),
+		newTestRetainPolicyBackup("base_05", "base_04", day(5)),
+		newTestRetainPolicyBackup("base_06", "base_03", day(5).Add(time.Hour)),
+	}
+	for _, backup := range backups {
+		require.NoError(t, folder.PutObject(t.Context(), "basebackups_005/"+backup.GetName(), &bytes.Buffer{}))
+		require.NoError(t, folder.PutObject(t.Context(),
+			fmt.Sprintf("basebackups_005/%s/tar_partitions/part_1.tar.br", backup.GetBackupName()), &bytes.Buffer{}))
+	}
+
+	deleteHandler := CreateMockDeleteHandler(backups, folder)
+	retained := deleteHandler.FindRetainPolicyBackups(GFSRetentionPolicy{Daily: 1})
+	assert.Equal(t, map[string]string{
+		"base_06": "daily",
+		"base_03": "increment base of base_06",
+		"base_04": "delta of base_03",
+		"base_05": "delta of base_03",
+	}, retained)
+
+	err := deleteHandler.DeleteRetainPolicy(t.Context(), GFSRetentionPolicy{Daily: 1}, true)
+	require.NoError(t, err)
+
+	objects, err := storage.ListFolderRecursively(t.Context(), folder.GetSubFolder("basebackups_005"))
+	require.NoError(t, err)
+	remaining := make(map[string]bool)
+	for _, object := range objects {
+		remaining[object.GetName()] = true
+	}
+	assert.Len(t, remaining, 8)
+	assert.False(t, remaining["base_01_backup_stop_sentinel.json"])
+	assert.False(t, remaining["base_02/tar_partitions/part_1.tar.br"])
+	assert.True(t, remaining["base_03_backup_stop_sentinel.json"])
+	assert.True(t, remaining["base_05/tar_partitions/part_1.tar.br"])
+}
+
+func TestGFSRetentionPolicyValidate(t *testing.T) {
+	assert.Error(t, GFSRetentionPolicy{}.Validate())
+	assert.Error(t, GFSRetentionPolicy{Daily: -1, Weekly: 2}.Validate())
+	assert.NoError(t, GFSRetentionPolicy{Yearly: 1}.Validate())
+}
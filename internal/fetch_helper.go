@@ -12,6 +12,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal/compression"
+	zstdcompression "github.com/wal-g/wal-g/internal/compression/zstd"
 	"github.com/wal-g/wal-g/internal/ioextensions"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
@@ -79,6 +80,9 @@ func DecompressDecryptBytes(archiveReader io.Reader, decompressor compression.De
 		tracelog.DebugLogger.Printf("No decompressor has been selected")
 		return io.NopCloser(decryptReader), nil
 	}
+	if decompressor.FileExtension() == zstdcompression.FileExtension {
+		decryptReader = withZstdDictionaryFolder(decryptReader, archiveReader)
+	}
 	return decompressor.Decompress(decryptReader)
 }
 
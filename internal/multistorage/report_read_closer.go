@@ -9,6 +9,7 @@ import (
 )
 
 var _ io.ReadCloser = &reportReadCloser{}
+var _ StorageTeller = &reportReadCloser{}
 
 // reportReadCloser wraps an io.ReadCloser and reports the stats.OperationRead result depending on whether the read was
 // successful or not.
@@ -42,6 +43,11 @@ func (r *reportReadCloser) Read(p []byte) (n int, err error) {
 	return n, nil
 }
 
+// GetStorage returns the name of the storage the object is read from
+func (r *reportReadCloser) GetStorage() string {
+	return r.storage
+}
+
 func (r *reportReadCloser) Close() error {
 	r.reportResult(true)
 	return r.ReadCloser.Close()
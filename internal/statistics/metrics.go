@@ -22,6 +22,9 @@ type metrics struct {
 	S3BytesWritten prometheus.Gauge
 	S3BytesRead    prometheus.Gauge
 	S3UploadTime   prometheus.Gauge
+
+	ScrubbedObjectsTotal prometheus.CounterVec
+	ScrubbedBytesTotal   prometheus.Counter
 }
 
 var (
@@ -67,6 +70,19 @@ var (
 				Help: "WAL upload time to S3.",
 			},
 		),
+		ScrubbedObjectsTotal: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: WalgMetricsPrefix + "scrub_objects_total",
+				Help: "Number of objects checked by the storage scrub.",
+			},
+			[]string{"status"},
+		),
+		ScrubbedBytesTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: WalgMetricsPrefix + "scrub_bytes_total",
+				Help: "Amount of stored bytes read by the storage scrub.",
+			},
+		),
 	}
 )
 
@@ -82,6 +98,8 @@ func init() {
 	prometheus.MustRegister(WalgMetrics.S3BytesWritten)
 	prometheus.MustRegister(WalgMetrics.S3BytesRead)
 	prometheus.MustRegister(WalgMetrics.S3UploadTime)
+	prometheus.MustRegister(WalgMetrics.ScrubbedObjectsTotal)
+	prometheus.MustRegister(WalgMetrics.ScrubbedBytesTotal)
 }
 
 func PushMetrics() {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 
+	"github.com/wal-g/wal-g/internal/checksum"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 )
 
@@ -14,14 +15,16 @@ type StorageReaderMaker struct {
 	localPath       string
 	StorageFileType FileType
 	FileMode        int64
+	// ExpectedChecksum is verified after the whole object is read, if set
+	ExpectedChecksum string
 }
 
 func NewStorageReaderMaker(folder storage.Folder, relativePath string) *StorageReaderMaker {
-	return &StorageReaderMaker{folder, relativePath, relativePath, TarFileType, 0}
+	return &StorageReaderMaker{folder, relativePath, relativePath, TarFileType, 0, ""}
 }
 
 func NewRegularFileStorageReaderMarker(folder storage.Folder, storagePath, localPath string, fileMode int64) *StorageReaderMaker {
-	return &StorageReaderMaker{folder, storagePath, localPath, RegularFileType, fileMode}
+	return &StorageReaderMaker{folder, storagePath, localPath, RegularFileType, fileMode, ""}
 }
 
 func (readerMaker *StorageReaderMaker) StoragePath() string { return readerMaker.storagePath }
@@ -29,7 +32,11 @@ func (readerMaker *StorageReaderMaker) StoragePath() string { return readerMaker
 func (readerMaker *StorageReaderMaker) LocalPath() string { return readerMaker.localPath }
 
 func (readerMaker *StorageReaderMaker) Reader(ctx context.Context) (io.ReadCloser, error) {
-	return readerMaker.Folder.ReadObject(ctx, readerMaker.storagePath)
+	readCloser, err := readerMaker.Folder.ReadObject(ctx, readerMaker.storagePath)
+	if err != nil || readerMaker.ExpectedChecksum == "" {
+		return readCloser, err
+	}
+	return checksum.NewVerifyingReader(readCloser, readerMaker.storagePath, readerMaker.ExpectedChecksum), nil
 }
 
 func (readerMaker *StorageReaderMaker) FileType() FileType { return readerMaker.StorageFileType }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/compression/auto"
 	"github.com/wal-g/wal-g/internal/crypto"
 	"github.com/wal-g/wal-g/utility"
 )
@@ -23,8 +24,10 @@ type StorageTarBall struct {
 	partSize    atomic.Int64
 	writeCloser io.Closer
 	tarWriter   *tar.Writer
-	uploader    Uploader
-	name        string
+	// methodWriter is the compressing writer of the tarball compressed with the auto method
+	methodWriter *auto.Writer
+	uploader     Uploader
+	name         string
 }
 
 func (tarBall *StorageTarBall) Name() string {
@@ -117,8 +120,9 @@ func (tarBall *StorageTarBall) startUpload(ctx context.Context, name string, cry
 		writerToCompress = &utility.CascadeWriteCloser{WriteCloser: encryptedWriter, Underlying: pipeWriter}
 	}
 
-	return &utility.CascadeWriteCloser{WriteCloser: uploader.Compression().NewWriter(writerToCompress),
-		Underlying: writerToCompress}
+	compressingWriter := uploader.Compression().NewWriter(writerToCompress)
+	tarBall.methodWriter, _ = compressingWriter.(*auto.Writer)
+	return &utility.CascadeWriteCloser{WriteCloser: compressingWriter, Underlying: writerToCompress}
 }
 
 // Size accumulated in this tarball
@@ -128,3 +132,9 @@ func (tarBall *StorageTarBall) Size() int64 { return tarBall.partSize.Load() }
 func (tarBall *StorageTarBall) AddSize(i int64) { tarBall.partSize.Add(i) }
 
 func (tarBall *StorageTarBall) TarWriter() *tar.Writer { return tarBall.tarWriter }
+
+func (tarBall *StorageTarBall) MemberCompression() bool { return tarBall.methodWriter != nil }
+
+func (tarBall *StorageTarBall) SetMemberCompression(method string) error {
+	return tarBall.methodWriter.SetMethod(method)
+}
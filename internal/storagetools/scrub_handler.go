@@ -0,0 +1,226 @@
+package storagetools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/checksum"
+	"github.com/wal-g/wal-g/internal/compression"
+	"github.com/wal-g/wal-g/internal/crypto"
+	"github.com/wal-g/wal-g/internal/statistics"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+)
+
+type ScrubStatus string
+
+const (
+	ScrubStatusOk ScrubStatus = "ok"
+	// ScrubStatusUnverified means that the object is readable, but there is no checksum recorded for it
+	ScrubStatusUnverified ScrubStatus = "unverified"
+	ScrubStatusCorrupted  ScrubStatus = "corrupted"
+	ScrubStatusUnreadable ScrubStatus = "unreadable"
+)
+
+type ScrubObjectResult struct {
+	Path             string      `json:"path"`
+	Status           ScrubStatus `json:"status"`
+	Checksum         string      `json:"checksum,omitempty"`
+	ExpectedChecksum string      `json:"expected_checksum,omitempty"`
+	Error            string      `json:"error,omitempty"`
+}
+
+type ScrubReport struct {
+	Prefix        string              `json:"prefix"`
+	ObjectsTotal  int                 `json:"objects_total"`
+	BytesTotal    int64               `json:"bytes_total"`
+	StatusCounts  map[ScrubStatus]int `json:"status_counts"`
+	FailedObjects []ScrubObjectResult `json:"failed_objects"`
+}
+
+func (report *ScrubReport) Failed() bool {
+	return len(report.FailedObjects) > 0
+}
+
+// HandleScrub reads every object under the prefix, decrypts and decompresses the tar and stream objects
+// and compares the checksums of the stored bytes with the ones recorded at upload time.
+// The JSON report is written to the output, the error is returned if some objects are corrupted or unreadable.
+func HandleScrub(ctx context.Context, rootFolder storage.Folder, prefix string, crypter crypto.Crypter,
+	concurrency int, output io.Writer) error {
+	report, err := Scrub(ctx, rootFolder.GetSubFolder(prefix), crypter, concurrency)
+	if err != nil {
+		return err
+	}
+	report.Prefix = prefix
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "    ")
+	err = encoder.Encode(report)
+	if err != nil {
+		return err
+	}
+
+	if report.Failed() {
+		return fmt.Errorf("%d of %d objects failed the scrub", len(report.FailedObjects), report.ObjectsTotal)
+	}
+	return nil
+}
+
+func Scrub(ctx context.Context, folder storage.Folder, crypter crypto.Crypter, concurrency int) (*ScrubReport, error) {
+	objects, err := storage.ListFolderRecursively(ctx, folder)
+	if err != nil {
+		return nil, fmt.Errorf("list the folder: %w", err)
+	}
+
+	expectedChecksums, err := loadChecksumManifests(ctx, folder, objects)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ScrubReport{
+		StatusCounts:  make(map[ScrubStatus]int),
+		FailedObjects: make([]ScrubObjectResult, 0),
+	}
+	reportMutex := new(sync.Mutex)
+
+	jobsQueue := make(chan storage.Object, len(objects))
+	for _, object := range objects {
+		if checksum.IsManifest(object.GetName()) {
+			continue
+		}
+		jobsQueue <- object
+	}
+	close(jobsQueue)
+
+	workersWG := new(sync.WaitGroup)
+	for i := 0; i < max(concurrency, 1); i++ {
+		workersWG.Add(1)
+		go func() {
+			defer workersWG.Done()
+			for object := range jobsQueue {
+				result := scrubObject(ctx, folder, object, crypter, expectedChecksums[object.GetName()])
+				statistics.WalgMetrics.ScrubbedObjectsTotal.WithLabelValues(string(result.Status)).Inc()
+				statistics.WalgMetrics.ScrubbedBytesTotal.Add(float64(object.GetSize()))
+
+				reportMutex.Lock()
+				report.addResult(result, object.GetSize())
+				reportMutex.Unlock()
+			}
+		}()
+	}
+	workersWG.Wait()
+
+	slices.SortFunc(report.FailedObjects, func(a, b ScrubObjectResult) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return report, nil
+}
+
+func (report *ScrubReport) addResult(result ScrubObjectResult, size int64) {
+	report.ObjectsTotal++
+	report.BytesTotal += size
+	report.StatusCounts[result.Status]++
+	if result.Status == ScrubStatusCorrupted || result.Status == ScrubStatusUnreadable {
+		tracelog.ErrorLogger.Printf("Object %s is %s: %s\n", result.Path, result.Status, result.Error)
+		report.FailedObjects = append(report.FailedObjects, result)
+	}
+}
+
+// loadChecksumManifests reads all checksum manifests found among the objects
+// and returns the expected checksums by the object path relative to the folder
+func loadChecksumManifests(ctx context.Context, folder storage.Folder, objects []storage.Object) (map[string]string, error) {
+	expectedChecksums := make(map[string]string)
+	for _, object := range objects {
+		if !checksum.IsManifest(object.GetName()) {
+			continue
+		}
+		manifestReader, err := folder.ReadObject(ctx, object.GetName())
+		if err != nil {
+			return nil, fmt.Errorf("read the checksum manifest %s: %w", object.GetName(), err)
+		}
+		manifest := checksum.Manifest{}
+		err = json.NewDecoder(manifestReader).Decode(&manifest)
+		manifestReader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal the checksum manifest %s: %w", object.GetName(), err)
+		}
+		if manifest.Algorithm != checksum.CreateCalculator().Algorithm() {
+			tracelog.WarningLogger.Printf("Checksum manifest %s uses unsupported algorithm %q, skipping it\n",
+				object.GetName(), manifest.Algorithm)
+			continue
+		}
+
+		manifestDir := path.Dir(object.GetName())
+		for objectPath, objectChecksum := range manifest.Checksums {
+			expectedChecksums[path.Join(manifestDir, objectPath)] = objectChecksum
+		}
+	}
+	return expectedChecksums, nil
+}
+
+func scrubObject(ctx context.Context, folder storage.Folder, object storage.Object, crypter crypto.Crypter,
+	expectedChecksum string) ScrubObjectResult {
+	result := ScrubObjectResult{Path: object.GetName(), ExpectedChecksum: expectedChecksum}
+
+	calculator := checksum.CreateCalculator()
+	err := readStoredObject(ctx, folder, object.GetName(), crypter, calculator)
+	if err != nil {
+		result.Status = ScrubStatusUnreadable
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Checksum = calculator.Checksum()
+	switch {
+	case expectedChecksum == "":
+		result.Status = ScrubStatusUnverified
+	case expectedChecksum != result.Checksum:
+		result.Status = ScrubStatusCorrupted
+		result.Error = "checksum mismatch"
+	default:
+		result.Status = ScrubStatusOk
+	}
+	return result
+}
+
+// readStoredObject reads the whole object feeding the stored bytes to the calculator.
+// Compressed objects are decrypted and decompressed to make sure that they can be restored.
+func readStoredObject(ctx context.Context, folder storage.Folder, objectPath string, crypter crypto.Crypter,
+	calculator *checksum.Calculator) error {
+	objReadCloser, err := folder.ReadObject(ctx, objectPath)
+	if err != nil {
+		return err
+	}
+	defer objReadCloser.Close()
+	storedReader := checksum.CreateReaderWithChecksum(objReadCloser, calculator)
+
+	decompressor := compression.FindDecompressor(path.Ext(objectPath))
+	if decompressor != nil {
+		var objReader io.Reader = storedReader
+		if crypter != nil {
+			objReader, err = crypter.Decrypt(objReader)
+			if err != nil {
+				return fmt.Errorf("decrypt: %w", err)
+			}
+		}
+		decompressedReader, err := decompressor.Decompress(objReader)
+		if err != nil {
+			return fmt.Errorf("decompress: %w", err)
+		}
+		_, err = io.Copy(io.Discard, decompressedReader)
+		decompressedReader.Close()
+		if err != nil {
+			return fmt.Errorf("decompress: %w", err)
+		}
+	}
+
+	// the rest of the stored bytes (if any) are still needed for the checksum
+	_, err = io.Copy(io.Discard, storedReader)
+	return err
+}
@@ -0,0 +1,57 @@
+package storagetools
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/checksum"
+	"github.com/wal-g/wal-g/internal/compression/lz4"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+)
+
+func TestScrub(t *testing.T) {
+	folder := memory.NewFolder("test/", memory.NewKVS())
+
+	var compressed bytes.Buffer
+	writer := lz4.Compressor{}.NewWriter(&compressed)
+	_, err := writer.Write([]byte("some tar partition content"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	calculator := checksum.CreateCalculator()
+	calculator.AddData(compressed.Bytes())
+
+	manifest := checksum.NewManifest()
+	manifest.Checksums["part_1.tar.lz4"] = calculator.Checksum()
+	manifest.Checksums["part_2.tar.lz4"] = "0123456789"
+	manifestBytes, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	require.NoError(t, folder.PutObject(t.Context(), "backup/"+checksum.ManifestFileName, bytes.NewBuffer(manifestBytes)))
+	require.NoError(t, folder.PutObject(t.Context(), "backup/part_1.tar.lz4", bytes.NewBuffer(compressed.Bytes())))
+	require.NoError(t, folder.PutObject(t.Context(), "backup/part_2.tar.lz4", bytes.NewBuffer(compressed.Bytes())))
+	require.NoError(t, folder.PutObject(t.Context(), "backup/part_3.tar.lz4", bytes.NewBufferString("not an lz4 stream")))
+	require.NoError(t, folder.PutObject(t.Context(), "backup_sentinel.json", bytes.NewBufferString("{}")))
+
+	report, err := Scrub(t.Context(), folder, nil, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.ObjectsTotal)
+	assert.Equal(t, map[ScrubStatus]int{
+		ScrubStatusOk:         1,
+		ScrubStatusCorrupted:  1,
+		ScrubStatusUnreadable: 1,
+		ScrubStatusUnverified: 1,
+	}, report.StatusCounts)
+	require.Len(t, report.FailedObjects, 2)
+	assert.Equal(t, "backup/part_2.tar.lz4", report.FailedObjects[0].Path)
+	assert.Equal(t, ScrubStatusCorrupted, report.FailedObjects[0].Status)
+	assert.Equal(t, "backup/part_3.tar.lz4", report.FailedObjects[1].Path)
+	assert.Equal(t, ScrubStatusUnreadable, report.FailedObjects[1].Status)
+
+	var output bytes.Buffer
+	err = HandleScrub(t.Context(), folder, "backup", nil, 1, &output)
+	assert.Error(t, err)
+	assert.Contains(t, output.String(), `"prefix": "backup"`)
+}
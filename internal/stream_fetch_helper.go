@@ -7,11 +7,13 @@ import (
 	"path"
 	"time"
 
+	"github.com/spf13/viper"
 	"github.com/wal-g/tracelog"
 	"github.com/wal-g/wal-g/internal/compression"
 	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/ioextensions"
 	"github.com/wal-g/wal-g/internal/splitmerge"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
 )
 
@@ -41,8 +43,12 @@ func GetLogsDstSettings(operationLogsDstEnvVariable string) (dstFolder string, e
 func DownloadAndDecompressStream(ctx context.Context, backup Backup, writeCloser io.WriteCloser) error {
 	defer utility.LoggedClose(writeCloser, "")
 
+	folderReader, err := newStreamFolderReader(ctx, backup)
+	if err != nil {
+		return err
+	}
 	for _, decompressor := range compression.Decompressors {
-		archiveReader, exists, err := TryDownloadFile(ctx, NewFolderReader(backup.Folder),
+		archiveReader, exists, err := TryDownloadFile(ctx, folderReader,
 			GetStreamName(backup.Name, decompressor.FileExtension()))
 		if err != nil {
 			return fmt.Errorf("failed to dowload file: %w", err)
@@ -58,6 +64,7 @@ func DownloadAndDecompressStream(ctx context.Context, backup Backup, writeCloser
 			return fmt.Errorf("failed to decompress and decrypt file: %w", err)
 		}
 		defer utility.LoggedClose(decompressedReader, "")
+		decompressedReader = newDrainingReader(decompressedReader, archiveReader)
 
 		_, err = utility.FastCopy(&utility.EmptyWriteIgnorer{Writer: writeCloser}, decompressedReader)
 		if err != nil {
@@ -81,6 +88,10 @@ func DownloadAndDecompressSplittedStream(ctx context.Context, backup Backup, blo
 	if err != nil {
 		return err
 	}
+	folderReader, err := newStreamFolderReader(ctx, backup)
+	if err != nil {
+		return err
+	}
 
 	errorsPerWorker := make([]chan error, 0)
 	writers := splitmerge.MergeWriter(utility.EmptyWriteCloserIgnorer{WriteCloser: writeCloser}, len(files), blockSize)
@@ -93,7 +104,7 @@ func DownloadAndDecompressSplittedStream(ctx context.Context, backup Backup, blo
 		go func(files []string) {
 			defer close(errCh)
 			for _, fileName := range files {
-				err := downloadAndDecompressFile(ctx, backup, decompressor, fileName, writer, maxDownloadRetry)
+				err := downloadAndDecompressFile(ctx, folderReader, decompressor, fileName, writer, maxDownloadRetry)
 				if err != nil {
 					tracelog.ErrorLogger.PrintOnError(writer.Close())
 					errCh <- err
@@ -116,10 +127,24 @@ func DownloadAndDecompressSplittedStream(ctx context.Context, backup Backup, blo
 	return lastErr
 }
 
-func downloadAndDecompressFile(ctx context.Context, backup Backup, decompressor compression.Decompressor,
+func downloadAndDecompressFile(ctx context.Context, folderReader StorageFolderReader, decompressor compression.Decompressor,
 	fileName string, writer io.WriteCloser, maxDownloadRetry int) error {
+	decompressedReader, err := openDecompressedFile(ctx, folderReader, decompressor, fileName, maxDownloadRetry)
+	if err != nil {
+		return err
+	}
+	defer utility.LoggedClose(decompressedReader, "")
+	_, err = utility.FastCopy(writer, decompressedReader)
+	if err != nil {
+		return fmt.Errorf("failed to decompress/decrypt/pipe file %v: %w", fileName, err)
+	}
+	return nil
+}
+
+func openDecompressedFile(ctx context.Context, folderReader StorageFolderReader, decompressor compression.Decompressor,
+	fileName string, maxDownloadRetry int) (io.ReadCloser, error) {
 	getArchiveReader := func() (io.ReadCloser, error) {
-		archiveReader, exists, err := TryDownloadFile(ctx, NewFolderReader(backup.Folder), fileName)
+		archiveReader, exists, err := TryDownloadFile(ctx, folderReader, fileName)
 		if err != nil {
 			return nil, fmt.Errorf("failed to dowload file %v: %w", fileName, err)
 		} else if !exists {
@@ -135,22 +160,155 @@ func downloadAndDecompressFile(ctx context.Context, backup Backup, decompressor
 	} else {
 		reader, err := getArchiveReader()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		archiveReader = reader
 	}
 	decompressedReader, err := DecompressDecryptBytes(archiveReader, decompressor)
 	if err != nil {
-		return fmt.Errorf("failed to decompress/decrypt file %v: %w", fileName, err)
+		return nil, fmt.Errorf("failed to decompress/decrypt file %v: %w", fileName, err)
 	}
-	defer utility.LoggedClose(decompressedReader, "")
-	_, err = utility.FastCopy(writer, decompressedReader)
-	if err != nil {
-		return fmt.Errorf("failed to decompress/decrypt/pipe file %v: %w", fileName, err)
+	return newDrainingReader(decompressedReader, archiveReader), nil
+}
+
+// newStreamFolderReader returns the reader of the backup stream objects. If WALG_VERIFY_CHECKSUMS is set
+// and the backup has the checksum manifest, the objects are verified against the checksums recorded at upload time.
+func newStreamFolderReader(ctx context.Context, backup Backup) (StorageFolderReader, error) {
+	folderReader := NewFolderReader(backup.Folder)
+	if !viper.GetBool(conf.VerifyChecksumsSetting) {
+		return folderReader, nil
+	}
+	manifest, err := backup.FetchChecksumManifest(ctx)
+	if err != nil || manifest == nil {
+		return folderReader, err
+	}
+	tracelog.InfoLogger.Printf("Stream of backup %s will be verified against the recorded checksums\n", backup.Name)
+	// the manifest paths are relative to the backup folder
+	checksums := make(map[string]string, len(manifest.Checksums))
+	for objectPath, objectChecksum := range manifest.Checksums {
+		checksums[storage.JoinPath(backup.Name, objectPath)] = objectChecksum
+	}
+	return &ChecksumVerifyingFolderReader{StorageFolderReader: folderReader, checksums: checksums}, nil
+}
+
+// drainingReader reads the rest of the stored object after the end of the decompressed data,
+// so the checksum of the whole object is verified
+type drainingReader struct {
+	io.ReadCloser
+	archiveReader io.Reader
+}
+
+func newDrainingReader(decompressedReader io.ReadCloser, archiveReader io.Reader) io.ReadCloser {
+	return &drainingReader{ReadCloser: decompressedReader, archiveReader: archiveReader}
+}
+
+func (reader *drainingReader) Read(p []byte) (int, error) {
+	n, err := reader.ReadCloser.Read(p)
+	if err == io.EOF {
+		if _, drainErr := io.Copy(io.Discard, reader.archiveReader); drainErr != nil {
+			return n, drainErr
+		}
+	}
+	return n, err
+}
+
+// StreamRange is the byte range [Start, End) of the stream before it is split
+type StreamRange struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// DownloadSplittedStreamRanges writes the ranges of the stream split into the files of the max file size.
+// Only the files which contain the ranges are downloaded and decompressed, the ranges have to be sorted.
+func DownloadSplittedStreamRanges(ctx context.Context, backup Backup, metadata BackupStreamMetadata,
+	ranges []StreamRange, writeCloser io.WriteCloser, maxDownloadRetry int) error {
+	defer utility.LoggedClose(writeCloser, "")
+
+	if metadata.Type != SplitMergeStreamBackup || metadata.MaxFileSize == 0 {
+		return fmt.Errorf("the stream of backup '%s' is not split into files", backup.Name)
+	}
+	decompressor := compression.FindDecompressor(metadata.Compression)
+	if decompressor == nil {
+		return fmt.Errorf("decompressor for file type '%s' not found", metadata.Compression)
+	}
+
+	partitions := make([]*partitionRangeReader, metadata.Partitions)
+	for i := range partitions {
+		partitions[i] = &partitionRangeReader{
+			backup:           backup,
+			decompressor:     decompressor,
+			partition:        i,
+			fileSize:         int64(metadata.MaxFileSize),
+			maxDownloadRetry: maxDownloadRetry,
+		}
+		defer partitions[i].close()
+	}
+
+	blockSize := int64(metadata.BlockSize)
+	for _, streamRange := range ranges {
+		for offset := streamRange.Start; offset < streamRange.End; {
+			// the blocks are distributed over the partitions round-robin
+			block := offset / blockSize
+			length := min(streamRange.End, (block+1)*blockSize) - offset
+			partition := partitions[block%int64(len(partitions))]
+			partitionOffset := block/int64(len(partitions))*blockSize + offset%blockSize
+			err := partition.copyRange(ctx, writeCloser, partitionOffset, length)
+			if err != nil {
+				return err
+			}
+			offset += length
+		}
+	}
+	return nil
+}
+
+// partitionRangeReader reads the partition of the split stream sequentially,
+// opening the file of the partition which contains the requested offset
+type partitionRangeReader struct {
+	backup           Backup
+	decompressor     compression.Decompressor
+	partition        int
+	fileSize         int64
+	maxDownloadRetry int
+
+	fileIdx  int64
+	reader   io.ReadCloser
+	position int64
+}
+
+func (p *partitionRangeReader) copyRange(ctx context.Context, writer io.Writer, offset, length int64) error {
+	for length > 0 {
+		fileIdx := offset / p.fileSize
+		if p.reader == nil || fileIdx != p.fileIdx || offset < p.position {
+			p.close()
+			fileName := GetPartitionedSteamMultipartName(p.backup.Name, p.decompressor.FileExtension(), p.partition, int(fileIdx))
+			reader, err := openDecompressedFile(ctx, NewFolderReader(p.backup.Folder), p.decompressor, fileName, p.maxDownloadRetry)
+			if err != nil {
+				return err
+			}
+			p.fileIdx, p.reader, p.position = fileIdx, reader, fileIdx*p.fileSize
+		}
+		if _, err := io.CopyN(io.Discard, p.reader, offset-p.position); err != nil {
+			return fmt.Errorf("failed to skip to offset %d of partition %d: %w", offset, p.partition, err)
+		}
+		n := min(length, (fileIdx+1)*p.fileSize-offset)
+		if _, err := io.CopyN(writer, p.reader, n); err != nil {
+			return fmt.Errorf("failed to read offset %d of partition %d: %w", offset, p.partition, err)
+		}
+		p.position = offset + n
+		offset += n
+		length -= n
 	}
 	return nil
 }
 
+func (p *partitionRangeReader) close() {
+	if p.reader != nil {
+		utility.LoggedClose(p.reader, "")
+		p.reader = nil
+	}
+}
+
 func GetPartitionedBackupFileNames(ctx context.Context, backup Backup, decompressor compression.Decompressor) ([][]string, error) {
 	// list all files in backup folder:
 	files, _, err := backup.Folder.GetSubFolder(backup.Name).ListFolder(ctx)
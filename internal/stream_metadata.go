@@ -21,6 +21,7 @@ type BackupStreamMetadata struct {
 	Partitions  uint   `json:"partitions,omitempty"`
 	BlockSize   uint   `json:"block_size,omitempty"`
 	Compression string `json:"compression,omitempty"`
+	MaxFileSize uint   `json:"max_file_size,omitempty"`
 }
 
 func GetBackupStreamFetcher(ctx context.Context, backup Backup) (StreamFetcher, error) {
@@ -8,7 +8,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/spf13/viper"
 	"github.com/wal-g/tracelog"
+	conf "github.com/wal-g/wal-g/internal/config"
 	"github.com/wal-g/wal-g/internal/splitmerge"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/utility"
@@ -28,10 +30,35 @@ func (uploader *RegularUploader) PushStream(ctx context.Context, stream io.Reade
 
 // PushStreamWithName compresses a stream and uploads it under the supplied backup name.
 func (uploader *RegularUploader) PushStreamWithName(ctx context.Context, stream io.Reader, backupName string) (string, error) {
+	streamUploader := newStreamChecksumsUploader(uploader)
 	dstPath := GetStreamName(backupName, uploader.Compressor.FileExtension())
-	err := uploader.PushStreamToDestination(ctx, stream, dstPath)
+	err := streamUploader.PushStreamToDestination(ctx, stream, dstPath)
+	if err != nil {
+		return backupName, err
+	}
 
-	return backupName, err
+	return backupName, uploadStreamChecksumManifest(ctx, streamUploader, backupName)
+}
+
+// newStreamChecksumsUploader returns the clone of the uploader recording the checksums of the stream objects
+// if WALG_UPLOAD_CHECKSUMS is set, the uploader is returned as is otherwise
+func newStreamChecksumsUploader(uploader Uploader) Uploader {
+	if !viper.GetBool(conf.UploadChecksumsSetting) {
+		return uploader
+	}
+	streamUploader := uploader.Clone()
+	streamUploader.EnableChecksums()
+	return streamUploader
+}
+
+// uploadStreamChecksumManifest uploads the checksums of the stream objects to the backup folder
+func uploadStreamChecksumManifest(ctx context.Context, uploader Uploader, backupName string) error {
+	if uploader.Checksums() == nil {
+		return nil
+	}
+	manifestUploader := uploader.Clone()
+	manifestUploader.DisableSizeTracking() // don't count checksums.json in backup size
+	return UploadChecksumManifest(ctx, manifestUploader, backupName)
 }
 
 // TODO : unit tests
@@ -43,6 +70,7 @@ func (uploader *SplitStreamUploader) PushStream(ctx context.Context, stream io.R
 
 // PushStreamWithName splits, compresses, and uploads a stream under the supplied backup name.
 func (uploader *SplitStreamUploader) PushStreamWithName(ctx context.Context, stream io.Reader, backupName string) (string, error) {
+	streamUploader := newStreamChecksumsUploader(uploader)
 	// Upload Stream:
 	errGroup, egCtx := errgroup.WithContext(ctx)
 	var readers = splitmerge.SplitReader(egCtx, stream, uploader.partitions, uploader.blockSize)
@@ -59,12 +87,13 @@ func (uploader *SplitStreamUploader) PushStreamWithName(ctx context.Context, str
 
 					tracelog.DebugLogger.Printf("Get file reader %d of part %d\n", idx, currentPartNumber)
 					dstPath := GetPartitionedSteamMultipartName(backupName, uploader.Compression().FileExtension(), currentPartNumber, idx)
-					err := uploader.PushStreamToDestination(egCtx, fileReader, dstPath)
+					err := streamUploader.PushStreamToDestination(egCtx, fileReader, dstPath)
 					if err != nil {
 						return err
 					}
 					if read.Load() == 0 {
 						err = uploader.Folder().DeleteObjects(egCtx, []storage.Object{storage.NewLocalObject(dstPath, time.Time{}, 0)})
+						forgetChecksum(streamUploader, dstPath)
 						return err
 					}
 					idx++
@@ -73,7 +102,7 @@ func (uploader *SplitStreamUploader) PushStreamWithName(ctx context.Context, str
 		} else {
 			dstPath := GetPartitionedStreamName(backupName, uploader.Compression().FileExtension(), partNumber)
 			errGroup.Go(func() error {
-				return uploader.PushStreamToDestination(egCtx, reader, dstPath)
+				return streamUploader.PushStreamToDestination(egCtx, reader, dstPath)
 			})
 		}
 	}
@@ -84,12 +113,17 @@ func (uploader *SplitStreamUploader) PushStreamWithName(ctx context.Context, str
 		return backupName, err
 	}
 
+	if err := uploadStreamChecksumManifest(ctx, streamUploader, backupName); err != nil {
+		return backupName, err
+	}
+
 	// Upload StreamMetadata
 	meta := BackupStreamMetadata{
 		Type:        SplitMergeStreamBackup,
 		Partitions:  uint(uploader.partitions),
 		BlockSize:   uint(uploader.blockSize),
 		Compression: uploader.Compression().FileExtension(),
+		MaxFileSize: uint(uploader.maxFileSize),
 	}
 	uploaderClone := uploader.Clone()
 	uploaderClone.DisableSizeTracking() // don't count metadata.json in backup size
@@ -111,6 +145,13 @@ func (uploader *RegularUploader) PushStreamToDestination(ctx context.Context, st
 	return err
 }
 
+// forgetChecksum removes the checksum of the deleted object, so it does not get into the manifest
+func forgetChecksum(uploader Uploader, dstPath string) {
+	if recorder := uploader.Checksums(); recorder != nil {
+		recorder.Remove(storage.JoinPath(uploader.Folder().GetPath(), dstPath))
+	}
+}
+
 func GetStreamName(backupName string, extension string) string {
 	return utility.AddFileExtension(utility.SanitizePath(path.Join(backupName, "stream")), extension)
 }
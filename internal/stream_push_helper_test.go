@@ -3,16 +3,22 @@ package internal_test
 import (
 	"bytes"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/wal-g/tracelog"
 	. "github.com/wal-g/wal-g/internal"
 	"github.com/wal-g/wal-g/internal/compression"
 	functests "github.com/wal-g/wal-g/internal/testutils"
 	"github.com/wal-g/wal-g/pkg/storages/fs"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
 	"github.com/wal-g/wal-g/pkg/storages/storage"
 	"github.com/wal-g/wal-g/testtools"
 )
@@ -165,6 +171,47 @@ func TestSplitBackup_Retry_NetworkError(t *testing.T) {
 	checkPushAndFetchBackup(t, 3, 1009, 100*1000, 30*1000, 5, 1000*1000)
 }
 
+func TestSplitBackup_DownloadRanges(t *testing.T) {
+	storageFolder := memory.NewFolder("", memory.NewKVS())
+	compressor := compression.Compressors[compression.CompressingAlgorithms[0]]
+	uploader := NewSplitStreamUploader(NewRegularUploader(compressor, storageFolder), 3, 10, 20)
+
+	sample := getByteSampleArray(600)
+	backupName, err := uploader.PushStream(t.Context(), bytes.NewReader(sample))
+	require.NoError(t, err)
+
+	var metadata BackupStreamMetadata
+	err = FetchDto(t.Context(), storageFolder, &metadata, StreamMetadataNameFromBackup(backupName))
+	require.NoError(t, err)
+	require.Equal(t, uint(20), metadata.MaxFileSize)
+
+	// the ranges are in the first block and in the last one: only the first file of the first partition
+	// and the last file of the third partition are needed
+	extension := compressor.FileExtension()
+	needed := []string{
+		GetPartitionedSteamMultipartName(backupName, extension, 0, 0),
+		GetPartitionedSteamMultipartName(backupName, extension, 2, 9),
+	}
+	objects, err := storage.ListFolderRecursively(t.Context(), storageFolder.GetSubFolder(backupName))
+	require.NoError(t, err)
+	for _, object := range objects {
+		objectPath := path.Join(backupName, object.GetName())
+		if strings.Contains(objectPath, "part_") && !slices.Contains(needed, objectPath) {
+			err = storageFolder.DeleteObjects(t.Context(), []storage.Object{storage.NewLocalObject(objectPath, time.Time{}, 0)})
+			require.NoError(t, err)
+		}
+	}
+
+	writer := newTestWriter()
+	backup := Backup{Name: backupName, Folder: storageFolder}
+	ranges := []StreamRange{{Start: 2, End: 7}, {Start: 592, End: 600}}
+	err = DownloadSplittedStreamRanges(t.Context(), backup, metadata, ranges, writer, 0)
+	require.NoError(t, err)
+	<-writer.CloseNotify
+
+	assert.Equal(t, append(slices.Clone(sample[2:7]), sample[592:600]...), writer.Result)
+}
+
 func GetS3Folder(networkErrorAfterByteSize int) (storage.Folder, func() error, error) {
 	cwd, err := filepath.Abs("./")
 	if err != nil {
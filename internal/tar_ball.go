@@ -2,10 +2,13 @@ package internal
 
 import (
 	"archive/tar"
+	"bufio"
 	"context"
 	"io"
 
 	"github.com/pkg/errors"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/compression/auto"
 	"github.com/wal-g/wal-g/internal/crypto"
 )
 
@@ -20,7 +23,34 @@ type TarBall interface {
 	Name() string
 }
 
+// MemberCompressionTarBall is the tarball compressed with the auto method,
+// it compresses every packed file with the method chosen by the content of the file
+type MemberCompressionTarBall interface {
+	TarBall
+	// MemberCompression tells whether the compression method is chosen for every packed file
+	MemberCompression() bool
+	// SetMemberCompression compresses the data written next with the method
+	SetMemberCompression(method string) error
+}
+
+// PackFileTo packs the file into the tarball. If the tarball chooses the compression method for every file,
+// the method is chosen by the first bytes of the file and is written to the frame header in front of the file,
+// so the extraction finds it there.
 func PackFileTo(tarBall TarBall, fileInfoHeader *tar.Header, fileContent io.Reader) (fileSize int64, err error) {
+	if memberTarBall, ok := tarBall.(MemberCompressionTarBall); ok && memberTarBall.MemberCompression() {
+		sampleReader := bufio.NewReaderSize(fileContent, auto.SampleSize)
+		sample, err := sampleReader.Peek(auto.SampleSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, errors.Wrap(err, "PackFileTo: failed to read sample")
+		}
+		method := auto.ChooseMethod(sample)
+		tracelog.DebugLogger.Printf("Compression method %s is chosen for %s", method, fileInfoHeader.Name)
+		err = memberTarBall.SetMemberCompression(method)
+		if err != nil {
+			return 0, errors.Wrap(err, "PackFileTo: failed to set compression method")
+		}
+		fileContent = sampleReader
+	}
 	tarWriter := tarBall.TarWriter()
 	err = tarWriter.WriteHeader(fileInfoHeader)
 	if err != nil {
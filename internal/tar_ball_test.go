@@ -3,13 +3,17 @@ package internal_test
 import (
 	"archive/tar"
 	"bytes"
+	"crypto/rand"
 	"io"
 	"strings"
 	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/wal-g/wal-g/internal"
+	"github.com/wal-g/wal-g/internal/compression/auto"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
 	"github.com/wal-g/wal-g/testtools"
 )
 
@@ -110,3 +114,44 @@ func TestPackFileTo(t *testing.T) {
 	}
 	assert.Equal(t, []byte(mockData), interpreter.Out)
 }
+
+func TestPackFileTo_AutoCompression(t *testing.T) {
+	folder := memory.NewFolder("", memory.NewKVS())
+	uploader := internal.NewRegularUploader(auto.Compressor{}, folder)
+	tarBall := internal.NewStorageTarBallMaker("backup", uploader).Make(false)
+	tarBall.SetUp(t.Context(), nil)
+
+	randomData := make([]byte, auto.SampleSize)
+	_, err := rand.Read(randomData)
+	require.NoError(t, err)
+	files := map[string][]byte{
+		"text":   []byte(strings.Repeat("How much wood could a woodchuck chuck? ", 10000)),
+		"random": randomData,
+		"empty":  nil,
+	}
+	for _, name := range []string{"text", "random", "empty"} {
+		header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(files[name])), Typeflag: tar.TypeReg}
+		_, err := internal.PackFileTo(tarBall, header, bytes.NewReader(files[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarBall.CloseTar())
+	require.NoError(t, tarBall.AwaitUploads())
+
+	object, err := folder.ReadObject(t.Context(), internal.GetBackupTarPath("backup", tarBall.Name()))
+	require.NoError(t, err)
+	decompressed, err := auto.Decompressor{}.Decompress(object)
+	require.NoError(t, err)
+	reader := tar.NewReader(decompressed)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(files[header.Name], content), header.Name)
+		delete(files, header.Name)
+	}
+	assert.Empty(t, files)
+}
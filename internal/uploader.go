@@ -10,6 +10,7 @@ import (
 	"sync/atomic"
 
 	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/checksum"
 	"github.com/wal-g/wal-g/internal/compression"
 	"github.com/wal-g/wal-g/internal/ioextensions"
 	"github.com/wal-g/wal-g/internal/statistics"
@@ -34,6 +35,10 @@ type Uploader interface {
 	// Compression returns configured compressor
 	Compression() compression.Compressor
 	DisableSizeTracking()
+	// EnableChecksums makes the uploader record the checksums of the uploaded bytes
+	EnableChecksums()
+	// Checksums returns the recorded checksums or nil if they are not enabled
+	Checksums() *checksum.Recorder
 	UploadedDataSize() (int64, error)
 	RawDataSize() (int64, error)
 	ChangeDirectory(relativePath string)
@@ -52,6 +57,7 @@ type RegularUploader struct {
 	failed          atomic.Bool
 	tarSize         *atomic.Int64
 	dataSize        *atomic.Int64
+	checksums       *checksum.Recorder
 }
 
 var _ Uploader = &RegularUploader{}
@@ -142,6 +148,7 @@ func (uploader *RegularUploader) Clone() Uploader {
 		failed:          atomic.Bool{},
 		tarSize:         uploader.tarSize,
 		dataSize:        uploader.dataSize,
+		checksums:       uploader.checksums,
 	}
 	clone.failed.Store(uploader.Failed())
 	return clone
@@ -183,6 +190,17 @@ func (uploader *RegularUploader) DisableSizeTracking() {
 	uploader.dataSize = nil
 }
 
+// EnableChecksums makes the uploader and its clones record the checksums of the uploaded bytes
+func (uploader *RegularUploader) EnableChecksums() {
+	if uploader.checksums == nil {
+		uploader.checksums = checksum.NewRecorder()
+	}
+}
+
+func (uploader *RegularUploader) Checksums() *checksum.Recorder {
+	return uploader.checksums
+}
+
 // Compression returns configured compressor
 func (uploader *RegularUploader) Compression() compression.Compressor {
 	return uploader.Compressor
@@ -196,6 +214,11 @@ func (uploader *RegularUploader) Upload(ctx context.Context, path string, conten
 	if uploader.tarSize != nil {
 		content = utility.NewWithSizeReader(content, uploader.tarSize)
 	}
+	var calculator *checksum.Calculator
+	if uploader.checksums != nil {
+		calculator = checksum.CreateCalculator()
+		content = checksum.CreateReaderWithChecksum(content, calculator)
+	}
 	err := uploader.UploadingFolder.PutObject(ctx, path, content)
 	if err != nil {
 		statistics.WalgMetrics.UploadedFilesFailedTotal.Inc()
@@ -203,6 +226,9 @@ func (uploader *RegularUploader) Upload(ctx context.Context, path string, conten
 		tracelog.ErrorLogger.Printf(tracelog.GetErrorFormatter()+"\n", err)
 		return err
 	}
+	if calculator != nil {
+		uploader.checksums.Record(storage.JoinPath(uploader.UploadingFolder.GetPath(), path), calculator.Checksum())
+	}
 	return nil
 }
 
@@ -258,8 +284,9 @@ func (uploader *RegularUploader) Failed() bool {
 
 func (uploader *SplitStreamUploader) Clone() Uploader {
 	return &SplitStreamUploader{
-		Uploader:   uploader.Uploader.Clone(),
-		partitions: uploader.partitions,
-		blockSize:  uploader.blockSize,
+		Uploader:    uploader.Uploader.Clone(),
+		partitions:  uploader.partitions,
+		blockSize:   uploader.blockSize,
+		maxFileSize: uploader.maxFileSize,
 	}
 }
@@ -0,0 +1,223 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"github.com/wal-g/tracelog"
+	"github.com/wal-g/wal-g/internal/checksum"
+	"github.com/wal-g/wal-g/internal/compression"
+	zstdcompression "github.com/wal-g/wal-g/internal/compression/zstd"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/multistorage"
+	"github.com/wal-g/wal-g/internal/multistorage/consts"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+)
+
+const (
+	ZstdDictionaryExtension  = "dict"
+	LatestZstdDictionaryName = "latest.json"
+	LatestZstdDictionary     = "LATEST"
+)
+
+// ZstdDictionaryMeta describes the last trained dictionary
+type ZstdDictionaryMeta struct {
+	ID           uint32    `json:"ID"`
+	CreatedTime  time.Time `json:"CreatedTime"`
+	SamplesCount int       `json:"SamplesCount"`
+	SourcePath   string    `json:"SourcePath"`
+}
+
+func zstdDictionaryName(id uint32) string {
+	return utility.AddFileExtension(strconv.FormatUint(uint64(id), 10), ZstdDictionaryExtension)
+}
+
+// TrainZstdDictionary trains the zstd dictionary on the beginnings of the last compressed objects under the prefix
+// and uploads it to the dictionaries folder. The dictionaries are never overwritten, so the objects compressed
+// with the previous ones remain readable.
+func TrainZstdDictionary(ctx context.Context, rootFolder storage.Folder, prefix string,
+	maxSamples int, sampleSize int64, dictionarySize int) (ZstdDictionaryMeta, error) {
+	objects, err := storage.ListFolderRecursivelyWithPrefix(ctx, rootFolder, prefix)
+	if err != nil {
+		return ZstdDictionaryMeta{}, errors.Wrapf(err, "failed to list the objects in %q", prefix)
+	}
+	objects = slices.DeleteFunc(objects, func(object storage.Object) bool {
+		return checksum.IsManifest(object.GetName()) ||
+			strings.HasPrefix(object.GetName(), utility.ZstdDictsPath) ||
+			compression.FindDecompressor(utility.GetFileExtension(object.GetName())) == nil
+	})
+	slices.SortFunc(objects, func(a, b storage.Object) int {
+		return b.GetLastModified().Compare(a.GetLastModified())
+	})
+	if len(objects) > maxSamples {
+		objects = objects[:maxSamples]
+	}
+
+	samples := make([][]byte, 0, len(objects))
+	for _, object := range objects {
+		sample, err := readZstdDictionarySample(ctx, rootFolder, object.GetName(), sampleSize)
+		if err != nil {
+			return ZstdDictionaryMeta{}, err
+		}
+		if len(sample) > 0 {
+			samples = append(samples, sample)
+		}
+	}
+	if len(samples) == 0 {
+		return ZstdDictionaryMeta{}, fmt.Errorf("no compressed objects to train the dictionary on found in %q", prefix)
+	}
+	tracelog.InfoLogger.Printf("Training the zstd dictionary on %d objects\n", len(samples))
+
+	dictionary, err := zstdcompression.TrainDictionary(samples, dictionarySize)
+	if err != nil {
+		return ZstdDictionaryMeta{}, errors.Wrap(err, "failed to train the zstd dictionary")
+	}
+	id, err := zstdcompression.DictionaryID(dictionary)
+	if err != nil {
+		return ZstdDictionaryMeta{}, err
+	}
+
+	dictionariesFolder := rootFolder.GetSubFolder(utility.ZstdDictsPath)
+	err = dictionariesFolder.PutObject(ctx, zstdDictionaryName(id),
+		CompressAndEncrypt(bytes.NewReader(dictionary), nil, ConfigureCrypter()))
+	if err != nil {
+		return ZstdDictionaryMeta{}, errors.Wrapf(err, "failed to upload the zstd dictionary %d", id)
+	}
+	meta := ZstdDictionaryMeta{
+		ID:           id,
+		CreatedTime:  utility.TimeNowCrossPlatformUTC(),
+		SamplesCount: len(samples),
+		SourcePath:   prefix,
+	}
+	err = UploadDto(ctx, dictionariesFolder, meta, LatestZstdDictionaryName)
+	if err != nil {
+		return ZstdDictionaryMeta{}, errors.Wrap(err, "failed to upload the latest zstd dictionary metadata")
+	}
+	return meta, nil
+}
+
+func readZstdDictionarySample(ctx context.Context, rootFolder storage.Folder, objectName string, size int64) ([]byte, error) {
+	reader, err := rootFolder.ReadObject(ctx, objectName)
+	if err != nil {
+		return nil, err
+	}
+	defer utility.LoggedClose(reader, "")
+
+	decompressor := compression.FindDecompressor(utility.GetFileExtension(objectName))
+	decompressedReader, err := DecompressDecryptBytes(reader, decompressor)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decompress %s", objectName)
+	}
+	defer utility.LoggedClose(decompressedReader, "")
+
+	sample, err := io.ReadAll(io.LimitReader(decompressedReader, size))
+	return sample, errors.Wrapf(err, "failed to read %s", objectName)
+}
+
+// LoadZstdDictionary downloads the zstd dictionary with the ID
+func LoadZstdDictionary(ctx context.Context, rootFolder storage.Folder, id uint32) ([]byte, error) {
+	dictionariesFolder := rootFolder.GetSubFolder(utility.ZstdDictsPath)
+	reader, err := dictionariesFolder.ReadObject(ctx, zstdDictionaryName(id))
+	if err != nil {
+		return nil, err
+	}
+	defer utility.LoggedClose(reader, "")
+
+	decryptedReader, err := DecryptBytes(reader)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(decryptedReader)
+}
+
+// ConfigureZstdDictionary makes the zstd compressor of the uploader use the dictionary set by WALG_ZSTD_DICTIONARY
+func ConfigureZstdDictionary(ctx context.Context, rootFolder storage.Folder, uploader *RegularUploader) error {
+	dictionaryName := viper.GetString(conf.ZstdDictionarySetting)
+	if dictionaryName == "" {
+		return nil
+	}
+	zstdCompressor, ok := uploader.Compressor.(zstdcompression.Compressor)
+	if !ok {
+		return fmt.Errorf("%s is set but the compression method is not '%s'",
+			conf.ZstdDictionarySetting, zstdcompression.AlgorithmName)
+	}
+
+	var id uint32
+	if strings.EqualFold(dictionaryName, LatestZstdDictionary) {
+		var meta ZstdDictionaryMeta
+		err := FetchDto(ctx, rootFolder.GetSubFolder(utility.ZstdDictsPath), &meta, LatestZstdDictionaryName)
+		if err != nil {
+			return errors.Wrap(err, "failed to find the latest zstd dictionary")
+		}
+		id = meta.ID
+	} else {
+		parsedID, err := strconv.ParseUint(dictionaryName, 10, 32)
+		if err != nil {
+			return fmt.Errorf("%s must be either %s or the dictionary ID: %w",
+				conf.ZstdDictionarySetting, LatestZstdDictionary, err)
+		}
+		id = uint32(parsedID)
+	}
+
+	dictionary, err := LoadZstdDictionary(ctx, rootFolder, id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load the zstd dictionary %d", id)
+	}
+	tracelog.DebugLogger.Printf("Compressing with the zstd dictionary %d", id)
+	zstdCompressor.Dictionary = dictionary
+	uploader.Compressor = zstdCompressor
+	return nil
+}
+
+// zstdDictionaryFolders are the root folders of the configured storages by the storage names
+var zstdDictionaryFolders sync.Map
+
+// setZstdDictionaryFolder registers the root folder the dictionaries of the storage objects are loaded from.
+// The default storage is used unless the object is known to be read from another one.
+func setZstdDictionaryFolder(storageName string, rootFolder storage.Folder) {
+	zstdDictionaryFolders.Store(storageName, rootFolder)
+	if storageName == consts.DefaultStorage {
+		zstdcompression.SetDictionaryLoader(newZstdDictionaryLoader(rootFolder))
+	}
+}
+
+func newZstdDictionaryLoader(rootFolder storage.Folder) zstdcompression.DictionaryLoader {
+	return func(id uint32) ([]byte, error) {
+		return LoadZstdDictionary(context.Background(), rootFolder, id)
+	}
+}
+
+// withZstdDictionaryFolder makes the zstd decompressor load the dictionaries of the decrypted stream
+// from the storage the archive is read from, falling back to the default storage
+func withZstdDictionaryFolder(decryptedReader io.Reader, archiveReader io.Reader) io.Reader {
+	teller, ok := archiveReader.(multistorage.StorageTeller)
+	if !ok || teller.GetStorage() == consts.DefaultStorage {
+		return decryptedReader
+	}
+	rootFolder, ok := zstdDictionaryFolders.Load(teller.GetStorage())
+	if !ok {
+		return decryptedReader
+	}
+	loadFromStorage := newZstdDictionaryLoader(rootFolder.(storage.Folder))
+	return zstdcompression.WithDictionaryLoader(decryptedReader, func(id uint32) ([]byte, error) {
+		dictionary, err := loadFromStorage(id)
+		if _, notFound := errors.Cause(err).(storage.ObjectNotFoundError); !notFound {
+			return dictionary, err
+		}
+		defaultFolder, ok := zstdDictionaryFolders.Load(consts.DefaultStorage)
+		if !ok {
+			return nil, err
+		}
+		return LoadZstdDictionary(context.Background(), defaultFolder.(storage.Folder), id)
+	})
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wal-g/wal-g/internal/compression"
+	zstdcompression "github.com/wal-g/wal-g/internal/compression/zstd"
+	conf "github.com/wal-g/wal-g/internal/config"
+	"github.com/wal-g/wal-g/internal/multistorage"
+	"github.com/wal-g/wal-g/internal/multistorage/consts"
+	"github.com/wal-g/wal-g/internal/multistorage/stats"
+	"github.com/wal-g/wal-g/pkg/storages/memory"
+	"github.com/wal-g/wal-g/pkg/storages/storage"
+	"github.com/wal-g/wal-g/utility"
+	"go.uber.org/mock/gomock"
+)
+
+func uploadZstdDictionaryTestSegments(t *testing.T, uploader *RegularUploader) {
+	for i := 0; i < 100; i++ {
+		var segment bytes.Buffer
+		for j := 0; j < 50; j++ {
+			fmt.Fprintf(&segment, "UPDATE accounts SET balance = %d WHERE id = %d;\n", i, i*50+j)
+		}
+		err := uploader.Upload(t.Context(), fmt.Sprintf("%08d.zst", i), CompressAndEncrypt(&segment, uploader.Compressor, nil))
+		require.NoError(t, err)
+	}
+}
+
+func TestTrainZstdDictionary(t *testing.T) {
+	folder := memory.NewFolder("", memory.NewKVS())
+	uploader := NewRegularUploader(zstdcompression.Compressor{}, folder.GetSubFolder(utility.WalPath))
+	uploadZstdDictionaryTestSegments(t, uploader)
+
+	meta, err := TrainZstdDictionary(t.Context(), folder, utility.WalPath, 64, 1<<20, 4096)
+	require.NoError(t, err)
+	assert.Equal(t, 64, meta.SamplesCount)
+
+	viper.Set(conf.ZstdDictionarySetting, LatestZstdDictionary)
+	defer viper.Set(conf.ZstdDictionarySetting, "")
+	err = ConfigureZstdDictionary(t.Context(), folder, uploader)
+	require.NoError(t, err)
+	dictionary := uploader.Compressor.(zstdcompression.Compressor).Dictionary
+	id, err := zstdcompression.DictionaryID(dictionary)
+	require.NoError(t, err)
+	assert.Equal(t, meta.ID, id)
+
+	setZstdDictionaryFolder(consts.DefaultStorage, folder)
+	defer zstdcompression.SetDictionaryLoader(nil)
+	content := []byte("UPDATE accounts SET balance = 100 WHERE id = 100500;\n")
+	compressed, err := io.ReadAll(CompressAndEncrypt(bytes.NewReader(content), uploader.Compressor, nil))
+	require.NoError(t, err)
+	decompressed, err := compression.FindDecompressor(zstdcompression.FileExtension).Decompress(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	actual, err := io.ReadAll(decompressed)
+	require.NoError(t, err)
+	assert.Equal(t, content, actual)
+}
+
+func TestDecompressDecryptBytes_LoadsZstdDictionaryFromReadStorage(t *testing.T) {
+	primary := memory.NewFolder("", memory.NewKVS())
+	failover := memory.NewFolder("", memory.NewKVS())
+	uploader := NewRegularUploader(zstdcompression.Compressor{}, failover.GetSubFolder(utility.WalPath))
+	uploadZstdDictionaryTestSegments(t, uploader)
+	_, err := TrainZstdDictionary(t.Context(), failover, utility.WalPath, 64, 1<<20, 4096)
+	require.NoError(t, err)
+	viper.Set(conf.ZstdDictionarySetting, LatestZstdDictionary)
+	defer viper.Set(conf.ZstdDictionarySetting, "")
+	require.NoError(t, ConfigureZstdDictionary(t.Context(), failover, uploader))
+	content := []byte("UPDATE accounts SET balance = 100 WHERE id = 100500;\n")
+	require.NoError(t, uploader.Upload(t.Context(), "latest.zst", CompressAndEncrypt(bytes.NewReader(content), uploader.Compressor, nil)))
+
+	setZstdDictionaryFolder(consts.DefaultStorage, primary)
+	setZstdDictionaryFolder("failover", failover)
+	defer zstdcompression.SetDictionaryLoader(nil)
+	defer zstdDictionaryFolders.Delete("failover")
+
+	mockCtrl := gomock.NewController(t)
+	collectorMock := stats.NewMockCollector(mockCtrl)
+	collectorMock.EXPECT().AllAliveStorages(gomock.Any()).Return([]string{consts.DefaultStorage, "failover"}, nil)
+	collectorMock.EXPECT().ReportOperationResult(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	multiFolder := multistorage.NewFolder(map[string]storage.Folder{
+		consts.DefaultStorage: primary,
+		"failover":            failover,
+	}, collectorMock)
+	multiFolder, err = multistorage.UseAllAliveStorages(t.Context(), multiFolder)
+	require.NoError(t, err)
+
+	// the dictionary is only in the failover storage the object is read from
+	archiveReader, err := multiFolder.GetSubFolder(utility.WalPath).ReadObject(t.Context(), "latest.zst")
+	require.NoError(t, err)
+	decompressed, err := DecompressDecryptBytes(archiveReader, zstdcompression.Decompressor{})
+	require.NoError(t, err)
+	actual, err := io.ReadAll(decompressed)
+	require.NoError(t, err)
+	assert.Equal(t, content, actual)
+}
@@ -15,6 +15,7 @@ import (
 	reflect "reflect"
 
 	internal "github.com/wal-g/wal-g/internal"
+	checksum "github.com/wal-g/wal-g/internal/checksum"
 	compression "github.com/wal-g/wal-g/internal/compression"
 	ioextensions "github.com/wal-g/wal-g/internal/ioextensions"
 	storage "github.com/wal-g/wal-g/pkg/storages/storage"
@@ -57,6 +58,20 @@ func (mr *MockUploaderMockRecorder) ChangeDirectory(relativePath any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeDirectory", reflect.TypeOf((*MockUploader)(nil).ChangeDirectory), relativePath)
 }
 
+// Checksums mocks base method.
+func (m *MockUploader) Checksums() *checksum.Recorder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checksums")
+	ret0, _ := ret[0].(*checksum.Recorder)
+	return ret0
+}
+
+// Checksums indicates an expected call of Checksums.
+func (mr *MockUploaderMockRecorder) Checksums() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checksums", reflect.TypeOf((*MockUploader)(nil).Checksums))
+}
+
 // Clone mocks base method.
 func (m *MockUploader) Clone() internal.Uploader {
 	m.ctrl.T.Helper()
@@ -97,6 +112,18 @@ func (mr *MockUploaderMockRecorder) DisableSizeTracking() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableSizeTracking", reflect.TypeOf((*MockUploader)(nil).DisableSizeTracking))
 }
 
+// EnableChecksums mocks base method.
+func (m *MockUploader) EnableChecksums() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "EnableChecksums")
+}
+
+// EnableChecksums indicates an expected call of EnableChecksums.
+func (mr *MockUploaderMockRecorder) EnableChecksums() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableChecksums", reflect.TypeOf((*MockUploader)(nil).EnableChecksums))
+}
+
 // Failed mocks base method.
 func (m *MockUploader) Failed() bool {
 	m.ctrl.T.Helper()
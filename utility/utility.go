@@ -13,6 +13,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -62,6 +63,7 @@ const (
 	CatchupPath      = "catchup_" + VersionStr + "/"
 	WalPath          = "wal_" + VersionStr + "/"
 	SegmentsPath     = "segments_" + VersionStr
+	ZstdDictsPath    = "zstd_dictionaries_" + VersionStr + "/"
 	BackupNamePrefix = "base_"
 	BackupTimeFormat = "20060102T150405Z" // timestamps in that format should be lexicographically sorted
 
@@ -417,6 +419,19 @@ func ParseUntilTS(untilTS string) (time.Time, error) {
 	return MaxTime, nil
 }
 
+// ParseDurationWithDays parses a duration like time.ParseDuration does
+// and additionally accepts a whole number of days, e.g. "14d"
+func ParseDurationWithDays(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
 // MarshalEnumToString is used to write the string enum representation
 // instead of int enum value to JSON
 func MarshalEnumToString(enum fmt.Stringer) ([]byte, error) {
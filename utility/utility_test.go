@@ -551,3 +551,16 @@ func TestLoggedCloseWithErrorAndCustomMessage(t *testing.T) {
 
 	assert.Equal(t, "custom error message: mock close: close error\n", string(loggedData))
 }
+
+func TestParseDurationWithDays(t *testing.T) {
+	duration, err := utility.ParseDurationWithDays("14d")
+	assert.NoError(t, err)
+	assert.Equal(t, 14*24*time.Hour, duration)
+
+	duration, err = utility.ParseDurationWithDays("36h")
+	assert.NoError(t, err)
+	assert.Equal(t, 36*time.Hour, duration)
+
+	_, err = utility.ParseDurationWithDays("1.5d")
+	assert.Error(t, err)
+}